@@ -0,0 +1,106 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/lib/pq"
+
+	"github.com/vera-byte/vgo/v/vbus"
+	"github.com/vera-byte/vgo/v/vconfig"
+)
+
+// notifyPayloadLimit PostgreSQL NOTIFY 消息内容的最大字节数
+const notifyPayloadLimit = 7999
+
+// BusPgsql PostgreSQL消息总线驱动
+// 功能: 基于 LISTEN/NOTIFY 实现vbus.Driver接口, 无需额外部署Redis即可在集群节点间传递消息
+type BusPgsql struct {
+	group string
+}
+
+// NewBusPgsql 创建PostgreSQL消息总线驱动
+// 功能: 创建使用指定数据库分组的消息总线驱动
+// 参数: group string - 数据库配置分组
+// 返回值: *BusPgsql - 消息总线驱动实例
+func NewBusPgsql(group string) *BusPgsql {
+	return &BusPgsql{group: group}
+}
+
+// Name 获取驱动名称
+// 功能: 返回消息总线驱动名称
+// 返回值: string - 驱动名称
+func (b *BusPgsql) Name() string {
+	return "pgsql"
+}
+
+// Publish 发布消息
+// 功能: 通过 pg_notify 向频道发布消息
+// 参数:
+//   - ctx context.Context - 上下文
+//   - channel string - 频道名称
+//   - payload string - 消息内容
+//
+// 返回值: error - 错误信息
+func (b *BusPgsql) Publish(ctx context.Context, channel string, payload string) error {
+	if len(payload) > notifyPayloadLimit {
+		return gerror.Newf("消息内容超过PostgreSQL NOTIFY限制(%d字节)", notifyPayloadLimit)
+	}
+	_, err := g.DB(b.group).Exec(ctx, "SELECT pg_notify(?, ?)", channel, payload)
+	return err
+}
+
+// Subscribe 订阅频道
+// 功能: 使用独立连接 LISTEN 频道, 断线后自动重连, 阻塞直到ctx结束
+// 参数:
+//   - ctx context.Context - 上下文
+//   - channel string - 频道名称
+//   - handler func(payload string) - 消息处理函数
+//
+// 返回值: error - 错误信息
+func (b *BusPgsql) Subscribe(ctx context.Context, channel string, handler func(payload string)) error {
+	config := g.DB(b.group).GetConfig()
+	if config == nil {
+		return gerror.Newf("数据库分组 %s 未配置", b.group)
+	}
+	source, err := buildSource(config)
+	if err != nil {
+		return err
+	}
+	listener := pq.NewListener(source, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			g.Log().Error(ctx, "PostgreSQL消息总线连接异常", err)
+		}
+	})
+	defer listener.Close()
+	if err = listener.Listen(channel); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(90 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			// 重连后会收到nil, 期间的消息已丢失
+			if n != nil {
+				handler(n.Extra)
+			}
+		case <-ticker.C:
+			// 定期检查连接, 连接断开时触发重连
+			go listener.Ping()
+		}
+	}
+}
+
+// init 初始化函数
+// 功能: 注册PostgreSQL消息总线驱动
+func init() {
+	if err := vbus.Register("pgsql", NewBusPgsql(vconfig.Config.Bus.Group)); err != nil {
+		panic(fmt.Sprintf("注册PostgreSQL消息总线驱动失败: %v", err))
+	}
+}
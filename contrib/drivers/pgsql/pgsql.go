@@ -55,15 +55,13 @@ func (ns ExactNamingStrategy) TableName(table string) string {
 func (ns ExactNamingStrategy) ColumnName(table, column string) string {
 	return column
 }
-// GetConn 获取数据库连接
-// 功能: 根据配置节点创建PostgreSQL数据库连接
+// buildSource 构建连接字符串
+// 功能: 根据配置节点构建PostgreSQL连接字符串, 供GORM连接与消息总线监听使用
 // 参数: config *gdb.ConfigNode - 数据库配置节点
-// 返回值: 
-//   - db *gorm.DB - GORM数据库连接实例
+// 返回值:
+//   - source string - 连接字符串
 //   - err error - 错误信息
-func (d *DriverPgsql) GetConn(config *gdb.ConfigNode) (db *gorm.DB, err error) {
-	var source string
-
+func buildSource(config *gdb.ConfigNode) (source string, err error) {
 	// 处理连接字符串配置
 	if config.Link != "" {
 		// ============================================================================
@@ -97,13 +95,27 @@ func (d *DriverPgsql) GetConn(config *gdb.ConfigNode) (db *gorm.DB, err error) {
 		if config.Extra != "" {
 			var extraMap map[string]interface{}
 			if extraMap, err = gstr.Parse(config.Extra); err != nil {
-				return nil, fmt.Errorf("解析额外配置参数失败: %w", err)
+				return "", fmt.Errorf("解析额外配置参数失败: %w", err)
 			}
 			for k, v := range extraMap {
 				source += fmt.Sprintf(` %s=%s`, k, v)
 			}
 		}
 	}
+	return source, nil
+}
+
+// GetConn 获取数据库连接
+// 功能: 根据配置节点创建PostgreSQL数据库连接
+// 参数: config *gdb.ConfigNode - 数据库配置节点
+// 返回值: 
+//   - db *gorm.DB - GORM数据库连接实例
+//   - err error - 错误信息
+func (d *DriverPgsql) GetConn(config *gdb.ConfigNode) (db *gorm.DB, err error) {
+	source, err := buildSource(config)
+	if err != nil {
+		return nil, err
+	}
 
 	// 创建GORM配置
 	gormConfig := &gorm.Config{
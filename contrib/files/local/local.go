@@ -2,40 +2,349 @@
 package local
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/gogf/gf/v2/os/gfile"
-	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/grand"
 	"github.com/vera-byte/vgo/v"
 	"github.com/vera-byte/vgo/v/vfile"
 )
 
+const (
+	// root 本地文件根目录, 文件键为相对该目录的路径, 如 uploads/20060102/name.png
+	root = "./public"
+	// partRoot 分片上传的临时目录, 每个上传使用一个以上传ID命名的子目录
+	partRoot = "./temp/multipart"
+	// sweepInterval 清理过期分片目录的最小间隔
+	sweepInterval = 10 * time.Minute
+)
+
 type Local struct {
 }
 
+var (
+	_ vfile.Storage   = (*Local)(nil)
+	_ vfile.Multipart = (*Local)(nil)
+)
+
 func (l *Local) Upload(ctx g.Ctx) (string, error) {
-	var (
-		err     error
-		Request = g.RequestFromCtx(ctx)
-	)
+	return vfile.SaveUpload(ctx, l, "file")
+}
+
+// Put 保存文件, 先写入同目录的临时文件再重命名, 避免读取到不完整的文件
+func (l *Local) Put(ctx g.Ctx, key string, reader io.Reader, size int64, contentType string) error {
+	filePath, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	return writeFile(filePath, reader)
+}
+
+// Delete 删除文件
+func (l *Local) Delete(ctx g.Ctx, key string) error {
+	filePath, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(filePath); os.IsNotExist(err) {
+		return vfile.ErrNotFound
+	}
+	return err
+}
+
+// Stat 获取文件信息
+func (l *Local) Stat(ctx g.Ctx, key string) (*vfile.ObjectInfo, error) {
+	filePath, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) || (err == nil && info.IsDir()) {
+		return nil, vfile.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l.objectInfo(key, info), nil
+}
+
+// Open 读取文件内容
+func (l *Local) Open(ctx g.Ctx, key string) (io.ReadCloser, *vfile.ObjectInfo, error) {
+	info, err := l.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	filePath, _ := l.path(key)
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, info, nil
+}
+
+// List 按前缀列出文件
+func (l *Local) List(ctx g.Ctx, prefix string) ([]*vfile.ObjectInfo, error) {
+	// 前缀可以是目录或文件名的一部分, 从前缀所在的目录开始遍历
+	dir := prefix
+	if !strings.HasSuffix(prefix, "/") {
+		dir = path.Dir(prefix)
+	}
+	dirPath, err := l.path(dir)
+	if err != nil {
+		dirPath = root
+	}
+	var infos []*vfile.ObjectInfo
+	err = filepath.WalkDir(dirPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		infos = append(infos, l.objectInfo(key, info))
+		return nil
+	})
+	return infos, err
+}
+
+// SignedURL 返回文件访问地址
+// 本地文件通过静态目录公开访问, 不需要签名, 有效期不生效
+func (l *Local) SignedURL(ctx g.Ctx, key string, expire time.Duration) (string, error) {
+	if _, err := l.Stat(ctx, key); err != nil {
+		return "", err
+	}
+	return l.URL(key), nil
+}
+
+// URL 返回文件键对应的访问地址
+func (l *Local) URL(key string) string {
+	return l.prefix() + strings.TrimPrefix(path.Clean("/"+key), "/")
+}
+
+// Key 返回访问地址对应的文件键
+func (l *Local) Key(url string) (string, bool) {
+	if !strings.HasPrefix(url, l.prefix()) {
+		return "", false
+	}
+	return strings.TrimPrefix(url, l.prefix()), true
+}
+
+// prefix 本地文件的访问地址前缀
+func (l *Local) prefix() string {
+	return v.ConfigBinding.Get().File.Domain + "/public/"
+}
+
+// InitMultipart 开始分片上传, 分片保存在临时目录中
+func (l *Local) InitMultipart(ctx g.Ctx, key, contentType string) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	sweepParts(ctx)
+	uploadId := grand.S(32)
+	if err := os.MkdirAll(filepath.Join(partRoot, uploadId), 0755); err != nil {
+		return "", err
+	}
+	return uploadId, nil
+}
+
+// lastSweep 上一次清理分片临时目录的时间
+var lastSweep atomic.Int64
 
-	file := Request.GetUploadFile("file")
-	if file == nil {
-		return "", gerror.New("上传文件为空")
+// sweepParts 删除超过分片上传有效期未更新的分片目录, 上传会话过期后这些目录不会再被使用
+// 每次开始分片上传时调用, 最多每 sweepInterval 清理一次
+func sweepParts(ctx g.Ctx) {
+	now := time.Now()
+	last := lastSweep.Load()
+	if now.Sub(time.Unix(0, last)) < sweepInterval || !lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	expire, err := time.ParseDuration(v.ConfigBinding.Get().File.Multipart.Expire)
+	if err != nil || expire <= 0 {
+		expire = 24 * time.Hour
+	}
+	entries, err := os.ReadDir(partRoot)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.IsDir() || now.Sub(info.ModTime()) < expire {
+			continue
+		}
+		if err = os.RemoveAll(filepath.Join(partRoot, entry.Name())); err != nil {
+			g.Log().Warningf(ctx, "删除过期的分片目录 %s 失败: %v", entry.Name(), err)
+		}
 	}
-	// 以当前年月日为目录
-	dir := gtime.Now().Format("Ymd")
+}
 
-	fileName, err := file.Save("./public/uploads/"+dir, true)
+// UploadPart 上传分片, 分片写入完成后才可见, 重复上传时覆盖
+func (l *Local) UploadPart(ctx g.Ctx, key, uploadId string, number int, reader io.Reader, size int64) (string, error) {
+	dir, err := l.partDir(uploadId)
+	if err != nil {
+		return "", err
+	}
+	temp, err := os.CreateTemp(dir, "part-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(temp.Name())
+	hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(temp, hash), reader)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return "", err
 	}
-	return v.Config.File.Domain + "/public/uploads/" + dir + "/" + fileName, err
+	if written != size {
+		return "", gerror.Newf("分片大小不一致: %d != %d", written, size)
+	}
+	if err = os.Rename(temp.Name(), filepath.Join(dir, strconv.Itoa(number))); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// CompleteMultipart 按序号拼接分片为目标文件并删除临时目录
+func (l *Local) CompleteMultipart(ctx g.Ctx, key, uploadId string, parts []*vfile.Part) error {
+	dir, err := l.partDir(uploadId)
+	if err != nil {
+		return err
+	}
+	filePath, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	// 先写入同目录的临时文件, 拼接完成后重命名, 避免读取到不完整的文件
+	temp, err := os.CreateTemp(filepath.Dir(filePath), ".multipart-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	for _, part := range parts {
+		if err = l.appendPart(temp, filepath.Join(dir, strconv.Itoa(part.Number))); err != nil {
+			temp.Close()
+			return err
+		}
+	}
+	if err = temp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(temp.Name(), filePath); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// AbortMultipart 取消分片上传并删除临时目录
+func (l *Local) AbortMultipart(ctx g.Ctx, key, uploadId string) error {
+	dir, err := l.partDir(uploadId)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// writeFile 写入文件, 先写入同目录的临时文件再重命名
+func writeFile(filePath string, reader io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	_, err = io.Copy(temp, reader)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), filePath)
+}
+
+// appendPart 将分片内容追加到文件
+func (l *Local) appendPart(w io.Writer, partPath string) error {
+	part, err := os.Open(partPath)
+	if os.IsNotExist(err) {
+		return gerror.Newf("分片不存在: %s", filepath.Base(partPath))
+	}
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+	_, err = io.Copy(w, part)
+	return err
+}
+
+// partDir 返回上传ID对应的分片目录, 上传不存在时返回 vfile.ErrNotFound
+func (l *Local) partDir(uploadId string) (string, error) {
+	if uploadId == "" || filepath.Base(uploadId) != uploadId || strings.HasPrefix(uploadId, ".") {
+		return "", gerror.Newf("上传ID无效: %s", uploadId)
+	}
+	dir := filepath.Join(partRoot, uploadId)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", vfile.ErrNotFound
+	}
+	return dir, nil
+}
+
+// path 将文件键转换为本地路径, 文件键不能访问根目录之外的文件
+func (l *Local) path(key string) (string, error) {
+	clean := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if clean == "" {
+		return "", gerror.New("文件键不能为空")
+	}
+	return filepath.Join(root, filepath.FromSlash(clean)), nil
+}
+
+// objectInfo 转换本地文件信息
+func (l *Local) objectInfo(key string, info fs.FileInfo) *vfile.ObjectInfo {
+	return &vfile.ObjectInfo{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  mime.TypeByExtension(path.Ext(key)),
+		LastModified: info.ModTime(),
+	}
 }
 
 func (l *Local) GetMode() (data interface{}, err error) {
 	data = g.MapStrStr{
-		"mode": v.Config.File.Mode,
+		"mode": v.ConfigBinding.Get().File.Mode,
 		"type": "local",
 	}
 	return
@@ -68,4 +377,5 @@ func init() {
 		}
 	}
 	s.AddStaticPath("/public", "./public")
+	s.BindHookHandler("/public/*", ghttp.HookBeforeServe, serveResized)
 }
@@ -0,0 +1,223 @@
+package local
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// serveResized 访问本地图片时按参数 w h fit format q 缩放, 缩放结果缓存在磁盘中
+// 未开启、没有配置允许的尺寸或没有缩放参数时交给静态文件服务处理
+func serveResized(r *ghttp.Request) {
+	image := v.ConfigBinding.Get().File.Image
+	if image == nil || image.Resize == nil || !image.Resize.Enable || len(image.Resize.Sizes) == 0 {
+		return
+	}
+	query := r.URL.Query()
+	if query.Get("w") == "" && query.Get("h") == "" {
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/public/")
+	format := vfile.ImageFormat(mime.TypeByExtension(path.Ext(key)))
+	if format == "" {
+		return
+	}
+	filePath, err := (&Local{}).path(key)
+	if err != nil {
+		return
+	}
+	info, err := os.Stat(filePath)
+	if err != nil || info.IsDir() {
+		return
+	}
+
+	options, err := resizeOptions(r)
+	if err != nil {
+		// 钩子中需要 ExitAll, 否则仍会继续返回原图
+		r.Response.WriteStatus(http.StatusBadRequest, err.Error())
+		r.ExitAll()
+	}
+	ext := path.Ext(key)
+	if options.Format != "" {
+		ext = "." + options.Format
+	}
+	// 原文件变化后缓存失效
+	sum := md5.Sum([]byte(fmt.Sprintf("%s|%d|%d|%+v", key, info.Size(), info.ModTime().UnixNano(), *options)))
+	name := hex.EncodeToString(sum[:])
+	cachePath := filepath.Join(image.Resize.CacheDir, name[:2], name+ext)
+	if _, err = os.Stat(cachePath); err != nil {
+		sweepCache(r.Context())
+		if err = resizeFile(filePath, cachePath, options); err != nil {
+			r.Response.WriteStatus(http.StatusInternalServerError, err.Error())
+			r.ExitAll()
+		}
+	} else {
+		// 修改时间记录最后访问时间, 清理缓存时按该时间判断
+		now := time.Now()
+		_ = os.Chtimes(cachePath, now, now)
+	}
+	r.Response.ServeFile(cachePath)
+	r.ExitAll()
+}
+
+// resizeOptions 解析并校验缩放参数
+func resizeOptions(r *ghttp.Request) (*vfile.ImageOptions, error) {
+	resize := v.ConfigBinding.Get().File.Image.Resize
+	query := r.URL.Query()
+	options := &vfile.ImageOptions{Fit: query.Get("fit"), Format: query.Get("format")}
+	var err error
+	if options.Width, err = resizeParam(query.Get("w"), resize.MaxWidth); err != nil {
+		return nil, err
+	}
+	if options.Height, err = resizeParam(query.Get("h"), resize.MaxHeight); err != nil {
+		return nil, err
+	}
+	if options.Quality, err = resizeParam(query.Get("q"), 100); err != nil {
+		return nil, err
+	}
+	if options.Width+options.Height == 0 {
+		return nil, gerror.New("图片尺寸错误")
+	}
+	size := strconv.Itoa(options.Width) + "x" + strconv.Itoa(options.Height)
+	allowed := false
+	for _, s := range resize.Sizes {
+		if strings.EqualFold(strings.TrimSpace(s), size) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, gerror.Newf("不支持的图片尺寸: %s", size)
+	}
+	if options.Quality > 0 && !slices.Contains(resize.Qualities, options.Quality) {
+		return nil, gerror.Newf("不支持的压缩质量: %d", options.Quality)
+	}
+	switch options.Fit {
+	case "", vfile.FitContain, vfile.FitCover, vfile.FitFill:
+	default:
+		return nil, gerror.Newf("不支持的缩放方式: %s", options.Fit)
+	}
+	switch options.Format {
+	case "", "jpeg", "png", "webp":
+	default:
+		return nil, gerror.Newf("不支持的图片格式: %s", options.Format)
+	}
+	return options, nil
+}
+
+// resizeParam 解析数值参数, 为空时为0, 不能超过 max
+func resizeParam(value string, max int) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || (max > 0 && n > max) {
+		return 0, gerror.Newf("参数错误: %s", value)
+	}
+	return n, nil
+}
+
+// resizeFile 缩放图片并写入缓存文件
+func resizeFile(filePath, cachePath string, options *vfile.ImageOptions) error {
+	src, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err = os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(cachePath), ".resize-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	_, err = vfile.ProcessImage(temp, src, options)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), cachePath)
+}
+
+// lastCacheSweep 上一次清理缩放缓存的时间
+var lastCacheSweep atomic.Int64
+
+// sweepCache 删除超过 cacheTTL 未被访问的缓存文件, 总大小超过 cacheMaxSize 时从最久未访问的文件开始删除
+// 每次生成缓存文件前调用, 最多每 sweepInterval 清理一次
+func sweepCache(ctx g.Ctx) {
+	now := time.Now()
+	last := lastCacheSweep.Load()
+	if now.Sub(time.Unix(0, last)) < sweepInterval || !lastCacheSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	resize := v.ConfigBinding.Get().File.Image.Resize
+	ttl, err := time.ParseDuration(resize.CacheTTL)
+	if err != nil || ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+	type cacheFile struct {
+		path string
+		info fs.FileInfo
+	}
+	var (
+		files []cacheFile
+		total int64
+	)
+	_ = filepath.WalkDir(resize.CacheDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if now.Sub(info.ModTime()) >= ttl {
+			removeCache(ctx, filePath)
+			return nil
+		}
+		files = append(files, cacheFile{path: filePath, info: info})
+		total += info.Size()
+		return nil
+	})
+	if resize.CacheMaxSize <= 0 || total <= resize.CacheMaxSize {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].info.ModTime().Before(files[j].info.ModTime())
+	})
+	for _, file := range files {
+		if total <= resize.CacheMaxSize {
+			break
+		}
+		removeCache(ctx, file.path)
+		total -= file.info.Size()
+	}
+}
+
+// removeCache 删除缓存文件, 失败时记录日志
+func removeCache(ctx g.Ctx, filePath string) {
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		g.Log().Warningf(ctx, "删除图片缓存 %s 失败: %v", filePath, err)
+	}
+}
@@ -0,0 +1,102 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+	"github.com/vera-byte/vgo/v"
+)
+
+// TestServeResized 测试访问时缩放的参数白名单和缓存清理
+func TestServeResized(t *testing.T) {
+	ctx := context.Background()
+	resize := v.ConfigBinding.Get().File.Image.Resize
+	original := *resize
+	defer func() { *resize = original }()
+	resize.Enable = true
+	resize.CacheDir = t.TempDir()
+	resize.CacheTTL = "24h"
+	resize.CacheMaxSize = 0
+	resize.Sizes = nil
+	resize.Qualities = []int{75}
+
+	dir := "uploads/" + guid.S()
+	defer os.RemoveAll(filepath.Join(root, dir))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 300))); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(filepath.Join(root, dir, "a.png"), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	s := g.Server(guid.S())
+	s.AddStaticPath("/public", root)
+	s.BindHookHandler("/public/*", ghttp.HookBeforeServe, serveResized)
+	s.SetDumpRouterMap(false)
+	s.SetAccessLogEnabled(false)
+	s.SetPort(0)
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		url := fmt.Sprintf("http://127.0.0.1:%d/public/%s/a.png", s.GetListenedPort(), dir)
+		get := func(query string) (int, image.Point) {
+			res, err := g.Client().Get(ctx, url+query)
+			t.AssertNil(err)
+			defer res.Close()
+			img, _, err := image.Decode(bytes.NewReader(res.ReadAll()))
+			if err != nil {
+				return res.StatusCode, image.Point{}
+			}
+			return res.StatusCode, img.Bounds().Size()
+		}
+
+		// 没有配置允许的尺寸时返回原图
+		status, size := get("?w=100&h=100")
+		t.Assert(status, 200)
+		t.Assert(size, image.Pt(400, 300))
+
+		resize.Sizes = []string{"100x100"}
+		status, _ = get("?w=50&h=50")
+		t.Assert(status, 400)
+		status, _ = get("?w=100&h=100&q=90")
+		t.Assert(status, 400)
+
+		// 生成缓存前删除过期和超出大小的缓存文件
+		resize.CacheMaxSize = 15
+		expired := filepath.Join(resize.CacheDir, "00", "expired.png")
+		older := filepath.Join(resize.CacheDir, "00", "older.png")
+		newer := filepath.Join(resize.CacheDir, "00", "newer.png")
+		for i, file := range []string{expired, older, newer} {
+			t.AssertNil(writeFile(file, bytes.NewReader([]byte("0123456789"))))
+			modTime := time.Now().Add(-time.Duration(3-i) * time.Hour)
+			if file == expired {
+				modTime = time.Now().Add(-48 * time.Hour)
+			}
+			t.AssertNil(os.Chtimes(file, modTime, modTime))
+		}
+		lastCacheSweep.Store(0)
+		status, size = get("?w=100&h=100&q=75")
+		t.Assert(status, 200)
+		t.Assert(size, image.Pt(100, 75))
+		for file, exists := range map[string]bool{expired: false, older: false, newer: true} {
+			_, err := os.Stat(file)
+			t.Assert(err == nil, exists)
+		}
+	})
+}
@@ -3,11 +3,11 @@ package minio
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
-	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
-	"github.com/gogf/gf/v2/os/gtime"
-	"github.com/gogf/gf/v2/util/grand"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/vera-byte/vgo/v"
@@ -24,6 +24,12 @@ type Minio struct {
 	BucketName string
 }
 
+var (
+	_ vfile.Storage   = (*Minio)(nil)
+	_ vfile.Presigner = (*Minio)(nil)
+	_ vfile.Multipart = (*Minio)(nil)
+)
+
 func (m *Minio) New() vfile.Driver {
 	g.Log().Debug(ctx, m, m.BucketName)
 	return m
@@ -38,51 +44,197 @@ func (m *Minio) GetMode() (data interface{}, err error) {
 }
 
 func (m *Minio) Upload(ctx g.Ctx) (string, error) {
-	g.Log().Debug(ctx, m)
+	return vfile.SaveUpload(ctx, m, "file")
+}
 
-	var (
-		err     error
-		Request = g.RequestFromCtx(ctx)
-	)
+// Put 保存文件
+func (m *Minio) Put(ctx g.Ctx, key string, reader io.Reader, size int64, contentType string) error {
+	_, err := m.Client.PutObject(ctx, m.BucketName, key, reader, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
 
-	file := Request.GetUploadFile("file")
-	if file == nil {
-		return "", gerror.New("上传文件为空")
+// Delete 删除文件
+func (m *Minio) Delete(ctx g.Ctx, key string) error {
+	if _, err := m.Stat(ctx, key); err != nil {
+		return err
 	}
+	return m.Client.RemoveObject(ctx, m.BucketName, key, minio.RemoveObjectOptions{})
+}
 
-	src, err := file.Open()
+// Stat 获取文件信息
+func (m *Minio) Stat(ctx g.Ctx, key string) (*vfile.ObjectInfo, error) {
+	info, err := m.Client.StatObject(ctx, m.BucketName, key, minio.StatObjectOptions{})
 	if err != nil {
-		g.Log().Error(ctx, "文件打开失败")
+		return nil, convertError(err)
 	}
-	defer src.Close()
+	return objectInfo(info), nil
+}
 
-	// 以当前年月日为目录
-	dir := gtime.Now().Format("Ymd")
-	fileName := Request.Get("key", grand.S(16, false)).String()
-	fullPath := fmt.Sprintf("uploads/%s/%s", dir, fileName)
+// Open 读取文件内容
+func (m *Minio) Open(ctx g.Ctx, key string) (io.ReadCloser, *vfile.ObjectInfo, error) {
+	object, err := m.Client.GetObject(ctx, m.BucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, convertError(err)
+	}
+	// GetObject 不会发起请求, 通过 Stat 确认文件存在
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, nil, convertError(err)
+	}
+	return object, objectInfo(info), nil
+}
 
-	g.Log().Debug(ctx, fullPath)
-	// 创建目录
-	info, err := m.Client.PutObject(ctx, m.BucketName, fullPath, src, -1, minio.PutObjectOptions{})
+// List 按前缀列出文件
+func (m *Minio) List(ctx g.Ctx, prefix string) ([]*vfile.ObjectInfo, error) {
+	var infos []*vfile.ObjectInfo
+	for object := range m.Client.ListObjects(ctx, m.BucketName, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		infos = append(infos, objectInfo(object))
+	}
+	return infos, nil
+}
 
-	g.Log().Debug(ctx, info)
+// SignedURL 生成限时访问地址
+func (m *Minio) SignedURL(ctx g.Ctx, key string, expire time.Duration) (string, error) {
+	u, err := m.Client.PresignedGetObject(ctx, m.BucketName, key, expire, nil)
 	if err != nil {
-		return "上传失败", err
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// PresignUpload 生成浏览器直传的POST表单凭证
+func (m *Minio) PresignUpload(ctx g.Ctx, key string, options *vfile.PresignOptions) (*vfile.PresignedUpload, error) {
+	expireTime := time.Now().Add(options.Expire)
+	policy := minio.NewPostPolicy()
+	if err := policy.SetBucket(m.BucketName); err != nil {
+		return nil, err
+	}
+	if err := policy.SetKey(key); err != nil {
+		return nil, err
+	}
+	if err := policy.SetExpires(expireTime); err != nil {
+		return nil, err
+	}
+	if options.MaxSize > 0 {
+		if err := policy.SetContentLengthRange(1, options.MaxSize); err != nil {
+			return nil, err
+		}
+	}
+	if options.ContentType != "" {
+		var err error
+		if strings.HasSuffix(options.ContentType, "/") {
+			err = policy.SetContentTypeStartsWith(options.ContentType)
+		} else {
+			err = policy.SetContentType(options.ContentType)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	u, fields, err := m.Client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	return &vfile.PresignedUpload{
+		Method:      "POST",
+		URL:         u.String(),
+		Key:         key,
+		Fields:      fields,
+		ExpireTime:  expireTime,
+		MaxSize:     options.MaxSize,
+		ContentType: options.ContentType,
+		FileURL:     m.URL(key),
+	}, nil
+}
+
+// InitMultipart 开始分片上传
+func (m *Minio) InitMultipart(ctx g.Ctx, key, contentType string) (string, error) {
+	core := minio.Core{Client: m.Client}
+	return core.NewMultipartUpload(ctx, m.BucketName, key, minio.PutObjectOptions{ContentType: contentType})
+}
+
+// UploadPart 上传分片
+func (m *Minio) UploadPart(ctx g.Ctx, key, uploadId string, number int, reader io.Reader, size int64) (string, error) {
+	core := minio.Core{Client: m.Client}
+	part, err := core.PutObjectPart(ctx, m.BucketName, key, uploadId, number, reader, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return "", err
+	}
+	return part.ETag, nil
+}
+
+// CompleteMultipart 合并分片
+func (m *Minio) CompleteMultipart(ctx g.Ctx, key, uploadId string, parts []*vfile.Part) error {
+	core := minio.Core{Client: m.Client}
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: part.Number, ETag: part.ETag})
+	}
+	_, err := core.CompleteMultipartUpload(ctx, m.BucketName, key, uploadId, completeParts, minio.PutObjectOptions{})
+	return err
+}
+
+// AbortMultipart 取消分片上传
+func (m *Minio) AbortMultipart(ctx g.Ctx, key, uploadId string) error {
+	core := minio.Core{Client: m.Client}
+	return core.AbortMultipartUpload(ctx, m.BucketName, key, uploadId)
+}
+
+// URL 返回文件键对应的访问地址
+func (m *Minio) URL(key string) string {
+	return m.prefix() + key
+}
+
+// Key 返回访问地址对应的文件键
+func (m *Minio) Key(url string) (string, bool) {
+	if !strings.HasPrefix(url, m.prefix()) {
+		return "", false
+	}
+	return strings.TrimPrefix(url, m.prefix()), true
+}
+
+// prefix 存储桶中文件的访问地址前缀
+func (m *Minio) prefix() string {
+	return fmt.Sprintf("%s/%s/", m.Client.EndpointURL().String(), m.BucketName)
+}
+
+// objectInfo 转换minio文件信息
+func objectInfo(info minio.ObjectInfo) *vfile.ObjectInfo {
+	return &vfile.ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+	}
+}
+
+// convertError 文件不存在时返回 vfile.ErrNotFound
+func convertError(err error) error {
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return vfile.ErrNotFound
 	}
-	return info.Location, nil
+	return err
 }
 
 func New() vfile.Driver {
 	ctx := context.Background()
-	if v.Config.File.Mode != "minio" {
+	file := v.ConfigBinding.Get().File
+	if file.Mode != "minio" {
 		return nil
 	}
-	endpoint := v.Config.File.Oss.Endpoint
-	accessKeyID := v.Config.File.Oss.AccessKeyID
-	secretAccessKey := v.Config.File.Oss.SecretAccessKey
-	useSSL := v.Config.File.Oss.UseSSL
-	bucketName := v.Config.File.Oss.BucketName
-	location := v.Config.File.Oss.Location
+	endpoint := file.Oss.Endpoint
+	accessKeyID := file.Oss.AccessKeyID
+	secretAccessKey := file.Oss.SecretAccessKey
+	useSSL := file.Oss.UseSSL
+	bucketName := file.Oss.BucketName
+	location := file.Oss.Location
 	// Initialize minio client object.
 	client, err := minio.New(endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
@@ -2,13 +2,19 @@ package oss
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
-	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
-	"github.com/gogf/gf/v2/os/gtime"
-	"github.com/gogf/gf/v2/util/grand"
+	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/vera-byte/vgo/v"
 	"github.com/vera-byte/vgo/v/vfile"
 )
@@ -23,6 +29,12 @@ type Oss struct {
 	Bucket *oss.Bucket
 }
 
+var (
+	_ vfile.Storage   = (*Oss)(nil)
+	_ vfile.Presigner = (*Oss)(nil)
+	_ vfile.Multipart = (*Oss)(nil)
+)
+
 func (m *Oss) New() vfile.Driver {
 	return m
 }
@@ -36,48 +48,215 @@ func (m *Oss) GetMode() (data interface{}, err error) {
 }
 
 func (m *Oss) Upload(ctx g.Ctx) (string, error) {
+	return vfile.SaveUpload(ctx, m, "file")
+}
+
+// Put 保存文件
+func (m *Oss) Put(ctx g.Ctx, key string, reader io.Reader, size int64, contentType string) error {
+	options := []oss.Option{oss.WithContext(ctx)}
+	if contentType != "" {
+		options = append(options, oss.ContentType(contentType))
+	}
+	return m.Bucket.PutObject(key, reader, options...)
+}
+
+// Delete 删除文件
+func (m *Oss) Delete(ctx g.Ctx, key string) error {
+	// OSS删除不存在的文件不会报错, 先确认文件存在
+	if _, err := m.Stat(ctx, key); err != nil {
+		return err
+	}
+	return m.Bucket.DeleteObject(key, oss.WithContext(ctx))
+}
+
+// Stat 获取文件信息
+func (m *Oss) Stat(ctx g.Ctx, key string) (*vfile.ObjectInfo, error) {
+	header, err := m.Bucket.GetObjectDetailedMeta(key, oss.WithContext(ctx))
+	if err != nil {
+		return nil, convertError(err)
+	}
+	info := &vfile.ObjectInfo{
+		Key:         key,
+		Size:        gconv.Int64(header.Get(oss.HTTPHeaderContentLength)),
+		ContentType: header.Get(oss.HTTPHeaderContentType),
+		ETag:        header.Get(oss.HTTPHeaderEtag),
+	}
+	info.LastModified, _ = http.ParseTime(header.Get(oss.HTTPHeaderLastModified))
+	return info, nil
+}
+
+// Open 读取文件内容
+func (m *Oss) Open(ctx g.Ctx, key string) (io.ReadCloser, *vfile.ObjectInfo, error) {
+	info, err := m.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err := m.Bucket.GetObject(key, oss.WithContext(ctx))
+	if err != nil {
+		return nil, nil, convertError(err)
+	}
+	return reader, info, nil
+}
+
+// List 按前缀列出文件
+func (m *Oss) List(ctx g.Ctx, prefix string) ([]*vfile.ObjectInfo, error) {
 	var (
-		err     error
-		Request = g.RequestFromCtx(ctx)
+		infos []*vfile.ObjectInfo
+		token string
 	)
+	for {
+		result, err := m.Bucket.ListObjectsV2(oss.Prefix(prefix), oss.ContinuationToken(token), oss.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range result.Objects {
+			infos = append(infos, &vfile.ObjectInfo{
+				Key:          object.Key,
+				Size:         object.Size,
+				ETag:         object.ETag,
+				LastModified: object.LastModified,
+			})
+		}
+		if !result.IsTruncated {
+			return infos, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
 
-	file := Request.GetUploadFile("file")
-	if file == nil {
-		return "", gerror.New("上传文件为空")
+// SignedURL 生成限时访问地址
+func (m *Oss) SignedURL(ctx g.Ctx, key string, expire time.Duration) (string, error) {
+	return m.Bucket.SignURL(key, oss.HTTPGet, int64(expire.Seconds()))
+}
+
+// PresignUpload 生成浏览器直传的POST表单凭证
+func (m *Oss) PresignUpload(ctx g.Ctx, key string, options *vfile.PresignOptions) (*vfile.PresignedUpload, error) {
+	expireTime := time.Now().Add(options.Expire)
+	conditions := []interface{}{
+		g.Map{"bucket": m.Bucket.BucketName},
+		g.Map{"key": key},
+	}
+	if options.MaxSize > 0 {
+		conditions = append(conditions, g.Slice{"content-length-range", 1, options.MaxSize})
+	}
+	if options.ContentType != "" {
+		if strings.HasSuffix(options.ContentType, "/") {
+			conditions = append(conditions, g.Slice{"starts-with", "$Content-Type", options.ContentType})
+		} else {
+			conditions = append(conditions, g.Map{"Content-Type": options.ContentType})
+		}
+	}
+	policyJson, err := json.Marshal(g.Map{
+		"expiration": expireTime.UTC().Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	})
+	if err != nil {
+		return nil, err
 	}
+	credentials := m.Client.Config.GetCredentials()
+	policy := base64.StdEncoding.EncodeToString(policyJson)
+	mac := hmac.New(sha1.New, []byte(credentials.GetAccessKeySecret()))
+	mac.Write([]byte(policy))
+	fields := map[string]string{
+		"key":                   key,
+		"policy":                policy,
+		"OSSAccessKeyId":        credentials.GetAccessKeyID(),
+		"Signature":             base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		"success_action_status": "200",
+	}
+	if token := credentials.GetSecurityToken(); token != "" {
+		fields["x-oss-security-token"] = token
+	}
+	return &vfile.PresignedUpload{
+		Method:      "POST",
+		URL:         m.prefix(),
+		Key:         key,
+		Fields:      fields,
+		ExpireTime:  expireTime,
+		MaxSize:     options.MaxSize,
+		ContentType: options.ContentType,
+		FileURL:     m.URL(key),
+	}, nil
+}
 
-	src, err := file.Open()
+// InitMultipart 开始分片上传
+func (m *Oss) InitMultipart(ctx g.Ctx, key, contentType string) (string, error) {
+	options := []oss.Option{oss.WithContext(ctx)}
+	if contentType != "" {
+		options = append(options, oss.ContentType(contentType))
+	}
+	result, err := m.Bucket.InitiateMultipartUpload(key, options...)
 	if err != nil {
-		g.Log().Error(ctx, "文件打开失败")
+		return "", err
 	}
-	defer src.Close()
+	return result.UploadID, nil
+}
 
-	// 以当前年月日为目录
-	dir := gtime.Now().Format("Ymd")
-	fileName := Request.Get("key", grand.S(16, false)).String()
-	fullPath := fmt.Sprintf("uploads/%s/%s", dir, fileName)
+// UploadPart 上传分片
+func (m *Oss) UploadPart(ctx g.Ctx, key, uploadId string, number int, reader io.Reader, size int64) (string, error) {
+	part, err := m.Bucket.UploadPart(m.multipart(key, uploadId), reader, size, number, oss.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	return part.ETag, nil
+}
 
-	// 创建目录
-	err = m.Bucket.PutObject(fullPath, src)
+// CompleteMultipart 合并分片
+func (m *Oss) CompleteMultipart(ctx g.Ctx, key, uploadId string, parts []*vfile.Part) error {
+	uploadParts := make([]oss.UploadPart, 0, len(parts))
+	for _, part := range parts {
+		uploadParts = append(uploadParts, oss.UploadPart{PartNumber: part.Number, ETag: part.ETag})
+	}
+	_, err := m.Bucket.CompleteMultipartUpload(m.multipart(key, uploadId), uploadParts, oss.WithContext(ctx))
+	return err
+}
 
-	if err != nil {
-		return "上传失败", err
+// AbortMultipart 取消分片上传
+func (m *Oss) AbortMultipart(ctx g.Ctx, key, uploadId string) error {
+	return m.Bucket.AbortMultipartUpload(m.multipart(key, uploadId), oss.WithContext(ctx))
+}
+
+// multipart 构造分片上传标识
+func (m *Oss) multipart(key, uploadId string) oss.InitiateMultipartUploadResult {
+	return oss.InitiateMultipartUploadResult{Bucket: m.Bucket.BucketName, Key: key, UploadID: uploadId}
+}
+
+// URL 返回文件键对应的访问地址
+func (m *Oss) URL(key string) string {
+	return m.prefix() + key
+}
+
+// Key 返回访问地址对应的文件键
+func (m *Oss) Key(url string) (string, bool) {
+	if !strings.HasPrefix(url, m.prefix()) {
+		return "", false
 	}
+	return strings.TrimPrefix(url, m.prefix()), true
+}
 
-	url := fmt.Sprintf("https://%s.%s/%s", m.Bucket.BucketName, v.Config.File.Oss.Endpoint, fullPath)
+// prefix 存储桶中文件的访问地址前缀
+func (m *Oss) prefix() string {
+	return fmt.Sprintf("https://%s.%s/", m.Bucket.BucketName, v.ConfigBinding.Get().File.Oss.Endpoint)
+}
 
-	return url, nil
+// convertError 文件不存在时返回 vfile.ErrNotFound
+func convertError(err error) error {
+	if serviceErr, ok := err.(oss.ServiceError); ok && serviceErr.StatusCode == http.StatusNotFound {
+		return vfile.ErrNotFound
+	}
+	return err
 }
 
 func New() vfile.Driver {
 	ctx := context.Background()
-	if v.Config.File.Mode != "oss" {
+	file := v.ConfigBinding.Get().File
+	if file.Mode != "oss" {
 		return nil
 	}
-	endpoint := v.Config.File.Oss.Endpoint
-	accessKeyID := v.Config.File.Oss.AccessKeyID
-	secretAccessKey := v.Config.File.Oss.SecretAccessKey
-	bucketName := v.Config.File.Oss.BucketName
+	endpoint := file.Oss.Endpoint
+	accessKeyID := file.Oss.AccessKeyID
+	secretAccessKey := file.Oss.SecretAccessKey
+	bucketName := file.Oss.BucketName
 	// Initialize oss client object.
 	client, err := oss.New(endpoint, accessKeyID, secretAccessKey)
 	if err != nil {
@@ -0,0 +1,301 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// S3 兼容S3协议的对象存储驱动, 支持 AWS S3、MinIO、Cloudflare R2、腾讯云COS等
+type S3 struct {
+	Client     *minio.Client
+	BucketName string
+	PathStyle  bool   // 是否以路径方式访问存储桶, 否则以子域名方式访问
+	Domain     string // 访问地址前缀, 为空时使用服务端点
+}
+
+var (
+	_ vfile.Storage       = (*S3)(nil)
+	_ vfile.Presigner     = (*S3)(nil)
+	_ vfile.Multipart     = (*S3)(nil)
+	_ vfile.ProfileDriver = (*S3)(nil)
+)
+
+// defaultDriver 按 v.file.oss 创建的驱动实例, 配置变化后重新创建
+var defaultDriver struct {
+	sync.Mutex
+	profile vfile.Profile
+	driver  *S3
+}
+
+// New 按 v.file.oss 配置创建驱动, 配置错误时返回nil
+func (s *S3) New() vfile.Driver {
+	if s.Client != nil {
+		return s
+	}
+	oss := v.ConfigBinding.Get().File.Oss
+	if oss == nil {
+		return nil
+	}
+	profile := vfile.Profile{
+		Name:            "s3",
+		Driver:          "s3",
+		Endpoint:        oss.Endpoint,
+		Region:          oss.Location,
+		BucketName:      oss.BucketName,
+		AccessKeyID:     oss.AccessKeyID,
+		SecretAccessKey: oss.SecretAccessKey,
+		UseSSL:          oss.UseSSL,
+		PathStyle:       true,
+	}
+	defaultDriver.Lock()
+	defer defaultDriver.Unlock()
+	if defaultDriver.driver != nil && defaultDriver.profile == profile {
+		return defaultDriver.driver
+	}
+	d, err := s.NewProfile(&profile)
+	if err != nil {
+		g.Log().Error(context.Background(), err)
+		return nil
+	}
+	defaultDriver.profile = profile
+	defaultDriver.driver = d.(*S3)
+	return d
+}
+
+// NewProfile 按命名存储配置创建驱动, 不会发起网络请求, 存储桶需要提前创建
+func (s *S3) NewProfile(profile *vfile.Profile) (vfile.Driver, error) {
+	if profile.Endpoint == "" || profile.BucketName == "" {
+		return nil, gerror.Newf("存储配置 %s 缺少 endpoint 或 bucketName", profile.Name)
+	}
+	lookup := minio.BucketLookupDNS
+	if profile.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(profile.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(profile.AccessKeyID, profile.SecretAccessKey, ""),
+		Secure:       profile.UseSSL,
+		Region:       profile.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3{
+		Client:     client,
+		BucketName: profile.BucketName,
+		PathStyle:  profile.PathStyle,
+		Domain:     strings.TrimRight(profile.Domain, "/"),
+	}, nil
+}
+
+func (s *S3) GetMode() (data interface{}, err error) {
+	data = g.MapStrStr{
+		"mode": "local",
+		"type": "s3",
+	}
+	return
+}
+
+func (s *S3) Upload(ctx g.Ctx) (string, error) {
+	return vfile.SaveUpload(ctx, s, "file")
+}
+
+// URL 返回文件键对应的访问地址
+func (s *S3) URL(key string) string {
+	return s.prefix() + key
+}
+
+// Key 返回访问地址对应的文件键
+func (s *S3) Key(url string) (string, bool) {
+	if !strings.HasPrefix(url, s.prefix()) {
+		return "", false
+	}
+	return strings.TrimPrefix(url, s.prefix()), true
+}
+
+// prefix 存储桶中文件的访问地址前缀
+// 配置了访问域名时使用访问域名, 否则按存储桶的访问方式使用服务端点
+func (s *S3) prefix() string {
+	if s.Domain != "" {
+		return s.Domain + "/"
+	}
+	endpoint := s.Client.EndpointURL()
+	if s.PathStyle {
+		return endpoint.String() + "/" + s.BucketName + "/"
+	}
+	return endpoint.Scheme + "://" + s.BucketName + "." + endpoint.Host + "/"
+}
+
+// Put 保存文件
+func (s *S3) Put(ctx g.Ctx, key string, reader io.Reader, size int64, contentType string) error {
+	_, err := s.Client.PutObject(ctx, s.BucketName, key, reader, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+// Delete 删除文件
+func (s *S3) Delete(ctx g.Ctx, key string) error {
+	if _, err := s.Stat(ctx, key); err != nil {
+		return err
+	}
+	return s.Client.RemoveObject(ctx, s.BucketName, key, minio.RemoveObjectOptions{})
+}
+
+// Stat 获取文件信息
+func (s *S3) Stat(ctx g.Ctx, key string) (*vfile.ObjectInfo, error) {
+	info, err := s.Client.StatObject(ctx, s.BucketName, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return objectInfo(info), nil
+}
+
+// Open 读取文件内容
+func (s *S3) Open(ctx g.Ctx, key string) (io.ReadCloser, *vfile.ObjectInfo, error) {
+	object, err := s.Client.GetObject(ctx, s.BucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, convertError(err)
+	}
+	// GetObject 不会发起请求, 通过 Stat 确认文件存在
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, nil, convertError(err)
+	}
+	return object, objectInfo(info), nil
+}
+
+// List 按前缀列出文件
+func (s *S3) List(ctx g.Ctx, prefix string) ([]*vfile.ObjectInfo, error) {
+	var infos []*vfile.ObjectInfo
+	for object := range s.Client.ListObjects(ctx, s.BucketName, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		infos = append(infos, objectInfo(object))
+	}
+	return infos, nil
+}
+
+// SignedURL 生成限时访问地址
+func (s *S3) SignedURL(ctx g.Ctx, key string, expire time.Duration) (string, error) {
+	u, err := s.Client.PresignedGetObject(ctx, s.BucketName, key, expire, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// PresignUpload 生成浏览器直传的POST表单凭证
+func (s *S3) PresignUpload(ctx g.Ctx, key string, options *vfile.PresignOptions) (*vfile.PresignedUpload, error) {
+	expireTime := time.Now().Add(options.Expire)
+	policy := minio.NewPostPolicy()
+	if err := policy.SetBucket(s.BucketName); err != nil {
+		return nil, err
+	}
+	if err := policy.SetKey(key); err != nil {
+		return nil, err
+	}
+	if err := policy.SetExpires(expireTime); err != nil {
+		return nil, err
+	}
+	if options.MaxSize > 0 {
+		if err := policy.SetContentLengthRange(1, options.MaxSize); err != nil {
+			return nil, err
+		}
+	}
+	if options.ContentType != "" {
+		var err error
+		if strings.HasSuffix(options.ContentType, "/") {
+			err = policy.SetContentTypeStartsWith(options.ContentType)
+		} else {
+			err = policy.SetContentType(options.ContentType)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	u, fields, err := s.Client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	return &vfile.PresignedUpload{
+		Method:      "POST",
+		URL:         u.String(),
+		Key:         key,
+		Fields:      fields,
+		ExpireTime:  expireTime,
+		MaxSize:     options.MaxSize,
+		ContentType: options.ContentType,
+		FileURL:     s.URL(key),
+	}, nil
+}
+
+// InitMultipart 开始分片上传
+func (s *S3) InitMultipart(ctx g.Ctx, key, contentType string) (string, error) {
+	core := minio.Core{Client: s.Client}
+	return core.NewMultipartUpload(ctx, s.BucketName, key, minio.PutObjectOptions{ContentType: contentType})
+}
+
+// UploadPart 上传分片
+func (s *S3) UploadPart(ctx g.Ctx, key, uploadId string, number int, reader io.Reader, size int64) (string, error) {
+	core := minio.Core{Client: s.Client}
+	part, err := core.PutObjectPart(ctx, s.BucketName, key, uploadId, number, reader, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return "", err
+	}
+	return part.ETag, nil
+}
+
+// CompleteMultipart 合并分片
+func (s *S3) CompleteMultipart(ctx g.Ctx, key, uploadId string, parts []*vfile.Part) error {
+	core := minio.Core{Client: s.Client}
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: part.Number, ETag: part.ETag})
+	}
+	_, err := core.CompleteMultipartUpload(ctx, s.BucketName, key, uploadId, completeParts, minio.PutObjectOptions{})
+	return err
+}
+
+// AbortMultipart 取消分片上传
+func (s *S3) AbortMultipart(ctx g.Ctx, key, uploadId string) error {
+	core := minio.Core{Client: s.Client}
+	return core.AbortMultipartUpload(ctx, s.BucketName, key, uploadId)
+}
+
+// objectInfo 转换S3文件信息
+func objectInfo(info minio.ObjectInfo) *vfile.ObjectInfo {
+	return &vfile.ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+	}
+}
+
+// convertError 文件不存在时返回 vfile.ErrNotFound
+func convertError(err error) error {
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return vfile.ErrNotFound
+	}
+	return err
+}
+
+func init() {
+	if err := vfile.Register("s3", &S3{}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,312 @@
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// fakeObject 内存中的对象
+type fakeObject struct {
+	data        []byte
+	contentType string
+}
+
+// fakeS3 进程内的S3服务, 支持测试用到的对象和分片上传接口, 不校验签名
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]*fakeObject    // bucket/key
+	uploads map[string]map[int][]byte // uploadId -> 分片
+	types   map[string]string         // uploadId -> 文件类型
+}
+
+func newFakeS3() *httptest.Server {
+	return httptest.NewServer(&fakeS3{
+		objects: map[string]*fakeObject{},
+		uploads: map[string]map[int][]byte{},
+		types:   map[string]string{},
+	})
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	query := r.URL.Query()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, bucket, query.Get("prefix"))
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadId := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[uploadId] = map[int][]byte{}
+		f.types[uploadId] = r.Header.Get("Content-Type")
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: key, UploadId: uploadId})
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		data := readBody(r)
+		f.uploads[query.Get("uploadId")][number] = data
+		w.Header().Set("ETag", etag(data))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts := f.uploads[query.Get("uploadId")]
+		numbers := make([]int, 0, len(parts))
+		for number := range parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var data []byte
+		for _, number := range numbers {
+			data = append(data, parts[number]...)
+		}
+		f.objects[path] = &fakeObject{data: data, contentType: f.types[query.Get("uploadId")]}
+		delete(f.uploads, query.Get("uploadId"))
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: bucket, Key: key, ETag: etag(data)})
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		data := readBody(r)
+		f.objects[path] = &fakeObject{data: data, contentType: r.Header.Get("Content-Type")}
+		w.Header().Set("ETag", etag(data))
+	case r.Method == http.MethodDelete:
+		delete(f.objects, path)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := f.objects[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				writeXML(w, struct {
+					XMLName xml.Name `xml:"Error"`
+					Code    string
+					Key     string
+				}{Code: "NoSuchKey", Key: key})
+			}
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("ETag", etag(object.data))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// list 返回 ListObjectsV2 结果
+func (f *fakeS3) list(w http.ResponseWriter, bucket, prefix string) {
+	type content struct {
+		Key          string
+		Size         int
+		ETag         string
+		LastModified string
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		IsTruncated bool
+		Contents    []content
+	}{Name: bucket, Prefix: prefix}
+	for path, object := range f.objects {
+		if key, ok := strings.CutPrefix(path, bucket+"/"); ok && strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, content{
+				Key:          key,
+				Size:         len(object.data),
+				ETag:         etag(object.data),
+				LastModified: time.Now().UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	result.KeyCount = len(result.Contents)
+	writeXML(w, result)
+}
+
+// readBody 读取请求内容, 解码 aws-chunked 格式
+func readBody(r *http.Request) []byte {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		data, _ := io.ReadAll(r.Body)
+		return data
+	}
+	var data []byte
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return data
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, _ := strconv.ParseInt(sizeHex, 16, 64)
+		if size == 0 {
+			return data
+		}
+		chunk := make([]byte, size+2)
+		if _, err = io.ReadFull(reader, chunk); err != nil {
+			return data
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func writeXML(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	data, _ := xml.Marshal(value)
+	w.Write(data)
+}
+
+// newTestDriver 连接到进程内S3服务的驱动
+func newTestDriver(endpoint string) (*S3, error) {
+	d, err := (&S3{}).NewProfile(&vfile.Profile{
+		Name:            "test",
+		Driver:          "s3",
+		Endpoint:        strings.TrimPrefix(endpoint, "http://"),
+		Region:          "us-east-1",
+		BucketName:      "vgo",
+		AccessKeyID:     "test",
+		SecretAccessKey: "test",
+		PathStyle:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.(*S3), nil
+}
+
+// TestS3 测试S3驱动的文件操作
+func TestS3(t *testing.T) {
+	server := newFakeS3()
+	defer server.Close()
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		d, err := newTestDriver(server.URL)
+		t.AssertNil(err)
+
+		url := d.URL("uploads/a.txt")
+		t.Assert(url, server.URL+"/vgo/uploads/a.txt")
+		key, ok := d.Key(url)
+		t.Assert(ok, true)
+		t.Assert(key, "uploads/a.txt")
+		_, ok = d.Key("http://other/uploads/a.txt")
+		t.Assert(ok, false)
+
+		t.AssertNil(vfile.Put(ctx, d, "uploads/a.txt", strings.NewReader("hello"), 5, "text/plain"))
+		info, err := vfile.Stat(ctx, d, "uploads/a.txt")
+		t.AssertNil(err)
+		t.Assert(info.Size, 5)
+		t.Assert(info.ContentType, "text/plain")
+		var buf bytes.Buffer
+		_, err = vfile.Download(ctx, d, "uploads/a.txt", &buf)
+		t.AssertNil(err)
+		t.Assert(buf.String(), "hello")
+		infos, err := vfile.List(ctx, d, "uploads/")
+		t.AssertNil(err)
+		t.Assert(len(infos), 1)
+		t.Assert(infos[0].Key, "uploads/a.txt")
+
+		t.AssertNil(vfile.Delete(ctx, d, "uploads/a.txt"))
+		_, err = vfile.Stat(ctx, d, "uploads/a.txt")
+		t.Assert(err, vfile.ErrNotFound)
+		t.Assert(vfile.Delete(ctx, d, "uploads/a.txt"), vfile.ErrNotFound)
+		_, _, err = vfile.Open(ctx, d, "uploads/a.txt")
+		t.Assert(err, vfile.ErrNotFound)
+
+		// 分片上传
+		uploadId, err := d.InitMultipart(ctx, "uploads/b.bin", "application/octet-stream")
+		t.AssertNil(err)
+		var parts []*vfile.Part
+		for number, content := range []string{"part1-", "part2"} {
+			etag, err := d.UploadPart(ctx, "uploads/b.bin", uploadId, number+1, strings.NewReader(content), int64(len(content)))
+			t.AssertNil(err)
+			parts = append(parts, &vfile.Part{Number: number + 1, ETag: etag})
+		}
+		t.AssertNil(d.CompleteMultipart(ctx, "uploads/b.bin", uploadId, parts))
+		buf.Reset()
+		_, err = vfile.Download(ctx, d, "uploads/b.bin", &buf)
+		t.AssertNil(err)
+		t.Assert(buf.String(), "part1-part2")
+
+		signed, err := vfile.SignedURL(ctx, d, "uploads/b.bin", time.Minute)
+		t.AssertNil(err)
+		t.Assert(strings.Contains(signed, "X-Amz-Signature="), true)
+	})
+}
+
+// TestProfile 测试命名存储配置
+func TestProfile(t *testing.T) {
+	server := newFakeS3()
+	defer server.Close()
+	gtest.C(t, func(t *gtest.T) {
+		file := v.ConfigBinding.Get().File
+		profiles := file.Profiles
+		defer func() { file.Profiles = profiles }()
+		endpoint := strings.TrimPrefix(server.URL, "http://")
+		t.AssertNil(json.Unmarshal([]byte(`{
+			"avatar": {"driver": "s3", "endpoint": "`+endpoint+`", "bucketName": "avatar", "region": "us-east-1", "pathStyle": true, "domain": "https://cdn.example.com/"},
+			"export": {"driver": "s3", "endpoint": "`+endpoint+`", "bucketName": "export", "region": "us-east-1", "pathStyle": true},
+			"broken": {"driver": "s3"}
+		}`), &file.Profiles))
+
+		avatar, err := vfile.Use("avatar")
+		t.AssertNil(err)
+		t.Assert(avatar.(*S3).BucketName, "avatar")
+		t.Assert(avatar.(*S3).URL("a.png"), "https://cdn.example.com/a.png")
+		t.Assert(vfile.DriverName(avatar), "avatar")
+		export, err := vfile.Use("export")
+		t.AssertNil(err)
+		t.Assert(export.(*S3).URL("a.csv"), server.URL+"/export/a.csv")
+		t.Assert(vfile.DriverName(export), "export")
+
+		// 配置不变时复用实例
+		again, err := vfile.Use("avatar")
+		t.AssertNil(err)
+		t.Assert(again == avatar, true)
+
+		// 不同存储桶的文件互不影响
+		ctx := context.Background()
+		t.AssertNil(vfile.Put(ctx, export, "a.csv", strings.NewReader("a,b"), 3, "text/csv"))
+		_, err = vfile.Stat(ctx, avatar, "a.csv")
+		t.Assert(err, vfile.ErrNotFound)
+
+		_, err = vfile.Use("broken")
+		t.AssertNE(err, nil)
+		_, err = vfile.Use("missing")
+		t.AssertNE(err, nil)
+	})
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/vera-byte/vgo/v/vconfig"
+)
+
+// Config 配置命令
+var Config = &gcmd.Command{
+	Name:  "config",
+	Usage: "config COMMAND",
+	Brief: "配置管理命令",
+	Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+		g.Log().Info(ctx, "请指定要执行的子命令，使用 -h 查看帮助")
+		return nil
+	},
+}
+
+// ConfigDump 输出合并后的生效配置
+var ConfigDump = &gcmd.Command{
+	Name:  "dump",
+	Usage: "config dump [KEY] [--explain] [--format=yaml|json]",
+	Brief: "输出合并后的生效配置",
+	Description: `
+输出所有配置源按优先级合并后的生效配置。
+使用 --explain 时输出每个配置项的来源以及被覆盖的低优先级配置源的值。
+示例:
+  vgo config dump
+  vgo config dump modules.base --format=json
+  vgo config dump modules.base.jwt --explain
+`,
+	Arguments: []gcmd.Argument{
+		{
+			Name:  "key",
+			IsArg: true,
+			Brief: "配置键，为空时输出全部配置",
+		},
+		{
+			Name:   "explain",
+			Short:  "e",
+			Brief:  "输出配置项的来源",
+			Orphan: true,
+		},
+		{
+			Name:  "format",
+			Short: "f",
+			Brief: "输出格式 yaml|json，默认yaml",
+		},
+	},
+	Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+		key := parser.GetArg(3).String()
+		manager := vconfig.GetManager()
+		fmt.Printf("# 配置源(优先级从高到低): %s\n", strings.Join(manager.Sources(), ", "))
+
+		if parser.GetOpt("explain") != nil {
+			entries, err := manager.Explain(ctx, key)
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				fmt.Printf("%s = %s [%s]\n", entry.Key, gjson.MustEncodeString(entry.Value), entry.Source)
+				for _, override := range entry.Overrides {
+					fmt.Printf("    覆盖 %s = %s [%s]\n", override.Key, gjson.MustEncodeString(override.Value), override.Source)
+				}
+			}
+			return nil
+		}
+
+		var data interface{}
+		if key == "" {
+			if data, err = manager.Data(ctx); err != nil {
+				return err
+			}
+		} else {
+			value, err := manager.Get(ctx, key)
+			if err != nil {
+				return err
+			}
+			data = g.Map{key: value.Val()}
+		}
+		// 解密后的敏感配置脱敏输出
+		data = vconfig.Redact(data)
+		if parser.GetOpt("format").String() == "json" {
+			fmt.Println(gjson.New(data).MustToJsonIndentString())
+			return nil
+		}
+		fmt.Print(gjson.New(data).MustToYamlString())
+		return nil
+	},
+}
+
+// ConfigGenkey 生成配置加密密钥
+var ConfigGenkey = &gcmd.Command{
+	Name:  "genkey",
+	Usage: "config genkey",
+	Brief: "生成配置加密密钥",
+	Description: `
+生成base64编码的32字节随机密钥。
+将密钥写入 manifest/config/secret.key 文件或设置到 VCONFIG_SECRET_KEY 环境变量后即可加密配置。
+`,
+	Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+		key, err := vconfig.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	},
+}
+
+// ConfigEncrypt 加密配置值
+var ConfigEncrypt = &gcmd.Command{
+	Name:  "encrypt",
+	Usage: "config encrypt VALUE",
+	Brief: "加密配置值",
+	Description: `
+使用当前的加密密钥加密配置值, 输出 ENC(...) 格式的加密值, 可直接写入配置文件。
+示例:
+  vgo config encrypt 123456
+`,
+	Arguments: []gcmd.Argument{
+		{
+			Name:  "value",
+			IsArg: true,
+			Brief: "要加密的值",
+		},
+	},
+	Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+		value := parser.GetArg(3).String()
+		if value == "" {
+			return fmt.Errorf("请指定要加密的值")
+		}
+		encrypted, err := vconfig.Encrypt(ctx, value)
+		if err != nil {
+			return err
+		}
+		fmt.Println(encrypted)
+		return nil
+	},
+}
+
+// ConfigRotate 使用新密钥重新加密配置文件
+var ConfigRotate = &gcmd.Command{
+	Name:  "rotate",
+	Usage: "config rotate [--file=manifest/config/config.yaml]",
+	Brief: "使用新密钥重新加密配置文件",
+	Description: `
+使用第一个密钥重新加密配置文件中所有 ENC(...) 格式的加密值。
+轮换密钥时, 将新密钥添加到密钥文件的第一行并保留旧密钥, 执行该命令后再删除旧密钥。
+示例:
+  vgo config rotate --file=manifest/config/config.yaml
+`,
+	Arguments: []gcmd.Argument{
+		{
+			Name:  "file",
+			Short: "f",
+			Brief: "配置文件路径，默认manifest/config/config.yaml",
+		},
+	},
+	Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+		path := parser.GetOpt("file", "manifest/config/config.yaml").String()
+		if !gfile.Exists(path) {
+			return fmt.Errorf("配置文件不存在: %s", path)
+		}
+		content, count, err := vconfig.Reencrypt(ctx, gfile.GetBytes(path))
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			g.Log().Infof(ctx, "配置文件 %s 中没有加密值", path)
+			return nil
+		}
+		if err = gfile.PutBytes(path, content); err != nil {
+			return err
+		}
+		g.Log().Infof(ctx, "已重新加密配置文件 %s 中的 %d 个值", path, count)
+		return nil
+	},
+}
+
+// 注册config子命令
+func init() {
+	Root.AddCommand(Config)
+	Config.AddCommand(ConfigDump)
+	Config.AddCommand(ConfigGenkey)
+	Config.AddCommand(ConfigEncrypt)
+	Config.AddCommand(ConfigRotate)
+}
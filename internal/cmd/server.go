@@ -18,9 +18,8 @@ func init() {
 			// 初始化vgo
 			v.NewVgo()
 			// g.Dump(g.DB("test").GetConfig())
-			if v.IsRedisMode {
-				go v.ListenFunc(ctx)
-			}
+			// 监听集群函数, 单机模式下直接返回
+			go v.ListenFunc(ctx)
 
 			s := g.Server()
 
@@ -28,7 +27,17 @@ func init() {
 			if gfile.IsDir("frontend/dist") {
 				s.SetServerRoot("frontend/dist")
 			}
-			s.Run()
+			if err = s.Start(); err != nil {
+				return err
+			}
+			// 注册到服务注册中心, 未配置时直接返回
+			if err = v.RegisterService(ctx, s); err != nil {
+				g.Log().Error(ctx, "服务注册失败:", err)
+			}
+			g.Wait()
+			if err = v.DeregisterService(ctx); err != nil {
+				g.Log().Error(ctx, "服务注销失败:", err)
+			}
 			return nil
 		},
 	}
@@ -11,6 +11,9 @@ import (
 	// Minio，按需启用
 	// _ "github.com/vera-byte/vgo/contrib/files/minio"
 
+	// S3兼容存储(AWS S3、MinIO、R2、COS)，按需启用
+	// _ "github.com/vera-byte/vgo/contrib/files/s3"
+
 	// 阿里云OSS，按需启用
 	// _ "github.com/vera-byte/vgo/contrib/files/oss"
 
@@ -21,15 +21,64 @@ type BaseCommLogoutReq struct {
 }
 
 // BaseCommUploadModeReq 获取上传模式请求参数
+// 驱动支持浏览器直传时根据文件信息返回直传凭证
 type BaseCommUploadModeReq struct {
 	g.Meta        `path:"/uploadMode" method:"GET" summary:"获取上传模式" tags:"通用接口"`
 	Authorization string `json:"Authorization" in:"header"`
+	FileName      string `json:"fileName"`    // 文件名
+	ContentType   string `json:"contentType"` // 文件类型
+	Size          int64  `json:"size"`        // 文件大小
+	Scene         string `json:"scene"`       // 上传场景, 对应 v.file.upload.scenes 中的规则
 }
 
 // BaseCommUploadReq 文件上传请求参数
+// 文件按上传场景的规则校验大小、扩展名和文件内容识别的类型后保存
 type BaseCommUploadReq struct {
 	g.Meta        `path:"/upload" method:"POST" summary:"文件上传" tags:"通用接口"`
 	Authorization string `json:"Authorization" in:"header"`
+	Scene         string `json:"scene"` // 上传场景, 对应 v.file.upload.scenes 中的规则
+}
+
+// BaseCommMultipartInitReq 开始分片上传请求参数
+// 传入文件的SHA-256时支持断点续传, 返回的 parts 为已上传的分片
+type BaseCommMultipartInitReq struct {
+	g.Meta        `path:"/multipart/init" method:"POST" summary:"开始分片上传" tags:"通用接口"`
+	Authorization string `json:"Authorization" in:"header"`
+	FileName      string `json:"fileName" v:"required#文件名不能为空"` // 文件名
+	ContentType   string `json:"contentType"`                   // 文件类型
+	Size          int64  `json:"size" v:"required|min:1#文件大小不能为空|文件大小必须大于0"`
+	Hash          string `json:"hash"`  // 文件内容的SHA-256
+	Scene         string `json:"scene"` // 上传场景, 对应 v.file.upload.scenes 中的规则
+}
+
+// BaseCommMultipartPartReq 上传分片请求参数, 分片内容通过表单字段 file 上传
+type BaseCommMultipartPartReq struct {
+	g.Meta        `path:"/multipart/part" method:"POST" mime:"multipart/form-data" summary:"上传分片" tags:"通用接口"`
+	Authorization string `json:"Authorization" in:"header"`
+	UploadId      string `json:"uploadId" v:"required#上传ID不能为空"` // 上传ID
+	Number        int    `json:"number" v:"required|min:1#分片序号不能为空|分片序号从1开始"`
+	Hash          string `json:"hash"` // 分片内容的SHA-256
+}
+
+// BaseCommMultipartStatusReq 查询分片上传状态请求参数
+type BaseCommMultipartStatusReq struct {
+	g.Meta        `path:"/multipart/status" method:"GET" summary:"查询分片上传状态" tags:"通用接口"`
+	Authorization string `json:"Authorization" in:"header"`
+	UploadId      string `json:"uploadId" v:"required#上传ID不能为空"` // 上传ID
+}
+
+// BaseCommMultipartCompleteReq 完成分片上传请求参数
+type BaseCommMultipartCompleteReq struct {
+	g.Meta        `path:"/multipart/complete" method:"POST" summary:"完成分片上传" tags:"通用接口"`
+	Authorization string `json:"Authorization" in:"header"`
+	UploadId      string `json:"uploadId" v:"required#上传ID不能为空"` // 上传ID
+}
+
+// BaseCommMultipartAbortReq 取消分片上传请求参数
+type BaseCommMultipartAbortReq struct {
+	g.Meta        `path:"/multipart/abort" method:"POST" summary:"取消分片上传" tags:"通用接口"`
+	Authorization string `json:"Authorization" in:"header"`
+	UploadId      string `json:"uploadId" v:"required#上传ID不能为空"` // 上传ID
 }
 
 // PersonUpdateReq 更新个人信息请求参数
@@ -0,0 +1,55 @@
+package v1
+
+import "github.com/gogf/gf/v2/frame/g"
+
+// BaseConfigHistoryReq 配置变更记录请求参数
+type BaseConfigHistoryReq struct {
+	g.Meta        `path:"/history" method:"GET" summary:"获取配置变更记录" tags:"系统配置"`
+	Authorization string `json:"Authorization" in:"header"`
+	Key           string `json:"key"`
+	Limit         int    `json:"limit" d:"50"`
+}
+
+// BaseConfigRollbackReq 配置回滚请求参数
+type BaseConfigRollbackReq struct {
+	g.Meta        `path:"/rollback" method:"POST" summary:"回滚配置到指定版本" tags:"系统配置"`
+	Authorization string `json:"Authorization" in:"header"`
+	Version       int64  `json:"version" v:"required#请输入版本号"`
+}
+
+// BaseConfigEffectiveReq 生效配置请求参数
+type BaseConfigEffectiveReq struct {
+	g.Meta        `path:"/effective" method:"GET" summary:"获取合并后的生效配置" tags:"系统配置"`
+	Authorization string `json:"Authorization" in:"header"`
+	Key           string `json:"key"`
+}
+
+// BaseConfigAdaptersReq 配置源列表请求参数
+type BaseConfigAdaptersReq struct {
+	g.Meta        `path:"/adapters" method:"GET" summary:"获取已注册的配置源" tags:"系统配置"`
+	Authorization string `json:"Authorization" in:"header"`
+}
+
+// BaseConfigSetReq 写入配置请求参数
+type BaseConfigSetReq struct {
+	g.Meta        `path:"/set" method:"POST" summary:"通过配置源写入配置" tags:"系统配置"`
+	Authorization string      `json:"Authorization" in:"header"`
+	Source        string      `json:"source"`
+	Key           string      `json:"key" v:"required#请输入配置键"`
+	Value         interface{} `json:"value"`
+}
+
+// BaseConfigWatchTicketReq 配置变更推送临时凭证请求参数
+type BaseConfigWatchTicketReq struct {
+	g.Meta        `path:"/watchTicket" method:"POST" summary:"获取配置变更推送的临时凭证" tags:"系统配置"`
+	Authorization string `json:"Authorization" in:"header"`
+}
+
+// BaseConfigWatchReq 配置变更推送请求参数
+// 浏览器 EventSource 无法设置请求头, 可以不传 Authorization, 改为通过 ticket 参数传递临时凭证
+type BaseConfigWatchReq struct {
+	g.Meta        `path:"/watch" method:"GET" summary:"通过SSE推送配置变更" tags:"系统配置"`
+	Authorization string `json:"Authorization" in:"header"`
+	Ticket        string `json:"ticket" in:"query"`
+	Key           string `json:"key"`
+}
@@ -1,8 +1,9 @@
 package config
 
 import (
-	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vconfig"
 )
 
 // sConfig 配置
@@ -26,7 +27,7 @@ type Log struct {
 
 type Token struct {
 	Expire        uint `json:"expire"`
-	RefreshExpire uint `json:"refreshExprire"`
+	RefreshExpire uint `json:"refreshExpire"`
 }
 
 type Jwt struct {
@@ -35,32 +36,46 @@ type Jwt struct {
 	Token  *Token `json:"token"`
 }
 
-// NewConfig new config
-func NewConfig() *sConfig {
-	var (
-		ctx g.Ctx
-	)
-	config := &sConfig{
-		Jwt: &Jwt{
-			Sso:    v.GetCfgWithDefault(ctx, "modules.base.jwt.sso", g.NewVar(false)).Bool(),
-			Secret: v.GetCfgWithDefault(ctx, "modules.base.jwt.secret", g.NewVar(v.ProcessFlag)).String(),
-			Token: &Token{
-				Expire:        v.GetCfgWithDefault(ctx, "modules.base.jwt.token.expire", g.NewVar(2*3600)).Uint(),
-				RefreshExpire: v.GetCfgWithDefault(ctx, "modules.base.jwt.token.refreshExpire", g.NewVar(15*24*3600)).Uint(),
-			},
-		},
-		Middleware: &Middleware{
-			Authority: &Authority{
-				Enable: v.GetCfgWithDefault(ctx, "modules.base.middleware.authority.enable", g.NewVar(true)).Bool(),
-			},
-			Log: &Log{
-				Enable: v.GetCfgWithDefault(ctx, "modules.base.middleware.log.enable", g.NewVar(true)).Bool(),
-			},
+// SetDefaults 设置默认值, 配置源中存在的配置会覆盖默认值
+func (c *sConfig) SetDefaults() {
+	c.Jwt = &Jwt{
+		Sso:    false,
+		Secret: v.ProcessFlag,
+		Token: &Token{
+			Expire:        2 * 3600,
+			RefreshExpire: 15 * 24 * 3600,
 		},
 	}
+	c.Middleware = &Middleware{
+		Authority: &Authority{Enable: true},
+		Log:       &Log{Enable: true},
+	}
+}
 
-	return config
+// Validate 校验配置, 校验失败的配置不会生效
+func (c *sConfig) Validate() error {
+	if c.Jwt.Secret == "" {
+		return gerror.New("modules.base.jwt.secret 不能为空")
+	}
+	if c.Jwt.Token.Expire == 0 || c.Jwt.Token.RefreshExpire == 0 {
+		return gerror.New("modules.base.jwt.token 过期时间必须大于0")
+	}
+	return nil
 }
 
-// Config config
-var Config = NewConfig()
+// configSchema 配置的结构定义, 启动和热更新时校验, 未知的配置项会产生警告
+var configSchema = vconfig.RegisterSchema("modules.base", newConfigSchema())
+
+// newConfigSchema 创建配置的结构定义
+func newConfigSchema() *vconfig.Schema {
+	schema := vconfig.SchemaOf[sConfig]()
+	schema.Field("jwt.token.expire").AtLeast(1).Describe("token过期时间, 单位秒")
+	schema.Field("jwt.token.refreshExpire").AtLeast(1).Describe("刷新token过期时间, 单位秒")
+	return schema
+}
+
+// ConfigBinding 配置的热更新绑定, 配置源变化时自动重新加载
+var ConfigBinding = vconfig.MustBind[sConfig]("modules.base")
+
+// Config 启动时的配置快照, 需要读取最新配置时使用 ConfigBinding.Get()
+var Config = ConfigBinding.Get()
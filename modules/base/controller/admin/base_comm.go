@@ -3,10 +3,13 @@ package admin
 import (
 	"context"
 
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
 	v1 "github.com/vera-byte/vgo/modules/base/api/v1"
 	"github.com/vera-byte/vgo/modules/base/service"
 	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vfile"
 )
 
 type BaseCommController struct {
@@ -58,19 +61,99 @@ func (c *BaseCommController) Logout(ctx context.Context, req *v1.BaseCommLogoutR
 }
 
 // UploadMode 方法
+// 驱动支持浏览器直传时 mode 为 cloud, presign 为直传凭证, 上传完成后调用 /admin/space/info/confirm 登记文件
 func (c *BaseCommController) UploadMode(ctx context.Context, req *v1.BaseCommUploadModeReq) (res *v.BaseRes, err error) {
-	data, err := v.File().GetMode()
-	res = v.Ok(data)
+	driver, err := v.FileOf(ctx)
+	if err != nil {
+		return
+	}
+	data, err := driver.GetMode()
+	if err != nil {
+		return
+	}
+	presigned, err := v.PresignUpload(ctx, req.FileName, req.ContentType, req.Size)
+	if gerror.Is(err, vfile.ErrUnsupported) {
+		return v.Ok(data), nil
+	}
+	if err != nil {
+		return
+	}
+	mode := gconv.Map(data)
+	mode["mode"] = "cloud"
+	mode["presign"] = presigned
+	res = v.Ok(mode)
 	return
 }
 
 // Upload 方法
 func (c *BaseCommController) Upload(ctx context.Context, req *v1.BaseCommUploadReq) (res *v.BaseRes, err error) {
-	data, err := v.File().Upload(ctx)
+	driver, err := v.FileOf(ctx)
+	if err != nil {
+		return
+	}
+	data, err := driver.Upload(ctx)
 	res = v.Ok(data)
 	return
 }
 
+// MultipartInit 开始分片上传
+func (c *BaseCommController) MultipartInit(ctx context.Context, req *v1.BaseCommMultipartInitReq) (res *v.BaseRes, err error) {
+	upload, err := v.InitMultipart(ctx, req.FileName, req.ContentType, req.Size, req.Hash)
+	if err != nil {
+		return
+	}
+	res = v.Ok(upload)
+	return
+}
+
+// MultipartPart 上传分片
+func (c *BaseCommController) MultipartPart(ctx context.Context, req *v1.BaseCommMultipartPartReq) (res *v.BaseRes, err error) {
+	file := g.RequestFromCtx(ctx).GetUploadFile("file")
+	if file == nil {
+		return nil, gerror.New("分片内容不能为空")
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+	part, err := v.UploadMultipartPart(ctx, req.UploadId, req.Number, reader, file.Size, req.Hash)
+	if err != nil {
+		return
+	}
+	res = v.Ok(part)
+	return
+}
+
+// MultipartStatus 查询分片上传状态, 用于断点续传
+func (c *BaseCommController) MultipartStatus(ctx context.Context, req *v1.BaseCommMultipartStatusReq) (res *v.BaseRes, err error) {
+	upload, err := v.GetMultipart(ctx, req.UploadId)
+	if err != nil {
+		return
+	}
+	res = v.Ok(upload)
+	return
+}
+
+// MultipartComplete 完成分片上传, 返回文件地址
+func (c *BaseCommController) MultipartComplete(ctx context.Context, req *v1.BaseCommMultipartCompleteReq) (res *v.BaseRes, err error) {
+	info, url, err := v.CompleteMultipart(ctx, req.UploadId)
+	if err != nil {
+		return
+	}
+	res = v.Ok(g.Map{"url": url, "key": info.Key, "size": info.Size})
+	return
+}
+
+// MultipartAbort 取消分片上传
+func (c *BaseCommController) MultipartAbort(ctx context.Context, req *v1.BaseCommMultipartAbortReq) (res *v.BaseRes, err error) {
+	if err = v.AbortMultipart(ctx, req.UploadId); err != nil {
+		return
+	}
+	res = v.Ok(nil)
+	return
+}
+
 // PersonUpdate 方法
 func (c *BaseCommController) PersonUpdate(ctx g.Ctx, req *v1.PersonUpdateReq) (res *v.BaseRes, err error) {
 	var (
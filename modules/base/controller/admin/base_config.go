@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	v1 "github.com/vera-byte/vgo/modules/base/api/v1"
+	"github.com/vera-byte/vgo/modules/base/service"
+	"github.com/vera-byte/vgo/v"
+)
+
+type BaseConfigController struct {
+	*v.ControllerSimple
+}
+
+func init() {
+	var base_config_controller = &BaseConfigController{
+		ControllerSimple: &v.ControllerSimple{
+			Perfix: "/admin/base/config",
+		},
+	}
+	// 注册路由
+	v.RegisterControllerSimple(base_config_controller)
+}
+
+// History 获取配置变更记录
+func (c *BaseConfigController) History(ctx context.Context, req *v1.BaseConfigHistoryReq) (res *v.BaseRes, err error) {
+	var (
+		baseConfigService = service.NewBaseConfigService()
+	)
+	res = v.Ok(baseConfigService.History(req.Key, req.Limit))
+	return
+}
+
+// Rollback 回滚配置到指定版本
+func (c *BaseConfigController) Rollback(ctx context.Context, req *v1.BaseConfigRollbackReq) (res *v.BaseRes, err error) {
+	var (
+		baseConfigService = service.NewBaseConfigService()
+	)
+	data, err := baseConfigService.Rollback(ctx, req.Version)
+	if err != nil {
+		return
+	}
+	res = v.Ok(data)
+	return
+}
+
+// Effective 获取合并后的生效配置
+func (c *BaseConfigController) Effective(ctx context.Context, req *v1.BaseConfigEffectiveReq) (res *v.BaseRes, err error) {
+	var (
+		baseConfigService = service.NewBaseConfigService()
+	)
+	data, err := baseConfigService.Effective(ctx, req.Key)
+	if err != nil {
+		return
+	}
+	res = v.Ok(data)
+	return
+}
+
+// Adapters 获取已注册的配置源及其可用状态
+func (c *BaseConfigController) Adapters(ctx context.Context, req *v1.BaseConfigAdaptersReq) (res *v.BaseRes, err error) {
+	var (
+		baseConfigService = service.NewBaseConfigService()
+	)
+	res = v.Ok(baseConfigService.Adapters(ctx))
+	return
+}
+
+// Set 通过配置源写入配置
+func (c *BaseConfigController) Set(ctx context.Context, req *v1.BaseConfigSetReq) (res *v.BaseRes, err error) {
+	var (
+		baseConfigService = service.NewBaseConfigService()
+	)
+	data, err := baseConfigService.Set(ctx, req.Source, req.Key, req.Value)
+	if err != nil {
+		return
+	}
+	res = v.Ok(data)
+	return
+}
+
+// WatchTicket 获取配置变更推送的临时凭证
+func (c *BaseConfigController) WatchTicket(ctx context.Context, req *v1.BaseConfigWatchTicketReq) (res *v.BaseRes, err error) {
+	var (
+		baseConfigService = service.NewBaseConfigService()
+	)
+	ticket, err := baseConfigService.WatchTicket(ctx, req.Authorization)
+	if err != nil {
+		return
+	}
+	res = v.Ok(g.Map{
+		"ticket": ticket,
+		"expire": int(service.WatchTicketTTL.Seconds()),
+	})
+	return
+}
+
+// Watch 通过SSE推送配置变更, 连接断开时结束
+func (c *BaseConfigController) Watch(ctx context.Context, req *v1.BaseConfigWatchReq) (res *v.BaseRes, err error) {
+	var (
+		baseConfigService = service.NewBaseConfigService()
+		r                 = ghttp.RequestFromCtx(ctx)
+	)
+	events, unsubscribe := baseConfigService.Subscribe(req.Key)
+	defer unsubscribe()
+
+	r.Response.Header().Set("Content-Type", "text/event-stream")
+	r.Response.Header().Set("Cache-Control", "no-cache")
+	r.Response.Header().Set("Connection", "keep-alive")
+	r.Response.Header().Set("X-Accel-Buffering", "no")
+	r.Response.Write(": connected\n\n")
+	r.Response.Flush()
+
+	// 定时发送注释保持连接, 避免被代理断开
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			r.Response.Writef("event: change\ndata: %s\n\n", gjson.MustEncodeString(event))
+			r.Response.Flush()
+		case <-ticker.C:
+			r.Response.Write(": ping\n\n")
+			r.Response.Flush()
+		}
+	}
+}
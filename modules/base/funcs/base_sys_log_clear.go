@@ -31,6 +31,16 @@ func (f *BaseFuncClearLog) IsAllWorker() bool {
 	return false
 }
 
+// ParamSchema 参数为是否清理全部日志
+func (f *BaseFuncClearLog) ParamSchema() string {
+	return `{"type":["boolean","null"]}`
+}
+
+// Description 函数说明
+func (f *BaseFuncClearLog) Description() string {
+	return "清理系统日志"
+}
+
 // init
 func init() {
 	v.RegisterFunc("BaseFuncClearLog", &BaseFuncClearLog{})
@@ -8,6 +8,7 @@ import (
 	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/vera-byte/vgo/modules/base/config"
+	"github.com/vera-byte/vgo/modules/base/service"
 	"github.com/vera-byte/vgo/v"
 )
 
@@ -41,9 +42,13 @@ func BaseAuthorityMiddleware(r *ghttp.Request) {
 	}
 
 	tokenString := r.GetHeader("Authorization")
+	// 浏览器 EventSource 无法设置请求头, 配置变更推送接口通过 ticket 参数传递临时凭证
+	if tokenString == "" && r.URL.Path == "/admin/base/config/watch" {
+		tokenString = service.NewBaseConfigService().WatchTicketToken(ctx, r.GetQuery("ticket").String())
+	}
 	token, err := jwt.ParseWithClaims(tokenString, &v.Claims{}, func(token *jwt.Token) (interface{}, error) {
 
-		return []byte(config.Config.Jwt.Secret), nil
+		return []byte(config.ConfigBinding.Get().Jwt.Secret), nil
 	})
 	if err != nil {
 		statusCode = 401
@@ -67,7 +72,7 @@ func BaseAuthorityMiddleware(r *ghttp.Request) {
 	rtoken := cachetoken.String()
 	// 超管拥有所有权限
 	if admin.UserId == 1 && !admin.IsRefresh {
-		if tokenString != rtoken && config.Config.Jwt.Sso {
+		if tokenString != rtoken && config.ConfigBinding.Get().Jwt.Sso {
 			statusCode = 401
 			r.Response.WriteStatusExit(statusCode, g.Map{
 				"code":    1001,
@@ -110,7 +115,7 @@ func BaseAuthorityMiddleware(r *ghttp.Request) {
 		})
 	}
 	// 如果rtoken不等于token 且 sso 未开启
-	if tokenString != rtoken && !config.Config.Jwt.Sso {
+	if tokenString != rtoken && !config.ConfigBinding.Get().Jwt.Sso {
 		statusCode = 401
 		r.Response.WriteStatusExit(statusCode, g.Map{
 			"code":    1001,
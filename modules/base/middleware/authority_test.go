@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/guid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/vera-byte/vgo/modules/base/config"
+	"github.com/vera-byte/vgo/modules/base/service"
+	"github.com/vera-byte/vgo/v"
+)
+
+// TestBaseAuthorityMiddlewareWatchTicket 测试配置变更推送接口通过 ticket 参数登录
+func TestBaseAuthorityMiddlewareWatchTicket(t *testing.T) {
+	ctx := context.Background()
+	passwordVersion := int32(1)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &v.Claims{
+		UserId:          1,
+		Username:        "admin",
+		PasswordVersion: &passwordVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(config.ConfigBinding.Get().Jwt.Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.CacheManager.Set(ctx, "admin:token:1", token, 0)
+	defer v.CacheManager.Remove(ctx, "admin:token:1")
+
+	s := g.Server(guid.S())
+	s.Group("/admin", func(group *ghttp.RouterGroup) {
+		group.Middleware(BaseAuthorityMiddleware)
+		group.GET("/base/config/watch", func(r *ghttp.Request) {
+			r.Response.Write("ok")
+		})
+		group.GET("/base/config/effective", func(r *ghttp.Request) {
+			r.Response.Write("ok")
+		})
+	})
+	s.SetDumpRouterMap(false)
+	s.SetAccessLogEnabled(false)
+	s.SetPort(0)
+	if err = s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	gtest.C(t, func(t *gtest.T) {
+		prefix := fmt.Sprintf("http://127.0.0.1:%d/admin/base/config", s.GetListenedPort())
+		client := g.Client()
+
+		res, err := client.Header(g.MapStrStr{"Authorization": token}).Get(ctx, prefix+"/watch")
+		t.AssertNil(err)
+		t.Assert(res.StatusCode, 200)
+		t.Assert(res.ReadAllString(), "ok")
+		res.Close()
+
+		ticket, err := service.NewBaseConfigService().WatchTicket(ctx, token)
+		t.AssertNil(err)
+		res, err = client.Get(ctx, prefix+"/watch?ticket="+ticket)
+		t.AssertNil(err)
+		t.Assert(res.StatusCode, 200)
+		t.Assert(res.ReadAllString(), "ok")
+		res.Close()
+
+		res, err = client.Get(ctx, prefix+"/watch")
+		t.AssertNil(err)
+		t.Assert(res.StatusCode, 401)
+		res.Close()
+
+		res, err = client.Get(ctx, prefix+"/watch?ticket="+guid.S())
+		t.AssertNil(err)
+		t.Assert(res.StatusCode, 401)
+		res.Close()
+
+		// 临时凭证只能用于配置变更推送接口
+		res, err = client.Get(ctx, prefix+"/effective?ticket="+ticket)
+		t.AssertNil(err)
+		t.Assert(res.StatusCode, 401)
+		res.Close()
+	})
+}
@@ -2,19 +2,35 @@ package middleware
 
 import (
 	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/vera-byte/vgo/modules/base/config"
 )
 
 func init() {
-	if config.Config.Middleware.Authority.Enable {
-		g.Server().BindMiddleware("/admin/*/open/*", BaseAuthorityMiddlewareOpen)
-		g.Server().BindMiddleware("/admin/*/comm/*", BaseAuthorityMiddlewareComm)
-		g.Server().BindMiddleware("/admin/*", BaseAuthorityMiddleware)
-		g.Server().BindMiddleware("/*", AutoI18n)
+	g.Server().BindMiddleware("/admin/*/open/*", enabled(authorityEnabled, BaseAuthorityMiddlewareOpen))
+	g.Server().BindMiddleware("/admin/*/comm/*", enabled(authorityEnabled, BaseAuthorityMiddlewareComm))
+	g.Server().BindMiddleware("/admin/*", enabled(authorityEnabled, BaseAuthorityMiddleware))
+	g.Server().BindMiddleware("/*", enabled(authorityEnabled, AutoI18n))
+	g.Server().BindMiddleware("/admin/*", enabled(logEnabled, BaseLog))
+}
 
-	}
-	if config.Config.Middleware.Log.Enable {
-		g.Server().BindMiddleware("/admin/*", BaseLog)
-	}
+// authorityEnabled 是否开启权限中间件
+func authorityEnabled(c *config.Middleware) bool {
+	return c.Authority.Enable
+}
 
+// logEnabled 是否开启日志中间件
+func logEnabled(c *config.Middleware) bool {
+	return c.Log.Enable
+}
+
+// enabled 按最新配置决定是否执行中间件, 支持运行时开关
+func enabled(flag func(c *config.Middleware) bool, handler ghttp.HandlerFunc) ghttp.HandlerFunc {
+	return func(r *ghttp.Request) {
+		if flag(config.ConfigBinding.Get().Middleware) {
+			handler(r)
+			return
+		}
+		r.Middleware.Next()
+	}
 }
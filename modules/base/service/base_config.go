@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/guid"
+	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vconfig"
+)
+
+// BaseConfigEvent 推送给浏览器的配置变更事件
+type BaseConfigEvent struct {
+	Key      string      `json:"key"`      // 配置键
+	Type     string      `json:"type"`     // 变更类型 ADD UPDATE DELETE
+	Value    interface{} `json:"value"`    // 新值
+	OldValue interface{} `json:"oldValue"` // 旧值
+	Time     time.Time   `json:"time"`     // 变更时间
+}
+
+var (
+	// configWatchOnce 所有订阅共用一个配置监听, 避免每个连接都在配置源上注册监听
+	configWatchOnce sync.Once
+	// configSubscribers 配置变更订阅, 值为订阅的配置键
+	configSubscribers = make(map[chan *BaseConfigEvent]string)
+	// configSubscribersMutex 配置变更订阅锁
+	configSubscribersMutex sync.RWMutex
+)
+
+type BaseConfigService struct {
+	*v.Service
+}
+
+func NewBaseConfigService() *BaseConfigService {
+	return &BaseConfigService{}
+}
+
+// History 获取配置变更记录, 敏感配置值脱敏
+func (s *BaseConfigService) History(key string, limit int) []*vconfig.JournalEntry {
+	entries := vconfig.GetManager().History(key, limit)
+	result := make([]*vconfig.JournalEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, redactJournalEntry(entry))
+	}
+	return result
+}
+
+// Rollback 回滚配置到指定版本
+func (s *BaseConfigService) Rollback(ctx context.Context, version int64) ([]*vconfig.JournalEntry, error) {
+	admin := v.GetAdmin(ctx)
+	entries, err := vconfig.GetManager().Rollback(ctx, version, admin.Username)
+	result := make([]*vconfig.JournalEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, redactJournalEntry(entry))
+	}
+	return result, err
+}
+
+// Effective 获取合并后的生效配置, 敏感配置值脱敏
+// key: 配置键, 为空时返回全部配置
+func (s *BaseConfigService) Effective(ctx context.Context, key string) (interface{}, error) {
+	manager := vconfig.GetManager()
+	if key == "" {
+		data, err := manager.Data(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return vconfig.RedactKey("", data), nil
+	}
+	value, err := manager.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return vconfig.RedactKey(key, value.Val()), nil
+}
+
+// Adapters 获取已注册的配置源及其可用状态
+func (s *BaseConfigService) Adapters(ctx context.Context) []*vconfig.AdapterInfo {
+	return vconfig.GetManager().AdapterInfos(ctx)
+}
+
+// Set 通过可写的配置源写入配置并记录变更
+// source: 配置源名称, 为空时使用主配置源
+func (s *BaseConfigService) Set(ctx context.Context, source, key string, value interface{}) (*vconfig.JournalEntry, error) {
+	admin := v.GetAdmin(ctx)
+	entry, err := vconfig.GetManager().Set(ctx, source, key, value, admin.Username)
+	if err != nil {
+		return nil, err
+	}
+	return redactJournalEntry(entry), nil
+}
+
+// WatchTicketTTL 配置变更推送临时凭证的有效期
+const WatchTicketTTL = time.Minute
+
+// WatchTicket 为当前登录的token创建配置变更推送的临时凭证
+// 浏览器 EventSource 无法设置请求头, 通过 ticket 参数传递凭证, 有效期内断线重连可以继续使用
+func (s *BaseConfigService) WatchTicket(ctx context.Context, token string) (ticket string, err error) {
+	ticket = guid.S()
+	err = v.CacheManager.Set(ctx, "admin:ticket:config:watch:"+ticket, token, WatchTicketTTL)
+	return
+}
+
+// WatchTicketToken 获取临时凭证对应的token, 凭证不存在或已过期时返回空字符串
+func (s *BaseConfigService) WatchTicketToken(ctx context.Context, ticket string) string {
+	if ticket == "" {
+		return ""
+	}
+	token, err := v.CacheManager.Get(ctx, "admin:ticket:config:watch:"+ticket)
+	if err != nil {
+		return ""
+	}
+	return token.String()
+}
+
+// Subscribe 订阅配置变更, 返回事件通道和取消订阅函数
+// 接收不及时的事件会被丢弃, 取消订阅后通道关闭
+// key: 配置键, 为空或为*时订阅全部配置, 否则订阅该键及其子级配置
+func (s *BaseConfigService) Subscribe(key string) (<-chan *BaseConfigEvent, func()) {
+	configWatchOnce.Do(func() {
+		ctx := context.Background()
+		if err := vconfig.GetManager().Watch(ctx, "*", publishConfigEvent); err != nil {
+			g.Log().Errorf(ctx, "监听配置变更失败: %v", err)
+		}
+	})
+
+	ch := make(chan *BaseConfigEvent, 32)
+	configSubscribersMutex.Lock()
+	configSubscribers[ch] = strings.TrimSuffix(key, ".*")
+	configSubscribersMutex.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			configSubscribersMutex.Lock()
+			delete(configSubscribers, ch)
+			configSubscribersMutex.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publishConfigEvent 将配置变更脱敏后发送给订阅了该配置键的连接
+func publishConfigEvent(event *vconfig.ConfigEvent) {
+	configEvent := &BaseConfigEvent{
+		Key:      event.Key,
+		Type:     event.Type.String(),
+		Value:    vconfig.RedactKey(event.Key, event.Value),
+		OldValue: vconfig.RedactKey(event.Key, event.OldValue),
+		Time:     time.Now(),
+	}
+	configSubscribersMutex.RLock()
+	defer configSubscribersMutex.RUnlock()
+	for ch, key := range configSubscribers {
+		if key != "" && key != "*" && event.Key != key && !strings.HasPrefix(event.Key, key+".") {
+			continue
+		}
+		select {
+		case ch <- configEvent:
+		default:
+			g.Log().Warningf(context.Background(), "配置变更推送缓冲已满, 丢弃事件: %s", event.Key)
+		}
+	}
+}
+
+// redactJournalEntry 复制变更记录并脱敏其中的配置值
+func redactJournalEntry(entry *vconfig.JournalEntry) *vconfig.JournalEntry {
+	redacted := *entry
+	redacted.OldValue = vconfig.RedactKey(entry.Key, entry.OldValue)
+	redacted.Value = vconfig.RedactKey(entry.Key, entry.Value)
+	redacted.Diff = make([]*vconfig.ConfigDiff, 0, len(entry.Diff))
+	for _, diff := range entry.Diff {
+		redacted.Diff = append(redacted.Diff, &vconfig.ConfigDiff{
+			Key:      diff.Key,
+			OldValue: vconfig.RedactKey(diff.Key, diff.OldValue),
+			Value:    vconfig.RedactKey(diff.Key, diff.Value),
+		})
+	}
+	return &redacted
+}
@@ -105,7 +105,7 @@ func (*BaseSysLoginService) Logout(ctx context.Context) (err error) {
 func (s *BaseSysLoginService) RefreshToken(ctx context.Context, token string) (result *TokenResult, err error) {
 
 	tokenClaims, err := jwt.ParseWithClaims(token, &v.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.Config.Jwt.Secret), nil
+		return []byte(config.ConfigBinding.Get().Jwt.Secret), nil
 	})
 	if err != nil {
 		return
@@ -163,7 +163,7 @@ func (*BaseSysLoginService) generateToken(ctx context.Context, user *model.BaseS
 	}
 	tokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	token, err = tokenClaims.SignedString([]byte(config.Config.Jwt.Secret))
+	token, err = tokenClaims.SignedString([]byte(config.ConfigBinding.Get().Jwt.Secret))
 	if err != nil {
 		g.Log().Error(ctx, "生成token失败", err)
 	}
@@ -187,8 +187,8 @@ func (s *BaseSysLoginService) generateTokenByUser(ctx context.Context, user *mod
 
 	// 生成token
 	result = &TokenResult{}
-	result.Expire = config.Config.Jwt.Token.Expire
-	result.RefreshExpire = config.Config.Jwt.Token.RefreshExpire
+	result.Expire = config.ConfigBinding.Get().Jwt.Token.Expire
+	result.RefreshExpire = config.ConfigBinding.Get().Jwt.Token.RefreshExpire
 	result.Token = s.generateToken(ctx, user, roleIds, result.Expire, false)
 	result.RefreshToken = s.generateToken(ctx, user, roleIds, result.RefreshExpire, true)
 	// 将用户相关信息保存到缓存
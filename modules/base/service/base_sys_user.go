@@ -15,6 +15,11 @@ import (
 	"github.com/vera-byte/vgo/v"
 )
 
+// 迁移文件存储时改写用户头像地址
+func init() {
+	v.RegisterFileColumns(model.NewBaseSysUser(), "headImg")
+}
+
 type BaseSysUserService struct {
 	*v.Service
 }
@@ -0,0 +1,30 @@
+package v1
+
+import "github.com/gogf/gf/v2/frame/g"
+
+// SpaceInfoConfirmReq 确认浏览器直传文件请求参数
+type SpaceInfoConfirmReq struct {
+	g.Meta        `path:"/confirm" method:"POST" summary:"确认直传文件并登记到空间" tags:"文件空间"`
+	Authorization string `json:"Authorization" in:"header"`
+	Key           string `json:"key" v:"required#请输入文件键"`
+	Type          string `json:"type"`       // 文件类型, 为空时按文件的Content-Type取大类, 如 image
+	ClassifyID    *int64 `json:"classifyId"` // 分类ID
+	FolderID      *int64 `json:"folderId"`   // 文件夹ID, 为空时放在根目录
+	Temporary     bool   `json:"temporary"`  // 是否为临时文件, 表单中上传的文件传true, 业务数据保存后转为永久文件
+}
+
+// SpaceInfoMoveReq 移动文件请求参数
+type SpaceInfoMoveReq struct {
+	g.Meta        `path:"/move" method:"POST" summary:"移动文件到文件夹" tags:"文件空间"`
+	Authorization string  `json:"Authorization" in:"header"`
+	Ids           []int64 `json:"ids" v:"required#请选择文件"`
+	FolderID      *int64  `json:"folderId"` // 目标文件夹ID, 为空时移动到根目录
+}
+
+// SpaceInfoRenameReq 重命名文件请求参数
+type SpaceInfoRenameReq struct {
+	g.Meta        `path:"/rename" method:"POST" summary:"重命名文件" tags:"文件空间"`
+	Authorization string `json:"Authorization" in:"header"`
+	Id            int64  `json:"id" v:"required#请选择文件"`
+	FileName      string `json:"fileName" v:"required#请输入文件名"`
+}
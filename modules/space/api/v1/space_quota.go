@@ -0,0 +1,9 @@
+package v1
+
+import "github.com/gogf/gf/v2/frame/g"
+
+// SpaceQuotaUsageReq 查询存储空间使用情况请求参数
+type SpaceQuotaUsageReq struct {
+	g.Meta        `path:"/usage" method:"GET" summary:"当前用户和部门的存储空间使用情况" tags:"文件空间"`
+	Authorization string `json:"Authorization" in:"header"`
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gcmd"
 	"github.com/vera-byte/vgo/internal/cmd"
+	"github.com/vera-byte/vgo/v"
 )
 
 // cSpace space模块主命令结构体
@@ -83,6 +84,52 @@ func (c *cSpaceCreate) Index(ctx context.Context, in cSpaceCreateInput) (out *cS
 	return &cSpaceCreateOutput{}, nil
 }
 
+// cSpaceMigrate 迁移文件存储命令结构体
+type cSpaceMigrate struct {
+	g.Meta `name:"space-migrate" brief:"迁移文件存储" dc:"将文件从一个驱动复制到另一个驱动, 并改写数据库中登记字段的文件地址, 重新执行时跳过已复制的文件"`
+}
+
+// cSpaceMigrateInput space migrate命令的输入参数
+type cSpaceMigrateInput struct {
+	g.Meta      `name:"space-migrate" brief:"迁移文件存储" dc:"将文件从一个驱动复制到另一个驱动, 并改写数据库中登记字段的文件地址, 重新执行时跳过已复制的文件"`
+	From        string `short:"f" name:"from" brief:"源存储" dc:"源驱动或命名存储配置, 如 local" v:"required"`
+	To          string `short:"t" name:"to" brief:"目标存储" dc:"目标驱动或命名存储配置, 如 minio" v:"required"`
+	Prefix      string `short:"p" name:"prefix" brief:"文件前缀" dc:"只迁移该前缀下的文件, 为空时迁移全部"`
+	Concurrency int    `short:"c" name:"concurrency" brief:"并发数" dc:"同时复制的文件数" default:"4"`
+	DryRun      bool   `short:"d" name:"dryRun" brief:"预演" dc:"只统计需要复制的文件和需要改写的地址, 不做修改" orphan:"true"`
+}
+
+// cSpaceMigrateOutput space migrate命令的输出
+type cSpaceMigrateOutput struct{}
+
+// Index space migrate命令的执行方法
+// 功能：迁移文件存储并输出迁移结果
+// 参数：ctx - 上下文，in - 输入参数
+// 返回值：out - 输出结果，err - 错误信息
+func (c *cSpaceMigrate) Index(ctx context.Context, in cSpaceMigrateInput) (out *cSpaceMigrateOutput, err error) {
+	report, err := v.MigrateFiles(ctx, &v.MigrateOptions{
+		From:        in.From,
+		To:          in.To,
+		Prefix:      in.Prefix,
+		Concurrency: in.Concurrency,
+		DryRun:      in.DryRun,
+	})
+	if report != nil {
+		action := "复制"
+		if report.DryRun {
+			action = "需要复制"
+		}
+		fmt.Printf("文件: %d, %s: %d (%d字节), 已存在: %d, 失败: %d\n", report.Total, action, report.Copied, report.Bytes, report.Skipped, len(report.Failed))
+		for _, failure := range report.Failed {
+			fmt.Printf("  失败 %s: %s\n", failure.Key, failure.Error)
+		}
+		for column, count := range report.Rewrites {
+			fmt.Printf("改写地址 %s: %d\n", column, count)
+		}
+	}
+	return &cSpaceMigrateOutput{}, err
+}
+
 // SpaceCommandProvider space模块命令提供者
 type SpaceCommandProvider struct{}
 
@@ -93,10 +140,12 @@ func (p *SpaceCommandProvider) GetCommands() []*gcmd.Command {
 	spaceCmd, _ := gcmd.NewFromObject(&cSpace{})
 	spaceListCmd, _ := gcmd.NewFromObject(&cSpaceList{})
 	spaceCreateCmd, _ := gcmd.NewFromObject(&cSpaceCreate{})
+	spaceMigrateCmd, _ := gcmd.NewFromObject(&cSpaceMigrate{})
 	return []*gcmd.Command{
 		spaceCmd,
 		spaceListCmd,
 		spaceCreateCmd,
+		spaceMigrateCmd,
 	}
 }
 
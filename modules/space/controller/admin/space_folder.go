@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"github.com/vera-byte/vgo/modules/space/service"
+	"github.com/vera-byte/vgo/v"
+)
+
+type SpaceFolderController struct {
+	*v.Controller
+}
+
+func init() {
+	var space_folder_controller = &SpaceFolderController{
+		&v.Controller{
+			Perfix:  "/admin/space/folder",
+			Api:     []string{"Add", "Delete", "Update", "Info", "List"},
+			Service: service.NewSpaceFolderService(),
+		},
+	}
+	// 注册路由
+	v.RegisterController(space_folder_controller)
+}
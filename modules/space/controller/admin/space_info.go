@@ -1,6 +1,8 @@
 package admin
 
 import (
+	"github.com/gogf/gf/v2/frame/g"
+	v1 "github.com/vera-byte/vgo/modules/space/api/v1"
 	"github.com/vera-byte/vgo/modules/space/service"
 	"github.com/vera-byte/vgo/v"
 )
@@ -20,3 +22,40 @@ func init() {
 	// 注册路由
 	v.RegisterController(space_info_controller)
 }
+
+// Confirm 确认浏览器直传的文件
+// 功能: 校验直传凭证和已上传的文件, 并将文件登记到空间
+// 参数: ctx - 上下文, req - 确认请求
+// 返回值: res - 响应结果包含文件ID和访问地址, err - 错误信息
+func (c *SpaceInfoController) Confirm(ctx g.Ctx, req *v1.SpaceInfoConfirmReq) (res *v.BaseRes, err error) {
+	data, err := c.Service.(*service.SpaceInfoService).Confirm(ctx, req.Key, req.Type, req.ClassifyID, req.FolderID, req.Temporary)
+	if err != nil {
+		return v.Fail(err.Error()), err
+	}
+	res = v.Ok(data)
+	return
+}
+
+// Move 移动文件
+// 功能: 将选中的文件移动到文件夹, 只能移动自己的文件
+// 参数: ctx - 上下文, req - 移动请求
+// 返回值: res - 响应结果, err - 错误信息
+func (c *SpaceInfoController) Move(ctx g.Ctx, req *v1.SpaceInfoMoveReq) (res *v.BaseRes, err error) {
+	if err = c.Service.(*service.SpaceInfoService).Move(ctx, req.Ids, req.FolderID); err != nil {
+		return v.Fail(err.Error()), err
+	}
+	res = v.Ok(nil)
+	return
+}
+
+// Rename 重命名文件
+// 功能: 修改文件名, 不影响存储中的文件
+// 参数: ctx - 上下文, req - 重命名请求
+// 返回值: res - 响应结果, err - 错误信息
+func (c *SpaceInfoController) Rename(ctx g.Ctx, req *v1.SpaceInfoRenameReq) (res *v.BaseRes, err error) {
+	if err = c.Service.(*service.SpaceInfoService).Rename(ctx, req.Id, req.FileName); err != nil {
+		return v.Fail(err.Error()), err
+	}
+	res = v.Ok(nil)
+	return
+}
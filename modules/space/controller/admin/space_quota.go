@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	v1 "github.com/vera-byte/vgo/modules/space/api/v1"
+	"github.com/vera-byte/vgo/modules/space/service"
+	"github.com/vera-byte/vgo/v"
+)
+
+type SpaceQuotaController struct {
+	*v.Controller
+}
+
+func init() {
+	var space_quota_controller = &SpaceQuotaController{
+		&v.Controller{
+			Perfix:  "/admin/space/quota",
+			Api:     []string{"Add", "Delete", "Update", "Info", "List", "Page"},
+			Service: service.NewSpaceQuotaService(),
+		},
+	}
+	// 注册路由
+	v.RegisterController(space_quota_controller)
+}
+
+// Usage 存储空间使用情况
+// 功能: 返回当前用户和所在部门已使用的空间和配额, 配额为0时不限制
+// 参数: ctx - 上下文, req - 查询请求
+// 返回值: res - 响应结果, err - 错误信息
+func (c *SpaceQuotaController) Usage(ctx g.Ctx, req *v1.SpaceQuotaUsageReq) (res *v.BaseRes, err error) {
+	data, err := c.Service.(*service.SpaceQuotaService).Usage(ctx)
+	if err != nil {
+		return v.Fail(err.Error()), err
+	}
+	res = v.Ok(data)
+	return
+}
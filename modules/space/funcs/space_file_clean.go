@@ -0,0 +1,50 @@
+package funcs
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/v"
+)
+
+// SpaceFuncCleanFiles 清理临时文件和存储中的孤立文件
+type SpaceFuncCleanFiles struct {
+}
+
+// Func 按 v.file.temporary 配置清理文件, 参数为true时只统计不删除
+func (f *SpaceFuncCleanFiles) Func(ctx g.Ctx, param string) (err error) {
+	temporary := v.ConfigBinding.Get().File.Temporary
+	options := &v.CleanOptions{DryRun: param == "true"}
+	if temporary != nil {
+		options.Orphan = temporary.Orphan
+		options.Prefix = temporary.Prefix
+	}
+	report, err := v.CleanFiles(ctx, options)
+	if report != nil {
+		g.Log().Info(ctx, "清理文件 SpaceFuncCleanFiles.Func", "report", report)
+	}
+	return
+}
+
+// IsSingleton
+func (f *SpaceFuncCleanFiles) IsSingleton() bool {
+	return true
+}
+
+// IsAllWorker
+func (f *SpaceFuncCleanFiles) IsAllWorker() bool {
+	return false
+}
+
+// ParamSchema 参数为是否只统计不删除
+func (f *SpaceFuncCleanFiles) ParamSchema() string {
+	return `{"type":["boolean","null"]}`
+}
+
+// Description 函数说明
+func (f *SpaceFuncCleanFiles) Description() string {
+	return "清理未被引用的临时文件和存储中的孤立文件"
+}
+
+// init
+func init() {
+	v.RegisterFunc("SpaceFuncCleanFiles", &SpaceFuncCleanFiles{})
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"github.com/vera-byte/vgo/v"
+)
+
+const TableNameSpaceFile = "space_file"
+
+// SpaceFile mapped from table <space_file>
+type SpaceFile struct {
+	*v.Model
+	Driver      string `json:"driver"`      // 存储驱动
+	Key         string `json:"key"`         // 文件键
+	URL         string `json:"url"`         // 访问地址
+	Hash        string `json:"hash"`        // 上传内容的SHA-256
+	Size        int64  `json:"size"`        // 文件大小
+	ContentType string `json:"contentType"` // 文件类型
+	FileName    string `json:"fileName"`    // 原文件名
+	UserId      uint   `json:"userId"`      // 上传者ID
+	RefCount    int    `json:"refCount"`    // 引用计数
+	Temporary   int32  `json:"temporary"`   // 是否为临时文件 0:否 1:是
+}
+
+// TableName SpaceFile's table name
+func (*SpaceFile) TableName() string {
+	return TableNameSpaceFile
+}
+
+// GroupName SpaceFile's table group
+func (*SpaceFile) GroupName() string {
+	return "default"
+}
+
+// NewSpaceFile create a new SpaceFile
+func NewSpaceFile() *SpaceFile {
+	return &SpaceFile{
+		Model: v.NewModel(),
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/vera-byte/vgo/v"
+)
+
+const TableNameSpaceFileUpload = "space_file_upload"
+
+// SpaceFileUpload mapped from table <space_file_upload>
+// 文件每被上传一次记录一条, 内容相同的文件由不同管理员上传时共用文件元数据, 上传者记录在这里
+type SpaceFileUpload struct {
+	*v.Model
+	FileID uint `json:"fileId"` // 文件元数据ID
+	UserID uint `json:"userId"` // 上传者ID
+}
+
+// TableName SpaceFileUpload's table name
+func (*SpaceFileUpload) TableName() string {
+	return TableNameSpaceFileUpload
+}
+
+// GroupName SpaceFileUpload's table group
+func (*SpaceFileUpload) GroupName() string {
+	return "default"
+}
+
+// NewSpaceFileUpload create a new SpaceFileUpload
+func NewSpaceFileUpload() *SpaceFileUpload {
+	return &SpaceFileUpload{
+		Model: v.NewModel(),
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"github.com/vera-byte/vgo/v"
+)
+
+const TableNameSpaceFolder = "space_folder"
+
+// SpaceFolder mapped from table <space_folder>
+type SpaceFolder struct {
+	*v.Model
+	Name         string `json:"name"`         // 文件夹名称
+	ParentID     *int64 `json:"parentId"`     // 父文件夹ID
+	UserID       uint   `json:"userId"`       // 所有者ID
+	DepartmentID *int64 `json:"departmentId"` // 所有者部门ID
+}
+
+// TableName SpaceFolder's table name
+func (*SpaceFolder) TableName() string {
+	return TableNameSpaceFolder
+}
+
+// GroupName SpaceFolder's table group
+func (*SpaceFolder) GroupName() string {
+	return "default"
+}
+
+// NewSpaceFolder create a new SpaceFolder
+func NewSpaceFolder() *SpaceFolder {
+	return &SpaceFolder{
+		Model: v.NewModel(),
+	}
+}
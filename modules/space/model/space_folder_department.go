@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/vera-byte/vgo/v"
+)
+
+const TableNameSpaceFolderDepartment = "space_folder_department"
+
+// SpaceFolderDepartment mapped from table <space_folder_department>
+type SpaceFolderDepartment struct {
+	*v.Model
+	FolderID     uint `json:"folderId"`     // 文件夹ID
+	DepartmentID uint `json:"departmentId"` // 共享的部门ID
+}
+
+// TableName SpaceFolderDepartment's table name
+func (*SpaceFolderDepartment) TableName() string {
+	return TableNameSpaceFolderDepartment
+}
+
+// GroupName SpaceFolderDepartment's table group
+func (*SpaceFolderDepartment) GroupName() string {
+	return "default"
+}
+
+// NewSpaceFolderDepartment create a new SpaceFolderDepartment
+func NewSpaceFolderDepartment() *SpaceFolderDepartment {
+	return &SpaceFolderDepartment{
+		Model: v.NewModel(),
+	}
+}
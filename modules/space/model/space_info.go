@@ -9,9 +9,15 @@ const TableNameSpaceInfo = "space_info"
 // SpaceInfo mapped from table <space_info>
 type SpaceInfo struct {
 	*v.Model
-	URL        string `json:"url"`        // 地址
-	Type       string `json:"type"`       // 类型
-	ClassifyID *int64 `json:"classifyId"` // 分类ID
+	URL          string `json:"url"`          // 地址
+	Type         string `json:"type"`         // 类型
+	ClassifyID   *int64 `json:"classifyId"`   // 分类ID
+	FolderID     *int64 `json:"folderId"`     // 文件夹ID
+	UserID       uint   `json:"userId"`       // 所有者ID
+	DepartmentID *int64 `json:"departmentId"` // 所有者部门ID
+	FileName     string `json:"fileName"`     // 文件名
+	Size         int64  `json:"size"`         // 文件大小
+	Temporary    int32  `json:"temporary"`    // 是否为临时文件 0:否 1:是, 表单中上传、尚未被业务数据引用的文件为临时文件
 }
 
 // TableName SpaceInfo's table name
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/vera-byte/vgo/v"
+)
+
+const TableNameSpaceQuota = "space_quota"
+
+// SpaceQuota mapped from table <space_quota>
+type SpaceQuota struct {
+	*v.Model
+	UserID       *int64 `json:"userId"`       // 用户ID
+	DepartmentID *int64 `json:"departmentId"` // 部门ID
+	MaxSize      int64  `json:"maxSize"`      // 最大存储空间(字节), 为0时不限制
+}
+
+// TableName SpaceQuota's table name
+func (*SpaceQuota) TableName() string {
+	return TableNameSpaceQuota
+}
+
+// GroupName SpaceQuota's table group
+func (*SpaceQuota) GroupName() string {
+	return "default"
+}
+
+// NewSpaceQuota create a new SpaceQuota
+func NewSpaceQuota() *SpaceQuota {
+	return &SpaceQuota{
+		Model: v.NewModel(),
+	}
+}
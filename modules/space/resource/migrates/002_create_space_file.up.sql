@@ -0,0 +1,27 @@
+-- Space模块PostgreSQL数据库迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 创建文件元数据表, 同一存储驱动中内容相同的文件只保存一份, 按引用计数删除
+
+CREATE TABLE IF NOT EXISTS space_file (
+    id BIGSERIAL PRIMARY KEY,
+    "createTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updateTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deletedAt" TIMESTAMP DEFAULT NULL,
+    driver VARCHAR(50) NOT NULL,
+    "key" VARCHAR(500) NOT NULL,
+    url VARCHAR(1000) NOT NULL,
+    hash CHAR(64) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    "contentType" VARCHAR(255),
+    "fileName" VARCHAR(255),
+    "userId" BIGINT,
+    "refCount" INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_space_file_create_time ON space_file("createTime");
+CREATE INDEX IF NOT EXISTS idx_space_file_deleted_at ON space_file("deletedAt");
+CREATE INDEX IF NOT EXISTS idx_space_file_url ON space_file(url);
+CREATE INDEX IF NOT EXISTS idx_space_file_user_id ON space_file("userId");
+CREATE UNIQUE INDEX IF NOT EXISTS uk_space_file_driver_hash ON space_file(driver, hash);
+
+CREATE TRIGGER update_space_file_updated_time BEFORE UPDATE ON space_file FOR EACH ROW EXECUTE FUNCTION update_space_updated_time_column();
@@ -0,0 +1,17 @@
+-- Space模块PostgreSQL数据库回滚迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 回滚文件夹、文件夹共享部门和存储配额表
+
+DROP INDEX IF EXISTS idx_space_info_folder_id;
+DROP INDEX IF EXISTS idx_space_info_user_id;
+DROP INDEX IF EXISTS idx_space_info_department_id;
+
+ALTER TABLE space_info DROP COLUMN IF EXISTS "folderId";
+ALTER TABLE space_info DROP COLUMN IF EXISTS "userId";
+ALTER TABLE space_info DROP COLUMN IF EXISTS "departmentId";
+ALTER TABLE space_info DROP COLUMN IF EXISTS "fileName";
+ALTER TABLE space_info DROP COLUMN IF EXISTS size;
+
+DROP TABLE IF EXISTS space_quota;
+DROP TABLE IF EXISTS space_folder_department;
+DROP TABLE IF EXISTS space_folder;
@@ -0,0 +1,61 @@
+-- Space模块PostgreSQL数据库迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 创建文件夹、文件夹共享部门和存储配额表, 空间文件增加所属文件夹、所有者和大小
+
+-- 1. 文件夹表
+CREATE TABLE IF NOT EXISTS space_folder (
+    id BIGSERIAL PRIMARY KEY,
+    "createTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updateTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deletedAt" TIMESTAMP DEFAULT NULL,
+    name VARCHAR(255) NOT NULL,
+    "parentId" BIGINT,
+    "userId" BIGINT NOT NULL,
+    "departmentId" BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_space_folder_deleted_at ON space_folder("deletedAt");
+CREATE INDEX IF NOT EXISTS idx_space_folder_parent_id ON space_folder("parentId");
+CREATE INDEX IF NOT EXISTS idx_space_folder_user_id ON space_folder("userId");
+
+-- 2. 文件夹共享部门表
+CREATE TABLE IF NOT EXISTS space_folder_department (
+    id BIGSERIAL PRIMARY KEY,
+    "createTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updateTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deletedAt" TIMESTAMP DEFAULT NULL,
+    "folderId" BIGINT NOT NULL,
+    "departmentId" BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_space_folder_department_folder_id ON space_folder_department("folderId");
+CREATE INDEX IF NOT EXISTS idx_space_folder_department_department_id ON space_folder_department("departmentId");
+
+-- 3. 存储配额表, userId 和 departmentId 只设置一个
+CREATE TABLE IF NOT EXISTS space_quota (
+    id BIGSERIAL PRIMARY KEY,
+    "createTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updateTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deletedAt" TIMESTAMP DEFAULT NULL,
+    "userId" BIGINT,
+    "departmentId" BIGINT,
+    "maxSize" BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_space_quota_user_id ON space_quota("userId") WHERE "deletedAt" IS NULL AND "userId" IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uk_space_quota_department_id ON space_quota("departmentId") WHERE "deletedAt" IS NULL AND "departmentId" IS NOT NULL;
+
+-- 4. 空间文件所属文件夹、所有者和大小
+ALTER TABLE space_info ADD COLUMN IF NOT EXISTS "folderId" BIGINT;
+ALTER TABLE space_info ADD COLUMN IF NOT EXISTS "userId" BIGINT;
+ALTER TABLE space_info ADD COLUMN IF NOT EXISTS "departmentId" BIGINT;
+ALTER TABLE space_info ADD COLUMN IF NOT EXISTS "fileName" VARCHAR(255);
+ALTER TABLE space_info ADD COLUMN IF NOT EXISTS size BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_space_info_folder_id ON space_info("folderId");
+CREATE INDEX IF NOT EXISTS idx_space_info_user_id ON space_info("userId");
+CREATE INDEX IF NOT EXISTS idx_space_info_department_id ON space_info("departmentId");
+
+CREATE TRIGGER update_space_folder_updated_time BEFORE UPDATE ON space_folder FOR EACH ROW EXECUTE FUNCTION update_space_updated_time_column();
+CREATE TRIGGER update_space_folder_department_updated_time BEFORE UPDATE ON space_folder_department FOR EACH ROW EXECUTE FUNCTION update_space_updated_time_column();
+CREATE TRIGGER update_space_quota_updated_time BEFORE UPDATE ON space_quota FOR EACH ROW EXECUTE FUNCTION update_space_updated_time_column();
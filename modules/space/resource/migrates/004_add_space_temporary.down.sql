@@ -0,0 +1,10 @@
+-- Space模块PostgreSQL数据库回滚迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 回滚文件元数据和空间文件的临时状态
+
+DROP INDEX IF EXISTS idx_space_file_temporary;
+DROP INDEX IF EXISTS idx_space_file_driver_key;
+DROP INDEX IF EXISTS idx_space_info_temporary;
+
+ALTER TABLE space_file DROP COLUMN IF EXISTS temporary;
+ALTER TABLE space_info DROP COLUMN IF EXISTS temporary;
@@ -0,0 +1,10 @@
+-- Space模块PostgreSQL数据库迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 文件元数据和空间文件增加临时状态, 临时文件被业务数据引用后转为永久文件, 否则由清理任务删除
+
+ALTER TABLE space_file ADD COLUMN IF NOT EXISTS temporary SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE space_info ADD COLUMN IF NOT EXISTS temporary SMALLINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_space_file_temporary ON space_file(temporary, "createTime");
+CREATE INDEX IF NOT EXISTS idx_space_file_driver_key ON space_file(driver, "key");
+CREATE INDEX IF NOT EXISTS idx_space_info_temporary ON space_info(temporary);
@@ -0,0 +1,5 @@
+-- Space模块PostgreSQL数据库回滚迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 回滚文件上传记录表
+
+DROP TABLE IF EXISTS space_file_upload;
@@ -0,0 +1,20 @@
+-- Space模块PostgreSQL数据库迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 创建文件上传记录表, 内容相同的文件被不同管理员上传时共用元数据, 每次上传的上传者记录在该表中
+
+CREATE TABLE IF NOT EXISTS space_file_upload (
+    id BIGSERIAL PRIMARY KEY,
+    "createTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updateTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deletedAt" TIMESTAMP DEFAULT NULL,
+    "fileId" BIGINT NOT NULL,
+    "userId" BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_space_file_upload_file_user ON space_file_upload("fileId", "userId");
+
+-- 已有记录的上传者为元数据中的上传者
+INSERT INTO space_file_upload ("fileId", "userId")
+SELECT id, COALESCE("userId", 0) FROM space_file;
+
+CREATE TRIGGER update_space_file_upload_updated_time BEFORE UPDATE ON space_file_upload FOR EACH ROW EXECUTE FUNCTION update_space_updated_time_column();
@@ -0,0 +1,10 @@
+-- Space模块PostgreSQL数据库回滚迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 回滚清理临时文件的定时任务
+
+DO $$
+BEGIN
+    IF to_regclass('task_info') IS NOT NULL THEN
+        DELETE FROM task_info WHERE service = 'SpaceFuncCleanFiles(false)';
+    END IF;
+END $$;
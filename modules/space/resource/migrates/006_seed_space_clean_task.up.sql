@@ -0,0 +1,13 @@
+-- Space模块PostgreSQL数据库迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 添加每天清理临时文件的定时任务, 未安装任务模块时跳过
+
+DO $$
+BEGIN
+    IF to_regclass('task_info') IS NOT NULL AND NOT EXISTS (
+        SELECT 1 FROM task_info WHERE service = 'SpaceFuncCleanFiles(false)'
+    ) THEN
+        INSERT INTO task_info (name, cron, remark, status, service, type, "taskType")
+        VALUES ('清理临时文件', '1 2 4 * * *', '每天04:02:01清理未被引用的临时文件, 需要开启 v.file.temporary', 1, 'SpaceFuncCleanFiles(false)', 0, 0);
+    END IF;
+END $$;
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	_ "github.com/vera-byte/vgo/contrib/drivers/sqlite"
+	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// testTables 测试使用的数据表
+var testTables = []string{
+	"CREATE TABLE base_sys_user (id INTEGER PRIMARY KEY, departmentId INTEGER)",
+	"CREATE TABLE space_file (id INTEGER PRIMARY KEY AUTOINCREMENT, driver TEXT, key TEXT, url TEXT, hash TEXT, size INTEGER, contentType TEXT, fileName TEXT, userId INTEGER, refCount INTEGER, temporary INTEGER DEFAULT 0, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+	"CREATE TABLE space_file_upload (id INTEGER PRIMARY KEY AUTOINCREMENT, fileId INTEGER, userId INTEGER, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+	"CREATE TABLE space_info (id INTEGER PRIMARY KEY AUTOINCREMENT, url TEXT, type TEXT, classifyId INTEGER, folderId INTEGER, userId INTEGER, departmentId INTEGER, fileName TEXT, size INTEGER DEFAULT 0, temporary INTEGER DEFAULT 0, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+	"CREATE TABLE space_folder (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, parentId INTEGER, userId INTEGER, departmentId INTEGER, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+	"CREATE TABLE space_folder_department (id INTEGER PRIMARY KEY AUTOINCREMENT, folderId INTEGER, departmentId INTEGER, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+	"CREATE TABLE space_quota (id INTEGER PRIMARY KEY AUTOINCREMENT, userId INTEGER, departmentId INTEGER, maxSize INTEGER, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+}
+
+// testDeleter 记录删除的文件键的存储驱动
+type testDeleter struct {
+	deleted []string
+}
+
+func (d *testDeleter) New() vfile.Driver { return d }
+
+func (d *testDeleter) GetMode() (data interface{}, err error) {
+	return g.MapStrStr{"mode": "local", "type": "space"}, nil
+}
+
+func (d *testDeleter) Upload(ctx g.Ctx) (string, error) { return "", vfile.ErrUnsupported }
+
+func (d *testDeleter) Delete(ctx g.Ctx, key string) error {
+	d.deleted = append(d.deleted, key)
+	return nil
+}
+
+var testDBOnce sync.Once
+
+// setupTestDB 使用临时的sqlite数据库并清空数据表, users 为用户ID到部门ID, 返回记录删除文件的存储驱动
+func setupTestDB(t *testing.T, users map[uint]int64) *testDeleter {
+	ctx := context.Background()
+	testDBOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "space")
+		if err != nil {
+			t.Fatal(err)
+		}
+		gdb.SetConfig(gdb.Config{"default": gdb.ConfigGroup{{
+			Type:      "sqlite",
+			Name:      filepath.Join(dir, "space.db"),
+			CreatedAt: "createTime",
+			UpdatedAt: "updateTime",
+		}}})
+		for _, table := range testTables {
+			if _, err = g.DB().Exec(ctx, table); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+	for _, table := range []string{"base_sys_user", "space_file", "space_file_upload", "space_info", "space_folder", "space_folder_department", "space_quota"} {
+		if _, err := g.DB().Exec(ctx, "DELETE FROM "+table); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for userId, departmentId := range users {
+		if _, err := g.DB().Model("base_sys_user").Data(g.Map{"id": userId, "departmentId": departmentId}).Insert(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := &testDeleter{}
+	vfile.Register("spacetest", d)
+	t.Cleanup(func() { delete(vfile.FileMap, "spacetest") })
+	return d
+}
+
+// testContext 以管理员身份发起请求的上下文, body 为JSON请求参数
+func testContext(userId uint, username string, body ...g.Map) context.Context {
+	var reader io.Reader
+	if len(body) > 0 {
+		reader = strings.NewReader(gjson.MustEncodeString(body[0]))
+	}
+	request := httptest.NewRequest(http.MethodPost, "/", reader)
+	request.Header.Set("Content-Type", "application/json")
+	r := &ghttp.Request{Request: request}
+	r.SetCtxVar("admin", &v.Admin{UserId: userId, Username: username})
+	return r.Context()
+}
+
+// addTestFile 添加引用计数为 refCount 的文件记录, 每次引用都由 userId 上传
+func addTestFile(t *testing.T, key string, userId uint, size int64, refCount int) string {
+	url := "http://127.0.0.1/" + key
+	id, err := g.DB().Model("space_file").Data(g.Map{
+		"driver":      "spacetest",
+		"key":         key,
+		"url":         url,
+		"hash":        key,
+		"size":        size,
+		"contentType": "text/plain",
+		"userId":      userId,
+		"refCount":    refCount,
+	}).InsertAndGetId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < refCount; i++ {
+		if _, err = g.DB().Model("space_file_upload").Data(g.Map{"fileId": id, "userId": userId}).Insert(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return url
+}
+
+// addTestFolder 添加文件夹, parentId 为0时在根目录
+func addTestFolder(t *testing.T, name string, parentId int64, userId uint, departmentId int64) int64 {
+	data := g.Map{"name": name, "userId": userId, "departmentId": departmentId}
+	if parentId > 0 {
+		data["parentId"] = parentId
+	}
+	id, err := g.DB().Model("space_folder").Data(data).InsertAndGetId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// addTestShare 将文件夹共享给部门
+func addTestShare(t *testing.T, folderId, departmentId int64) {
+	if _, err := g.DB().Model("space_folder_department").Data(g.Map{"folderId": folderId, "departmentId": departmentId}).Insert(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// addTestInfo 添加空间文件, folderId 为0时在根目录
+func addTestInfo(t *testing.T, key string, folderId int64, userId uint, departmentId, size int64) int64 {
+	data := g.Map{"url": "http://127.0.0.1/" + key, "fileName": key, "userId": userId, "departmentId": departmentId, "size": size}
+	if folderId > 0 {
+		data["folderId"] = folderId
+	}
+	id, err := g.DB().Model("space_info").Data(data).InsertAndGetId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
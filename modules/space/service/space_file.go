@@ -0,0 +1,199 @@
+package service
+
+import (
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/vera-byte/vgo/modules/space/model"
+	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// 文件元数据保存在 space_file 表中, 上传的文件按内容去重
+func init() {
+	vfile.RegisterRecordStore(NewSpaceFileService())
+}
+
+// SpaceFileService 文件元数据, 实现 vfile.RecordStore、vfile.RecordMover 和 vfile.TemporaryStore
+// 每次上传获得的引用在 space_file_upload 中记录上传者, 内容相同的文件由不同管理员上传时共用一条元数据
+type SpaceFileService struct {
+	*v.Service
+}
+
+func NewSpaceFileService() *SpaceFileService {
+	return &SpaceFileService{
+		&v.Service{
+			Model: model.NewSpaceFile(),
+		},
+	}
+}
+
+// Acquire 查找存储驱动中哈希相同的记录并增加一次引用, 不存在时返回nil
+func (s *SpaceFileService) Acquire(ctx g.Ctx, driver, hash string) (record *vfile.Record, err error) {
+	result, err := v.DBM(s.Model).Ctx(ctx).Where("driver", driver).Where("hash", hash).Increment("refCount", 1)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, nil
+	}
+	file, err := v.DBM(s.Model).Ctx(ctx).Where("driver", driver).Where("hash", hash).One()
+	if err != nil {
+		return nil, err
+	}
+	if err = file.Struct(&record); err != nil {
+		return nil, err
+	}
+	err = s.addUpload(ctx, file["id"].Int64(), currentUploader(ctx))
+	return
+}
+
+// Create 保存引用计数为1的新记录, 上传者为空时使用当前管理员
+func (s *SpaceFileService) Create(ctx g.Ctx, record *vfile.Record) error {
+	if record.UserId == 0 {
+		record.UserId = currentUploader(ctx)
+	}
+	id, err := v.DBM(s.Model).Ctx(ctx).Data(g.Map{
+		"driver":      record.Driver,
+		"key":         record.Key,
+		"url":         record.URL,
+		"hash":        record.Hash,
+		"size":        record.Size,
+		"contentType": record.ContentType,
+		"fileName":    record.FileName,
+		"userId":      record.UserId,
+		"refCount":    1,
+		"temporary":   gconv.Int(record.Temporary),
+	}).InsertAndGetId()
+	if err != nil {
+		// 违反唯一索引时说明并发上传了相同内容
+		if count, countErr := v.DBM(s.Model).Ctx(ctx).Where("driver", record.Driver).Where("hash", record.Hash).Count(); countErr == nil && count > 0 {
+			return vfile.ErrRecordExists
+		}
+		return err
+	}
+	return s.addUpload(ctx, id, record.UserId)
+}
+
+// currentUploader 当前上传者, 没有请求时为命令行或任务中的上传, 上传者为0
+func currentUploader(ctx g.Ctx) uint {
+	if g.RequestFromCtx(ctx) == nil {
+		return 0
+	}
+	return v.GetAdmin(ctx).UserId
+}
+
+// addUpload 记录一次上传获得的引用及其上传者
+func (s *SpaceFileService) addUpload(ctx g.Ctx, fileId int64, userId uint) error {
+	_, err := v.DBM(model.NewSpaceFileUpload()).Ctx(ctx).Data(g.Map{"fileId": fileId, "userId": userId}).Insert()
+	return err
+}
+
+// deleteFile 删除文件元数据及其上传记录
+func (s *SpaceFileService) deleteFile(ctx g.Ctx, m *gdb.Model) (deleted bool, err error) {
+	id, err := m.Clone().Value("id")
+	if err != nil || id.IsEmpty() {
+		return
+	}
+	result, err := m.Unscoped().Where("id", id).Delete()
+	if err != nil {
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return
+	}
+	_, err = v.DBM(model.NewSpaceFileUpload()).Ctx(ctx).Unscoped().Where("fileId", id).Delete()
+	return true, err
+}
+
+// Release 按访问地址减少一次引用, 没有引用时删除记录
+func (s *SpaceFileService) Release(ctx g.Ctx, url string) (record *vfile.Record, err error) {
+	file, err := v.DBM(s.Model).Ctx(ctx).Where("url", url).One()
+	if err != nil || file.IsEmpty() {
+		return nil, err
+	}
+	if _, err = v.DBM(s.Model).Ctx(ctx).Where("id", file["id"]).WhereGT("refCount", 0).Decrement("refCount", 1); err != nil {
+		return nil, err
+	}
+	if err = v.DBM(s.Model).Ctx(ctx).Where("id", file["id"]).Scan(&record); err != nil {
+		return nil, err
+	}
+	if record.RefCount <= 0 {
+		_, err = s.deleteFile(ctx, v.DBM(s.Model).Ctx(ctx).Where("id", file["id"]))
+	}
+	return
+}
+
+// MoveRecords 存储迁移后将记录转移到目标存储并改写访问地址, 目标存储中已有相同哈希的记录保持不变
+func (s *SpaceFileService) MoveRecords(ctx g.Ctx, from, to, prefix string, skip []string) error {
+	dst, err := vfile.Use(to)
+	if err != nil {
+		return err
+	}
+	hashes, err := v.DBM(s.Model).Ctx(ctx).Where("driver", to).Array("hash")
+	if err != nil {
+		return err
+	}
+	m := v.DBM(s.Model).Ctx(ctx).Fields("id", "key").Where("driver", from).WhereLike("key", prefix+"%")
+	if len(hashes) > 0 {
+		m = m.WhereNotIn("hash", hashes)
+	}
+	if len(skip) > 0 {
+		m = m.WhereNotIn("key", skip)
+	}
+	files, err := m.All()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		url, err := vfile.URL(dst, file["key"].String())
+		if err != nil {
+			return err
+		}
+		if _, err = v.DBM(s.Model).Ctx(ctx).Data(g.Map{"driver": to, "url": url}).Where("id", file["id"]).Update(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Keep 将临时文件转为永久文件, 引用该文件的临时空间文件同时转为永久文件
+func (s *SpaceFileService) Keep(ctx g.Ctx, urls []string) (err error) {
+	if _, err = v.DBM(s.Model).Ctx(ctx).Data("temporary", 0).WhereIn("url", urls).Where("temporary", 1).Update(); err != nil {
+		return
+	}
+	_, err = v.DBM(model.NewSpaceInfo()).Ctx(ctx).Data("temporary", 0).WhereIn("url", urls).Where("temporary", 1).Update()
+	return
+}
+
+// Expired 返回创建时间早于 before 的临时文件记录
+func (s *SpaceFileService) Expired(ctx g.Ctx, before time.Time) (records []*vfile.Record, err error) {
+	err = v.DBM(s.Model).Ctx(ctx).Where("temporary", 1).WhereLT("createTime", before).OrderAsc("id").Scan(&records)
+	return
+}
+
+// Purge 引用计数不大于1时删除记录以及引用该文件的临时空间文件, 返回是否已删除
+func (s *SpaceFileService) Purge(ctx g.Ctx, url string) (purged bool, err error) {
+	if purged, err = s.deleteFile(ctx, v.DBM(s.Model).Ctx(ctx).Where("url", url).WhereLTE("refCount", 1)); err != nil || !purged {
+		return
+	}
+	_, err = v.DBM(model.NewSpaceInfo()).Ctx(ctx).Where("url", url).Where("temporary", 1).Delete()
+	return
+}
+
+// Records 返回驱动中文件键以 prefix 开头的全部记录
+func (s *SpaceFileService) Records(ctx g.Ctx, driver, prefix string) (records []*vfile.Record, err error) {
+	err = v.DBM(s.Model).Ctx(ctx).Fields("driver", "key", "url", "contentType").Where("driver", driver).WhereLike("key", prefix+"%").Scan(&records)
+	return
+}
+
+// FirstCreated 返回驱动中最早的记录的创建时间, 没有记录时返回零值
+func (s *SpaceFileService) FirstCreated(ctx g.Ctx, driver string) (first time.Time, err error) {
+	value, err := v.DBM(s.Model).Ctx(ctx).Where("driver", driver).OrderAsc("createTime").Value("createTime")
+	if err != nil || value.IsEmpty() {
+		return
+	}
+	return value.Time(), nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// TestSpaceFilePurge 测试清理临时文件时被多次上传的文件只减少引用
+func TestSpaceFilePurge(t *testing.T) {
+	ctx := context.Background()
+	d := setupTestDB(t, map[uint]int64{1: 0})
+	gtest.C(t, func(t *gtest.T) {
+		s := NewSpaceFileService()
+		first, err := s.FirstCreated(ctx, "spacetest")
+		t.AssertNil(err)
+		t.Assert(first.IsZero(), true)
+
+		url := addTestFile(t.T, "uploads/a.txt", 1, 4, 2)
+		addTestInfo(t.T, "uploads/a.txt", 0, 1, 0, 4)
+		_, err = g.DB().Model("space_info").Data("temporary", 1).Where("url", url).Update()
+		t.AssertNil(err)
+		first, err = s.FirstCreated(ctx, "spacetest")
+		t.AssertNil(err)
+		t.Assert(time.Since(first) < time.Minute, true)
+
+		record := &vfile.Record{Driver: "spacetest", Key: "uploads/a.txt", URL: url, RefCount: 2}
+		purged, err := vfile.PurgeRecord(ctx, record)
+		t.AssertNil(err)
+		t.Assert(purged, false)
+		refCount, err := g.DB().Model("space_file").Where("url", url).Value("refCount")
+		t.AssertNil(err)
+		t.Assert(refCount.Int(), 1)
+		t.Assert(len(d.deleted), 0)
+
+		purged, err = vfile.PurgeRecord(ctx, record)
+		t.AssertNil(err)
+		t.Assert(purged, true)
+		count, err := g.DB().Model("space_file").Where("url", url).Count()
+		t.AssertNil(err)
+		t.Assert(count, 0)
+		count, err = g.DB().Model("space_info").Where("url", url).Count()
+		t.AssertNil(err)
+		t.Assert(count, 0)
+		t.Assert(d.deleted, []string{"uploads/a.txt"})
+	})
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/vera-byte/vgo/modules/space/model"
+	"github.com/vera-byte/vgo/v"
+)
+
+// owner 当前管理员
+type owner struct {
+	UserId       uint
+	DepartmentId int64
+	IsAdmin      bool // 超级管理员可以查看和修改所有文件
+}
+
+// currentOwner 获取当前管理员及其部门, 部门从 base_sys_user 表中读取
+func currentOwner(ctx g.Ctx) (*owner, error) {
+	if g.RequestFromCtx(ctx) == nil {
+		return nil, gerror.New("未登录")
+	}
+	admin := v.GetAdmin(ctx)
+	o := &owner{UserId: admin.UserId, IsAdmin: admin.Username == "admin"}
+	departmentId, err := g.DB().Model("base_sys_user").Ctx(ctx).Where("id", admin.UserId).Value("departmentId")
+	if err != nil {
+		return nil, err
+	}
+	o.DepartmentId = departmentId.Int64()
+	return o, nil
+}
+
+type SpaceFolderService struct {
+	*v.Service
+}
+
+func NewSpaceFolderService() *SpaceFolderService {
+	return &SpaceFolderService{
+		&v.Service{
+			Model: model.NewSpaceFolder(),
+			ListQueryOp: &v.QueryOp{
+				FieldEQ:      []string{"parentId"},
+				KeyWordField: []string{"name"},
+				AddOrderby:   g.MapStrStr{"name": "ASC"},
+				Extend: func(ctx g.Ctx, m *gdb.Model) *gdb.Model {
+					return visibleFolders(ctx, m)
+				},
+			},
+		},
+	}
+}
+
+// visibleFolders 只查询当前管理员自己的和共享给其部门的文件夹
+func visibleFolders(ctx g.Ctx, m *gdb.Model) *gdb.Model {
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return m.Where("1 = 0")
+	}
+	if o.IsAdmin {
+		return m
+	}
+	shared, err := NewSpaceFolderService().SharedIds(ctx, o.DepartmentId)
+	if err != nil {
+		g.Log().Error(ctx, err)
+	}
+	builder := m.Builder().Where("userId", o.UserId)
+	if len(shared) > 0 {
+		builder = builder.WhereOrIn("id", shared)
+	}
+	return m.Where(builder)
+}
+
+// ServiceAdd 新建文件夹, 所有者为当前管理员
+// departmentIdList: 共享的部门ID
+func (s *SpaceFolderService) ServiceAdd(ctx context.Context, req *v.AddReq) (data interface{}, err error) {
+	r := g.RequestFromCtx(ctx).GetMap()
+	name := gconv.String(r["name"])
+	if name == "" {
+		return nil, gerror.New("文件夹名称不能为空")
+	}
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return
+	}
+	if r["parentId"] != nil {
+		if err = s.CheckModify(ctx, o, gconv.Int64(r["parentId"])); err != nil {
+			return
+		}
+	}
+	id, err := v.DBM(s.Model).Ctx(ctx).Data(g.Map{
+		"name":         name,
+		"parentId":     r["parentId"],
+		"userId":       o.UserId,
+		"departmentId": o.DepartmentId,
+	}).InsertAndGetId()
+	if err != nil {
+		return
+	}
+	if err = s.updateShares(ctx, id, gconv.SliceUint(r["departmentIdList"])); err != nil {
+		return
+	}
+	data = g.Map{"id": id}
+	return
+}
+
+// ServiceUpdate 重命名、移动文件夹或修改共享部门, 只有所有者可以修改
+func (s *SpaceFolderService) ServiceUpdate(ctx context.Context, req *v.UpdateReq) (data interface{}, err error) {
+	r := g.RequestFromCtx(ctx).GetMap()
+	id := gconv.Int64(r["id"])
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return
+	}
+	if err = s.CheckModify(ctx, o, id); err != nil {
+		return
+	}
+	update := g.Map{}
+	if name, ok := r["name"]; ok {
+		if gconv.String(name) == "" {
+			return nil, gerror.New("文件夹名称不能为空")
+		}
+		update["name"] = name
+	}
+	if parentId, ok := r["parentId"]; ok {
+		if parentId != nil {
+			if err = s.checkMove(ctx, o, id, gconv.Int64(parentId)); err != nil {
+				return
+			}
+		}
+		update["parentId"] = parentId
+	}
+	if len(update) > 0 {
+		if _, err = v.DBM(s.Model).Ctx(ctx).Data(update).Where("id", id).Update(); err != nil {
+			return
+		}
+	}
+	if list, ok := r["departmentIdList"]; ok {
+		err = s.updateShares(ctx, id, gconv.SliceUint(list))
+	}
+	return
+}
+
+// ServiceDelete 删除文件夹及其子文件夹和其中的文件, 同时减少文件引用
+func (s *SpaceFolderService) ServiceDelete(ctx context.Context, req *v.DeleteReq) (data interface{}, err error) {
+	ids := gconv.SliceInt64(g.RequestFromCtx(ctx).Get("ids").Slice())
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return
+	}
+	for _, id := range ids {
+		if err = s.CheckModify(ctx, o, id); err != nil {
+			return
+		}
+	}
+	folderIds, err := s.Descendants(ctx, ids)
+	if err != nil {
+		return
+	}
+	fileIds, err := v.DBM(model.NewSpaceInfo()).Ctx(ctx).WhereIn("folderId", folderIds).Array("id")
+	if err != nil {
+		return
+	}
+	if err = NewSpaceInfoService().Remove(ctx, gconv.SliceInt64(fileIds)); err != nil {
+		return
+	}
+	if _, err = v.DBM(model.NewSpaceFolderDepartment()).Ctx(ctx).WhereIn("folderId", folderIds).Delete(); err != nil {
+		return
+	}
+	return v.DBM(s.Model).Ctx(ctx).WhereIn("id", folderIds).Delete()
+}
+
+// ServiceInfo 文件夹详情, 包含共享的部门ID
+func (s *SpaceFolderService) ServiceInfo(ctx context.Context, req *v.InfoReq) (data interface{}, err error) {
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return
+	}
+	info, err := v.DBM(s.Model).Ctx(ctx).Where("id", req.Id).One()
+	if err != nil || info.IsEmpty() {
+		return g.Map{}, err
+	}
+	if !o.IsAdmin && info["userId"].Uint() != o.UserId {
+		shared, err := s.SharedIds(ctx, o.DepartmentId)
+		if err != nil {
+			return nil, err
+		}
+		if !containsId(shared, info["id"].Int64()) {
+			return nil, gerror.New("没有权限查看该文件夹")
+		}
+	}
+	departmentIds, err := v.DBM(model.NewSpaceFolderDepartment()).Ctx(ctx).Where("folderId", req.Id).Array("departmentId")
+	if err != nil {
+		return
+	}
+	result := gconv.Map(info)
+	result["departmentIdList"] = gconv.SliceInt64(departmentIds)
+	data = result
+	return
+}
+
+// CheckModify 检查当前管理员是否为文件夹的所有者
+func (s *SpaceFolderService) CheckModify(ctx g.Ctx, o *owner, id int64) error {
+	folder, err := v.DBM(s.Model).Ctx(ctx).Fields("userId").Where("id", id).One()
+	if err != nil {
+		return err
+	}
+	if folder.IsEmpty() {
+		return gerror.New("文件夹不存在")
+	}
+	if !o.IsAdmin && folder["userId"].Uint() != o.UserId {
+		return gerror.New("没有权限修改该文件夹")
+	}
+	return nil
+}
+
+// checkMove 检查文件夹能否移动到 parentId 下, 不能移动到自身或子文件夹下
+func (s *SpaceFolderService) checkMove(ctx g.Ctx, o *owner, id, parentId int64) error {
+	if err := s.CheckModify(ctx, o, parentId); err != nil {
+		return err
+	}
+	children, err := s.Descendants(ctx, []int64{id})
+	if err != nil {
+		return err
+	}
+	if containsId(children, parentId) {
+		return gerror.New("不能移动到自身或子文件夹下")
+	}
+	return nil
+}
+
+// SharedIds 共享给部门的文件夹及其子文件夹ID
+func (s *SpaceFolderService) SharedIds(ctx g.Ctx, departmentId int64) ([]int64, error) {
+	if departmentId == 0 {
+		return nil, nil
+	}
+	ids, err := v.DBM(model.NewSpaceFolderDepartment()).Ctx(ctx).Where("departmentId", departmentId).Array("folderId")
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	return s.Descendants(ctx, gconv.SliceInt64(ids))
+}
+
+// Descendants 返回文件夹及其所有子文件夹的ID
+func (s *SpaceFolderService) Descendants(ctx g.Ctx, ids []int64) ([]int64, error) {
+	folders, err := v.DBM(s.Model).Ctx(ctx).Fields("id", "parentId").WhereNotNull("parentId").All()
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[int64][]int64)
+	for _, folder := range folders {
+		parentId := folder["parentId"].Int64()
+		children[parentId] = append(children[parentId], folder["id"].Int64())
+	}
+	var (
+		result  []int64
+		visited = make(map[int64]bool)
+	)
+	for len(ids) > 0 {
+		id := ids[0]
+		ids = ids[1:]
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		result = append(result, id)
+		ids = append(ids, children[id]...)
+	}
+	return result, nil
+}
+
+// updateShares 更新文件夹共享的部门
+func (s *SpaceFolderService) updateShares(ctx g.Ctx, folderId int64, departmentIds []uint) (err error) {
+	if _, err = v.DBM(model.NewSpaceFolderDepartment()).Ctx(ctx).Unscoped().Where("folderId", folderId).Delete(); err != nil {
+		return
+	}
+	if len(departmentIds) == 0 {
+		return
+	}
+	list := make([]g.MapStrAny, len(departmentIds))
+	for i, departmentId := range departmentIds {
+		list[i] = g.MapStrAny{
+			"folderId":     folderId,
+			"departmentId": departmentId,
+		}
+	}
+	_, err = v.DBM(model.NewSpaceFolderDepartment()).Ctx(ctx).Data(list).Insert()
+	return
+}
+
+// containsId 判断ID是否在列表中
+func containsId(ids []int64, id int64) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
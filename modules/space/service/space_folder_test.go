@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// TestSpaceFolderVisible 测试只能查看自己的和共享给部门的文件夹, 只有所有者可以修改
+func TestSpaceFolderVisible(t *testing.T) {
+	setupTestDB(t, map[uint]int64{1: 10, 2: 10, 3: 20, 4: 20})
+	gtest.C(t, func(t *gtest.T) {
+		shared := addTestFolder(t.T, "shared", 0, 1, 10)
+		addTestShare(t.T, shared, 10)
+		child := addTestFolder(t.T, "child", shared, 1, 10)
+		private := addTestFolder(t.T, "private", 0, 1, 10)
+		other := addTestFolder(t.T, "other", 0, 3, 20)
+
+		visible := func(ctx g.Ctx) []int64 {
+			ids, err := visibleFolders(ctx, g.DB().Model("space_folder")).OrderAsc("id").Array("id")
+			t.AssertNil(err)
+			return gconv.SliceInt64(ids)
+		}
+		t.Assert(visible(testContext(1, "u1")), []int64{shared, child, private})
+		t.Assert(visible(testContext(2, "u2")), []int64{shared, child})
+		t.Assert(visible(testContext(3, "u3")), []int64{other})
+		t.Assert(visible(testContext(4, "admin")), []int64{shared, child, private, other})
+
+		s := NewSpaceFolderService()
+		ctx := testContext(2, "u2")
+		t.AssertNE(s.CheckModify(ctx, &owner{UserId: 2, DepartmentId: 10}, child), nil)
+		t.AssertNE(s.CheckModify(ctx, &owner{UserId: 2, DepartmentId: 10}, 999), nil)
+		t.AssertNil(s.CheckModify(ctx, &owner{UserId: 1, DepartmentId: 10}, child))
+		t.AssertNil(s.CheckModify(ctx, &owner{UserId: 4, IsAdmin: true}, other))
+	})
+}
+
+// TestSpaceFolderDelete 测试删除文件夹时删除子文件夹和其中的文件并减少文件引用
+func TestSpaceFolderDelete(t *testing.T) {
+	d := setupTestDB(t, map[uint]int64{1: 10, 2: 10})
+	gtest.C(t, func(t *gtest.T) {
+		parent := addTestFolder(t.T, "parent", 0, 1, 10)
+		child := addTestFolder(t.T, "child", parent, 1, 10)
+		kept := addTestFolder(t.T, "kept", 0, 1, 10)
+		a := addTestFile(t.T, "uploads/a.txt", 1, 4, 1)
+		b := addTestFile(t.T, "uploads/b.txt", 1, 4, 2)
+		addTestInfo(t.T, "uploads/a.txt", child, 1, 10, 4)
+		addTestInfo(t.T, "uploads/b.txt", parent, 1, 10, 4)
+		addTestInfo(t.T, "uploads/b.txt", kept, 1, 10, 4)
+
+		s := NewSpaceFolderService()
+		_, err := s.ServiceDelete(testContext(2, "u2", g.Map{"ids": []int64{parent}}), nil)
+		t.AssertNE(err, nil)
+
+		_, err = s.ServiceDelete(testContext(1, "u1", g.Map{"ids": []int64{parent}}), nil)
+		t.AssertNil(err)
+		folders, err := g.DB().Model("space_folder").Array("id")
+		t.AssertNil(err)
+		t.Assert(gconv.SliceInt64(folders), []int64{kept})
+		count, err := g.DB().Model("space_info").Count()
+		t.AssertNil(err)
+		t.Assert(count, 1)
+
+		// 没有引用的文件从存储中删除, 仍被引用的文件只减少引用
+		count, err = g.DB().Model("space_file").Where("url", a).Count()
+		t.AssertNil(err)
+		t.Assert(count, 0)
+		refCount, err := g.DB().Model("space_file").Where("url", b).Value("refCount")
+		t.AssertNil(err)
+		t.Assert(refCount.Int(), 1)
+		t.Assert(d.deleted, []string{"uploads/a.txt"})
+	})
+}
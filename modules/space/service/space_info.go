@@ -1,20 +1,343 @@
 package service
 
 import (
+	"context"
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/vera-byte/vgo/modules/space/model"
 	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vfile"
 )
 
+// 迁移文件存储时改写空间文件地址, 非临时的空间文件算作对文件的引用
+func init() {
+	v.RegisterTemporaryFileColumn(model.NewSpaceInfo(), "url", "temporary")
+}
+
 type SpaceInfoService struct {
 	*v.Service
 }
 
 func NewSpaceInfoService() *SpaceInfoService {
+	queryOp := &v.QueryOp{
+		FieldEQ:      []string{"folderId", "classifyId", "type"},
+		KeyWordField: []string{"fileName"},
+		AddOrderby:   g.MapStrStr{"createTime": "DESC"},
+		Extend: func(ctx g.Ctx, m *gdb.Model) *gdb.Model {
+			return visibleFiles(ctx, m)
+		},
+	}
 	return &SpaceInfoService{
 		&v.Service{
-			Model: model.NewSpaceInfo(),
+			Model:       model.NewSpaceInfo(),
+			ListQueryOp: queryOp,
+			PageQueryOp: queryOp,
+			// 所有者为当前管理员, 文件名和大小从文件元数据或存储中读取, 不使用请求中的大小, 表单中上传的文件传入 temporary 为临时文件
+			InsertParam: func(ctx context.Context) g.MapStrAny {
+				o, err := currentOwner(ctx)
+				if err != nil {
+					return nil
+				}
+				r := g.RequestFromCtx(ctx)
+				url := r.Get("url").String()
+				param := g.MapStrAny{
+					"userId":       o.UserId,
+					"departmentId": o.DepartmentId,
+					"temporary":    gconv.Int(r.Get("temporary").Bool()),
+					"size":         int64(0),
+				}
+				file, err := v.DBM(model.NewSpaceFile()).Ctx(ctx).Fields("fileName", "size").Where("url", url).One()
+				if err == nil && !file.IsEmpty() {
+					if r.Get("fileName").IsEmpty() {
+						param["fileName"] = file["fileName"]
+					}
+					param["size"] = file["size"].Int64()
+				} else if info, err := statURL(ctx, url); err == nil {
+					param["size"] = info.Size
+				}
+				return param
+			},
 		},
 
 		// Service: v.NewService(model.NewSpaceInfo()),
 	}
 }
+
+// statURL 从当前存储驱动中获取访问地址对应的文件信息
+func statURL(ctx g.Ctx, url string) (*vfile.ObjectInfo, error) {
+	d, err := vfile.Use("")
+	if err != nil {
+		return nil, err
+	}
+	key, ok := vfile.Key(d, url)
+	if !ok {
+		return nil, vfile.ErrNotFound
+	}
+	return vfile.Stat(ctx, d, key)
+}
+
+// visibleFiles 只查询当前管理员自己的和共享文件夹中的文件
+func visibleFiles(ctx g.Ctx, m *gdb.Model) *gdb.Model {
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return m.Where("1 = 0")
+	}
+	if o.IsAdmin {
+		return m
+	}
+	shared, err := NewSpaceFolderService().SharedIds(ctx, o.DepartmentId)
+	if err != nil {
+		g.Log().Error(ctx, err)
+	}
+	builder := m.Builder().Where("userId", o.UserId)
+	if len(shared) > 0 {
+		builder = builder.WhereOrIn("folderId", shared)
+	}
+	return m.Where(builder)
+}
+
+// ModifyBefore 新增文件时检查文件地址和目标文件夹, 删除文件前检查所有者
+func (s *SpaceInfoService) ModifyBefore(ctx g.Ctx, method string, param g.MapStrAny) (err error) {
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return
+	}
+	switch method {
+	case "Add":
+		if err = s.checkClaim(ctx, o, gconv.String(param["url"])); err != nil {
+			return
+		}
+		if param["folderId"] != nil {
+			err = NewSpaceFolderService().CheckModify(ctx, o, gconv.Int64(param["folderId"]))
+		}
+	case "Delete":
+		err = s.CheckModify(ctx, o, gconv.SliceInt64(param["ids"]))
+	}
+	return
+}
+
+// ModifyAfter 删除文件后减少文件引用, 没有引用时从存储驱动删除
+func (s *SpaceInfoService) ModifyAfter(ctx g.Ctx, method string, param g.MapStrAny) (err error) {
+	if method == "Delete" {
+		err = s.release(ctx, gconv.SliceInt64(param["ids"]))
+	}
+	return
+}
+
+// ServiceInfo 文件详情, 只能查看自己的和共享文件夹中的文件
+func (s *SpaceInfoService) ServiceInfo(ctx context.Context, req *v.InfoReq) (data interface{}, err error) {
+	info, err := visibleFiles(ctx, v.DBM(s.Model).Ctx(ctx)).Where("id", req.Id).One()
+	if err != nil {
+		return
+	}
+	if info.IsEmpty() {
+		return nil, gerror.New("文件不存在或没有权限查看")
+	}
+	data = info
+	return
+}
+
+// ServiceUpdate 修改文件名、分类、类型和所在文件夹, 只有所有者可以修改
+func (s *SpaceInfoService) ServiceUpdate(ctx context.Context, req *v.UpdateReq) (data interface{}, err error) {
+	r := g.RequestFromCtx(ctx).GetMap()
+	id := gconv.Int64(r["id"])
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return
+	}
+	if err = s.CheckModify(ctx, o, []int64{id}); err != nil {
+		return
+	}
+	if value, ok := r["folderId"]; ok {
+		var folderId *int64
+		if value != nil {
+			id := gconv.Int64(value)
+			folderId = &id
+		}
+		if err = s.Move(ctx, []int64{id}, folderId); err != nil {
+			return
+		}
+	}
+	update := g.Map{}
+	for _, field := range []string{"fileName", "classifyId", "type"} {
+		if value, ok := r[field]; ok {
+			update[field] = value
+		}
+	}
+	if len(update) > 0 {
+		_, err = v.DBM(s.Model).Ctx(ctx).Data(update).Where("id", id).Update()
+	}
+	return
+}
+
+// Move 将文件移动到文件夹, folderId 为nil时移动到根目录
+func (s *SpaceInfoService) Move(ctx g.Ctx, ids []int64, folderId *int64) (err error) {
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return
+	}
+	if err = s.CheckModify(ctx, o, ids); err != nil {
+		return
+	}
+	if folderId != nil {
+		if err = NewSpaceFolderService().CheckModify(ctx, o, *folderId); err != nil {
+			return
+		}
+	}
+	_, err = v.DBM(s.Model).Ctx(ctx).Data("folderId", folderId).WhereIn("id", ids).Update()
+	return
+}
+
+// Rename 修改文件名
+func (s *SpaceInfoService) Rename(ctx g.Ctx, id int64, fileName string) (err error) {
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return
+	}
+	if err = s.CheckModify(ctx, o, []int64{id}); err != nil {
+		return
+	}
+	_, err = v.DBM(s.Model).Ctx(ctx).Data("fileName", fileName).Where("id", id).Update()
+	return
+}
+
+// Remove 删除文件并减少文件引用, 没有引用时从存储驱动删除, 调用方负责检查所有者
+func (s *SpaceInfoService) Remove(ctx g.Ctx, ids []int64) (err error) {
+	if len(ids) == 0 {
+		return
+	}
+	if _, err = v.DBM(s.Model).Ctx(ctx).WhereIn("id", ids).Delete(); err != nil {
+		return
+	}
+	return s.release(ctx, ids)
+}
+
+// CheckModify 检查当前管理员是否为所有文件的所有者
+func (s *SpaceInfoService) CheckModify(ctx g.Ctx, o *owner, ids []int64) error {
+	if len(ids) == 0 {
+		return gerror.New("请选择文件")
+	}
+	if o.IsAdmin {
+		return nil
+	}
+	m := v.DBM(s.Model).Ctx(ctx)
+	count, err := m.WhereIn("id", ids).Where(m.Builder().WhereNot("userId", o.UserId).WhereOrNull("userId")).Count()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return gerror.New("没有权限修改该文件")
+	}
+	return nil
+}
+
+// checkClaim 检查新增的空间文件能否使用该地址, 当前管理员需要上传过该文件, 且其上传获得的引用没有被自己登记过的空间文件占用
+// 删除空间文件时会减少文件引用, 使用他人文件的地址新增后再删除会导致存储中的文件被删除
+// 已删除的空间文件同样算作占用过引用, 其引用在删除时已经释放
+func (s *SpaceInfoService) checkClaim(ctx g.Ctx, o *owner, url string) error {
+	if url == "" {
+		return gerror.New("文件地址不能为空")
+	}
+	file, err := v.DBM(model.NewSpaceFile()).Ctx(ctx).Fields("id", "refCount").Where("url", url).One()
+	if err != nil {
+		return err
+	}
+	if file.IsEmpty() {
+		return gerror.New("文件不存在")
+	}
+	if !o.IsAdmin {
+		uploads, err := v.DBM(model.NewSpaceFileUpload()).Ctx(ctx).Where("fileId", file["id"]).Where("userId", o.UserId).Count()
+		if err != nil {
+			return err
+		}
+		claimed, err := v.DBM(s.Model).Ctx(ctx).Unscoped().Where("url", url).Where("userId", o.UserId).Count()
+		if err != nil {
+			return err
+		}
+		if uploads <= claimed {
+			return gerror.New("不是当前管理员上传的文件或已登记到空间, 请重新上传")
+		}
+	}
+	count, err := v.DBM(s.Model).Ctx(ctx).Where("url", url).Count()
+	if err != nil {
+		return err
+	}
+	if count >= file["refCount"].Int() {
+		return gerror.New("文件已登记到空间, 请重新上传")
+	}
+	return nil
+}
+
+// release 减少已删除文件的引用, 没有引用时从存储驱动删除
+func (s *SpaceInfoService) release(ctx g.Ctx, ids []int64) error {
+	urls, err := v.DBM(s.Model).Ctx(ctx).Unscoped().WhereIn("id", ids).Array("url")
+	if err != nil {
+		return err
+	}
+	for _, url := range urls {
+		if err = vfile.ReleaseRecord(ctx, url.String()); err != nil {
+			g.Log().Warningf(ctx, "删除文件 %s 失败: %v", url.String(), err)
+		}
+	}
+	return nil
+}
+
+// Confirm 确认浏览器直传的文件并登记到空间
+// key: 直传凭证中的文件键
+// fileType: 文件类型, 为空时按文件的Content-Type取大类
+// classifyId: 分类ID
+// folderId: 文件夹ID, 为nil时放在根目录
+// temporary: 是否为临时文件, 表单中上传的文件在业务数据保存前为临时文件
+func (s *SpaceInfoService) Confirm(ctx g.Ctx, key, fileType string, classifyId, folderId *int64, temporary bool) (data g.Map, err error) {
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return
+	}
+	if folderId != nil {
+		if err = NewSpaceFolderService().CheckModify(ctx, o, *folderId); err != nil {
+			return
+		}
+	}
+	info, url, err := v.ConfirmUpload(ctx, key)
+	if err != nil {
+		return
+	}
+	if fileType == "" {
+		fileType = strings.Split(info.ContentType, "/")[0]
+	}
+	fileName, err := v.DBM(model.NewSpaceFile()).Ctx(ctx).Where("url", url).Value("fileName")
+	if err != nil {
+		return
+	}
+	id, err := v.DBM(s.Model).Data(g.Map{
+		"url":          url,
+		"type":         fileType,
+		"classifyId":   classifyId,
+		"folderId":     folderId,
+		"userId":       o.UserId,
+		"departmentId": o.DepartmentId,
+		"fileName":     fileName.String(),
+		"size":         info.Size,
+		"temporary":    gconv.Int(temporary),
+	}).InsertAndGetId()
+	if err != nil {
+		return
+	}
+	if !temporary {
+		if err = vfile.Keep(ctx, url); err != nil {
+			return
+		}
+	}
+	data = g.Map{
+		"id":   id,
+		"url":  url,
+		"key":  info.Key,
+		"size": info.Size,
+		"type": fileType,
+	}
+	return
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// TestSpaceInfoAddForeignURL 测试不能使用他人文件的地址新增空间文件, 避免删除时释放他人的文件引用
+func TestSpaceInfoAddForeignURL(t *testing.T) {
+	d := setupTestDB(t, map[uint]int64{1: 0, 2: 0})
+	gtest.C(t, func(t *gtest.T) {
+		s := NewSpaceInfoService()
+		owner, other := testContext(1, "owner"), testContext(2, "other")
+		url := addTestFile(t.T, "uploads/a.txt", 1, 4, 1)
+
+		// 上传者登记到空间后, 上传时获得的引用已被占用
+		t.AssertNil(s.ModifyBefore(owner, "Add", g.MapStrAny{"url": url}))
+		_, err := g.DB().Model("space_info").Data(g.Map{"url": url, "userId": 1}).Insert()
+		t.AssertNil(err)
+		t.AssertNE(s.ModifyBefore(owner, "Add", g.MapStrAny{"url": url}), nil)
+
+		// 其他管理员使用该地址新增被拒绝, 文件引用和存储中的文件不受影响
+		t.AssertNE(s.ModifyBefore(other, "Add", g.MapStrAny{"url": url}), nil)
+		t.AssertNE(s.ModifyBefore(other, "Add", g.MapStrAny{"url": "http://127.0.0.1/uploads/none.txt"}), nil)
+		refCount, err := g.DB().Model("space_file").Where("url", url).Value("refCount")
+		t.AssertNil(err)
+		t.Assert(refCount.Int(), 1)
+		t.Assert(len(d.deleted), 0)
+
+		// 同一文件再次上传后可以再登记一次
+		_, err = vfile.TrackRecord(owner, d, &vfile.Record{Key: "uploads/a2.txt", URL: "http://127.0.0.1/uploads/a2.txt", Hash: "uploads/a.txt"}, "uploads/a2.txt")
+		t.AssertNil(err)
+		t.AssertNil(s.ModifyBefore(owner, "Add", g.MapStrAny{"url": url}))
+	})
+}
+
+// TestSpaceInfoAddSameContent 测试不同管理员上传相同内容后都可以登记, 全部删除后才删除存储中的文件
+func TestSpaceInfoAddSameContent(t *testing.T) {
+	d := setupTestDB(t, map[uint]int64{1: 0, 2: 0, 3: 0})
+	gtest.C(t, func(t *gtest.T) {
+		s := NewSpaceInfoService()
+		first, second, other := testContext(1, "first"), testContext(2, "second"), testContext(3, "other")
+		upload := func(ctx g.Ctx, key string) string {
+			record, err := vfile.TrackRecord(ctx, d, &vfile.Record{Key: key, URL: "http://127.0.0.1/" + key, Hash: "same", Size: 4}, key)
+			t.AssertNil(err)
+			return record.URL
+		}
+		url := upload(first, "uploads/a.txt")
+		t.Assert(upload(second, "uploads/b.txt"), url)
+		t.Assert(d.deleted, []string{"uploads/b.txt"})
+
+		var ids []int64
+		for _, user := range []struct {
+			ctx    g.Ctx
+			userId uint
+		}{{first, 1}, {second, 2}} {
+			t.AssertNil(s.ModifyBefore(user.ctx, "Add", g.MapStrAny{"url": url}))
+			id, err := g.DB().Model("space_info").Data(g.Map{"url": url, "userId": user.userId}).InsertAndGetId()
+			t.AssertNil(err)
+			ids = append(ids, id)
+			t.AssertNE(s.ModifyBefore(user.ctx, "Add", g.MapStrAny{"url": url}), nil)
+		}
+		t.AssertNE(s.ModifyBefore(other, "Add", g.MapStrAny{"url": url}), nil)
+
+		// 删除后不能再次登记, 全部删除后才删除存储中的文件
+		t.AssertNil(s.Remove(second, ids[1:]))
+		t.AssertNE(s.ModifyBefore(second, "Add", g.MapStrAny{"url": url}), nil)
+		t.Assert(d.deleted, []string{"uploads/b.txt"})
+		t.AssertNil(s.Remove(first, ids[:1]))
+		t.Assert(d.deleted, []string{"uploads/b.txt", "uploads/a.txt"})
+		count, err := g.DB().Model("space_file_upload").Count()
+		t.AssertNil(err)
+		t.Assert(count, 0)
+	})
+}
+
+// TestSpaceInfoAddSize 测试新增空间文件时大小从文件元数据读取, 不使用请求中的大小
+func TestSpaceInfoAddSize(t *testing.T) {
+	setupTestDB(t, map[uint]int64{1: 10})
+	gtest.C(t, func(t *gtest.T) {
+		s := NewSpaceInfoService()
+		url := addTestFile(t.T, "uploads/a.txt", 1, 4, 1)
+		ctx := testContext(1, "owner", g.Map{"url": url, "size": 999})
+		_, err := s.ServiceAdd(ctx, nil)
+		t.AssertNil(err)
+		info, err := g.DB().Model("space_info").Where("url", url).One()
+		t.AssertNil(err)
+		t.Assert(info["size"].Int64(), 4)
+		t.Assert(info["userId"].Uint(), 1)
+		t.Assert(info["departmentId"].Int64(), 10)
+
+		// 没有文件元数据时从存储中读取, 读取失败时为0
+		url = "http://127.0.0.1/uploads/none.txt"
+		_, err = s.ServiceAdd(testContext(1, "owner", g.Map{"url": url, "size": 999}), nil)
+		t.AssertNil(err)
+		size, err := g.DB().Model("space_info").Where("url", url).Value("size")
+		t.AssertNil(err)
+		t.Assert(size.Int64(), 0)
+	})
+}
+
+// TestSpaceInfoVisible 测试只能查看自己的和共享文件夹中的文件, 只能修改自己的文件
+func TestSpaceInfoVisible(t *testing.T) {
+	setupTestDB(t, map[uint]int64{1: 10, 2: 10, 3: 20, 4: 20})
+	gtest.C(t, func(t *gtest.T) {
+		shared := addTestFolder(t.T, "shared", 0, 1, 10)
+		addTestShare(t.T, shared, 10)
+		child := addTestFolder(t.T, "child", shared, 1, 10)
+		private := addTestFolder(t.T, "private", 0, 1, 10)
+		inChild := addTestInfo(t.T, "uploads/a.txt", child, 1, 10, 1)
+		inPrivate := addTestInfo(t.T, "uploads/b.txt", private, 1, 10, 1)
+		own := addTestInfo(t.T, "uploads/c.txt", 0, 2, 10, 1)
+		other := addTestInfo(t.T, "uploads/d.txt", 0, 3, 20, 1)
+
+		visible := func(ctx g.Ctx) []int64 {
+			ids, err := visibleFiles(ctx, g.DB().Model("space_info")).OrderAsc("id").Array("id")
+			t.AssertNil(err)
+			return gconv.SliceInt64(ids)
+		}
+		t.Assert(visible(testContext(1, "u1")), []int64{inChild, inPrivate})
+		t.Assert(visible(testContext(2, "u2")), []int64{inChild, own})
+		t.Assert(visible(testContext(3, "u3")), []int64{other})
+		t.Assert(visible(testContext(4, "admin")), []int64{inChild, inPrivate, own, other})
+		t.Assert(len(visible(context.Background())), 0)
+
+		s := NewSpaceInfoService()
+		t.AssertNE(s.CheckModify(testContext(2, "u2"), &owner{UserId: 2, DepartmentId: 10}, []int64{inChild}), nil)
+		t.AssertNE(s.CheckModify(testContext(2, "u2"), &owner{UserId: 2, DepartmentId: 10}, []int64{own, inChild}), nil)
+		t.AssertNil(s.CheckModify(testContext(2, "u2"), &owner{UserId: 2, DepartmentId: 10}, []int64{own}))
+		t.AssertNil(s.CheckModify(testContext(4, "admin"), &owner{UserId: 4, IsAdmin: true}, []int64{inChild, other}))
+		_, err := s.ServiceInfo(testContext(3, "u3"), &v.InfoReq{Id: int(inChild)})
+		t.AssertNE(err, nil)
+	})
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/modules/space/model"
+	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// 上传文件前检查用户和部门的存储配额
+func init() {
+	vfile.RegisterQuota(NewSpaceQuotaService())
+}
+
+// SpaceQuotaService 用户和部门的存储配额, 实现 vfile.Quota
+// 已使用的空间按空间文件的大小统计, 没有配置配额时不限制
+type SpaceQuotaService struct {
+	*v.Service
+}
+
+func NewSpaceQuotaService() *SpaceQuotaService {
+	return &SpaceQuotaService{
+		&v.Service{
+			Model: model.NewSpaceQuota(),
+			UniqueKey: g.MapStrStr{
+				"userId":       "该用户已设置存储配额",
+				"departmentId": "该部门已设置存储配额",
+			},
+			PageQueryOp: &v.QueryOp{
+				FieldEQ: []string{"userId", "departmentId"},
+			},
+		},
+	}
+}
+
+// ModifyBefore 用户和部门只能设置一个
+func (s *SpaceQuotaService) ModifyBefore(ctx g.Ctx, method string, param g.MapStrAny) (err error) {
+	if method == "Add" || method == "Update" {
+		if (param["userId"] == nil) == (param["departmentId"] == nil) {
+			return gerror.New("请选择用户或部门中的一个")
+		}
+	}
+	return
+}
+
+// Check 检查当前管理员和所在部门上传 size 字节后是否超出配额
+func (s *SpaceQuotaService) Check(ctx g.Ctx, size int64) error {
+	// 没有请求时为命令行或任务中的上传, 不检查配额
+	if g.RequestFromCtx(ctx) == nil {
+		return nil
+	}
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return err
+	}
+	used, maxSize, err := s.usage(ctx, "userId", int64(o.UserId))
+	if err != nil {
+		return err
+	}
+	if maxSize > 0 && used+size > maxSize {
+		return gerror.Newf("个人存储空间不足, 已使用%d字节, 上限%d字节", used, maxSize)
+	}
+	if o.DepartmentId == 0 {
+		return nil
+	}
+	used, maxSize, err = s.usage(ctx, "departmentId", o.DepartmentId)
+	if err != nil {
+		return err
+	}
+	if maxSize > 0 && used+size > maxSize {
+		return gerror.Newf("部门存储空间不足, 已使用%d字节, 上限%d字节", used, maxSize)
+	}
+	return nil
+}
+
+// Usage 当前管理员和所在部门已使用的空间和配额, 配额为0时不限制
+func (s *SpaceQuotaService) Usage(ctx g.Ctx) (data g.Map, err error) {
+	o, err := currentOwner(ctx)
+	if err != nil {
+		return
+	}
+	userUsed, userMaxSize, err := s.usage(ctx, "userId", int64(o.UserId))
+	if err != nil {
+		return
+	}
+	data = g.Map{
+		"userUsed":          userUsed,
+		"userMaxSize":       userMaxSize,
+		"departmentUsed":    int64(0),
+		"departmentMaxSize": int64(0),
+	}
+	if o.DepartmentId > 0 {
+		data["departmentUsed"], data["departmentMaxSize"], err = s.usage(ctx, "departmentId", o.DepartmentId)
+	}
+	return
+}
+
+// usage 按用户或部门统计已使用的空间并读取配额
+func (s *SpaceQuotaService) usage(ctx g.Ctx, field string, id int64) (used, maxSize int64, err error) {
+	sum, err := v.DBM(model.NewSpaceInfo()).Ctx(ctx).Where(field, id).Sum("size")
+	if err != nil {
+		return
+	}
+	value, err := v.DBM(s.Model).Ctx(ctx).Where(field, id).Value("maxSize")
+	if err != nil {
+		return
+	}
+	return int64(sum), value.Int64(), nil
+}
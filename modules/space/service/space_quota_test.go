@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// TestSpaceQuotaCheck 测试上传前按用户和部门的配额检查
+func TestSpaceQuotaCheck(t *testing.T) {
+	setupTestDB(t, map[uint]int64{1: 10, 2: 10, 3: 0})
+	gtest.C(t, func(t *gtest.T) {
+		for _, quota := range []g.Map{{"userId": 1, "maxSize": 100}, {"departmentId": 10, "maxSize": 150}} {
+			_, err := g.DB().Model("space_quota").Data(quota).Insert()
+			t.AssertNil(err)
+		}
+		addTestInfo(t.T, "uploads/a.txt", 0, 1, 10, 60)
+		addTestInfo(t.T, "uploads/b.txt", 0, 2, 10, 50)
+
+		s := NewSpaceQuotaService()
+		// 用户配额
+		t.AssertNil(s.Check(testContext(1, "u1"), 40))
+		t.AssertNE(s.Check(testContext(1, "u1"), 41), nil)
+		// 没有用户配额时检查部门配额
+		t.AssertNil(s.Check(testContext(2, "u2"), 40))
+		t.AssertNE(s.Check(testContext(2, "u2"), 41), nil)
+		// 没有配额和没有请求时不限制
+		t.AssertNil(s.Check(testContext(3, "u3"), 1<<30))
+		t.AssertNil(s.Check(context.Background(), 1<<30))
+	})
+}
@@ -3,6 +3,7 @@ package demo
 import (
 	_ "github.com/vera-byte/vgo/modules/space/cmd"
 	_ "github.com/vera-byte/vgo/modules/space/controller"
+	_ "github.com/vera-byte/vgo/modules/space/funcs"
 	_ "github.com/vera-byte/vgo/modules/space/middleware"
 )
 
@@ -25,4 +25,17 @@ type TaskInfoLogReq struct {
 	g.Meta `path:"/log" method:"GET" summary:"获取任务日志" tags:"任务管理"`
 	ID     int64 `json:"id"`
 	Status int   `json:"status"`
-}
\ No newline at end of file
+}
+
+// TaskInfoFuncsReq 已注册函数列表请求结构
+type TaskInfoFuncsReq struct {
+	g.Meta `path:"/funcs" method:"GET" summary:"获取已注册函数列表" tags:"任务管理"`
+}
+
+// TaskInfoCronPreviewReq Cron表达式预览请求结构
+type TaskInfoCronPreviewReq struct {
+	g.Meta `path:"/cronPreview" method:"GET" summary:"校验并预览Cron执行时间" tags:"任务管理"`
+	Cron   string `json:"cron"`
+	Every  uint   `json:"every"`
+	Count  int    `json:"count" d:"5"`
+}
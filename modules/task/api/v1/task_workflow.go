@@ -0,0 +1,16 @@
+package v1
+
+import "github.com/gogf/gf/v2/frame/g"
+
+// TaskWorkflowRunReq 运行工作流请求结构
+type TaskWorkflowRunReq struct {
+	g.Meta `path:"/run" method:"POST" summary:"运行工作流" tags:"任务工作流"`
+	ID     uint64 `json:"id" v:"required#请输入id"`
+}
+
+// TaskWorkflowGraphReq 工作流运行图请求结构
+type TaskWorkflowGraphReq struct {
+	g.Meta `path:"/graph" method:"GET" summary:"获取工作流运行图" tags:"任务工作流"`
+	ID     uint64 `json:"id"`
+	RunId  uint64 `json:"runId"`
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/v"
+	"github.com/vera-byte/vgo/v/vconfig"
+)
+
+// sConfig 配置
+type sConfig struct {
+	Http  *Http
+	Shell *Shell
+}
+
+// Http HTTP任务配置
+type Http struct {
+	Timeout     uint `json:"timeout"`     // 默认超时时间,单位秒
+	OutputLimit int  `json:"outputLimit"` // 日志中记录的响应内容最大长度
+}
+
+// Shell 命令任务配置
+type Shell struct {
+	Commands    []string `json:"commands"`    // 允许执行的命令白名单,需与任务配置中的command完全一致
+	Timeout     uint     `json:"timeout"`     // 默认超时时间,单位秒
+	MaxTimeout  uint     `json:"maxTimeout"`  // 任务可配置的最大超时时间,单位秒
+	OutputLimit int      `json:"outputLimit"` // 日志中记录的输出最大长度
+}
+
+// NewConfig new config
+func NewConfig() *sConfig {
+	var (
+		ctx g.Ctx
+	)
+	config := &sConfig{
+		Http: &Http{
+			Timeout:     v.GetCfgWithDefault(ctx, "modules.task.http.timeout", g.NewVar(30)).Uint(),
+			OutputLimit: v.GetCfgWithDefault(ctx, "modules.task.http.outputLimit", g.NewVar(2000)).Int(),
+		},
+		Shell: &Shell{
+			Commands:    v.GetCfgWithDefault(ctx, "modules.task.shell.commands", g.NewVar([]string{})).Strings(),
+			Timeout:     v.GetCfgWithDefault(ctx, "modules.task.shell.timeout", g.NewVar(60)).Uint(),
+			MaxTimeout:  v.GetCfgWithDefault(ctx, "modules.task.shell.maxTimeout", g.NewVar(3600)).Uint(),
+			OutputLimit: v.GetCfgWithDefault(ctx, "modules.task.shell.outputLimit", g.NewVar(2000)).Int(),
+		},
+	}
+
+	return config
+}
+
+// configSchema 配置的结构定义, 启动时校验
+var configSchema = vconfig.RegisterSchema("modules.task", newConfigSchema())
+
+// newConfigSchema 创建配置的结构定义
+func newConfigSchema() *vconfig.Schema {
+	schema := vconfig.SchemaOf[sConfig]()
+	schema.Field("http.timeout").AtLeast(1)
+	schema.Field("shell.timeout").AtLeast(1)
+	schema.Field("shell.maxTimeout").AtLeast(1)
+	return schema
+}
+
+// Config config
+var Config = NewConfig()
@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"github.com/vera-byte/vgo/modules/task/service"
+	"github.com/vera-byte/vgo/v"
+)
+
+type TaskDependController struct {
+	*v.Controller
+}
+
+func init() {
+	var task_depend_controller = &TaskDependController{
+		&v.Controller{
+			Perfix:  "/admin/task/depend",
+			Api:     []string{"Add", "Delete", "Update", "Info", "List", "Page"},
+			Service: service.NewTaskDependService(),
+		},
+	}
+	// 注册路由
+	v.RegisterController(task_depend_controller)
+}
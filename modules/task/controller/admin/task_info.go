@@ -80,3 +80,29 @@ func (c *TaskInfoController) Log(ctx g.Ctx, req *v1.TaskInfoLogReq) (res *v.Base
 	res = v.Ok(data)
 	return
 }
+
+// Funcs 已注册函数列表
+// 功能: 获取所有已注册函数的名称、说明、单例/全部worker标识及参数Schema
+// 参数: ctx - 上下文, req - 函数列表请求
+// 返回值: res - 响应结果包含函数列表, err - 错误信息
+func (c *TaskInfoController) Funcs(ctx g.Ctx, req *v1.TaskInfoFuncsReq) (res *v.BaseRes, err error) {
+	data, err := v.ListFuncs()
+	if err != nil {
+		return v.Fail(err.Error()), err
+	}
+	res = v.Ok(data)
+	return
+}
+
+// CronPreview Cron表达式预览
+// 功能: 校验Cron表达式并返回接下来N次的执行时间
+// 参数: ctx - 上下文, req - Cron预览请求
+// 返回值: res - 响应结果包含执行时间列表, err - 错误信息
+func (c *TaskInfoController) CronPreview(ctx g.Ctx, req *v1.TaskInfoCronPreviewReq) (res *v.BaseRes, err error) {
+	data, err := c.Service.(*service.TaskInfoService).CronPreview(ctx, req.Cron, req.Every, req.Count)
+	if err != nil {
+		return v.Fail(err.Error()), err
+	}
+	res = v.Ok(data)
+	return
+}
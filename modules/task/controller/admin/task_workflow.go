@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	v1 "github.com/vera-byte/vgo/modules/task/api/v1"
+	"github.com/vera-byte/vgo/modules/task/service"
+	"github.com/vera-byte/vgo/v"
+)
+
+type TaskWorkflowController struct {
+	*v.Controller
+}
+
+func init() {
+	var task_workflow_controller = &TaskWorkflowController{
+		&v.Controller{
+			Perfix:  "/admin/task/workflow",
+			Api:     []string{"Add", "Delete", "Update", "Info", "List", "Page"},
+			Service: service.NewTaskWorkflowService(),
+		},
+	}
+	// 注册路由
+	v.RegisterController(task_workflow_controller)
+}
+
+// Run 运行工作流
+// 功能: 立即运行一次工作流, 返回运行记录ID
+// 参数: ctx - 上下文, req - 运行工作流请求
+// 返回值: res - 响应结果, err - 错误信息
+func (c *TaskWorkflowController) Run(ctx g.Ctx, req *v1.TaskWorkflowRunReq) (res *v.BaseRes, err error) {
+	runId, err := c.Service.(*service.TaskWorkflowService).Run(ctx, req.ID)
+	if err != nil {
+		return v.Fail(err.Error()), err
+	}
+	res = v.Ok(g.Map{"runId": runId})
+	return
+}
+
+// Graph 工作流运行图
+// 功能: 获取工作流的任务节点与依赖边, 指定 runId 时包含该次运行中各任务的状态
+// 参数: ctx - 上下文, req - 工作流运行图请求
+// 返回值: res - 响应结果包含图数据, err - 错误信息
+func (c *TaskWorkflowController) Graph(ctx g.Ctx, req *v1.TaskWorkflowGraphReq) (res *v.BaseRes, err error) {
+	if req.ID == 0 && req.RunId == 0 {
+		err = gerror.New("请输入id或runId")
+		return v.Fail(err.Error()), err
+	}
+	data, err := c.Service.(*service.TaskWorkflowService).Graph(ctx, req.ID, req.RunId)
+	if err != nil {
+		return v.Fail(err.Error()), err
+	}
+	res = v.Ok(data)
+	return
+}
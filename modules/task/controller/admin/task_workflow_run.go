@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"github.com/vera-byte/vgo/modules/task/service"
+	"github.com/vera-byte/vgo/v"
+)
+
+type TaskWorkflowRunController struct {
+	*v.Controller
+}
+
+func init() {
+	var task_workflow_run_controller = &TaskWorkflowRunController{
+		&v.Controller{
+			Perfix:  "/admin/task/workflowRun",
+			Api:     []string{"Info", "List", "Page"},
+			Service: service.NewTaskWorkflowRunService(),
+		},
+	}
+	// 注册路由
+	v.RegisterController(task_workflow_run_controller)
+}
@@ -41,6 +41,10 @@ func (t *TaskAddTask) IsAllWorker() bool {
 	return true
 }
 
+func (t *TaskAddTask) Description() string {
+	return "添加任务到调度器"
+}
+
 func init() {
 	v.RegisterFunc("TaskAddTask", &TaskAddTask{})
 }
@@ -0,0 +1,58 @@
+package funcs
+
+import (
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/modules/task/model"
+	"github.com/vera-byte/vgo/modules/task/service"
+	"github.com/vera-byte/vgo/v"
+)
+
+// TaskExecute 在主进程中立即执行一次任务并记录日志
+type TaskExecute struct {
+}
+
+func (t *TaskExecute) Func(ctx g.Ctx, param string) error {
+	j, err := gjson.DecodeToJson(param)
+	if err != nil {
+		return err
+	}
+	id := j.Get("id").String()
+	result, err := v.DBM(model.NewTaskInfo()).Where("id = ?", id).One()
+	if err != nil {
+		return err
+	}
+	if result.IsEmpty() {
+		return gerror.New("任务不存在")
+	}
+	taskInfoService := service.NewTaskInfoService()
+	detail, err := service.ExecuteTask(ctx, result["type"].Int(), result["service"].String())
+	if gerror.Is(err, service.ErrTaskSkipped) {
+		return nil
+	}
+	if err != nil {
+		return taskInfoService.Record(ctx, id, 0, err.Error())
+	}
+	return taskInfoService.Record(ctx, id, 1, detail)
+}
+
+func (t *TaskExecute) IsSingleton() bool {
+	return false
+}
+
+func (t *TaskExecute) IsAllWorker() bool {
+	return false
+}
+
+func (t *TaskExecute) ParamSchema() string {
+	return `{"type":"object","required":["id"],"properties":{"id":{"type":"integer","minimum":1}}}`
+}
+
+func (t *TaskExecute) Description() string {
+	return "执行HTTP/Shell等执行器类型的任务并记录日志"
+}
+
+func init() {
+	v.RegisterFunc("TaskExecute", &TaskExecute{})
+}
@@ -28,6 +28,10 @@ func (t *TaskStopFunc) IsAllWorker() bool {
 	return true
 }
 
+func (t *TaskStopFunc) Description() string {
+	return "停止指定的定时任务"
+}
+
 func init() {
 	v.RegisterFunc("TaskStopFunc", &TaskStopFunc{})
 }
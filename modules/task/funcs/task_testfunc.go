@@ -19,6 +19,10 @@ func (t *TaskTest) IsAllWorker() bool {
 	return true
 }
 
+func (t *TaskTest) Description() string {
+	return "测试函数, 打印参数"
+}
+
 func init() {
 	v.RegisterFunc("TaskTest", &TaskTest{})
 }
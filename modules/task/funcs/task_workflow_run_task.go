@@ -0,0 +1,40 @@
+package funcs
+
+import (
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/modules/task/service"
+	"github.com/vera-byte/vgo/v"
+)
+
+// TaskWorkflowRunTask 在主进程中执行工作流中的任务
+type TaskWorkflowRunTask struct {
+}
+
+func (t *TaskWorkflowRunTask) Func(ctx g.Ctx, param string) error {
+	j, err := gjson.DecodeToJson(param)
+	if err != nil {
+		return err
+	}
+	return service.NewTaskWorkflowService().RunTask(ctx, j.Get("runId").Uint64(), j.Get("taskId").Uint64())
+}
+
+func (t *TaskWorkflowRunTask) IsSingleton() bool {
+	return false
+}
+
+func (t *TaskWorkflowRunTask) IsAllWorker() bool {
+	return false
+}
+
+func (t *TaskWorkflowRunTask) ParamSchema() string {
+	return `{"type":"object","required":["runId","taskId"],"properties":{"runId":{"type":"integer","minimum":1},"taskId":{"type":"integer","minimum":1}}}`
+}
+
+func (t *TaskWorkflowRunTask) Description() string {
+	return "执行工作流运行中的任务节点"
+}
+
+func init() {
+	v.RegisterFunc("TaskWorkflowRunTask", &TaskWorkflowRunTask{})
+}
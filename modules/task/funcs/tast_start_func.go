@@ -44,6 +44,10 @@ func (t *TaskStartFunc) IsAllWorker() bool {
 	return true
 }
 
+func (t *TaskStartFunc) Description() string {
+	return "启动指定的定时任务"
+}
+
 func init() {
 	v.RegisterFunc("TaskStartFunc", &TaskStartFunc{})
 }
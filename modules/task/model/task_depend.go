@@ -0,0 +1,40 @@
+package model
+
+import (
+	"github.com/vera-byte/vgo/v"
+)
+
+const TableNameTaskDepend = "task_depend"
+
+// 依赖触发条件
+const (
+	TaskDependOnSuccess = 0 // 上游成功后触发
+	TaskDependOnFailure = 1 // 上游失败后触发
+	TaskDependOnDone    = 2 // 上游执行完成后触发
+)
+
+// TaskDepend mapped from table <task_depend>
+type TaskDepend struct {
+	*v.Model
+	WorkflowId uint64 `json:"workflowId"`
+	TaskId     uint64 `json:"taskId"`
+	DependId   uint64 `json:"dependId"`
+	Condition  int    `json:"condition"`
+}
+
+// TableName TaskDepend's table name
+func (*TaskDepend) TableName() string {
+	return TableNameTaskDepend
+}
+
+// GroupName TaskDepend's table group
+func (*TaskDepend) GroupName() string {
+	return "default"
+}
+
+// NewTaskDepend create a new TaskDepend
+func NewTaskDepend() *TaskDepend {
+	return &TaskDepend{
+		Model: v.NewModel(),
+	}
+}
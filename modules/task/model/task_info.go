@@ -7,6 +7,14 @@ import (
 
 const TableNameTaskInfo = "task_info"
 
+// 任务类型, 系统与用户任务执行注册的函数, 其他类型由对应的执行器执行
+const (
+	TaskTypeSystem = 0 // 系统任务
+	TaskTypeUser   = 1 // 用户任务
+	TaskTypeHttp   = 2 // HTTP请求任务
+	TaskTypeShell  = 3 // 本地命令任务
+)
+
 // TaskInfo mapped from table <task_info>
 type TaskInfo struct {
 	*v.Model
@@ -6,10 +6,18 @@ import (
 
 const TableNameTaskLog = "task_log"
 
+// 任务日志状态
+const (
+	TaskLogFailed  = 0 // 失败
+	TaskLogSuccess = 1 // 成功
+	TaskLogSkipped = 2 // 工作流中条件不满足被跳过
+)
+
 // TaskLog mapped from table <task_log>
 type TaskLog struct {
 	*v.Model
 	TaskId uint64 `json:"taskId"`
+	RunId  uint64 `json:"runId"`
 	Status uint8  `json:"status"`
 	Detail string `json:"detail"`
 }
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/vera-byte/vgo/v"
+)
+
+const TableNameTaskWorkflow = "task_workflow"
+
+// TaskWorkflow mapped from table <task_workflow>
+type TaskWorkflow struct {
+	*v.Model
+	Name   string `json:"name"`
+	Remark string `json:"remark"`
+	Status int    `json:"status"`
+}
+
+// TableName TaskWorkflow's table name
+func (*TaskWorkflow) TableName() string {
+	return TableNameTaskWorkflow
+}
+
+// GroupName TaskWorkflow's table group
+func (*TaskWorkflow) GroupName() string {
+	return "default"
+}
+
+// NewTaskWorkflow create a new TaskWorkflow
+func NewTaskWorkflow() *TaskWorkflow {
+	return &TaskWorkflow{
+		Model: v.NewModel(),
+	}
+}
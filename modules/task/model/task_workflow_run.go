@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/vera-byte/vgo/v"
+)
+
+const TableNameTaskWorkflowRun = "task_workflow_run"
+
+// 工作流运行状态
+const (
+	TaskWorkflowRunRunning = 0 // 运行中
+	TaskWorkflowRunSuccess = 1 // 成功
+	TaskWorkflowRunFailed  = 2 // 失败
+)
+
+// TaskWorkflowRun mapped from table <task_workflow_run>
+type TaskWorkflowRun struct {
+	*v.Model
+	WorkflowId uint64    `json:"workflowId"`
+	TriggerId  uint64    `json:"triggerId"`
+	Status     int       `json:"status"`
+	EndTime    time.Time `json:"endTime"`
+}
+
+// TableName TaskWorkflowRun's table name
+func (*TaskWorkflowRun) TableName() string {
+	return TableNameTaskWorkflowRun
+}
+
+// GroupName TaskWorkflowRun's table group
+func (*TaskWorkflowRun) GroupName() string {
+	return "default"
+}
+
+// NewTaskWorkflowRun create a new TaskWorkflowRun
+func NewTaskWorkflowRun() *TaskWorkflowRun {
+	return &TaskWorkflowRun{
+		Model: v.NewModel(),
+	}
+}
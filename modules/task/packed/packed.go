@@ -3,7 +3,7 @@ package packed
 import "github.com/gogf/gf/v2/os/gres"
 
 func init() {
-	if err := gres.Add("H4sIAAAAAAAC/6RWeTjUe/v+DjMZ2SZZfpaQYpJlSKUzlsq+dNCUn7axy8jYZhBZTw4pg7GFaJQ1y1TWZAlROWjI0MiQGMUYZC1LeK86l2PqVdf1vu/nn88/z3M/z3U/z3Xfj6UZJ1gIgAIAIGKJOA+wvR0AN4Bzxnv64hydEa4erj6X8J4eVqchAKid8QJjacYFZY/ewIH+G47MVjgIH3u8m62rx0VPle9gfeJfmqqrCUV+xgtfOxucecYEdSM1V/i4l9zA9ZaS35JLqCdMKELRVactwRBR3/OWzRFVwvv91c1ZzsahkZV7EqxItbUW5qXSQAYEAOyddPnu8lrn06v3OBUaKIs7yiXtNzmZICFDqyjGnLTZqTsyVO6+xquYq4hTw+6Tl8dHSV566CrZsWiCK3e8GZ99eLf1TvqZDyL5eUMrz8amcL2BrTqBc9FwvwPc+/Y1tfq/N/LuvrEdNU5kieQq+7w0WaW7f1qUbT23T3lkenypJlTdKlZnqox27UGjTM3U6CQp/pT0JSL+nT9P2nZTtxC9cEJbcyJLf+Gv1PQxV82KhUsnxvwSGaF8BO3Vjmf97nGOsqGSyPd13ZQQx7vxrzTcyrCAl5TkFzAArK9/ZT9b7P+04CAAWAdtsg8A4+KaP5+iu6sLzt7HGf+N7ntnaL+Y4o84alvhIFRV1Wwdcc72Ps623wbqY++AdcarOHle9lDBe2P/qdPVYwU1UaKEVDoMUR9TOWejzbqiUeGcwaTfB6aLLw9Q0YmZFNJn7Odi0RWJTMTgKvbcSuIAvcrHV5jk1x5SHUgrY9JtzAcdcu2c7JwK3ezuuTnxJwS+Hlx3WN+mCImiCFYNdbhUaL2pW3GlPR1liehkdn4K+hSbT063VbrQCFHzamxLM7NRujATYvGijESBr3x40oucdGV9GE2/XI/FBFYXSWe9WyWZq6TIrAMRMCO4tDXEQgNCg3a8I9pBiIdmfltivvbPUZ/rmiWDQBsTgONtS7oAAJj+JXOI/4Q5X6/veOtPQVtIWO988XFVwRDKTZQyChPSTiDbKLHCywrr5Fm+eveL+WpKJL/8f+CuCE1LvYAiHndAEWaK3oG14jtyFdUWOe0cROkbzEKuDGu8PkjvX6D0qc/R09TV18/WDMiYPjZAKQxlD19A5j8vNV30DpuwMhnipQ2We1iX48InHhySsFZ5JDG9vNuj22SmEF073zA/Mq/3eSQpcHl9noD1fORRJEU1nLSrCKe9NOT0vFlellA5V+d3pqlIlIx6ZnA7TyrMu7dWOT4Zv5wqDNsjC0tLAzg8RBaJUAyJJ9r+TidGa0GLj6EChFXxqmccJYBjuRsyGiBiQwpBMaQ1i6O7zrbsTyiMmsk5wCErqm8fz01haukIQsMEXh6ID4Ni9WEwhKGmfF6gzp7Q6zXFe4Ws4sUqaHRcFpg6ZbAsBo9r0CA06Dj8OcO8JgAP44OER4nat1dJUjQuirskE2QLsMiFILD+QYSfMt0NEoTCPBApT1enIuXkkgMf++ffSS7KW9A9fFvc4fe4c7mmLCo8qM49IiEKzhiHetVGfTEe4x6Z5Leplw1NarERvlWhO7dMu0h+WyxM4KSiAwqcUrAZ0SmT4ejsRkD00y0NCPlLGTPRKgHl521MWHSKuXD6RFvG/oKlfnRtZYDBTWu7rnBrRN8zAdX3jbQ2/9H+VoV807M98IJg4Y9Phq+IpTwivFMSkJMTqKCJe7a88s6sUyroIoIJd8sELUsRDJ6mQqPDreQugb5Gt9n7YRpmeXHVSSexqLXehnpsY45RDQYT45g6i6kRLl1LST5mGpC7fanbLv2yZm6lgVtBfclRX2TMMMqwhAnCvyNbs8iH3qv1xwjGPZavrebgJdT7LUk8eVumH8YIxoQcAw/PFmGMoFnPCUY9/qg/YINWUTNvmZnTITqdqVc07eKkjoBuIWHJ3SAba5nqKmiHdmzvqblo7U5RpsurKi3gYFKzCfZDxrw9wcV83xXGgtrYTiPwMkslj+5gbleenf0mqPV+ZN3A1J8FPHpJr0OBXite+BE5OXqjkpr31dKkrPrI53r45jyynNmNjzPuM/ibWNldK4jJM7d2okvVMzIZz+6yeiSveRG5wffQcP50CG62xHeJdKNvmf+tVuUI+mSuv4Od+iOKxV+Xiziv0hQiOBvNchwrr6QYv1iL6T+ZcbuPeWTUwcj78Ll86kTOlLS9oWbb6t6yBH5VTnEjhV3Xywq4dN54fiS5LpzC+Uw4cMgKtisw2yGMOZDEcFNWQ6d8wJNe6QNjt1UlBGGjo8ycYxqXYJ1KgrCOLFLcm5kqreNNc3cBwR0PT6Q6B7VAKYupHskWM58sFt0hDwaIdYWV511fh2LJq7EaiiPTCRPWNT1PpW+MTFiwcLocUIxKw0PoxT1airjdUsUJVJpEvXPd2qsIH9nMEzEWVeC9hmAd3qyGJeY5l6eqyPFbx0NiaXjGq0Su4MSVngHQwvjNbdeR/uiozwHo7GwiEVVSK3TKTimxpRk3KP+8Hdk97uSYVku/Y6C65jL2WMyW77bimHGK2AVPpDZPfwv3RZgyWP9D0LLvPGYvGSYTGQzWHeO+E9BsqA9/NFKHIE5hBiS4JvMQfIyPwIZALgzex9SAAaCJb0Mgvz4OTe3vBJKbXSC/SZ/wwT7MpqX9GA9li2cL/9HJQBxCnD+/bP5+MGDy+Nf/V3fOBs7flw2768r8gwMAOX8Yb4XzszuHvb2tLHuzvecg4JcGvtneVxx2S1L7rj1+DuB/MPAf67BPFvE9DZzAf213G1V+tiUbrKwfd4UCW+7MJqtbbc1mfhpbPls6ZNvXAC6ACzjIAQC93zbqXwEAAP//XJcxZCILAAA="); err != nil {
+	if err := gres.Add("H4sIAAAAAAAC/7xYeTyUe9u/J9GYUhJZyzSnc6Yj+xayNGTLEmMIyT723SCNbUSEg6xRkSVrw8lWCE2ITpaYg5DGNjM0MihbGO9H5z3njN7O+TzPc57Pe//jc5vffX1/9/e6r+v7vX5Geix7uQEwAADx4nqXAabrMMAO+KH9vQP8HNASrl6uGDd/by9TFCsAyliYdTHS2wdmXv17HPD/iQP9VhwJjJ2/u42rl5O3+K6wmFuECyxSnDfoG9wahiJmKWkwiosmp7KuN0xa3sSisTvDo868rSASdupqyVAk7HhbuREh0/ex6o2o+f4KmGJ3ZtJ6eMoWMC4KALZ5TpDbtuXVjzzZj9U+Px4sWp8mR3C3Z2lr4OJop7nf/Yg03PhsY3FhgDO+qihWaFYDo4/TLSgZhy/2JVbA9ELVhxIMin/h6HEj03mJja9CPqWFJlLaHaZO7082iuEJ/6X81Fx0i7rruUBzzYxrLo1hmozAOyrNCw2TSN9bNirhSd5Ot4hJG3PLE+7SLys6yCtteXpHis514QIuz5Q05CYw9sspPXRjH0a8WREYbYuqMyF6aUUFnlG1fm+b9tqPf+5GaOWivSzm/QrSJGTsOctZ/Qb8cw6g5/r1XBCww30BP5/y9yAA2Ab9yT0A0E6bXPnLHHq6OvvZYdD+X8gWKR9w+9dzKPWtOBKSklI2Dn5oOwza5ks6MXb2Hmh/cUfvIC9xf1+PP5LaP2AK1hXtCauznyDWE1mW4vX645HXWUJzDMYWHgaNEa+k5vbkrHqsPuTdEMyVIG15WG6kjo0+wQTw5AR2hTVgh6pnR60NSfYPbB1tHcvcbUvcHQ+mYAdJ2/bbbKdZY3u4nkz0OtcqDzdtuA49p9KOqua+XglZSSzG37ERtSKw9n+qcSC8ytKzFrVaDLvYUZ3TA9+gNL9R+uBKo1DvBLV4uGAbyoXzx7dyDMUzodtANKc2XPgS68UzrEPg3vFkW9ZkuUXF9dnBq4UyH/uX8CCWLwmA+9s86gMAYOFviZP4d4gL8NlF29tMkreANmcHaf20VmeKZ+n5ugclXCWHqA4GhtGtiokvgtV5Say3Fsp9zr+8U/CidiptxHPuvoLuFRH4/TLf2LkG8Nxart3qeEvJiheORdisIrh8eh8tkFJdfVaGZBGimpw+GYqsnDyTTrYyvni05k235LB78tJhLxW6tbK8m+eSfQFRdXktJOKMzURgPMoQm35ETbRLzGq1u3vjaHORuyO2Edu3ICMYGiG3kosZ8XxqKpdY9qIn6UX5D9Zq82a04+9lwzketvdopiszzql95Jp03Cv9mE8LKorjSwfhwVKKbNzZOmg6xfYFC5CMtDMGtyef9LEzz4gCw8duePd9qOwypbshTDAGhmhZiU71ve2O4lUeS21XFQ4gatraW6UXfHXV8+/y3ZZcRVpvcQWZ6rk3FPk1hH5k5MgCTkESQdpGTQvH4ItnFFydJj4vW4wX3AJzoW/XiWuDDWWmIjOGuO/1ounXEFqnl15XPTZ+EUN+d21YQc1+0OL7gisv28+iCwZtB8ujrQIj5a68N6ONXcxdGJaXWS0yyUqbZ0WkGYN1685Zz493myxdSp+Tu08UDeoJo8tevuc0bGk7P8KCX+gk1Pv1j5JqIQd8giv9reXdWqRzIzOMIavwtthDZzp971kcDdCU4JGY+glvUrXCR9m8uvXkUvChj2EhdTeCVCXPeBtfglwz6tOGOD1pHxaoUKmOpGFdLALym/rCDN8JJbwpj6vQV4gYwCRc/hVyCFmeWqWdM9gwkJuwBZ8O4Pc2zUpinKpUFFp3ENxeOUDZfOE76iL87CnpDT61O/x26plylV8fIAOf6rHZBWT3uoh3H9NOMmw0DksbEnKLkAsJd7dUk6Q/fjtLYszXOMvbijwz3xCEh49XNzWDwfac+ISb5y6E3uYDI56/X4zK+cWFkycGIuVAZww/o7LgK4L2jHx6xpE8e069r+OqBo5vcjLfR2ZeX2zs8bEN63lD2INEkNj3vkcEaamHE5a7OdpFnx5m25qMO1e5Le1kbu9lW5N/JBo5+GPMiltQq2z0CQSVwOdTwuflY2kWELnc3mbqqP+j1nN7YYt4sU8xoqVP23nTe/mrZq2WtmUdZrtfn2g8FbXXOEfB2uJsG8xKQf8D20XWGJDf2iPsUkbcCOXgOyVbtzK3hpQLSQaU+Zig6ZhlKYJg4fPo8Qf+utpNetSUbc35Uz26H/TvYh8VKOzDy7i2KtdHZKHudGadzNDlvS31U0Eark1xmkW4eebDcX580HQ3Lmb/Kx37i30M14j9bLikOCmLSZdbb9EN+qv58rYZb5EodXWdwnt7WksQ6no66rAkj6ThrDQlvdb9+ZIphxesEqiRnSJHaVJj+KbW0ZbxzICnvoYrAi+8moif6xsX4e/KUi/BpzUdbottNqsnMuZtDFF72MViFI8TkFbT6BMi6mpkDJYWPtAVbi+XKY6ukqk+zv7SVzcBJ8p+Qmmwnk/fIPiosQ5nd/XNmUE5LO7ZmUxhAojYDzOOidh3kxDh7AbpvyBvgUwR0KcS1x0XNz7JYsGyl6dnBYK3ai7jq0n8lOGHl1rHIZ+1afuc1RI9Iy47R2qePwJaJEPxPI1a/QdAk2uCUE1vkCUixCrQXw9Xvoe/bjDd1yh9q1+ScexLs1wmVbh07AWAVg7mZtn4lVrJ/0WzlLax88Cg/Wz+8Aw2/mi/QFcH9G6pIZYNuP1XpabI3eW+uws7s9TAG4PUPliuPfRfj49W2RGdO8SNGvcJYi0xFz/6OWRqvvhjQ/0ToVB1q+vc6kL3Qqx4LnDFV0/tCAcPNL+HoZrXHAZpL9VEZ70shZy4BaqA2CUieo4nIgS0ioqyB60iflP3Y3LIJ60AABCAv+NL9t/mK8BnF1vADMC7LSYGRdn5u1NK8sj3Mo28/THOfmgTY31K+s+UnyrIDakzLThqcSMlI2a6sQ4iJgYlx94hNzZQMutmM2uUoNKS0vJiUpJiUoo7v1GSkmZaflaCTjc2kuPyKDeLZ/ISqNlRlLs/7dymVVIScNTGVEpOFDkhY+bn69S0EigW5mXniYYpwcTFxWGiMB87PztPf5gSVlxcPDRUFEq5WUouLCbjMykVtbPpLeSGIggEoY/SREJRCHV9Tegf7wj97b8aF/VNDQyh//vCUJSFkSYUpWmOOgv5TbYHrF2l6AAAzOxi9mvfpPQXzMrsku0gbz93Jw/voG8aH5Hy/7ev0ZH0lvdyscpw3ypmox+OpSqPjqz96X/ayVzTwvfHZ6mTAnnjs0XjAhP8QlwPk7OQh21SzO8ey03NPW6DyubNzbLvJs8cmRQo5JycmZ4qA1JRgHvQyir78rJGfyUKlU3NynfkkDuev+8Eh3yDcsups2LwFiHHZrnjAT+WspYG/7iGSqPyoovJ05mX/UFsX8jOQcknbe4QDfo7shX+I7IDfHZRPW1G8h4x4w6jVipMsp8sicCnauUI+evi+VOzaWKhVUZz0P0/n3b4tYli58Yd4F6IJ+fR5Is0X73K19+KjlNi8M9d227H1A9R9hhCXq5UqY5Q186S1nvqSQGqh54Mu+9z1thetx2aDRYb8nibfh3OS3hMZYzSW19ObJA6CMQY4iINpXqI0ScTvinP95SBXag68EB9tjrkUbJEmKE9YapjYjq/y69o6lG48l3GsnLIMkbpc0+4v1LoqsPC+4Wt6lvb3fE+VzNgJG5vhNAJ+nkedj7Q0Tr0XiRPmhJbDVzOh+WpUyVezeftCqIjGMjiBiLQz+pqiOfKTnb+XH5BDtG0z+Y+tt5CtomebdDbZjakz5LhVoKLHpk/YNLnyC9SIuIizWZsYlpoq2Jol7tKFzyz/lbybJGzQGQHb5Ib156ahLG57IUH9+OC9sqZNISeFTxS6Ds0Jz+2os7G8elp0RAdV2hbc/3Xgbq40jkzESQKKeLirWv8as3Sxsi0OHI2Z0Aur0L0Qs4EucvLk62r5vUd6z3zViWKD4t0gmodP4Vhby7rE3jMlzLAyb0as0cXaHwHe/1kDqaAPnHwUUKJ8ie/27eR6GmeQ35jdOqidgfBhVvo3lqHmsYtlc8tW/zNytHIT1cZpxSU1scn+/dsF9QaaKvraWVjvvtRoYIgXdKWqfndS4mbvdTXJexVXfA2Me7Bdz6WHs3SGYclDnCg18IG33qxKZ1OBjpHSAcS7H541n7NE6PDl+Na08VzvmLKShkvJIq6wC6tJSLjmHgInA6JDXiAM8V1UWwfWe9VEUTXVsBctU8IHoxXuh5XpqMZF5uxqeLAb/H8caRj1OFYdQ1uERdatgeYvveHvggzc/MV3Ee69P3zXApdte9iWX1rL641+vtxHRSOpvIyGuEyWIdMjTz974h9wkaiMvDT6aUxoa9r8o1nvUiMzoevai3FiAY6sbrcR70mTuvH8ch5OtQ/AseNe4VZNIZiVU/0SE4GeZGaE/GLJJpHEow27sAvoCfLrkqvKiBQ2w6xa5+EXHkl2YlYl21gXCeUqvEnk3pbhbmSDEO8Nm3Cl5LCcUjFkI+wu8pWKsvHits/p9RmV8mW14w+rrS6t8zW52vpeGPT46zh6dbX9aGEshqNMNDsIVp/9utMtq3SUa22BMQoPnnDIbisrRTEPnnMiGYDGtHIv3EYJafZhrxiEUE1Xg4tJtJHsuYGTaNuH6lAxCi+D9f94f3VIrpA2trmnSMbzl/6QpeZY2YpCwAE/mEHdq7vRHf3BXbmvvCl0FO+DK2/D7tfrwczrWda/vWMC9rDzfLXJx6/XZzAFmLn79+df/we57ehj3keh/4RBwBqcDoA8K+ffzBv71vD/J/bawMBfzva794e87QqtWt7B/YA/2C0/xqH2ehJ7MLRZAH+40mYGWXHBDHbI3kmlEKcHxj4pxbyazBmxyC7CyyfHfhn/osZaifbzHqptAtqEwL8NwzJ14DMhaiwC9D1APDPRfl3uL+q7t+/5m0EjhP4Zq3/WQ3fqvY/n3/E9DzT46xsOwv2A/uBORYAWODcufufAQAT1cRW8hQAAA=="); err != nil {
 		panic("add binary content to resource manager failed: " + err.Error())
 	}
 }
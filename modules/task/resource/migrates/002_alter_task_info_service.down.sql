@@ -0,0 +1,5 @@
+-- Task模块PostgreSQL数据库回滚迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 回滚任务执行的服务字段类型
+
+ALTER TABLE task_info ALTER COLUMN service TYPE VARCHAR(255);
@@ -0,0 +1,5 @@
+-- Task模块PostgreSQL数据库迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 任务执行的服务支持结构化调用 {"name":"...","params":{...}}, 扩大字段长度
+
+ALTER TABLE task_info ALTER COLUMN service TYPE TEXT;
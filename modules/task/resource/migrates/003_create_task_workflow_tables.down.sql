@@ -0,0 +1,10 @@
+-- Task模块PostgreSQL数据库回滚迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 回滚任务依赖与工作流相关表
+
+DROP INDEX IF EXISTS idx_task_log_run_id;
+ALTER TABLE task_log DROP COLUMN IF EXISTS "runId";
+
+DROP TABLE IF EXISTS task_workflow_run;
+DROP TABLE IF EXISTS task_depend;
+DROP TABLE IF EXISTS task_workflow;
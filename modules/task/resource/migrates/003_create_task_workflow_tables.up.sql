@@ -0,0 +1,68 @@
+-- Task模块PostgreSQL数据库迁移文件
+-- 创建时间: 2026-10-19
+-- 描述: 创建任务依赖与工作流相关表
+
+-- 1. 工作流表
+CREATE TABLE IF NOT EXISTS task_workflow (
+    id BIGSERIAL PRIMARY KEY,
+    "createTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updateTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deletedAt" TIMESTAMP DEFAULT NULL,
+    name VARCHAR(255) NOT NULL,
+    remark VARCHAR(255),
+    status INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_workflow_create_time ON task_workflow("createTime");
+CREATE INDEX IF NOT EXISTS idx_task_workflow_deleted_at ON task_workflow("deletedAt");
+CREATE UNIQUE INDEX IF NOT EXISTS uk_task_workflow_name ON task_workflow(name);
+
+-- 2. 任务依赖表, taskId 依赖 dependId, condition 0:上游成功 1:上游失败 2:上游完成
+CREATE TABLE IF NOT EXISTS task_depend (
+    id BIGSERIAL PRIMARY KEY,
+    "createTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updateTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deletedAt" TIMESTAMP DEFAULT NULL,
+    "workflowId" BIGINT NOT NULL,
+    "taskId" BIGINT NOT NULL,
+    "dependId" BIGINT NOT NULL,
+    condition SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_depend_workflow_id ON task_depend("workflowId");
+CREATE INDEX IF NOT EXISTS idx_task_depend_task_id ON task_depend("taskId");
+CREATE INDEX IF NOT EXISTS idx_task_depend_depend_id ON task_depend("dependId");
+CREATE UNIQUE INDEX IF NOT EXISTS uk_task_depend_edge ON task_depend("workflowId", "taskId", "dependId");
+
+ALTER TABLE task_depend ADD CONSTRAINT fk_task_depend_workflow
+    FOREIGN KEY ("workflowId") REFERENCES task_workflow(id) ON DELETE CASCADE;
+ALTER TABLE task_depend ADD CONSTRAINT fk_task_depend_task
+    FOREIGN KEY ("taskId") REFERENCES task_info(id) ON DELETE CASCADE;
+ALTER TABLE task_depend ADD CONSTRAINT fk_task_depend_depend
+    FOREIGN KEY ("dependId") REFERENCES task_info(id) ON DELETE CASCADE;
+
+-- 3. 工作流运行记录表, status 0:运行中 1:成功 2:失败
+CREATE TABLE IF NOT EXISTS task_workflow_run (
+    id BIGSERIAL PRIMARY KEY,
+    "createTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updateTime" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deletedAt" TIMESTAMP DEFAULT NULL,
+    "workflowId" BIGINT NOT NULL,
+    "triggerId" BIGINT NOT NULL DEFAULT 0,
+    status SMALLINT NOT NULL DEFAULT 0,
+    "endTime" TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_workflow_run_workflow_id ON task_workflow_run("workflowId");
+CREATE INDEX IF NOT EXISTS idx_task_workflow_run_status ON task_workflow_run(status);
+
+ALTER TABLE task_workflow_run ADD CONSTRAINT fk_task_workflow_run_workflow
+    FOREIGN KEY ("workflowId") REFERENCES task_workflow(id) ON DELETE CASCADE;
+
+-- 4. 任务日志关联工作流运行记录, status 增加 2:跳过
+ALTER TABLE task_log ADD COLUMN IF NOT EXISTS "runId" BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_task_log_run_id ON task_log("runId");
+
+CREATE TRIGGER update_task_workflow_updated_time BEFORE UPDATE ON task_workflow FOR EACH ROW EXECUTE FUNCTION update_task_updated_time_column();
+CREATE TRIGGER update_task_depend_updated_time BEFORE UPDATE ON task_depend FOR EACH ROW EXECUTE FUNCTION update_task_updated_time_column();
+CREATE TRIGGER update_task_workflow_run_updated_time BEFORE UPDATE ON task_workflow_run FOR EACH ROW EXECUTE FUNCTION update_task_updated_time_column();
@@ -6,19 +6,37 @@ import (
 	"github.com/gogf/gf/v2/os/gcron"
 	"github.com/gogf/gf/v2/os/gtime"
 	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/vera-byte/vgo/modules/task/model"
 	"github.com/vera-byte/vgo/v"
 )
 
 // EnableTask 启用任务
 func EnableTask(ctx g.Ctx, cronId string, funcstring string, cron string, startDate string) (err error) {
-	funcName := gstr.SubStr(funcstring, 0, gstr.Pos(funcstring, "("))
-	if _, ok := v.FuncMap[funcName]; !ok {
-		err = gerror.New("函数不存在" + funcName)
+	value, err := v.DBM(model.NewTaskInfo()).Where("id = ?", cronId).Value("type")
+	if err != nil {
 		return
 	}
+	var (
+		taskType  = value.Int()
+		funcName  = funcstring
+		singleton = true // 执行器任务始终单例执行, 避免上一次未结束时重复执行
+	)
+	if GetTaskExecutor(taskType) == nil {
+		call, err := v.ParseFuncCall(funcstring)
+		if err != nil {
+			return err
+		}
+		funcName = call.Name
+		if _, ok := v.FuncMap[funcName]; !ok {
+			err = gerror.New("函数不存在" + funcName)
+			return err
+		}
+		singleton = v.FuncMap[funcName].IsSingleton()
+	}
 	taskInfoService := NewTaskInfoService()
+	taskWorkflowService := NewTaskWorkflowService()
 
-	if v.FuncMap[funcName].IsSingleton() {
+	if singleton {
 		gcron.Remove(cronId)
 		_, err = gcron.AddSingleton(ctx, cron, func(ctx g.Ctx) {
 			nowDate := gtime.Now().Format("Y-m-d H:i:s")
@@ -26,12 +44,15 @@ func EnableTask(ctx g.Ctx, cronId string, funcstring string, cron string, startD
 				g.Log().Debug(ctx, "当前时间小于启用时间, 不执行单例函数", funcName)
 				return
 			}
-			err := v.RunFunc(ctx, funcstring)
+			detail, err := ExecuteTask(ctx, taskType, funcstring)
+			if gerror.Is(err, ErrTaskSkipped) {
+				return
+			}
 			if err != nil {
 				g.Log().Error(ctx, err)
-				taskInfoService.Record(ctx, cronId, 0, err.Error())
+				taskWorkflowService.TaskDone(ctx, cronId, 0, err.Error())
 			} else {
-				taskInfoService.Record(ctx, cronId, 1, "任务执行成功")
+				taskWorkflowService.TaskDone(ctx, cronId, 1, detail)
 			}
 		}, cronId)
 	} else {
@@ -42,12 +63,15 @@ func EnableTask(ctx g.Ctx, cronId string, funcstring string, cron string, startD
 				g.Log().Debug(ctx, "当前时间小于启用时间, 不执行函数", funcName)
 				return
 			}
-			err := v.RunFunc(ctx, funcstring)
+			detail, err := ExecuteTask(ctx, taskType, funcstring)
+			if gerror.Is(err, ErrTaskSkipped) {
+				return
+			}
 			if err != nil {
 				g.Log().Error(ctx, err)
-				taskInfoService.Record(ctx, cronId, 0, gstr.AddSlashes(err.Error()))
+				taskWorkflowService.TaskDone(ctx, cronId, 0, gstr.AddSlashes(err.Error()))
 			} else {
-				taskInfoService.Record(ctx, cronId, 1, gstr.AddSlashes("任务执行成功"))
+				taskWorkflowService.TaskDone(ctx, cronId, 1, gstr.AddSlashes(detail))
 			}
 			taskInfoService.SetNextRunTime(ctx, cronId, cron)
 		}, cronId)
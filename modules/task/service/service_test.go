@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	_ "github.com/vera-byte/vgo/contrib/drivers/sqlite"
+	"github.com/vera-byte/vgo/modules/task/model"
+)
+
+// testTables 测试使用的数据表
+var testTables = []string{
+	"CREATE TABLE task_info (id INTEGER PRIMARY KEY AUTOINCREMENT, jobId TEXT, repeatConf TEXT, name TEXT, cron TEXT, \"limit\" INTEGER, every INTEGER, remark TEXT, status INTEGER, startDate DATETIME, endDate DATETIME, data TEXT, service TEXT, type INTEGER, nextRunTime DATETIME, taskType INTEGER, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+	"CREATE TABLE task_log (id INTEGER PRIMARY KEY AUTOINCREMENT, taskId INTEGER, runId INTEGER DEFAULT 0, status INTEGER, detail TEXT, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+	"CREATE TABLE task_workflow (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, remark TEXT, status INTEGER, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+	"CREATE TABLE task_depend (id INTEGER PRIMARY KEY AUTOINCREMENT, workflowId INTEGER, taskId INTEGER, dependId INTEGER, condition INTEGER DEFAULT 0, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+	"CREATE TABLE task_workflow_run (id INTEGER PRIMARY KEY AUTOINCREMENT, workflowId INTEGER, triggerId INTEGER, status INTEGER, endTime DATETIME, createTime DATETIME, updateTime DATETIME, deletedAt DATETIME)",
+}
+
+var testDBOnce sync.Once
+
+// setupTestDB 使用临时的sqlite数据库并清空数据表
+func setupTestDB(t *testing.T) {
+	ctx := context.Background()
+	testDBOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "task")
+		if err != nil {
+			t.Fatal(err)
+		}
+		gdb.SetConfig(gdb.Config{"default": gdb.ConfigGroup{{
+			Type:      "sqlite",
+			Name:      filepath.Join(dir, "task.db"),
+			CreatedAt: "createTime",
+			UpdatedAt: "updateTime",
+		}}})
+		for _, table := range testTables {
+			if _, err = g.DB().Exec(ctx, table); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+	for _, table := range []string{"task_info", "task_log", "task_workflow", "task_depend", "task_workflow_run"} {
+		if _, err := g.DB().Exec(ctx, "DELETE FROM "+table); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// addTestTask 添加任务
+func addTestTask(t *testing.T, name string) uint64 {
+	id, err := g.DB().Model(model.TableNameTaskInfo).Data(g.Map{"name": name, "status": 1, "service": "TestFunc()"}).InsertAndGetId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uint64(id)
+}
+
+// addTestWorkflow 添加工作流, status 为1时启用
+func addTestWorkflow(t *testing.T, name string, status int) uint64 {
+	id, err := g.DB().Model(model.TableNameTaskWorkflow).Data(g.Map{"name": name, "status": status}).InsertAndGetId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uint64(id)
+}
+
+// addTestDepend 添加依赖, taskId 在 dependId 执行后按 condition 触发
+func addTestDepend(t *testing.T, workflowId, taskId, dependId uint64, condition int) uint64 {
+	id, err := g.DB().Model(model.TableNameTaskDepend).Data(g.Map{
+		"workflowId": workflowId,
+		"taskId":     taskId,
+		"dependId":   dependId,
+		"condition":  condition,
+	}).InsertAndGetId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uint64(id)
+}
+
+// addTestRun 添加运行中的工作流运行记录
+func addTestRun(t *testing.T, workflowId uint64) uint64 {
+	id, err := g.DB().Model(model.TableNameTaskWorkflowRun).Data(g.Map{
+		"workflowId": workflowId,
+		"status":     model.TaskWorkflowRunRunning,
+	}).InsertAndGetId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uint64(id)
+}
+
+// addTestLog 记录工作流运行中任务的执行状态
+func addTestLog(t *testing.T, runId, taskId uint64, status int) {
+	if _, err := g.DB().Model(model.TableNameTaskLog).Data(g.Map{"taskId": taskId, "runId": runId, "status": status}).Insert(); err != nil {
+		t.Fatal(err)
+	}
+}
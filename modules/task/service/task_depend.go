@@ -0,0 +1,118 @@
+package service
+
+import (
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/vera-byte/vgo/modules/task/model"
+	"github.com/vera-byte/vgo/v"
+)
+
+type TaskDependService struct {
+	*v.Service
+}
+
+func NewTaskDependService() *TaskDependService {
+	return &TaskDependService{
+		&v.Service{
+			Model: model.NewTaskDepend(),
+			ListQueryOp: &v.QueryOp{
+				FieldEQ: []string{"workflowId", "taskId", "dependId"},
+			},
+			PageQueryOp: &v.QueryOp{
+				FieldEQ: []string{"workflowId", "taskId", "dependId"},
+			},
+			NotNullKey: g.MapStrStr{
+				"workflowId": "工作流不能为空",
+				"taskId":     "任务不能为空",
+				"dependId":   "依赖的任务不能为空",
+			},
+		},
+	}
+}
+
+// ModifyBefore 新增或修改依赖前检查, 同一工作流内的依赖关系必须是有向无环图
+func (s *TaskDependService) ModifyBefore(ctx g.Ctx, method string, param g.MapStrAny) (err error) {
+	if method != "Add" && method != "Update" {
+		return nil
+	}
+	var (
+		id         = gconv.Uint64(param["id"])
+		workflowId = gconv.Uint64(param["workflowId"])
+		taskId     = gconv.Uint64(param["taskId"])
+		dependId   = gconv.Uint64(param["dependId"])
+		condition  = gconv.Int(param["condition"])
+	)
+	if method == "Update" && (workflowId == 0 || taskId == 0 || dependId == 0) {
+		record, err := v.DBM(s.Model).Where("id = ?", id).One()
+		if err != nil {
+			return err
+		}
+		if record.IsEmpty() {
+			return gerror.New("依赖不存在")
+		}
+		if workflowId == 0 {
+			workflowId = record["workflowId"].Uint64()
+		}
+		if taskId == 0 {
+			taskId = record["taskId"].Uint64()
+		}
+		if dependId == 0 {
+			dependId = record["dependId"].Uint64()
+		}
+	}
+	if taskId == dependId {
+		return gerror.New("任务不能依赖自身")
+	}
+	if condition < model.TaskDependOnSuccess || condition > model.TaskDependOnDone {
+		return gerror.New("触发条件错误")
+	}
+	count, err := v.DBM(model.NewTaskInfo()).WhereIn("id", g.Slice{taskId, dependId}).Count()
+	if err != nil {
+		return err
+	}
+	if count != 2 {
+		return gerror.New("任务不存在")
+	}
+	m := v.DBM(s.Model).Where("workflowId = ?", workflowId)
+	if method == "Update" {
+		m = m.WhereNot("id", id)
+	}
+	result, err := m.Fields("taskId", "dependId").All()
+	if err != nil {
+		return err
+	}
+	edges := make(map[uint64][]uint64)
+	for _, item := range result {
+		edges[item["dependId"].Uint64()] = append(edges[item["dependId"].Uint64()], item["taskId"].Uint64())
+	}
+	for _, next := range edges[dependId] {
+		if next == taskId {
+			return gerror.New("依赖关系已存在")
+		}
+	}
+	edges[dependId] = append(edges[dependId], taskId)
+	if hasCycle(edges, taskId, dependId) {
+		return gerror.New("依赖关系存在环路")
+	}
+	return nil
+}
+
+// hasCycle 判断从 from 出发沿依赖方向能否回到 target
+func hasCycle(edges map[uint64][]uint64, from uint64, target uint64) bool {
+	visited := make(map[uint64]bool)
+	stack := []uint64{from}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node == target {
+			return true
+		}
+		if visited[node] {
+			continue
+		}
+		visited[node] = true
+		stack = append(stack, edges[node]...)
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/modules/task/model"
+)
+
+// TestTaskDependModifyBefore 测试依赖关系必须是有向无环图
+func TestTaskDependModifyBefore(t *testing.T) {
+	setupTestDB(t)
+	var (
+		task1    = addTestTask(t, "t1")
+		task2    = addTestTask(t, "t2")
+		task3    = addTestTask(t, "t3")
+		workflow = addTestWorkflow(t, "w1", 1)
+		other    = addTestWorkflow(t, "w2", 1)
+		depend12 = addTestDepend(t, workflow, task2, task1, model.TaskDependOnSuccess)
+	)
+	addTestDepend(t, workflow, task3, task2, model.TaskDependOnSuccess)
+
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			ctx = gctx.New()
+			s   = NewTaskDependService()
+		)
+		add := func(taskId, dependId uint64, condition int) error {
+			return s.ModifyBefore(ctx, "Add", g.MapStrAny{
+				"workflowId": workflow,
+				"taskId":     taskId,
+				"dependId":   dependId,
+				"condition":  condition,
+			})
+		}
+		// 自身依赖
+		t.AssertNE(add(task1, task1, model.TaskDependOnSuccess), nil)
+		// t1 -> t2 -> t3 -> t1 形成环路
+		t.AssertNE(add(task1, task3, model.TaskDependOnSuccess), nil)
+		t.AssertNE(add(task2, task3, model.TaskDependOnDone), nil)
+		// 重复的依赖
+		t.AssertNE(add(task2, task1, model.TaskDependOnFailure), nil)
+		// 触发条件错误和任务不存在
+		t.AssertNE(add(task3, task1, 3), nil)
+		t.AssertNE(add(task3, 999, model.TaskDependOnSuccess), nil)
+		// 汇聚到 t3 的依赖不构成环路
+		t.AssertNil(add(task3, task1, model.TaskDependOnDone))
+
+		// 环路只在同一工作流内判断
+		t.AssertNil(s.ModifyBefore(ctx, "Add", g.MapStrAny{
+			"workflowId": other,
+			"taskId":     task1,
+			"dependId":   task3,
+		}))
+
+		// 修改时未传入的字段使用原记录, 改为依赖自身时拒绝
+		t.AssertNE(s.ModifyBefore(ctx, "Update", g.MapStrAny{"id": depend12, "taskId": task1}), nil)
+		// 修改的依赖不计入已有依赖, 将 t1 -> t2 反转为 t2 -> t1 不构成环路
+		t.AssertNil(s.ModifyBefore(ctx, "Update", g.MapStrAny{"id": depend12, "taskId": task1, "dependId": task2}))
+	})
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/modules/task/model"
+	"github.com/vera-byte/vgo/v"
+)
+
+// TaskExecutor 任务执行器, 执行函数以外类型的任务, 任务配置为保存在 service 字段中的JSON
+type TaskExecutor interface {
+	// Validate 保存任务时校验任务配置
+	Validate(ctx g.Ctx, config string) error
+	// Execute 执行任务, 返回记录到任务日志的执行详情
+	Execute(ctx g.Ctx, config string) (detail string, err error)
+}
+
+// ErrTaskSkipped 非主进程不执行执行器类型的任务, 调用方不应记录执行结果
+var ErrTaskSkipped = gerror.New("当前进程不是主进程, 跳过执行")
+
+var (
+	taskExecutors = map[int]TaskExecutor{
+		model.TaskTypeHttp:  &HttpTaskExecutor{},
+		model.TaskTypeShell: &ShellTaskExecutor{},
+	}
+	taskExecutorsMu sync.RWMutex
+)
+
+// RegisterTaskExecutor 注册任务执行器, 可覆盖内置的执行器
+func RegisterTaskExecutor(taskType int, executor TaskExecutor) {
+	taskExecutorsMu.Lock()
+	defer taskExecutorsMu.Unlock()
+	taskExecutors[taskType] = executor
+}
+
+// GetTaskExecutor 获取任务类型对应的执行器, 执行函数的任务类型返回nil
+func GetTaskExecutor(taskType int) TaskExecutor {
+	taskExecutorsMu.RLock()
+	defer taskExecutorsMu.RUnlock()
+	return taskExecutors[taskType]
+}
+
+// ValidateTask 校验任务配置, 函数类型任务校验函数及其参数
+func ValidateTask(ctx g.Ctx, taskType int, service string) (err error) {
+	if executor := GetTaskExecutor(taskType); executor != nil {
+		return executor.Validate(ctx, service)
+	}
+	_, err = v.ValidateFuncCall(service)
+	return
+}
+
+// ExecuteTask 执行任务, 函数类型任务运行注册的函数, 其他类型交给执行器在主进程中执行
+// 非主进程中的执行器任务返回 ErrTaskSkipped
+func ExecuteTask(ctx g.Ctx, taskType int, service string) (detail string, err error) {
+	executor := GetTaskExecutor(taskType)
+	if executor == nil {
+		if err = v.RunFunc(ctx, service); err != nil {
+			return
+		}
+		return "任务执行成功", nil
+	}
+	if !v.IsMasterProcess(ctx) {
+		g.Log().Debug(ctx, "当前进程不是主进程, 不执行任务", service)
+		return "", ErrTaskSkipped
+	}
+	return executor.Execute(ctx, service)
+}
+
+// truncateOutput 截断记录到日志中的输出
+func truncateOutput(output string, limit int) string {
+	if limit <= 0 {
+		return output
+	}
+	runes := []rune(output)
+	if len(runes) <= limit {
+		return output
+	}
+	return string(runes[:limit]) + "...(已截断)"
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/vera-byte/vgo/modules/task/config"
+)
+
+// HttpTaskConfig HTTP任务配置
+type HttpTaskConfig struct {
+	Url          string            `json:"url"`          // 请求地址
+	Method       string            `json:"method"`       // 请求方法,默认GET
+	Headers      map[string]string `json:"headers"`      // 请求头
+	Body         string            `json:"body"`         // 请求体
+	Timeout      uint              `json:"timeout"`      // 超时时间,单位秒,不传使用全局配置
+	ExpectStatus []int             `json:"expectStatus"` // 期望的响应状态码,不传时2xx视为成功
+}
+
+// HttpTaskExecutor HTTP请求任务执行器
+type HttpTaskExecutor struct {
+}
+
+var httpTaskMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}
+
+// parse 解析并校验任务配置
+func (e *HttpTaskExecutor) parse(cfg string) (c *HttpTaskConfig, err error) {
+	c = &HttpTaskConfig{}
+	if err = json.Unmarshal([]byte(cfg), c); err != nil {
+		return nil, gerror.Wrap(err, "HTTP任务配置格式错误")
+	}
+	u, err := url.Parse(c.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, gerror.New("HTTP任务url必须是http或https地址")
+	}
+	c.Method = gstr.ToUpper(c.Method)
+	if c.Method == "" {
+		c.Method = "GET"
+	}
+	if !gstr.InArray(httpTaskMethods, c.Method) {
+		return nil, gerror.New("HTTP任务不支持的请求方法:" + c.Method)
+	}
+	if c.Timeout == 0 {
+		c.Timeout = config.Config.Http.Timeout
+	}
+	return c, nil
+}
+
+func (e *HttpTaskExecutor) Validate(ctx g.Ctx, cfg string) error {
+	_, err := e.parse(cfg)
+	return err
+}
+
+func (e *HttpTaskExecutor) Execute(ctx g.Ctx, cfg string) (detail string, err error) {
+	c, err := e.parse(cfg)
+	if err != nil {
+		return
+	}
+	client := gclient.New().Timeout(time.Duration(c.Timeout) * time.Second)
+	if len(c.Headers) > 0 {
+		client.SetHeaderMap(c.Headers)
+	}
+	var data []interface{}
+	if c.Body != "" {
+		data = append(data, c.Body)
+	}
+	resp, err := client.DoRequest(ctx, c.Method, c.Url, data...)
+	if err != nil {
+		return "", gerror.Wrap(err, "HTTP请求失败")
+	}
+	defer resp.Close()
+	detail = "HTTP " + gconv.String(resp.StatusCode) + ": " + truncateOutput(resp.ReadAllString(), config.Config.Http.OutputLimit)
+	if !httpStatusExpected(c.ExpectStatus, resp.StatusCode) {
+		return "", gerror.New(detail)
+	}
+	return
+}
+
+// httpStatusExpected 判断响应状态码是否符合预期
+func httpStatusExpected(expect []int, status int) bool {
+	if len(expect) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, item := range expect {
+		if item == status {
+			return true
+		}
+	}
+	return false
+}
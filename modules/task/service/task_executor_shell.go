@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/vera-byte/vgo/modules/task/config"
+)
+
+// shellWaitDelay 命令被结束后等待输出关闭的最长时间
+const shellWaitDelay = 2 * time.Second
+
+// ShellTaskConfig 本地命令任务配置, 命令不经过shell解释, 参数原样传递
+type ShellTaskConfig struct {
+	Command string   `json:"command"` // 命令,必须在白名单中
+	Args    []string `json:"args"`    // 参数
+	Dir     string   `json:"dir"`     // 工作目录
+	Timeout uint     `json:"timeout"` // 超时时间,单位秒,不传使用全局配置
+}
+
+// ShellTaskExecutor 本地命令任务执行器
+type ShellTaskExecutor struct {
+}
+
+// parse 解析并校验任务配置
+func (e *ShellTaskExecutor) parse(cfg string) (c *ShellTaskConfig, err error) {
+	c = &ShellTaskConfig{}
+	if err = json.Unmarshal([]byte(cfg), c); err != nil {
+		return nil, gerror.Wrap(err, "命令任务配置格式错误")
+	}
+	if c.Command == "" {
+		return nil, gerror.New("命令任务command不能为空")
+	}
+	if !gstr.InArray(config.Config.Shell.Commands, c.Command) {
+		return nil, gerror.New("命令不在白名单中:" + c.Command)
+	}
+	if c.Timeout == 0 {
+		c.Timeout = config.Config.Shell.Timeout
+	}
+	if config.Config.Shell.MaxTimeout > 0 && c.Timeout > config.Config.Shell.MaxTimeout {
+		return nil, gerror.Newf("命令任务超时时间不能大于%d秒", config.Config.Shell.MaxTimeout)
+	}
+	return c, nil
+}
+
+func (e *ShellTaskExecutor) Validate(ctx g.Ctx, cfg string) error {
+	_, err := e.parse(cfg)
+	return err
+}
+
+func (e *ShellTaskExecutor) Execute(ctx g.Ctx, cfg string) (detail string, err error) {
+	c, err := e.parse(cfg)
+	if err != nil {
+		return
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(c.Timeout)*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(timeoutCtx, c.Command, c.Args...)
+	cmd.Dir = c.Dir
+	// 超时后结束整个进程组, 子进程仍占用输出时最多再等待 shellWaitDelay
+	setProcessGroup(cmd)
+	cmd.WaitDelay = shellWaitDelay
+	output, err := cmd.CombinedOutput()
+	out := truncateOutput(string(output), config.Config.Shell.OutputLimit)
+	if timeoutCtx.Err() == context.DeadlineExceeded {
+		return "", gerror.Newf("命令执行超时(%d秒): %s", c.Timeout, out)
+	}
+	if err != nil {
+		return "", gerror.Newf("命令执行失败(%s): %s", err.Error(), out)
+	}
+	detail = "exit " + gconv.String(cmd.ProcessState.ExitCode()) + ": " + out
+	return
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package service
+
+import "os/exec"
+
+// setProcessGroup 非Unix系统只结束命令进程本身
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/modules/task/config"
+)
+
+// TestHttpTaskExecutor 测试HTTP任务执行器
+func TestHttpTaskExecutor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(r.Method + " ok"))
+	}))
+	defer server.Close()
+
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			ctx      = gctx.New()
+			executor = &HttpTaskExecutor{}
+		)
+		t.AssertNE(executor.Validate(ctx, `{"url":"ftp://example.com"}`), nil)
+		t.AssertNE(executor.Validate(ctx, `{"url":"http://example.com","method":"TRACE"}`), nil)
+		t.AssertNil(executor.Validate(ctx, `{"url":"http://example.com"}`))
+
+		detail, err := executor.Execute(ctx, `{"url":"`+server.URL+`","method":"post","headers":{"X-Token":"abc"},"body":"{}"}`)
+		t.AssertNil(err)
+		t.Assert(detail, "HTTP 202: POST ok")
+
+		_, err = executor.Execute(ctx, `{"url":"`+server.URL+`"}`)
+		t.AssertNE(err, nil)
+
+		_, err = executor.Execute(ctx, `{"url":"`+server.URL+`","expectStatus":[401]}`)
+		t.AssertNil(err)
+	})
+}
+
+// TestShellTaskExecutor 测试命令任务执行器
+func TestShellTaskExecutor(t *testing.T) {
+	commands := config.Config.Shell.Commands
+	config.Config.Shell.Commands = []string{"echo", "sleep", "sh"}
+	defer func() { config.Config.Shell.Commands = commands }()
+
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			ctx      = gctx.New()
+			executor = &ShellTaskExecutor{}
+		)
+		t.AssertNE(executor.Validate(ctx, `{"command":"rm","args":["-rf","/"]}`), nil)
+		t.AssertNE(executor.Validate(ctx, `{"command":"echo","timeout":999999}`), nil)
+
+		detail, err := executor.Execute(ctx, `{"command":"echo","args":["hello;","$(id)"]}`)
+		t.AssertNil(err)
+		t.Assert(detail, "exit 0: hello; $(id)\n")
+
+		_, err = executor.Execute(ctx, `{"command":"sleep","args":["5"],"timeout":1}`)
+		t.AssertNE(err, nil)
+
+		// 超时后后台子进程仍占用输出时不会一直阻塞
+		start := time.Now()
+		_, err = executor.Execute(ctx, `{"command":"sh","args":["-c","sleep 30 & sleep 30"],"timeout":1}`)
+		t.AssertNE(err, nil)
+		t.AssertLT(time.Since(start), 10*time.Second)
+	})
+}
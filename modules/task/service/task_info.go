@@ -29,6 +29,29 @@ func NewTaskInfoService() *TaskInfoService {
 	}
 }
 
+// ModifyBefore 新增或修改任务前校验执行的函数及其参数
+func (s *TaskInfoService) ModifyBefore(ctx g.Ctx, method string, param g.MapStrAny) (err error) {
+	if method != "Add" && method != "Update" {
+		return nil
+	}
+	funcString := gconv.String(param["service"])
+	if funcString == "" {
+		if method == "Add" {
+			return gerror.New("执行的服务不能为空")
+		}
+		return nil
+	}
+	taskType := gconv.Int(param["type"])
+	if _, ok := param["type"]; !ok && method == "Update" {
+		value, err := v.DBM(s.Model).Where("id = ?", param["id"]).Value("type")
+		if err != nil {
+			return err
+		}
+		taskType = value.Int()
+	}
+	return ValidateTask(ctx, taskType, funcString)
+}
+
 func (s *TaskInfoService) ModifyAfter(ctx g.Ctx, method string, param g.MapStrAny) (err error) {
 	g.Log().Info(ctx, "TaskInfoService.ModifyAfter", method, param)
 	if method == "Add" {
@@ -57,17 +80,23 @@ func (s *TaskInfoService) ModifyAfter(ctx g.Ctx, method string, param g.MapStrAn
 
 // Record 保存任务记录,成功任务每个任务保留最新20条日志,失败日志不会删除
 func (s *TaskInfoService) Record(ctx g.Ctx, id string, status int, detail string) error {
+	return s.RecordRun(ctx, id, 0, status, detail)
+}
+
+// RecordRun 保存工作流运行中的任务记录, runId 为0时表示不属于工作流, 工作流中的日志不会被清理
+func (s *TaskInfoService) RecordRun(ctx g.Ctx, id string, runId uint64, status int, detail string) error {
 	taskLog := model.NewTaskLog()
 	_, err := v.DBM(taskLog).Data(g.Map{
 		"taskId": id,
+		"runId":  runId,
 		"status": status,
 		"detail": detail,
 	}).Insert()
 	if err != nil {
 		return err
 	}
-	if status == 1 {
-		record, err := v.DBM(taskLog).Where("taskId = ?", id).Where("status", 1).Order("id", "desc").Offset(19).One()
+	if status == 1 && runId == 0 {
+		record, err := v.DBM(taskLog).Where("taskId = ?", id).Where("runId", 0).Where("status", 1).Order("id", "desc").Offset(19).One()
 		if err != nil {
 			return err
 		}
@@ -78,7 +107,7 @@ func (s *TaskInfoService) Record(ctx g.Ctx, id string, status int, detail string
 		if err != nil {
 			return err
 		}
-		_, err = v.DBM(taskLog).Where("taskId = ?", id).Where("status", 1).Where("id < ?", minId).Delete()
+		_, err = v.DBM(taskLog).Where("taskId = ?", id).Where("runId", 0).Where("status", 1).Where("id < ?", minId).Delete()
 		if err != nil {
 			return err
 		}
@@ -96,6 +125,13 @@ func (s *TaskInfoService) Once(ctx g.Ctx, id int64) error {
 		return gerror.New("任务不存在")
 	}
 	funcString := record["service"].String()
+	if GetTaskExecutor(record["type"].Int()) != nil {
+		call, err := v.NewFuncCall("TaskExecute", g.Map{"id": id})
+		if err != nil {
+			return err
+		}
+		return v.ClusterRunFunc(ctx, call.String())
+	}
 	return v.ClusterRunFunc(ctx, funcString)
 }
 
@@ -113,7 +149,10 @@ func (s *TaskInfoService) Log(ctx g.Ctx, param g.MapStrStr) (data interface{}, e
 		m = m.Where("taskId = ?", id)
 	}
 	if status, ok := param["status"]; ok {
-		m = m.Where("status = ?", status)
+		m = m.Where("task_log.status = ?", status)
+	}
+	if runId, ok := param["runId"]; ok {
+		m = m.Where("runId = ?", runId)
 	}
 	Total, err = m.Clone().Count()
 	m = m.Fields("task_log.*,task_info.name as taskName")
@@ -173,6 +212,37 @@ func (s *TaskInfoService) SetNextRunTime(ctx g.Ctx, cronId string, cron string)
 	return nil
 }
 
+// CronPreview 校验Cron表达式并预览接下来 count 次的执行时间
+// every 大于0时按间隔(毫秒)计算, 与 TaskAddTask 中间隔任务的处理一致
+func (s *TaskInfoService) CronPreview(ctx g.Ctx, cronStr string, every uint, count int) (data g.Map, err error) {
+	if every > 0 {
+		cronStr = "@every " + gconv.String(every/1000) + "s"
+	}
+	if cronStr == "" {
+		return nil, gerror.New("cron表达式不能为空")
+	}
+	if count <= 0 {
+		return nil, gerror.New("预览次数必须大于0")
+	}
+	if count > 50 {
+		count = 50
+	}
+	times := make([]string, 0, count)
+	t := time.Now()
+	for i := 0; i < count; i++ {
+		t, err = getCronNextTime(cronStr, t)
+		if err != nil {
+			return nil, gerror.Wrap(err, "cron表达式错误")
+		}
+		times = append(times, t.Format("2006-01-02 15:04:05"))
+	}
+	data = g.Map{
+		"cron":  cronStr,
+		"times": times,
+	}
+	return
+}
+
 // getCronNextTime 获取下一次Cron的执行时间
 func getCronNextTime(cronStr string, t time.Time) (nextTime time.Time, err error) {
 	p := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
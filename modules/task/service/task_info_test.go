@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// TestCronPreview 测试预览Cron表达式的执行时间
+func TestCronPreview(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			ctx = gctx.New()
+			s   = NewTaskInfoService()
+		)
+		parse := func(data map[string]interface{}) []time.Time {
+			var result []time.Time
+			for _, item := range data["times"].([]string) {
+				value, err := time.ParseInLocation("2006-01-02 15:04:05", item, time.Local)
+				t.AssertNil(err)
+				result = append(result, value)
+			}
+			return result
+		}
+
+		data, err := s.CronPreview(ctx, "0 */5 * * * *", 0, 3)
+		t.AssertNil(err)
+		t.Assert(data["cron"], "0 */5 * * * *")
+		times := parse(data)
+		t.Assert(len(times), 3)
+		t.Assert(times[0].After(time.Now().Add(-time.Second)), true)
+		for i := 1; i < len(times); i++ {
+			t.Assert(times[i].Sub(times[i-1]), 5*time.Minute)
+		}
+
+		// 按间隔计算时忽略Cron表达式
+		data, err = s.CronPreview(ctx, "", 30000, 4)
+		t.AssertNil(err)
+		t.Assert(data["cron"], "@every 30s")
+		times = parse(data)
+		t.Assert(len(times), 4)
+		for i := 1; i < len(times); i++ {
+			t.Assert(times[i].Sub(times[i-1]), 30*time.Second)
+		}
+
+		// 最多预览50次
+		data, err = s.CronPreview(ctx, "* * * * * *", 0, 100)
+		t.AssertNil(err)
+		t.Assert(len(data["times"].([]string)), 50)
+
+		_, err = s.CronPreview(ctx, "0 */5 * * *", 0, 3)
+		t.AssertNE(err, nil)
+		_, err = s.CronPreview(ctx, "", 0, 3)
+		t.AssertNE(err, nil)
+		_, err = s.CronPreview(ctx, "0 */5 * * * *", 0, 0)
+		t.AssertNE(err, nil)
+		_, err = s.CronPreview(ctx, "0 */5 * * * *", 0, -1)
+		t.AssertNE(err, nil)
+	})
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/vera-byte/vgo/modules/task/model"
+	"github.com/vera-byte/vgo/v"
+)
+
+type TaskWorkflowService struct {
+	*v.Service
+}
+
+func NewTaskWorkflowService() *TaskWorkflowService {
+	return &TaskWorkflowService{
+		&v.Service{
+			Model: model.NewTaskWorkflow(),
+			PageQueryOp: &v.QueryOp{
+				FieldEQ:      []string{"status"},
+				KeyWordField: []string{"name"},
+			},
+			UniqueKey: map[string]string{
+				"name": "工作流名称不能重复",
+			},
+		},
+	}
+}
+
+// ModifyAfter 删除工作流时同时删除依赖关系与运行记录
+func (s *TaskWorkflowService) ModifyAfter(ctx g.Ctx, method string, param g.MapStrAny) (err error) {
+	if method == "Delete" {
+		ids := gconv.SliceAny(param["ids"])
+		if _, err = v.DBM(model.NewTaskDepend()).WhereIn("workflowId", ids).Delete(); err != nil {
+			return err
+		}
+		_, err = v.DBM(model.NewTaskWorkflowRun()).WhereIn("workflowId", ids).Delete()
+	}
+	return
+}
+
+// Run 手动运行工作流, 所有没有上游依赖的任务会被同时触发
+func (s *TaskWorkflowService) Run(ctx g.Ctx, workflowId uint64) (runId uint64, err error) {
+	workflow, err := v.DBM(s.Model).Where("id = ?", workflowId).One()
+	if err != nil {
+		return
+	}
+	if workflow.IsEmpty() {
+		return 0, gerror.New("工作流不存在")
+	}
+	if workflow["status"].Int() != 1 {
+		return 0, gerror.New("工作流未启用")
+	}
+	depends, err := s.depends(workflowId)
+	if err != nil {
+		return
+	}
+	roots := workflowRoots(depends)
+	if len(roots) == 0 {
+		return 0, gerror.New("工作流没有配置任务依赖")
+	}
+	runId, err = s.createRun(workflowId, 0)
+	if err != nil {
+		return
+	}
+	for _, taskId := range roots {
+		if err = s.dispatch(ctx, runId, taskId); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// TaskDone 定时任务执行完成后记录日志, 若任务是启用中工作流的起点则创建工作流运行并触发下游任务
+// 工作流有多个起点时同时触发其他起点, 与手动运行一致, 避免汇聚节点一直等待
+func (s *TaskWorkflowService) TaskDone(ctx g.Ctx, taskId string, status int, detail string) error {
+	taskInfoService := NewTaskInfoService()
+	workflowIds, err := s.rootWorkflows(gconv.Uint64(taskId))
+	if err != nil {
+		return err
+	}
+	// 非主进程只记录日志, 避免集群中重复创建工作流运行
+	if len(workflowIds) == 0 || !v.IsMasterProcess(ctx) {
+		return taskInfoService.Record(ctx, taskId, status, detail)
+	}
+	for _, workflowId := range workflowIds {
+		runId, err := s.createRun(workflowId, gconv.Uint64(taskId))
+		if err != nil {
+			return err
+		}
+		if err = taskInfoService.RecordRun(ctx, taskId, runId, status, detail); err != nil {
+			return err
+		}
+		depends, err := s.depends(workflowId)
+		if err != nil {
+			return err
+		}
+		for _, root := range workflowRoots(depends) {
+			if root == gconv.Uint64(taskId) {
+				continue
+			}
+			if err = s.dispatch(ctx, runId, root); err != nil {
+				return err
+			}
+		}
+		if err = s.Next(ctx, runId, gconv.Uint64(taskId)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunTask 在工作流运行中执行任务, 执行完成后触发下游任务
+func (s *TaskWorkflowService) RunTask(ctx g.Ctx, runId uint64, taskId uint64) error {
+	taskInfoService := NewTaskInfoService()
+	record, err := v.DBM(model.NewTaskInfo()).Where("id = ?", taskId).One()
+	if err != nil {
+		return err
+	}
+	status, detail := model.TaskLogSuccess, ""
+	if record.IsEmpty() {
+		status, detail = model.TaskLogFailed, "任务不存在"
+	} else if detail, err = ExecuteTask(ctx, record["type"].Int(), record["service"].String()); gerror.Is(err, ErrTaskSkipped) {
+		return nil
+	} else if err != nil {
+		g.Log().Error(ctx, err)
+		status, detail = model.TaskLogFailed, err.Error()
+	}
+	if err = taskInfoService.RecordRun(ctx, gconv.String(taskId), runId, status, detail); err != nil {
+		return err
+	}
+	return s.Next(ctx, runId, taskId)
+}
+
+// Next 任务完成后检查下游任务, 所有上游都已完成时按触发条件执行或跳过下游任务
+func (s *TaskWorkflowService) Next(ctx g.Ctx, runId uint64, taskId uint64) error {
+	run, err := v.DBM(model.NewTaskWorkflowRun()).Where("id = ?", runId).One()
+	if err != nil {
+		return err
+	}
+	if run.IsEmpty() {
+		return gerror.New("工作流运行记录不存在")
+	}
+	depends, err := s.depends(run["workflowId"].Uint64())
+	if err != nil {
+		return err
+	}
+	states, err := s.runStates(runId)
+	if err != nil {
+		return err
+	}
+	for _, next := range depends {
+		if next.DependId != taskId {
+			continue
+		}
+		ready, satisfied := true, true
+		for _, up := range depends {
+			if up.TaskId != next.TaskId {
+				continue
+			}
+			state, ok := states[up.DependId]
+			if !ok {
+				ready = false
+				break
+			}
+			if !dependSatisfied(up.Condition, state) {
+				satisfied = false
+			}
+		}
+		if !ready {
+			continue
+		}
+		// 汇聚节点的多个上游可能同时完成, 加锁保证下游只触发一次
+		locked, err := v.CacheManager.SetIfNotExist(ctx, "v:task:workflow:"+gconv.String(runId)+":"+gconv.String(next.TaskId), 1, 24*time.Hour)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			continue
+		}
+		if satisfied {
+			if err = s.dispatch(ctx, runId, next.TaskId); err != nil {
+				return err
+			}
+			continue
+		}
+		err = NewTaskInfoService().RecordRun(ctx, gconv.String(next.TaskId), runId, model.TaskLogSkipped, "依赖条件不满足, 跳过执行")
+		if err != nil {
+			return err
+		}
+		states[next.TaskId] = model.TaskLogSkipped
+		if err = s.Next(ctx, runId, next.TaskId); err != nil {
+			return err
+		}
+	}
+	return s.finish(ctx, runId, depends)
+}
+
+// Graph 获取工作流的任务图, 指定 runId 时附带该次运行中每个任务的状态
+func (s *TaskWorkflowService) Graph(ctx g.Ctx, workflowId uint64, runId uint64) (data g.Map, err error) {
+	var run g.Map
+	states := make(map[uint64]int)
+	details := make(map[uint64]g.Map)
+	if runId > 0 {
+		record, err := v.DBM(model.NewTaskWorkflowRun()).Where("id = ?", runId).One()
+		if err != nil {
+			return nil, err
+		}
+		if record.IsEmpty() {
+			return nil, gerror.New("工作流运行记录不存在")
+		}
+		run = record.Map()
+		workflowId = record["workflowId"].Uint64()
+		logs, err := v.DBM(model.NewTaskLog()).Where("runId = ?", runId).Order("id", "asc").All()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range logs {
+			states[item["taskId"].Uint64()] = item["status"].Int()
+			details[item["taskId"].Uint64()] = g.Map{
+				"detail":     item["detail"],
+				"createTime": item["createTime"],
+			}
+		}
+	}
+	depends, err := s.depends(workflowId)
+	if err != nil {
+		return
+	}
+	taskIds := workflowTasks(depends)
+	tasks, err := v.DBM(model.NewTaskInfo()).WhereIn("id", taskIds).Fields("id", "name", "service").All()
+	if err != nil {
+		return
+	}
+	nodes := g.Slice{}
+	for _, task := range tasks {
+		id := task["id"].Uint64()
+		node := g.Map{
+			"id":      id,
+			"name":    task["name"],
+			"service": task["service"],
+			"status":  -1,
+		}
+		if state, ok := states[id]; ok {
+			node["status"] = state
+			node["detail"] = details[id]["detail"]
+			node["runTime"] = details[id]["createTime"]
+		}
+		nodes = append(nodes, node)
+	}
+	edges := g.Slice{}
+	for _, item := range depends {
+		edges = append(edges, g.Map{
+			"from":      item.DependId,
+			"to":        item.TaskId,
+			"condition": item.Condition,
+		})
+	}
+	data = g.Map{
+		"workflowId": workflowId,
+		"run":        run,
+		"nodes":      nodes,
+		"edges":      edges,
+	}
+	return
+}
+
+// dispatch 通过集群函数在主进程中执行工作流中的任务
+func (s *TaskWorkflowService) dispatch(ctx g.Ctx, runId uint64, taskId uint64) error {
+	call, err := v.NewFuncCall("TaskWorkflowRunTask", g.Map{"runId": runId, "taskId": taskId})
+	if err != nil {
+		return err
+	}
+	return v.ClusterRunFunc(ctx, call.String())
+}
+
+// finish 工作流中所有任务都有执行结果时结束运行, 存在失败任务则运行失败
+func (s *TaskWorkflowService) finish(ctx g.Ctx, runId uint64, depends []*model.TaskDepend) error {
+	states, err := s.runStates(runId)
+	if err != nil {
+		return err
+	}
+	status := model.TaskWorkflowRunSuccess
+	for _, taskId := range workflowTasks(depends) {
+		state, ok := states[taskId]
+		if !ok {
+			return nil
+		}
+		if state == model.TaskLogFailed {
+			status = model.TaskWorkflowRunFailed
+		}
+	}
+	_, err = v.DBM(model.NewTaskWorkflowRun()).
+		Where("id = ?", runId).
+		Where("status = ?", model.TaskWorkflowRunRunning).
+		Data(g.Map{"status": status, "endTime": gtime.Now()}).
+		Update()
+	return err
+}
+
+// createRun 创建工作流运行记录
+func (s *TaskWorkflowService) createRun(workflowId uint64, triggerId uint64) (runId uint64, err error) {
+	id, err := v.DBM(model.NewTaskWorkflowRun()).Data(g.Map{
+		"workflowId": workflowId,
+		"triggerId":  triggerId,
+		"status":     model.TaskWorkflowRunRunning,
+	}).InsertAndGetId()
+	return uint64(id), err
+}
+
+// depends 获取工作流的全部依赖关系
+func (s *TaskWorkflowService) depends(workflowId uint64) (depends []*model.TaskDepend, err error) {
+	err = v.DBM(model.NewTaskDepend()).Where("workflowId = ?", workflowId).Scan(&depends)
+	return
+}
+
+// runStates 获取工作流运行中每个任务最新的执行状态
+func (s *TaskWorkflowService) runStates(runId uint64) (states map[uint64]int, err error) {
+	states = make(map[uint64]int)
+	logs, err := v.DBM(model.NewTaskLog()).Where("runId = ?", runId).Order("id", "asc").Fields("taskId", "status").All()
+	if err != nil {
+		return
+	}
+	for _, item := range logs {
+		states[item["taskId"].Uint64()] = item["status"].Int()
+	}
+	return
+}
+
+// rootWorkflows 获取以该任务为起点的启用中的工作流
+func (s *TaskWorkflowService) rootWorkflows(taskId uint64) (workflowIds []uint64, err error) {
+	result, err := v.DBM(model.NewTaskDepend()).As("d").
+		InnerJoin(model.TableNameTaskWorkflow+" w", "w.id = d.workflowId").
+		Where("d.dependId = ?", taskId).
+		Where("w.status = ?", 1).
+		Fields("DISTINCT d.workflowId").
+		All()
+	if err != nil {
+		return
+	}
+	for _, item := range result {
+		workflowId := item["workflowId"].Uint64()
+		count, err := v.DBM(model.NewTaskDepend()).Where("workflowId = ?", workflowId).Where("taskId = ?", taskId).Count()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			workflowIds = append(workflowIds, workflowId)
+		}
+	}
+	return
+}
+
+// workflowTasks 获取依赖关系中涉及的全部任务
+func workflowTasks(depends []*model.TaskDepend) (taskIds []uint64) {
+	seen := make(map[uint64]bool)
+	for _, item := range depends {
+		for _, id := range []uint64{item.DependId, item.TaskId} {
+			if !seen[id] {
+				seen[id] = true
+				taskIds = append(taskIds, id)
+			}
+		}
+	}
+	return
+}
+
+// workflowRoots 获取没有上游依赖的任务
+func workflowRoots(depends []*model.TaskDepend) (roots []uint64) {
+	hasUpstream := make(map[uint64]bool)
+	for _, item := range depends {
+		hasUpstream[item.TaskId] = true
+	}
+	for _, taskId := range workflowTasks(depends) {
+		if !hasUpstream[taskId] {
+			roots = append(roots, taskId)
+		}
+	}
+	return
+}
+
+// dependSatisfied 判断上游任务状态是否满足触发条件, 被跳过的上游不会触发下游
+func dependSatisfied(condition int, state int) bool {
+	switch condition {
+	case model.TaskDependOnSuccess:
+		return state == model.TaskLogSuccess
+	case model.TaskDependOnFailure:
+		return state == model.TaskLogFailed
+	case model.TaskDependOnDone:
+		return state == model.TaskLogSuccess || state == model.TaskLogFailed
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"github.com/vera-byte/vgo/modules/task/model"
+	"github.com/vera-byte/vgo/v"
+)
+
+type TaskWorkflowRunService struct {
+	*v.Service
+}
+
+func NewTaskWorkflowRunService() *TaskWorkflowRunService {
+	return &TaskWorkflowRunService{
+		&v.Service{
+			Model: model.NewTaskWorkflowRun(),
+			ListQueryOp: &v.QueryOp{
+				FieldEQ: []string{"workflowId", "status"},
+			},
+			PageQueryOp: &v.QueryOp{
+				FieldEQ: []string{"workflowId", "status"},
+			},
+		},
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/modules/task/model"
+)
+
+// TestWorkflowRoots 测试有多个起点的工作流
+func TestWorkflowRoots(t *testing.T) {
+	setupTestDB(t)
+	var (
+		task1    = addTestTask(t, "t1")
+		task2    = addTestTask(t, "t2")
+		task3    = addTestTask(t, "t3")
+		task4    = addTestTask(t, "t4")
+		task5    = addTestTask(t, "t5")
+		workflow = addTestWorkflow(t, "w1", 1)
+		disabled = addTestWorkflow(t, "w2", 0)
+	)
+	// t1 -> t3, t2 -> t3, t3 -> t4, t5 -> t4
+	addTestDepend(t, workflow, task3, task1, model.TaskDependOnSuccess)
+	addTestDepend(t, workflow, task3, task2, model.TaskDependOnSuccess)
+	addTestDepend(t, workflow, task4, task3, model.TaskDependOnSuccess)
+	addTestDepend(t, workflow, task4, task5, model.TaskDependOnSuccess)
+	addTestDepend(t, disabled, task2, task1, model.TaskDependOnSuccess)
+
+	gtest.C(t, func(t *gtest.T) {
+		s := NewTaskWorkflowService()
+		depends, err := s.depends(workflow)
+		t.AssertNil(err)
+		t.Assert(workflowRoots(depends), []uint64{task1, task2, task5})
+		t.Assert(workflowTasks(depends), []uint64{task1, task3, task2, task4, task5})
+
+		// 停用的工作流和非起点的任务不会被定时任务触发
+		for _, taskId := range []uint64{task1, task2, task5} {
+			workflowIds, err := s.rootWorkflows(taskId)
+			t.AssertNil(err)
+			t.Assert(workflowIds, []uint64{workflow})
+		}
+		for _, taskId := range []uint64{task3, task4} {
+			workflowIds, err := s.rootWorkflows(taskId)
+			t.AssertNil(err)
+			t.AssertEQ(len(workflowIds), 0)
+		}
+	})
+}
+
+// TestDependSatisfied 测试每种触发条件在上游成功、失败和跳过时的结果
+func TestDependSatisfied(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		cases := []struct {
+			condition int
+			success   bool
+			failed    bool
+			skipped   bool
+		}{
+			{model.TaskDependOnSuccess, true, false, false},
+			{model.TaskDependOnFailure, false, true, false},
+			{model.TaskDependOnDone, true, true, false},
+			{3, false, false, false},
+		}
+		for _, c := range cases {
+			t.Assert(dependSatisfied(c.condition, model.TaskLogSuccess), c.success)
+			t.Assert(dependSatisfied(c.condition, model.TaskLogFailed), c.failed)
+			t.Assert(dependSatisfied(c.condition, model.TaskLogSkipped), c.skipped)
+		}
+	})
+}
+
+// TestTaskWorkflowNextSkip 测试条件不满足时跳过下游任务, 跳过会沿依赖继续传递
+func TestTaskWorkflowNextSkip(t *testing.T) {
+	setupTestDB(t)
+	var (
+		task1    = addTestTask(t, "t1")
+		task2    = addTestTask(t, "t2")
+		task3    = addTestTask(t, "t3")
+		workflow = addTestWorkflow(t, "w1", 1)
+	)
+	// t1 成功后执行 t2, t2 执行完成后执行 t3
+	addTestDepend(t, workflow, task2, task1, model.TaskDependOnSuccess)
+	addTestDepend(t, workflow, task3, task2, model.TaskDependOnDone)
+
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			ctx   = gctx.New()
+			s     = NewTaskWorkflowService()
+			runId = addTestRun(t.T, workflow)
+		)
+		addTestLog(t.T, runId, task1, model.TaskLogFailed)
+		t.AssertNil(s.Next(ctx, runId, task1))
+
+		states, err := s.runStates(runId)
+		t.AssertNil(err)
+		t.Assert(states, map[uint64]int{
+			task1: model.TaskLogFailed,
+			task2: model.TaskLogSkipped,
+			task3: model.TaskLogSkipped,
+		})
+		run, err := g.DB().Model(model.TableNameTaskWorkflowRun).Where("id", runId).One()
+		t.AssertNil(err)
+		t.Assert(run["status"].Int(), model.TaskWorkflowRunFailed)
+		t.AssertNE(run["endTime"].String(), "")
+
+		// 已处理过的下游任务不会再次被跳过或触发
+		t.AssertNil(s.Next(ctx, runId, task1))
+		count, err := g.DB().Model(model.TableNameTaskLog).Where("runId", runId).Count()
+		t.AssertNil(err)
+		t.Assert(count, 3)
+	})
+}
+
+// TestTaskWorkflowFinish 测试所有任务都有执行结果后结束运行
+func TestTaskWorkflowFinish(t *testing.T) {
+	setupTestDB(t)
+	var (
+		task1    = addTestTask(t, "t1")
+		task2    = addTestTask(t, "t2")
+		workflow = addTestWorkflow(t, "w1", 1)
+	)
+	addTestDepend(t, workflow, task2, task1, model.TaskDependOnSuccess)
+
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			ctx = gctx.New()
+			s   = NewTaskWorkflowService()
+		)
+		depends, err := s.depends(workflow)
+		t.AssertNil(err)
+		status := func(runId uint64) int {
+			run, err := g.DB().Model(model.TableNameTaskWorkflowRun).Where("id", runId).One()
+			t.AssertNil(err)
+			return run["status"].Int()
+		}
+
+		// 还有任务未执行时继续运行
+		runId := addTestRun(t.T, workflow)
+		addTestLog(t.T, runId, task1, model.TaskLogSuccess)
+		t.AssertNil(s.finish(ctx, runId, depends))
+		t.Assert(status(runId), model.TaskWorkflowRunRunning)
+
+		// 全部成功
+		addTestLog(t.T, runId, task2, model.TaskLogSuccess)
+		t.AssertNil(s.finish(ctx, runId, depends))
+		t.Assert(status(runId), model.TaskWorkflowRunSuccess)
+
+		// 跳过的任务不算失败
+		runId = addTestRun(t.T, workflow)
+		addTestLog(t.T, runId, task1, model.TaskLogSuccess)
+		addTestLog(t.T, runId, task2, model.TaskLogSkipped)
+		t.AssertNil(s.finish(ctx, runId, depends))
+		t.Assert(status(runId), model.TaskWorkflowRunSuccess)
+
+		// 存在失败的任务, 以最新的执行结果为准
+		runId = addTestRun(t.T, workflow)
+		addTestLog(t.T, runId, task1, model.TaskLogSuccess)
+		addTestLog(t.T, runId, task2, model.TaskLogSuccess)
+		addTestLog(t.T, runId, task2, model.TaskLogFailed)
+		t.AssertNil(s.finish(ctx, runId, depends))
+		t.Assert(status(runId), model.TaskWorkflowRunFailed)
+
+		// 已结束的运行不会被修改
+		addTestLog(t.T, runId, task2, model.TaskLogSuccess)
+		t.AssertNil(s.finish(ctx, runId, depends))
+		t.Assert(status(runId), model.TaskWorkflowRunFailed)
+	})
+}
@@ -3,6 +3,7 @@ package v
 import "github.com/vera-byte/vgo/v/vconfig"
 
 var (
-	Config            = vconfig.Config            // 配置中的v节相关配置
+	Config            = vconfig.Config            // 配置中的v节相关配置, 为启动时的快照
+	ConfigBinding     = vconfig.ConfigBinding     // 配置中的v节相关配置的热更新绑定
 	GetCfgWithDefault = vconfig.GetCfgWithDefault // GetCfgWithDefault 获取配置，如果配置不存在，则使用默认值
 )
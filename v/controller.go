@@ -81,6 +81,8 @@ func (c *Controller) Add(ctx context.Context, req *AddReq) (res *BaseRes, err er
 		if err != nil {
 			return Fail(err.Error()), err
 		}
+		// 数据引用的临时文件转为永久文件
+		KeepFiles(ctx, c.Service.GetModel(), g.RequestFromCtx(ctx).GetMap())
 		return Ok(data), err
 	}
 	g.RequestFromCtx(ctx).Response.Status = 404
@@ -115,6 +117,8 @@ func (c *Controller) Update(ctx context.Context, req *UpdateReq) (res *BaseRes,
 			return Fail(err.Error()), err
 		}
 		c.Service.ModifyAfter(ctx, "Update", g.RequestFromCtx(ctx).GetMap())
+		// 数据引用的临时文件转为永久文件
+		KeepFiles(ctx, c.Service.GetModel(), g.RequestFromCtx(ctx).GetMap())
 		return Ok(data), err
 	}
 	g.RequestFromCtx(ctx).Response.Status = 404
@@ -1,7 +1,230 @@
 package v
 
-import "github.com/vera-byte/vgo/v/vfile"
+import (
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/v/vfile"
+)
 
 var (
 	File = vfile.NewFile // File 文件上传操作
 )
+
+// PresignUpload 生成浏览器直传凭证
+// 签发的文件键记录在缓存中, 浏览器上传完成后需通过 ConfirmUpload 确认
+// fileName: 原文件名, 用于保留扩展名
+// contentType: 文件类型, 为空时按扩展名确定, 浏览器会执行的类型需要上传场景显式允许
+// size: 文件大小, 为0时不检查
+func PresignUpload(ctx g.Ctx, fileName, contentType string, size int64) (*vfile.PresignedUpload, error) {
+	presign := ConfigBinding.Get().File.Presign
+	if presign == nil || !presign.Enable {
+		return nil, gerror.Wrap(vfile.ErrUnsupported, "未开启浏览器直传")
+	}
+	rule, err := uploadRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := vfile.UseOrFallback(rule.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := driver.(vfile.Presigner); !ok {
+		return nil, gerror.Wrapf(vfile.ErrUnsupported, "%T: PresignUpload", driver)
+	}
+	if presign.MaxSize > 0 && size > presign.MaxSize {
+		return nil, gerror.Newf("文件大小不能超过%d字节", presign.MaxSize)
+	}
+	// 直传无法读取文件内容, 按上传场景校验文件名、大小和声明的类型
+	if err = rule.CheckName(fileName); err != nil {
+		return nil, err
+	}
+	if rule.MaxSize > 0 && size > rule.MaxSize {
+		return nil, gerror.Newf("文件大小不能超过%d字节", rule.MaxSize)
+	}
+	if err = vfile.CheckQuota(ctx, size); err != nil {
+		return nil, err
+	}
+	// 未声明类型时也在凭证中限定类型, 避免浏览器以会执行的类型保存到公开的存储中
+	if contentType == "" {
+		contentType = vfile.SafeContentType(fileName)
+	}
+	if !matchContentTypes(contentType, presign.ContentTypes) {
+		return nil, gerror.Newf("不允许上传该类型的文件: %s", contentType)
+	}
+	if err = rule.CheckContentType(contentType); err != nil {
+		return nil, err
+	}
+	expire, err := time.ParseDuration(presign.Expire)
+	if err != nil || expire <= 0 {
+		expire = 10 * time.Minute
+	}
+
+	key := vfile.NewKey(fileName)
+	presigned, err := vfile.PresignUpload(ctx, driver, key, &vfile.PresignOptions{
+		Expire:      expire,
+		MaxSize:     minSize(size, rule.MaxSize, presign.MaxSize),
+		ContentType: contentType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 凭证过期前开始的上传可能在过期后才完成, 确认的有效期适当延长
+	err = CacheManager.Set(ctx, presignCacheKey(key), g.Map{
+		"userId":   uploaderId(ctx),
+		"scene":    rule.Scene,
+		"driver":   vfile.DriverName(driver),
+		"fileName": vfile.SanitizeFileName(fileName),
+	}, expire+time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	return presigned, nil
+}
+
+// ConfirmUpload 确认浏览器直传的文件, 返回文件信息和访问地址
+// 仅能确认由 PresignUpload 签发的文件键, 每个文件键只能确认一次
+// 文件大小或类型不符合配置、超出配额, 或未通过病毒扫描时删除已上传的文件
+func ConfirmUpload(ctx g.Ctx, key string) (*vfile.ObjectInfo, string, error) {
+	cached, err := CacheManager.Remove(ctx, presignCacheKey(key))
+	if err != nil {
+		return nil, "", err
+	}
+	if cached.IsNil() || cached.MapStrVar()["userId"].Uint() != uploaderId(ctx) {
+		return nil, "", gerror.New("上传凭证无效或已过期")
+	}
+	rule, err := vfile.RuleOf(cached.MapStrVar()["scene"].String())
+	if err != nil {
+		return nil, "", err
+	}
+
+	driver, err := vfile.Use(cached.MapStrVar()["driver"].String())
+	if err != nil {
+		return nil, "", err
+	}
+	info, err := vfile.Stat(ctx, driver, key)
+	if gerror.Is(err, vfile.ErrNotFound) {
+		return nil, "", gerror.New("文件未上传")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	presign := ConfigBinding.Get().File.Presign
+	if presign != nil {
+		if presign.MaxSize > 0 && info.Size > presign.MaxSize {
+			err = gerror.Newf("文件大小不能超过%d字节", presign.MaxSize)
+		} else if !matchContentTypes(info.ContentType, presign.ContentTypes) {
+			err = gerror.Newf("不允许上传该类型的文件: %s", info.ContentType)
+		}
+	}
+	if err == nil {
+		if rule.MaxSize > 0 && info.Size > rule.MaxSize {
+			err = gerror.Newf("文件大小不能超过%d字节", rule.MaxSize)
+		} else {
+			err = rule.CheckContentType(info.ContentType)
+		}
+	}
+	// 凭证中的大小由客户端声明, 按实际大小重新检查配额
+	if err == nil {
+		err = vfile.CheckQuota(ctx, info.Size)
+	}
+	if err == nil {
+		err = vfile.ScanObject(ctx, driver, &vfile.Upload{
+			Key:         key,
+			FileName:    cached.MapStrVar()["fileName"].String(),
+			ContentType: info.ContentType,
+			Size:        info.Size,
+			Scene:       rule.Scene,
+		})
+	}
+	if err != nil {
+		if deleteErr := vfile.Delete(ctx, driver, key); deleteErr != nil {
+			g.Log().Warningf(ctx, "删除不符合限制的文件 %s 失败: %v", key, deleteErr)
+		}
+		return nil, "", err
+	}
+	url, err := vfile.URL(driver, key)
+	if err != nil {
+		return nil, "", err
+	}
+	return trackUpload(ctx, driver, info, url, cached.MapStrVar()["fileName"].String(), "")
+}
+
+// minSize 返回大于0的大小中最小的一个, 都不大于0时返回0表示不限制
+func minSize(sizes ...int64) int64 {
+	var result int64
+	for _, size := range sizes {
+		if size > 0 && (result == 0 || size < result) {
+			result = size
+		}
+	}
+	return result
+}
+
+// trackUpload 登记浏览器上传完成的文件, 驱动中已有相同内容时删除新文件并返回已有文件
+// hash 为空时读取文件计算
+func trackUpload(ctx g.Ctx, driver vfile.Driver, info *vfile.ObjectInfo, url, fileName, hash string) (*vfile.ObjectInfo, string, error) {
+	record, err := vfile.TrackRecord(ctx, driver, &vfile.Record{
+		Key:         info.Key,
+		URL:         url,
+		Hash:        strings.ToLower(hash),
+		Size:        info.Size,
+		ContentType: info.ContentType,
+		FileName:    fileName,
+		UserId:      uploaderId(ctx),
+	}, info.Key)
+	if err != nil {
+		return nil, "", err
+	}
+	if record.Key != info.Key {
+		info = &vfile.ObjectInfo{Key: record.Key, Size: record.Size, ContentType: record.ContentType}
+	}
+	return info, record.URL, nil
+}
+
+// matchContentTypes 判断文件类型是否在允许的类型中, 未配置时不限制
+func matchContentTypes(contentType string, limits []string) bool {
+	if len(limits) == 0 {
+		return true
+	}
+	for _, limit := range limits {
+		if vfile.MatchContentType(contentType, strings.TrimSpace(limit)) {
+			return true
+		}
+	}
+	return false
+}
+
+// presignCacheKey 直传文件键的缓存键
+func presignCacheKey(key string) string {
+	return "v:file:presign:" + key
+}
+
+// uploadRule 当前请求的上传场景规则, 场景由请求参数 scene 指定
+func uploadRule(ctx g.Ctx) (*vfile.Rule, error) {
+	scene := ""
+	if request := g.RequestFromCtx(ctx); request != nil {
+		scene = request.Get("scene").String()
+	}
+	return vfile.RuleOf(scene)
+}
+
+// FileOf 当前请求上传场景使用的文件驱动, 场景由请求参数 scene 指定
+// 场景未配置存储时使用 v.file.mode, 驱动不可用时使用 v.file.fallback
+func FileOf(ctx g.Ctx) (vfile.Driver, error) {
+	rule, err := uploadRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return vfile.UseOrFallback(rule.Profile)
+}
+
+// uploaderId 当前请求的管理员ID, 非请求上下文时为0
+func uploaderId(ctx g.Ctx) uint {
+	if g.RequestFromCtx(ctx) == nil {
+		return 0
+	}
+	return GetAdmin(ctx).UserId
+}
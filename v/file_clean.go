@@ -0,0 +1,182 @@
+package v
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// RegisterTemporaryFileColumn 登记保存文件地址的字段, temporary 字段为真的数据是临时数据, 不算作对文件的引用
+// 如 v.RegisterTemporaryFileColumn(model.NewSpaceInfo(), "url", "temporary")
+func RegisterTemporaryFileColumn(model IModel, column, temporary string) {
+	fileColumnsMu.Lock()
+	defer fileColumnsMu.Unlock()
+	fileColumns = append(fileColumns, &fileColumn{model: model, column: column, temporary: temporary})
+}
+
+// KeepFiles 业务数据保存后调用, 将已登记字段引用的临时文件转为永久文件
+// data 为保存的数据, 临时数据引用的文件不处理
+func KeepFiles(ctx g.Ctx, model IModel, data g.MapStrAny) {
+	fileColumnsMu.RLock()
+	var urls []string
+	for _, column := range fileColumns {
+		if column.model.TableName() != model.TableName() {
+			continue
+		}
+		if column.temporary != "" && gconv.Bool(data[column.temporary]) {
+			continue
+		}
+		if url := gconv.String(data[column.column]); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	fileColumnsMu.RUnlock()
+	if err := vfile.Keep(ctx, urls...); err != nil {
+		g.Log().Warningf(ctx, "保留文件 %v 失败: %v", urls, err)
+	}
+}
+
+// FileReferenced 文件地址是否被已登记字段中的业务数据引用, 已删除和临时数据不算作引用
+func FileReferenced(ctx g.Ctx, url string) (bool, error) {
+	fileColumnsMu.RLock()
+	columns := append([]*fileColumn(nil), fileColumns...)
+	fileColumnsMu.RUnlock()
+	for _, column := range columns {
+		m := DBM(column.model).Ctx(ctx).Where(column.column, url)
+		if column.temporary != "" {
+			m = m.Where(column.temporary, 0)
+		}
+		count, err := m.Count()
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CleanOptions 文件清理参数
+type CleanOptions struct {
+	TTL    time.Duration // 保留时间, 为0时使用 v.file.temporary.ttl
+	Orphan bool          // 是否扫描存储中没有元数据记录的孤立文件
+	Driver string        // 扫描的驱动或命名存储配置, 为空时使用 v.file.mode
+	Prefix string        // 扫描存储时的文件键前缀, 开启 Orphan 时不能为空
+	DryRun bool          // 只统计需要删除的文件, 不做修改
+}
+
+// CleanReport 文件清理结果
+type CleanReport struct {
+	Kept     int   `json:"kept"`     // 被业务数据引用而转为永久文件的临时文件数
+	Purged   int   `json:"purged"`   // 已删除或需要删除的临时文件数
+	Released int   `json:"released"` // 被多次上传而只减少一次引用的临时文件数
+	Orphans  int   `json:"orphans"`  // 已删除或需要删除的孤立文件数
+	Bytes    int64 `json:"bytes"`    // 已释放或需要释放的字节数
+	Failed   int   `json:"failed"`   // 删除失败的文件数, 失败原因记录在日志中
+	DryRun   bool  `json:"dryRun"`   // 是否为预演
+}
+
+// CleanFiles 删除超过保留时间且没有被业务数据引用的临时文件, 被多次上传的临时文件只减少一次引用
+// 开启 Orphan 时同时删除存储中超过保留时间、没有元数据记录也没有被业务数据引用的文件
+func CleanFiles(ctx g.Ctx, options *CleanOptions) (*CleanReport, error) {
+	if options.Orphan && strings.Trim(options.Prefix, "/") == "" {
+		return nil, gerror.New("扫描孤立文件需要指定文件键前缀")
+	}
+	ttl := options.TTL
+	if ttl <= 0 {
+		ttl = vfile.TemporaryTTL()
+	}
+	before := time.Now().Add(-ttl)
+	report := &CleanReport{DryRun: options.DryRun}
+
+	records, err := vfile.ExpiredRecords(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		referenced, err := FileReferenced(ctx, record.URL)
+		if err != nil {
+			return report, err
+		}
+		if referenced {
+			report.Kept++
+			if !options.DryRun {
+				if err = vfile.Keep(ctx, record.URL); err != nil {
+					return report, err
+				}
+			}
+			continue
+		}
+		purged := record.RefCount <= 1
+		if !options.DryRun {
+			if purged, err = vfile.PurgeRecord(ctx, record); err != nil {
+				g.Log().Warningf(ctx, "删除临时文件 %s 失败: %v", record.URL, err)
+				report.Failed++
+				continue
+			}
+		}
+		if !purged {
+			report.Released++
+			continue
+		}
+		report.Purged++
+		report.Bytes += record.Size
+	}
+
+	if options.Orphan {
+		err = cleanOrphans(ctx, options, before, report)
+	}
+	return report, err
+}
+
+// cleanOrphans 删除存储中修改时间早于 before、没有元数据记录也没有被业务数据引用的文件
+// 修改时间未知的文件不删除, 早于第一条元数据记录的文件在记录元数据之前上传, 同样不删除
+func cleanOrphans(ctx g.Ctx, options *CleanOptions, before time.Time, report *CleanReport) error {
+	d, err := vfile.Use(options.Driver)
+	if err != nil {
+		return err
+	}
+	first, err := vfile.FirstRecordTime(ctx, d)
+	if err != nil || first.IsZero() {
+		return err
+	}
+	objects, err := vfile.List(ctx, d, options.Prefix)
+	if err != nil {
+		return err
+	}
+	known, err := vfile.RecordKeys(ctx, d, options.Prefix)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if known[object.Key] || object.LastModified.IsZero() || !object.LastModified.Before(before) || object.LastModified.Before(first) {
+			continue
+		}
+		url, err := vfile.URL(d, object.Key)
+		if err != nil {
+			return err
+		}
+		referenced, err := FileReferenced(ctx, url)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			continue
+		}
+		if !options.DryRun {
+			if err = vfile.Delete(ctx, d, object.Key); err != nil {
+				g.Log().Warningf(ctx, "删除孤立文件 %s 失败: %v", object.Key, err)
+				report.Failed++
+				continue
+			}
+		}
+		report.Orphans++
+		report.Bytes += object.Size
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package v
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// testTemporaryStore 支持临时文件的内存元数据存储
+type testTemporaryStore struct {
+	records map[string]*vfile.Record // url -> 记录
+	created map[string]time.Time     // url -> 创建时间
+}
+
+func (s *testTemporaryStore) Acquire(ctx g.Ctx, driver, hash string) (*vfile.Record, error) {
+	return nil, nil
+}
+
+func (s *testTemporaryStore) Create(ctx g.Ctx, record *vfile.Record) error {
+	s.records[record.URL] = record
+	return nil
+}
+
+func (s *testTemporaryStore) Release(ctx g.Ctx, url string) (*vfile.Record, error) {
+	record, ok := s.records[url]
+	if !ok {
+		return nil, nil
+	}
+	record.RefCount--
+	if record.RefCount <= 0 {
+		delete(s.records, url)
+	}
+	return record, nil
+}
+
+func (s *testTemporaryStore) Keep(ctx g.Ctx, urls []string) error {
+	for _, url := range urls {
+		if record, ok := s.records[url]; ok {
+			record.Temporary = false
+		}
+	}
+	return nil
+}
+
+func (s *testTemporaryStore) Expired(ctx g.Ctx, before time.Time) ([]*vfile.Record, error) {
+	var records []*vfile.Record
+	for url, record := range s.records {
+		if record.Temporary && s.created[url].Before(before) {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (s *testTemporaryStore) Purge(ctx g.Ctx, url string) (bool, error) {
+	if record, ok := s.records[url]; ok && record.RefCount > 1 {
+		return false, nil
+	}
+	delete(s.records, url)
+	return true, nil
+}
+
+func (s *testTemporaryStore) Records(ctx g.Ctx, driver, prefix string) ([]*vfile.Record, error) {
+	var records []*vfile.Record
+	for _, record := range s.records {
+		if record.Driver == driver && strings.HasPrefix(record.Key, prefix) {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (s *testTemporaryStore) FirstCreated(ctx g.Ctx, driver string) (first time.Time, err error) {
+	for url, record := range s.records {
+		if record.Driver == driver && (first.IsZero() || s.created[url].Before(first)) {
+			first = s.created[url]
+		}
+	}
+	return
+}
+
+// TestCleanFiles 测试临时文件和孤立文件的清理
+func TestCleanFiles(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		old := time.Now().Add(-48 * time.Hour)
+		d := newTestStorageDriver(map[string]string{
+			"uploads/temp.txt":   "temp",
+			"uploads/new.txt":    "new",
+			"uploads/kept.txt":   "kept",
+			"uploads/orphan.txt": "orphan",
+			"uploads/recent.txt": "recent",
+			"uploads/shared.txt": "shared",
+			"uploads/legacy.txt": "legacy",
+			"readme.txt":         "readme",
+		})
+		for key, info := range d.files {
+			switch key {
+			case "uploads/recent.txt":
+			case "uploads/legacy.txt":
+				// 早于第一条元数据记录的文件不算作孤立文件
+				info.LastModified = old.Add(-time.Hour)
+			default:
+				info.LastModified = old
+			}
+		}
+		vfile.Register("clean", d)
+		defer delete(vfile.FileMap, "clean")
+		store := &testTemporaryStore{records: map[string]*vfile.Record{}, created: map[string]time.Time{}}
+		vfile.RegisterRecordStore(store)
+		defer vfile.RegisterRecordStore(nil)
+		for key, temporary := range map[string]bool{"uploads/temp.txt": true, "uploads/new.txt": true, "uploads/kept.txt": false, "uploads/shared.txt": true} {
+			url := d.URL(key)
+			store.records[url] = &vfile.Record{Driver: "clean", Key: key, URL: url, Size: 4, RefCount: 1, Temporary: temporary}
+			store.created[url] = old
+		}
+		store.created[d.URL("uploads/new.txt")] = time.Now()
+		store.records[d.URL("uploads/shared.txt")].RefCount = 2
+
+		// 扫描孤立文件需要指定前缀
+		_, err := CleanFiles(ctx, &CleanOptions{Orphan: true, Driver: "clean", Prefix: "/"})
+		t.AssertNE(err, nil)
+
+		options := &CleanOptions{Orphan: true, Driver: "clean", Prefix: "uploads/", DryRun: true}
+		report, err := CleanFiles(ctx, options)
+		t.AssertNil(err)
+		t.Assert(report.Purged, 1)
+		t.Assert(report.Released, 1)
+		t.Assert(report.Orphans, 1)
+		t.Assert(len(d.files), 8)
+
+		options.DryRun = false
+		report, err = CleanFiles(ctx, options)
+		t.AssertNil(err)
+		t.Assert(report.Purged, 1)
+		t.Assert(report.Released, 1)
+		t.Assert(report.Orphans, 1)
+		t.Assert(report.Bytes, 10)
+		for _, key := range []string{"uploads/temp.txt", "uploads/orphan.txt"} {
+			_, ok := d.files[key]
+			t.Assert(ok, false)
+		}
+		t.Assert(len(d.files), 6)
+		_, ok := store.records[d.URL("uploads/temp.txt")]
+		t.Assert(ok, false)
+		t.Assert(store.records[d.URL("uploads/shared.txt")].RefCount, 1)
+
+		// 转为永久文件后不再清理, 只剩一次引用的临时文件被删除
+		t.AssertNil(vfile.Keep(ctx, d.URL("uploads/new.txt")))
+		store.created[d.URL("uploads/new.txt")] = old
+		report, err = CleanFiles(ctx, options)
+		t.AssertNil(err)
+		t.Assert(report.Purged, 1)
+		t.Assert(report.Released, 0)
+		t.Assert(report.Orphans, 0)
+		_, ok = d.files["uploads/shared.txt"]
+		t.Assert(ok, false)
+		_, ok = d.files["uploads/legacy.txt"]
+		t.Assert(ok, true)
+	})
+}
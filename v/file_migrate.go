@@ -0,0 +1,205 @@
+package v
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// fileColumn 保存文件地址的数据表字段
+type fileColumn struct {
+	model     IModel
+	column    string
+	temporary string // 标记临时数据的字段, 临时数据不算作对文件的引用
+}
+
+var (
+	fileColumns   []*fileColumn
+	fileColumnsMu sync.RWMutex
+)
+
+// RegisterFileColumns 登记保存文件地址的字段, 迁移存储时改写字段中的地址
+// 如 v.RegisterFileColumns(model.NewBaseSysUser(), "headImg")
+func RegisterFileColumns(model IModel, columns ...string) {
+	fileColumnsMu.Lock()
+	defer fileColumnsMu.Unlock()
+	for _, column := range columns {
+		fileColumns = append(fileColumns, &fileColumn{model: model, column: column})
+	}
+}
+
+// MigrateOptions 存储迁移参数
+type MigrateOptions struct {
+	From        string // 源驱动或命名存储配置
+	To          string // 目标驱动或命名存储配置
+	Prefix      string // 只迁移该前缀下的文件, 为空时迁移全部
+	Concurrency int    // 并发复制的文件数, 默认4
+	DryRun      bool   // 只统计需要复制的文件和需要改写的地址, 不做修改
+}
+
+// MigrateFailure 复制失败的文件
+type MigrateFailure struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// MigrateReport 存储迁移结果
+type MigrateReport struct {
+	Total    int               `json:"total"`    // 源存储中的文件数
+	Copied   int               `json:"copied"`   // 已复制或需要复制的文件数
+	Skipped  int               `json:"skipped"`  // 目标存储中已存在的文件数
+	Bytes    int64             `json:"bytes"`    // 已复制或需要复制的字节数
+	Failed   []*MigrateFailure `json:"failed"`   // 复制失败的文件
+	Rewrites map[string]int    `json:"rewrites"` // 每个字段改写或需要改写的地址数, 如 space_info.url
+	DryRun   bool              `json:"dryRun"`   // 是否为预演
+}
+
+// MigrateFiles 将文件从一个存储复制到另一个存储, 并改写已登记字段中的文件地址
+// 目标存储中已有大小相同的文件时跳过, 中断后重新执行即可继续迁移
+// 复制失败的文件不改写地址, 源存储中的文件不会删除
+func MigrateFiles(ctx g.Ctx, options *MigrateOptions) (*MigrateReport, error) {
+	if options.From == "" || options.To == "" || options.From == options.To {
+		return nil, gerror.New("源存储和目标存储不能为空或相同")
+	}
+	src, err := vfile.Use(options.From)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := vfile.Use(options.To)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := vfile.List(ctx, src, options.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	report := &MigrateReport{Total: len(objects), Rewrites: map[string]int{}, DryRun: options.DryRun}
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed = map[string]bool{}
+		queue  = make(chan *vfile.ObjectInfo)
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for object := range queue {
+				copied, err := migrateObject(ctx, src, dst, object, options.DryRun)
+				mu.Lock()
+				switch {
+				case err != nil:
+					failed[object.Key] = true
+					report.Failed = append(report.Failed, &MigrateFailure{Key: object.Key, Error: err.Error()})
+				case copied:
+					report.Copied++
+					report.Bytes += object.Size
+				default:
+					report.Skipped++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, object := range objects {
+		queue <- object
+	}
+	close(queue)
+	wg.Wait()
+	sort.Slice(report.Failed, func(i, j int) bool { return report.Failed[i].Key < report.Failed[j].Key })
+
+	if !options.DryRun {
+		skip := make([]string, 0, len(failed))
+		for key := range failed {
+			skip = append(skip, key)
+		}
+		if err = vfile.MoveRecords(ctx, options.From, options.To, options.Prefix, skip); err != nil {
+			return report, err
+		}
+	}
+	fileColumnsMu.RLock()
+	columns := append([]*fileColumn(nil), fileColumns...)
+	fileColumnsMu.RUnlock()
+	for _, column := range columns {
+		count, err := rewriteFileColumn(ctx, column, src, dst, options, failed)
+		report.Rewrites[column.model.TableName()+"."+column.column] += count
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// migrateObject 复制单个文件, 目标存储中已有大小相同的文件时返回false
+func migrateObject(ctx g.Ctx, src, dst vfile.Driver, object *vfile.ObjectInfo, dryRun bool) (bool, error) {
+	info, err := vfile.Stat(ctx, dst, object.Key)
+	if err == nil && info.Size == object.Size {
+		return false, nil
+	}
+	if err != nil && !gerror.Is(err, vfile.ErrNotFound) {
+		return false, err
+	}
+	if dryRun {
+		return true, nil
+	}
+	reader, info, err := vfile.Open(ctx, src, object.Key)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+	return true, vfile.Put(ctx, dst, object.Key, reader, info.Size, info.ContentType)
+}
+
+// rewriteFileColumn 改写字段中源存储的文件地址, 返回改写或需要改写的行数
+// 软删除的数据也会改写
+func rewriteFileColumn(ctx g.Ctx, column *fileColumn, src, dst vfile.Driver, options *MigrateOptions, failed map[string]bool) (int, error) {
+	const batch = 500
+	count := 0
+	lastId := 0
+	for {
+		rows, err := DBM(column.model).Ctx(ctx).Unscoped().
+			Fields("id", column.column).
+			WhereGT("id", lastId).
+			WhereNotNull(column.column).
+			OrderAsc("id").
+			Limit(batch).
+			All()
+		if err != nil {
+			return count, err
+		}
+		for _, row := range rows {
+			lastId = row["id"].Int()
+			url := row[column.column].String()
+			key, ok := vfile.Key(src, url)
+			if !ok || failed[key] || !strings.HasPrefix(key, options.Prefix) {
+				continue
+			}
+			target, err := vfile.URL(dst, key)
+			if err != nil {
+				return count, err
+			}
+			if target == url {
+				continue
+			}
+			count++
+			if options.DryRun {
+				continue
+			}
+			if _, err = DBM(column.model).Ctx(ctx).Unscoped().Data(column.column, target).Where("id", lastId).Update(); err != nil {
+				return count, err
+			}
+		}
+		if len(rows) < batch {
+			return count, nil
+		}
+	}
+}
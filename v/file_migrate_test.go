@@ -0,0 +1,119 @@
+package v
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// testStorageDriver 支持列出、读取和保存的内存文件驱动, 保存文件键 failKey 时失败
+type testStorageDriver struct {
+	testPresignDriver
+	mu       sync.Mutex
+	contents map[string]string
+	failKey  string
+}
+
+func newTestStorageDriver(contents map[string]string) *testStorageDriver {
+	d := &testStorageDriver{testPresignDriver: testPresignDriver{files: map[string]*vfile.ObjectInfo{}}, contents: map[string]string{}}
+	for key, content := range contents {
+		d.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "text/plain")
+	}
+	return d
+}
+
+func (d *testStorageDriver) New() vfile.Driver { return d }
+
+func (d *testStorageDriver) Put(ctx g.Ctx, key string, reader io.Reader, size int64, contentType string) error {
+	if key == d.failKey {
+		return gerror.New("保存失败")
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.contents[key] = string(content)
+	d.files[key] = &vfile.ObjectInfo{Key: key, Size: int64(len(content)), ContentType: contentType}
+	return nil
+}
+
+func (d *testStorageDriver) Stat(ctx g.Ctx, key string) (*vfile.ObjectInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.testPresignDriver.Stat(ctx, key)
+}
+
+func (d *testStorageDriver) Open(ctx g.Ctx, key string) (io.ReadCloser, *vfile.ObjectInfo, error) {
+	info, err := d.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return io.NopCloser(strings.NewReader(d.contents[key])), info, nil
+}
+
+func (d *testStorageDriver) List(ctx g.Ctx, prefix string) ([]*vfile.ObjectInfo, error) {
+	var infos []*vfile.ObjectInfo
+	for key, info := range d.files {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// TestMigrateFiles 测试存储迁移的预演、复制和断点继续
+func TestMigrateFiles(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		src := newTestStorageDriver(map[string]string{
+			"uploads/a.txt":   "a",
+			"uploads/b.txt":   "bb",
+			"uploads/bad.txt": "bad",
+			"other/c.txt":     "c",
+		})
+		dst := newTestStorageDriver(map[string]string{"uploads/b.txt": "bb"})
+		dst.failKey = "uploads/bad.txt"
+		vfile.Register("migrate-src", src)
+		vfile.Register("migrate-dst", dst)
+		defer delete(vfile.FileMap, "migrate-src")
+		defer delete(vfile.FileMap, "migrate-dst")
+
+		_, err := MigrateFiles(ctx, &MigrateOptions{From: "migrate-src", To: "migrate-src"})
+		t.AssertNE(err, nil)
+
+		options := &MigrateOptions{From: "migrate-src", To: "migrate-dst", Prefix: "uploads/", Concurrency: 2, DryRun: true}
+		report, err := MigrateFiles(ctx, options)
+		t.AssertNil(err)
+		t.Assert(report.Total, 3)
+		t.Assert(report.Copied, 2)
+		t.Assert(report.Skipped, 1)
+		t.Assert(report.Bytes, 4)
+		t.Assert(len(dst.contents), 1)
+
+		options.DryRun = false
+		report, err = MigrateFiles(ctx, options)
+		t.AssertNil(err)
+		t.Assert(report.Copied, 1)
+		t.Assert(report.Skipped, 1)
+		t.Assert(len(report.Failed), 1)
+		t.Assert(report.Failed[0].Key, "uploads/bad.txt")
+		t.Assert(dst.contents["uploads/a.txt"], "a")
+		_, ok := dst.contents["other/c.txt"]
+		t.Assert(ok, false)
+
+		// 再次执行时跳过已复制的文件
+		report, err = MigrateFiles(ctx, options)
+		t.AssertNil(err)
+		t.Assert(report.Copied, 0)
+		t.Assert(report.Skipped, 2)
+	})
+}
@@ -0,0 +1,134 @@
+package v
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// testPresignDriver 支持直传的内存文件驱动
+type testPresignDriver struct {
+	files map[string]*vfile.ObjectInfo
+}
+
+func (d *testPresignDriver) New() vfile.Driver                      { return d }
+func (d *testPresignDriver) GetMode() (data interface{}, err error) { return nil, nil }
+func (d *testPresignDriver) Upload(ctx g.Ctx) (string, error)       { return "", nil }
+
+func (d *testPresignDriver) PresignUpload(ctx g.Ctx, key string, options *vfile.PresignOptions) (*vfile.PresignedUpload, error) {
+	return &vfile.PresignedUpload{Method: "PUT", URL: "memory://upload/" + key, Key: key, MaxSize: options.MaxSize, ContentType: options.ContentType}, nil
+}
+
+func (d *testPresignDriver) Stat(ctx g.Ctx, key string) (*vfile.ObjectInfo, error) {
+	if info, ok := d.files[key]; ok {
+		return info, nil
+	}
+	return nil, vfile.ErrNotFound
+}
+
+func (d *testPresignDriver) Delete(ctx g.Ctx, key string) error {
+	delete(d.files, key)
+	return nil
+}
+
+func (d *testPresignDriver) URL(key string) string { return "memory://" + key }
+
+func (d *testPresignDriver) Key(url string) (string, bool) {
+	return strings.TrimPrefix(url, "memory://"), strings.HasPrefix(url, "memory://")
+}
+
+// TestPresignUpload 测试浏览器直传凭证签发与确认
+func TestPresignUpload(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		driver := &testPresignDriver{files: map[string]*vfile.ObjectInfo{}}
+		mode := ConfigBinding.Get().File.Mode
+		original := vfile.FileMap[mode]
+		vfile.Register(mode, driver)
+		defer vfile.Register(mode, original)
+		maxSize := ConfigBinding.Get().File.Presign.MaxSize
+
+		_, err := PresignUpload(ctx, "a.png", "image/png", maxSize+1)
+		t.AssertNE(err, nil)
+
+		presigned, err := PresignUpload(ctx, "a.png", "image/png", 10)
+		t.AssertNil(err)
+		t.Assert(strings.HasSuffix(presigned.Key, ".png"), true)
+		// 凭证按声明的大小限制上传的大小
+		t.Assert(presigned.MaxSize, 10)
+
+		// 未上传时确认失败, 凭证已使用
+		_, _, err = ConfirmUpload(ctx, presigned.Key)
+		t.AssertNE(err, nil)
+		driver.files[presigned.Key] = &vfile.ObjectInfo{Key: presigned.Key, Size: 10, ContentType: "image/png"}
+		_, _, err = ConfirmUpload(ctx, presigned.Key)
+		t.AssertNE(err, nil)
+
+		presigned, err = PresignUpload(ctx, "b.png", "", 0)
+		t.AssertNil(err)
+		driver.files[presigned.Key] = &vfile.ObjectInfo{Key: presigned.Key, Size: 10, ContentType: "image/png"}
+		info, url, err := ConfirmUpload(ctx, presigned.Key)
+		t.AssertNil(err)
+		t.Assert(info.Size, 10)
+		t.Assert(url, "memory://"+presigned.Key)
+		_, _, err = ConfirmUpload(ctx, presigned.Key)
+		t.AssertNE(err, nil)
+		_, _, err = ConfirmUpload(ctx, "uploads/other.png")
+		t.AssertNE(err, nil)
+
+		// 浏览器会执行的类型需要上传场景显式允许, 未声明类型时按扩展名限定安全的类型
+		_, err = PresignUpload(ctx, "a.txt", "text/html", 10)
+		t.AssertNE(err, nil)
+		_, err = PresignUpload(ctx, "a.txt", "image/svg+xml", 10)
+		t.AssertNE(err, nil)
+		presigned, err = PresignUpload(ctx, "d.png", "", 0)
+		t.AssertNil(err)
+		t.Assert(presigned.ContentType, "image/png")
+
+		// 存储中为会执行的类型时删除文件
+		presigned, err = PresignUpload(ctx, "e.txt", "text/plain", 0)
+		t.AssertNil(err)
+		driver.files[presigned.Key] = &vfile.ObjectInfo{Key: presigned.Key, Size: 10, ContentType: "text/html; charset=utf-8"}
+		_, _, err = ConfirmUpload(ctx, presigned.Key)
+		t.AssertNE(err, nil)
+		_, exists := driver.files[presigned.Key]
+		t.Assert(exists, false)
+
+		// 超过大小限制的文件被删除
+		presigned, err = PresignUpload(ctx, "c.png", "", 0)
+		t.AssertNil(err)
+		driver.files[presigned.Key] = &vfile.ObjectInfo{Key: presigned.Key, Size: maxSize + 1}
+		_, _, err = ConfirmUpload(ctx, presigned.Key)
+		t.AssertNE(err, nil)
+		_, exists = driver.files[presigned.Key]
+		t.Assert(exists, false)
+
+		// 确认时按实际大小检查配额, 超出时删除文件
+		vfile.RegisterQuota(&testQuota{remain: 5})
+		defer vfile.RegisterQuota(nil)
+		presigned, err = PresignUpload(ctx, "f.png", "", 1)
+		t.AssertNil(err)
+		driver.files[presigned.Key] = &vfile.ObjectInfo{Key: presigned.Key, Size: 10, ContentType: "image/png"}
+		_, _, err = ConfirmUpload(ctx, presigned.Key)
+		t.AssertNE(err, nil)
+		_, exists = driver.files[presigned.Key]
+		t.Assert(exists, false)
+	})
+}
+
+// testQuota 剩余空间固定的存储配额
+type testQuota struct {
+	remain int64
+}
+
+func (q *testQuota) Check(ctx g.Ctx, size int64) error {
+	if size > q.remain {
+		return gerror.New("存储空间不足")
+	}
+	return nil
+}
@@ -1,11 +1,14 @@
 package v
 
 import (
+	"encoding/json"
+	"sort"
 	"time"
 
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/vera-byte/vgo/v/vbus"
 )
 
 type vFunc interface {
@@ -17,6 +20,136 @@ type vFunc interface {
 	IsAllWorker() bool
 }
 
+// vFuncParamSchema 可选接口, 实现后可声明函数参数的JSON Schema, 保存任务时据此校验参数
+type vFuncParamSchema interface {
+	ParamSchema() string
+}
+
+// vFuncDescription 可选接口, 实现后可为函数提供说明, 用于管理后台展示
+type vFuncDescription interface {
+	Description() string
+}
+
+// FuncInfo 已注册函数的描述信息
+type FuncInfo struct {
+	Name        string `json:"name"`                  // 函数名称
+	Description string `json:"description"`           // 函数说明
+	IsSingleton bool   `json:"isSingleton"`           // 是否单例
+	IsAllWorker bool   `json:"isAllWorker"`           // 是否所有worker都执行
+	ParamSchema g.Map  `json:"paramSchema,omitempty"` // 参数Schema
+}
+
+// FuncCall 结构化的函数调用, 序列化后形如 {"name":"TaskTest","params":{"id":1}}
+type FuncCall struct {
+	Name   string          `json:"name"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Param 获取传递给 vFunc.Func 的参数字符串, 字符串参数去掉引号, 其他类型保持JSON原文
+func (c *FuncCall) Param() string {
+	if len(c.Params) == 0 || string(c.Params) == "null" {
+		return ""
+	}
+	var str string
+	if err := json.Unmarshal(c.Params, &str); err == nil {
+		return str
+	}
+	return string(c.Params)
+}
+
+// String 序列化为结构化调用字符串
+func (c *FuncCall) String() string {
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+// NewFuncCall 创建结构化函数调用, params 为任意可JSON序列化的值
+func NewFuncCall(name string, params interface{}) (call *FuncCall, err error) {
+	call = &FuncCall{Name: name}
+	if params == nil {
+		return
+	}
+	call.Params, err = json.Marshal(params)
+	return
+}
+
+// ParseFuncCall 解析函数调用字符串
+// 支持结构化格式 {"name":"TaskTest","params":{...}} 以及兼容旧格式 TaskTest(abc)
+// 旧格式的参数与原实现一致, 取第一个"("与第一个")"之间的原始内容, 不去除引号
+// 仅当参数为JSON对象或数组时取到最后一个")"并按JSON处理, 以便参数Schema校验
+func ParseFuncCall(funcstring string) (call *FuncCall, err error) {
+	funcstring = gstr.Trim(funcstring)
+	if gstr.HasPrefix(funcstring, "{") {
+		call = &FuncCall{}
+		if err = json.Unmarshal([]byte(funcstring), call); err != nil {
+			return nil, gerror.Wrap(err, "函数调用格式错误")
+		}
+		if call.Name == "" {
+			return nil, gerror.New("函数调用缺少name")
+		}
+		return
+	}
+	call = &FuncCall{Name: funcstring}
+	start := gstr.Pos(funcstring, "(")
+	if start < 0 {
+		return
+	}
+	call.Name = gstr.Trim(funcstring[:start])
+	if end := gstr.PosR(funcstring, ")"); end > start {
+		param := gstr.Trim(funcstring[start+1 : end])
+		if (gstr.HasPrefix(param, "{") || gstr.HasPrefix(param, "[")) && json.Valid([]byte(param)) {
+			call.Params = json.RawMessage(param)
+			return
+		}
+	}
+	end := gstr.Pos(funcstring, ")", start)
+	if end < 0 {
+		return nil, gerror.New("函数调用格式错误:" + funcstring)
+	}
+	if param := funcstring[start+1 : end]; param != "" {
+		call.Params, _ = json.Marshal(param)
+	}
+	return
+}
+
+// GetFuncSchema 获取函数声明的参数Schema, 未声明时返回nil
+func GetFuncSchema(name string) (schema g.Map, err error) {
+	f, ok := FuncMap[name]
+	if !ok {
+		return nil, gerror.New("函数不存在:" + name)
+	}
+	s, ok := f.(vFuncParamSchema)
+	if !ok || s.ParamSchema() == "" {
+		return nil, nil
+	}
+	if err = json.Unmarshal([]byte(s.ParamSchema()), &schema); err != nil {
+		return nil, gerror.Wrapf(err, "函数%s的参数Schema格式错误", name)
+	}
+	return
+}
+
+// ValidateFuncCall 校验函数调用字符串, 检查函数是否存在以及参数是否符合函数声明的Schema
+func ValidateFuncCall(funcstring string) (call *FuncCall, err error) {
+	call, err = ParseFuncCall(funcstring)
+	if err != nil {
+		return
+	}
+	schema, err := GetFuncSchema(call.Name)
+	if err != nil || schema == nil {
+		return
+	}
+	var data interface{}
+	if len(call.Params) > 0 {
+		if err = json.Unmarshal(call.Params, &data); err != nil {
+			return nil, gerror.Wrap(err, "函数参数格式错误")
+		}
+	}
+	if err = ValidateSchema(schema, data); err != nil {
+		return nil, gerror.Wrapf(err, "函数%s参数校验失败", call.Name)
+	}
+	return
+}
+
 // FuncMap 函数列表
 var FuncMap = make(map[string]vFunc)
 
@@ -25,79 +158,116 @@ func RegisterFunc(name string, f vFunc) {
 	FuncMap[name] = f
 }
 
+// GetFuncInfo 获取已注册函数的描述信息
+func GetFuncInfo(name string) (info *FuncInfo, err error) {
+	f, ok := FuncMap[name]
+	if !ok {
+		return nil, gerror.New("函数不存在:" + name)
+	}
+	info = &FuncInfo{
+		Name:        name,
+		IsSingleton: f.IsSingleton(),
+		IsAllWorker: f.IsAllWorker(),
+	}
+	if d, ok := f.(vFuncDescription); ok {
+		info.Description = d.Description()
+	}
+	info.ParamSchema, err = GetFuncSchema(name)
+	return
+}
+
+// ListFuncs 获取所有已注册函数的描述信息, 按名称排序
+func ListFuncs() (list []*FuncInfo, err error) {
+	names := make([]string, 0, len(FuncMap))
+	for name := range FuncMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		info, err := GetFuncInfo(name)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, info)
+	}
+	return
+}
+
 // GetFunc 获取函数
 func GetFunc(name string) vFunc {
 	return FuncMap[name]
 }
 
+// IsMasterProcess 当前进程是否为主进程, 集群中只有一个进程持有主进程标识
+func IsMasterProcess(ctx g.Ctx) bool {
+	return ProcessFlag == CacheManager.MustGetOrSet(ctx, "v:masterflag", ProcessFlag, 60*time.Second).String()
+}
+
 // RunFunc 运行函数
 func RunFunc(ctx g.Ctx, funcstring string) (err error) {
-	funcName := gstr.SubStr(funcstring, 0, gstr.Pos(funcstring, "("))
-	funcParam := gstr.SubStr(funcstring, gstr.Pos(funcstring, "(")+1, gstr.Pos(funcstring, ")")-gstr.Pos(funcstring, "(")-1)
+	call, err := ParseFuncCall(funcstring)
+	if err != nil {
+		return
+	}
+	funcName := call.Name
 	if _, ok := FuncMap[funcName]; !ok {
 		err = gerror.New("函数不存在:" + funcName)
 		return
 	}
 	if !FuncMap[funcName].IsAllWorker() {
 		// 检查当前是否为主进程, 如果不是主进程, 则不执行
-		if ProcessFlag != CacheManager.MustGetOrSet(ctx, "v:masterflag", ProcessFlag, 60*time.Second).String() {
+		if !IsMasterProcess(ctx) {
 			g.Log().Debug(ctx, "当前进程不是主进程, 不执行单例函数", funcName)
 			return
 		}
 	}
-	err = FuncMap[funcName].Func(ctx, funcParam)
+	err = FuncMap[funcName].Func(ctx, call.Param())
 	return
 }
 
-// ClusterRunFunc 集群运行函数,如果是单机模式, 则直接运行函数
+// FuncTopic 集群函数在消息总线上的主题
+const FuncTopic = "v:func"
+
+// ClusterRunFunc 集群运行函数, 广播到所有节点执行, 如果是单机模式, 则直接运行函数
 func ClusterRunFunc(ctx g.Ctx, funcstring string) (err error) {
-	if IsRedisMode {
-		conn, err := g.Redis("v").Conn(ctx)
-		if err != nil {
-			return err
-		}
-		defer conn.Close(ctx)
-		_, err = conn.Do(ctx, "publish", "v:func", funcstring)
-		return err
-	} else {
+	if Bus == nil {
 		return RunFunc(ctx, funcstring)
 	}
+	return Bus.Broadcast(ctx, FuncTopic, funcstring)
 }
 
-// ListenFunc 监听函数
-func ListenFunc(ctx g.Ctx) {
-	if IsRedisMode {
-		conn, err := g.Redis("v").Conn(ctx)
-		if err != nil {
-			panic(err)
-		}
-		defer conn.Close(ctx)
-		_, err = conn.Do(ctx, "subscribe", "v:func")
-		if err != nil {
-			panic(err)
-		}
-		for {
-			data, err := conn.Receive(ctx)
-			if err != nil {
-				g.Log().Error(ctx, err)
-				time.Sleep(10 * time.Second)
-				continue
-			}
-			if data != nil {
-				dataMap := data.MapStrStr()
-				if dataMap["Kind"] == "subscribe" {
-					continue
-				}
-				if dataMap["Channel"] == "v:func" {
-					g.Log().Debug(ctx, "执行函数", dataMap["Payload"])
-					err := RunFunc(ctx, dataMap["Payload"])
-					if err != nil {
-						g.Log().Error(ctx, "执行函数失败", err)
-					}
-				}
-			}
+// ClusterSendFunc 发送到指定节点运行函数, node 为空时随机选择一个节点, 如果是单机模式, 则直接运行函数
+func ClusterSendFunc(ctx g.Ctx, node string, funcstring string) (err error) {
+	if Bus == nil {
+		return RunFunc(ctx, funcstring)
+	}
+	return Bus.Send(ctx, node, FuncTopic, funcstring)
+}
+
+// ClusterCallFunc 在所有节点运行函数并等待各节点返回执行结果
+// 超时时返回已收到的结果和错误, 如果是单机模式, 则直接运行函数
+func ClusterCallFunc(ctx g.Ctx, funcstring string, timeout time.Duration) (replies []*vbus.Reply, err error) {
+	if Bus == nil {
+		reply := &vbus.Reply{Node: ProcessFlag}
+		if err := RunFunc(ctx, funcstring); err != nil {
+			reply.Error = err.Error()
 		}
-	} else {
-		panic(gerror.New("集群模式下, 请使用Redis作为缓存"))
+		return []*vbus.Reply{reply}, nil
+	}
+	return Bus.Request(ctx, "", FuncTopic, funcstring, timeout)
+}
+
+// ListenFunc 监听集群函数, 阻塞直到ctx结束, 单机模式下直接返回
+func ListenFunc(ctx g.Ctx) {
+	if Bus == nil {
+		g.Log().Debug(ctx, "单机模式, 无需监听集群函数")
+		return
+	}
+	Bus.Handle(FuncTopic, func(ctx g.Ctx, msg *vbus.Message) (string, error) {
+		g.Log().Debug(ctx, "执行函数", msg.Payload)
+		return "", RunFunc(ctx, msg.Payload)
+	})
+	if err := Bus.Listen(ctx); err != nil {
+		g.Log().Error(ctx, "监听集群函数失败", err)
 	}
 }
@@ -0,0 +1,125 @@
+package v
+
+import (
+	"math"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// ValidateSchema 按JSON Schema的常用子集校验数据
+// 支持的关键字: type properties required additionalProperties items enum minimum maximum minLength maxLength minItems maxItems
+// data 应为 encoding/json 解码得到的值(map[string]interface{}、[]interface{}、float64、string、bool、nil)
+func ValidateSchema(schema g.Map, data interface{}) error {
+	return validateSchema(schema, data, "params")
+}
+
+func validateSchema(schema g.Map, data interface{}, path string) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	if t, ok := schema["type"]; ok {
+		types := gconv.Strings(t)
+		matched := false
+		for _, typ := range types {
+			if schemaTypeMatch(typ, data) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return gerror.Newf("%s 类型应为 %v", path, types)
+		}
+	}
+	if enum, ok := schema["enum"]; ok {
+		found := false
+		for _, item := range gconv.Interfaces(enum) {
+			if gconv.String(item) == gconv.String(data) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return gerror.Newf("%s 的值必须是 %v 之一", path, enum)
+		}
+	}
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for _, key := range gconv.Strings(schema["required"]) {
+			if _, ok := value[key]; !ok {
+				return gerror.Newf("%s.%s 不能为空", path, key)
+			}
+		}
+		properties := gconv.Map(schema["properties"])
+		for key, item := range value {
+			sub, ok := properties[key]
+			if !ok {
+				if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+					return gerror.Newf("%s.%s 是未声明的参数", path, key)
+				}
+				continue
+			}
+			if err := validateSchema(gconv.Map(sub), item, path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if min, ok := schema["minItems"]; ok && len(value) < gconv.Int(min) {
+			return gerror.Newf("%s 至少包含 %d 项", path, gconv.Int(min))
+		}
+		if max, ok := schema["maxItems"]; ok && len(value) > gconv.Int(max) {
+			return gerror.Newf("%s 最多包含 %d 项", path, gconv.Int(max))
+		}
+		if items, ok := schema["items"]; ok {
+			for i, item := range value {
+				if err := validateSchema(gconv.Map(items), item, path+"["+gconv.String(i)+"]"); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := len([]rune(value))
+		if min, ok := schema["minLength"]; ok && length < gconv.Int(min) {
+			return gerror.Newf("%s 长度不能小于 %d", path, gconv.Int(min))
+		}
+		if max, ok := schema["maxLength"]; ok && length > gconv.Int(max) {
+			return gerror.Newf("%s 长度不能大于 %d", path, gconv.Int(max))
+		}
+	case float64:
+		if min, ok := schema["minimum"]; ok && value < gconv.Float64(min) {
+			return gerror.Newf("%s 不能小于 %v", path, min)
+		}
+		if max, ok := schema["maximum"]; ok && value > gconv.Float64(max) {
+			return gerror.Newf("%s 不能大于 %v", path, max)
+		}
+	}
+	return nil
+}
+
+// schemaTypeMatch 判断数据是否符合JSON Schema中的type
+func schemaTypeMatch(typ string, data interface{}) bool {
+	switch typ {
+	case "object":
+		_, ok := data.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := data.([]interface{})
+		return ok
+	case "string":
+		_, ok := data.(string)
+		return ok
+	case "number":
+		_, ok := data.(float64)
+		return ok
+	case "integer":
+		f, ok := data.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := data.(bool)
+		return ok
+	case "null":
+		return data == nil
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package v
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+type testSchemaFunc struct{}
+
+func (f *testSchemaFunc) Func(ctx g.Ctx, param string) error { return nil }
+func (f *testSchemaFunc) IsSingleton() bool                  { return false }
+func (f *testSchemaFunc) IsAllWorker() bool                  { return true }
+func (f *testSchemaFunc) Description() string                { return "测试函数" }
+func (f *testSchemaFunc) ParamSchema() string {
+	return `{"type":"object","required":["id"],"properties":{"id":{"type":"integer","minimum":1},"mode":{"enum":["a","b"]}}}`
+}
+
+// TestParseFuncCall 测试函数调用字符串解析
+func TestParseFuncCall(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		// 兼容旧格式
+		call, err := ParseFuncCall("TaskTest(abc)")
+		t.AssertNil(err)
+		t.Assert(call.Name, "TaskTest")
+		t.Assert(call.Param(), "abc")
+
+		call, err = ParseFuncCall("BaseFuncClearLog(false)")
+		t.AssertNil(err)
+		t.Assert(call.Param(), "false")
+
+		// 旧格式参数保留引号, 取到第一个")"为止
+		call, err = ParseFuncCall(`TaskTest("abc")`)
+		t.AssertNil(err)
+		t.Assert(call.Param(), `"abc"`)
+
+		call, err = ParseFuncCall("TaskTest(a(b)c)")
+		t.AssertNil(err)
+		t.Assert(call.Param(), "a(b")
+
+		call, err = ParseFuncCall("TaskTest(1) ")
+		t.AssertNil(err)
+		t.Assert(call.Param(), "1")
+
+		call, err = ParseFuncCall("TaskTest()")
+		t.AssertNil(err)
+		t.Assert(call.Param(), "")
+
+		_, err = ParseFuncCall("TaskTest(abc")
+		t.AssertNE(err, nil)
+
+		// 旧格式中的JSON对象参数
+		call, err = ParseFuncCall(`TaskTest({"text":"a)b"})`)
+		t.AssertNil(err)
+		t.Assert(call.Param(), `{"text":"a)b"}`)
+
+		call, err = ParseFuncCall("TaskTest")
+		t.AssertNil(err)
+		t.Assert(call.Name, "TaskTest")
+		t.Assert(call.Param(), "")
+
+		// 结构化格式
+		call, err = ParseFuncCall(`{"name":"TaskTest","params":{"id":1,"text":"a,b(c)"}}`)
+		t.AssertNil(err)
+		t.Assert(call.Name, "TaskTest")
+		t.Assert(call.Param(), `{"id":1,"text":"a,b(c)"}`)
+
+		call, err = ParseFuncCall(`{"name":"TaskTest","params":"abc"}`)
+		t.AssertNil(err)
+		t.Assert(call.Param(), "abc")
+
+		_, err = ParseFuncCall(`{"params":{}}`)
+		t.AssertNE(err, nil)
+
+		call, err = NewFuncCall("TaskTest", g.Map{"id": 1})
+		t.AssertNil(err)
+		t.Assert(call.String(), `{"name":"TaskTest","params":{"id":1}}`)
+	})
+}
+
+// TestValidateFuncCall 测试函数参数Schema校验
+func TestValidateFuncCall(t *testing.T) {
+	RegisterFunc("TestSchemaFunc", &testSchemaFunc{})
+	defer delete(FuncMap, "TestSchemaFunc")
+
+	gtest.C(t, func(t *gtest.T) {
+		_, err := ValidateFuncCall(`{"name":"TestSchemaFunc","params":{"id":1,"mode":"a"}}`)
+		t.AssertNil(err)
+
+		_, err = ValidateFuncCall(`TestSchemaFunc({"id":2})`)
+		t.AssertNil(err)
+
+		_, err = ValidateFuncCall(`{"name":"TestSchemaFunc","params":{"mode":"a"}}`)
+		t.AssertNE(err, nil)
+
+		_, err = ValidateFuncCall(`{"name":"TestSchemaFunc","params":{"id":0}}`)
+		t.AssertNE(err, nil)
+
+		_, err = ValidateFuncCall(`{"name":"TestSchemaFunc","params":{"id":1.5}}`)
+		t.AssertNE(err, nil)
+
+		_, err = ValidateFuncCall(`{"name":"TestSchemaFunc","params":{"id":1,"mode":"c"}}`)
+		t.AssertNE(err, nil)
+
+		_, err = ValidateFuncCall(`TestSchemaFunc(abc)`)
+		t.AssertNE(err, nil)
+
+		_, err = ValidateFuncCall(`NotExistFunc(abc)`)
+		t.AssertNE(err, nil)
+	})
+}
+
+// TestListFuncs 测试获取已注册函数信息
+func TestListFuncs(t *testing.T) {
+	RegisterFunc("TestSchemaFunc", &testSchemaFunc{})
+	defer delete(FuncMap, "TestSchemaFunc")
+
+	gtest.C(t, func(t *gtest.T) {
+		info, err := GetFuncInfo("TestSchemaFunc")
+		t.AssertNil(err)
+		t.Assert(info.Description, "测试函数")
+		t.Assert(info.IsSingleton, false)
+		t.Assert(info.IsAllWorker, true)
+		t.Assert(info.ParamSchema["required"], g.Slice{"id"})
+
+		list, err := ListFuncs()
+		t.AssertNil(err)
+		t.AssertGE(len(list), 1)
+
+		_, err = GetFuncInfo("NotExistFunc")
+		t.AssertNE(err, nil)
+	})
+}
@@ -7,6 +7,7 @@ toolchain go1.24.1
 require (
 	github.com/gogf/gf/v2 v2.9.3
 	github.com/golang-jwt/jwt/v5 v5.3.0
+	golang.org/x/image v0.25.0
 	gorm.io/gorm v1.31.0
 )
 
@@ -0,0 +1,369 @@
+package v
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// maxPartCount 分片数量上限, 与S3的限制一致
+const maxPartCount = 10000
+
+// MultipartUpload 分片上传会话, 保存在缓存中, 集群中需要使用redis缓存
+type MultipartUpload struct {
+	UploadId    string        `json:"uploadId"`    // 上传ID
+	Key         string        `json:"key"`         // 文件键
+	FileName    string        `json:"fileName"`    // 原文件名
+	ContentType string        `json:"contentType"` // 文件类型
+	Size        int64         `json:"size"`        // 文件大小
+	PartSize    int64         `json:"partSize"`    // 分片大小, 最后一个分片可以更小
+	PartCount   int           `json:"partCount"`   // 分片数量
+	Hash        string        `json:"hash"`        // 文件内容的SHA-256, 为空时完成时不校验
+	UserId      uint          `json:"userId"`      // 上传人
+	Driver      string        `json:"driver"`      // 文件驱动或命名存储配置
+	Scene       string        `json:"scene"`       // 上传场景
+	Parts       []*vfile.Part `json:"parts"`       // 已上传的分片, 用于断点续传
+}
+
+// InitMultipart 开始分片上传
+// 传入文件的SHA-256时, 同一用户上传相同的未完成文件会返回已有的上传会话, 只需上传缺少的分片
+// fileName: 原文件名, 用于保留扩展名
+// contentType: 文件类型, 为空时按扩展名确定, 浏览器会执行的类型需要上传场景显式允许
+// size: 文件大小
+// hash: 文件内容的SHA-256, 可以为空
+func InitMultipart(ctx g.Ctx, fileName, contentType string, size int64, hash string) (*MultipartUpload, error) {
+	rule, err := uploadRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := vfile.UseOrFallback(rule.Profile)
+	if err != nil {
+		return nil, err
+	}
+	multipart, err := vfile.AsMultipart(driver)
+	if err != nil {
+		return nil, err
+	}
+	config := ConfigBinding.Get().File.Multipart
+	if size <= 0 {
+		return nil, gerror.New("文件大小不能为空")
+	}
+	if config.MaxSize > 0 && size > config.MaxSize {
+		return nil, gerror.Newf("文件大小不能超过%d字节", config.MaxSize)
+	}
+	// 分片上传在合并前无法读取文件内容, 按上传场景校验文件名
+	if err = rule.CheckName(fileName); err != nil {
+		return nil, err
+	}
+	if contentType == "" {
+		contentType = vfile.SafeContentType(fileName)
+	}
+	if err = rule.CheckContentType(contentType); err != nil {
+		return nil, err
+	}
+	if err = vfile.CheckQuota(ctx, size); err != nil {
+		return nil, err
+	}
+	userId := uploaderId(ctx)
+	hash = strings.ToLower(hash)
+	if hash != "" {
+		uploadId, _ := CacheManager.Get(ctx, multipartHashCacheKey(userId, hash, size))
+		if !uploadId.IsEmpty() {
+			if upload, err := GetMultipart(ctx, uploadId.String()); err == nil {
+				return upload, nil
+			}
+		}
+	}
+
+	partSize := config.PartSize
+	if partSize < vfile.MinPartSize {
+		partSize = vfile.MinPartSize
+	}
+	if (size+partSize-1)/partSize > maxPartCount {
+		partSize = (size + maxPartCount - 1) / maxPartCount
+	}
+	key := vfile.NewKey(fileName)
+	uploadId, err := multipart.InitMultipart(ctx, key, contentType)
+	if err != nil {
+		return nil, err
+	}
+	upload := &MultipartUpload{
+		UploadId:    uploadId,
+		Key:         key,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		PartSize:    partSize,
+		PartCount:   int((size + partSize - 1) / partSize),
+		Hash:        hash,
+		UserId:      userId,
+		Driver:      vfile.DriverName(driver),
+		Scene:       rule.Scene,
+		Parts:       []*vfile.Part{},
+	}
+	expire := multipartExpire()
+	if err = CacheManager.Set(ctx, multipartCacheKey(uploadId), upload, expire); err != nil {
+		return nil, err
+	}
+	if hash != "" {
+		if err = CacheManager.Set(ctx, multipartHashCacheKey(userId, hash, size), uploadId, expire); err != nil {
+			return nil, err
+		}
+	}
+	return upload, nil
+}
+
+// GetMultipart 获取分片上传会话和已上传的分片
+func GetMultipart(ctx g.Ctx, uploadId string) (*MultipartUpload, error) {
+	cached, err := CacheManager.Get(ctx, multipartCacheKey(uploadId))
+	if err != nil {
+		return nil, err
+	}
+	if cached.IsNil() {
+		return nil, gerror.New("上传不存在或已过期")
+	}
+	upload := &MultipartUpload{}
+	if err = cached.Scan(upload); err != nil {
+		return nil, err
+	}
+	if upload.UserId != uploaderId(ctx) {
+		return nil, gerror.New("上传不存在或已过期")
+	}
+	upload.Parts = []*vfile.Part{}
+	for number := 1; number <= upload.PartCount; number++ {
+		cachedPart, err := CacheManager.Get(ctx, multipartPartCacheKey(uploadId, number))
+		if err != nil {
+			return nil, err
+		}
+		if cachedPart.IsNil() {
+			continue
+		}
+		part := &vfile.Part{}
+		if err = cachedPart.Scan(part); err != nil {
+			return nil, err
+		}
+		upload.Parts = append(upload.Parts, part)
+	}
+	return upload, nil
+}
+
+// UploadMultipartPart 上传分片, 同一分片可以重复上传
+// number: 分片序号, 从1开始
+// size: 分片大小, 除最后一个分片外必须等于会话的分片大小
+// hash: 分片内容的SHA-256, 不为空时校验
+func UploadMultipartPart(ctx g.Ctx, uploadId string, number int, reader io.Reader, size int64, hash string) (*vfile.Part, error) {
+	upload, err := getMultipartSession(ctx, uploadId)
+	if err != nil {
+		return nil, err
+	}
+	if number < 1 || number > upload.PartCount {
+		return nil, gerror.Newf("分片序号应在1到%d之间", upload.PartCount)
+	}
+	expected := upload.PartSize
+	if number == upload.PartCount {
+		expected = upload.Size - upload.PartSize*int64(upload.PartCount-1)
+	}
+	if size != expected {
+		return nil, gerror.Newf("分片%d的大小应为%d字节", number, expected)
+	}
+	driver, err := vfile.Use(upload.Driver)
+	if err != nil {
+		return nil, err
+	}
+	multipart, err := vfile.AsMultipart(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	etag, err := multipart.UploadPart(ctx, upload.Key, uploadId, number, io.TeeReader(reader, hasher), size)
+	if err != nil {
+		return nil, err
+	}
+	part := &vfile.Part{
+		Number: number,
+		ETag:   etag,
+		Size:   size,
+		Hash:   hex.EncodeToString(hasher.Sum(nil)),
+	}
+	// 校验失败的分片不记录, 重新上传后覆盖
+	if hash != "" && !strings.EqualFold(hash, part.Hash) {
+		return nil, gerror.Newf("分片%d校验失败", number)
+	}
+	if err = CacheManager.Set(ctx, multipartPartCacheKey(uploadId, number), part, multipartExpire()); err != nil {
+		return nil, err
+	}
+	return part, nil
+}
+
+// CompleteMultipart 合并全部分片, 返回文件信息和访问地址
+// 会话中有文件的SHA-256时读取合并后的文件校验, 校验失败时删除文件
+func CompleteMultipart(ctx g.Ctx, uploadId string) (*vfile.ObjectInfo, string, error) {
+	upload, err := GetMultipart(ctx, uploadId)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(upload.Parts) != upload.PartCount {
+		uploaded := make(map[int]bool, len(upload.Parts))
+		for _, part := range upload.Parts {
+			uploaded[part.Number] = true
+		}
+		var missing []string
+		for number := 1; number <= upload.PartCount && len(missing) < 10; number++ {
+			if !uploaded[number] {
+				missing = append(missing, strconv.Itoa(number))
+			}
+		}
+		return nil, "", gerror.Newf("分片未上传完成, 缺少分片: %s", strings.Join(missing, ","))
+	}
+	driver, err := vfile.Use(upload.Driver)
+	if err != nil {
+		return nil, "", err
+	}
+	multipart, err := vfile.AsMultipart(driver)
+	if err != nil {
+		return nil, "", err
+	}
+	if err = multipart.CompleteMultipart(ctx, upload.Key, uploadId, upload.Parts); err != nil {
+		return nil, "", err
+	}
+	clearMultipart(ctx, upload)
+
+	if upload.Hash != "" {
+		if err = verifyFileHash(ctx, driver, upload.Key, upload.Hash); err != nil {
+			if deleteErr := vfile.Delete(ctx, driver, upload.Key); deleteErr != nil {
+				g.Log().Warningf(ctx, "删除校验失败的文件 %s 失败: %v", upload.Key, deleteErr)
+			}
+			return nil, "", err
+		}
+	}
+	info, err := vfile.Stat(ctx, driver, upload.Key)
+	if err != nil {
+		return nil, "", err
+	}
+	// 存储中的类型与开始上传时声明的可能不同, 合并后按上传场景重新校验并扫描
+	if err = checkMultipartObject(ctx, driver, upload, info); err != nil {
+		if deleteErr := vfile.Delete(ctx, driver, upload.Key); deleteErr != nil {
+			g.Log().Warningf(ctx, "删除不符合限制的文件 %s 失败: %v", upload.Key, deleteErr)
+		}
+		return nil, "", err
+	}
+	url, err := vfile.URL(driver, upload.Key)
+	if err != nil {
+		return nil, "", err
+	}
+	return trackUpload(ctx, driver, info, url, upload.FileName, upload.Hash)
+}
+
+// checkMultipartObject 按上传场景校验合并后文件的类型并调用病毒扫描器
+func checkMultipartObject(ctx g.Ctx, driver vfile.Driver, upload *MultipartUpload, info *vfile.ObjectInfo) error {
+	rule, err := vfile.RuleOf(upload.Scene)
+	if err != nil {
+		return err
+	}
+	if err = rule.CheckContentType(info.ContentType); err != nil {
+		return err
+	}
+	return vfile.ScanObject(ctx, driver, &vfile.Upload{
+		Key:         upload.Key,
+		FileName:    upload.FileName,
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		Scene:       rule.Scene,
+	})
+}
+
+// AbortMultipart 取消分片上传并删除已上传的分片
+func AbortMultipart(ctx g.Ctx, uploadId string) error {
+	upload, err := GetMultipart(ctx, uploadId)
+	if err != nil {
+		return err
+	}
+	driver, err := vfile.Use(upload.Driver)
+	if err != nil {
+		return err
+	}
+	multipart, err := vfile.AsMultipart(driver)
+	if err != nil {
+		return err
+	}
+	if err = multipart.AbortMultipart(ctx, upload.Key, uploadId); err != nil {
+		return err
+	}
+	clearMultipart(ctx, upload)
+	return nil
+}
+
+// getMultipartSession 获取分片上传会话, 不读取已上传的分片
+func getMultipartSession(ctx g.Ctx, uploadId string) (*MultipartUpload, error) {
+	cached, err := CacheManager.Get(ctx, multipartCacheKey(uploadId))
+	if err != nil {
+		return nil, err
+	}
+	upload := &MultipartUpload{}
+	if cached.IsNil() || cached.Scan(upload) != nil || upload.UserId != uploaderId(ctx) {
+		return nil, gerror.New("上传不存在或已过期")
+	}
+	return upload, nil
+}
+
+// verifyFileHash 读取文件校验SHA-256, 驱动不支持读取时不校验
+func verifyFileHash(ctx g.Ctx, driver vfile.Driver, key, hash string) error {
+	hasher := sha256.New()
+	_, err := vfile.Download(ctx, driver, key, hasher)
+	if gerror.Is(err, vfile.ErrUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(hex.EncodeToString(hasher.Sum(nil)), hash) {
+		return gerror.New("文件校验失败")
+	}
+	return nil
+}
+
+// clearMultipart 删除分片上传会话的缓存
+func clearMultipart(ctx g.Ctx, upload *MultipartUpload) {
+	keys := []interface{}{multipartCacheKey(upload.UploadId)}
+	if upload.Hash != "" {
+		keys = append(keys, multipartHashCacheKey(upload.UserId, upload.Hash, upload.Size))
+	}
+	for number := 1; number <= upload.PartCount; number++ {
+		keys = append(keys, multipartPartCacheKey(upload.UploadId, number))
+	}
+	if _, err := CacheManager.Remove(ctx, keys...); err != nil {
+		g.Log().Warningf(ctx, "删除分片上传缓存失败: %v", err)
+	}
+}
+
+// multipartExpire 未完成的分片上传保留时间
+func multipartExpire() time.Duration {
+	expire, err := time.ParseDuration(ConfigBinding.Get().File.Multipart.Expire)
+	if err != nil || expire <= 0 {
+		return 24 * time.Hour
+	}
+	return expire
+}
+
+// multipartCacheKey 分片上传会话的缓存键
+func multipartCacheKey(uploadId string) string {
+	return "v:file:multipart:" + uploadId
+}
+
+// multipartPartCacheKey 已上传分片的缓存键, 每个分片单独保存, 支持并发上传分片
+func multipartPartCacheKey(uploadId string, number int) string {
+	return "v:file:multipart:" + uploadId + ":" + strconv.Itoa(number)
+}
+
+// multipartHashCacheKey 文件内容到上传ID的缓存键, 用于断点续传
+func multipartHashCacheKey(userId uint, hash string, size int64) string {
+	return "v:file:multipart:hash:" + strconv.FormatUint(uint64(userId), 10) + ":" + hash + ":" + strconv.FormatInt(size, 10)
+}
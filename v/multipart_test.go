@@ -0,0 +1,189 @@
+package v
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/v/vfile"
+)
+
+// testMultipartDriver 支持分片上传的内存文件驱动
+type testMultipartDriver struct {
+	testPresignDriver
+	contents map[string][]byte
+	parts    map[string]map[int][]byte
+}
+
+func (d *testMultipartDriver) New() vfile.Driver { return d }
+
+func (d *testMultipartDriver) InitMultipart(ctx g.Ctx, key, contentType string) (string, error) {
+	uploadId := "upload-" + strconv.Itoa(len(d.parts)+1)
+	d.parts[uploadId] = map[int][]byte{}
+	return uploadId, nil
+}
+
+func (d *testMultipartDriver) UploadPart(ctx g.Ctx, key, uploadId string, number int, reader io.Reader, size int64) (string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	d.parts[uploadId][number] = data
+	return "etag-" + strconv.Itoa(number), nil
+}
+
+func (d *testMultipartDriver) CompleteMultipart(ctx g.Ctx, key, uploadId string, parts []*vfile.Part) error {
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+	var content []byte
+	for _, part := range parts {
+		content = append(content, d.parts[uploadId][part.Number]...)
+	}
+	delete(d.parts, uploadId)
+	d.contents[key] = content
+	d.files[key] = &vfile.ObjectInfo{Key: key, Size: int64(len(content))}
+	return nil
+}
+
+func (d *testMultipartDriver) AbortMultipart(ctx g.Ctx, key, uploadId string) error {
+	delete(d.parts, uploadId)
+	return nil
+}
+
+func (d *testMultipartDriver) Open(ctx g.Ctx, key string) (io.ReadCloser, *vfile.ObjectInfo, error) {
+	info, err := d.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return io.NopCloser(bytes.NewReader(d.contents[key])), info, nil
+}
+
+func (d *testMultipartDriver) Delete(ctx g.Ctx, key string) error {
+	delete(d.contents, key)
+	return d.testPresignDriver.Delete(ctx, key)
+}
+
+// testScanner 内容包含 EICAR 时报告病毒的扫描器
+type testScanner struct{}
+
+func (s *testScanner) Scan(ctx g.Ctx, reader io.Reader, upload *vfile.Upload) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(data, []byte("EICAR")) {
+		return gerror.New("发现病毒")
+	}
+	return nil
+}
+
+// TestMultipart 测试分片上传、断点续传与校验
+func TestMultipart(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		driver := &testMultipartDriver{
+			testPresignDriver: testPresignDriver{files: map[string]*vfile.ObjectInfo{}},
+			contents:          map[string][]byte{},
+			parts:             map[string]map[int][]byte{},
+		}
+		mode := ConfigBinding.Get().File.Mode
+		original := vfile.FileMap[mode]
+		vfile.Register(mode, driver)
+		defer vfile.Register(mode, original)
+
+		content := bytes.Repeat([]byte("0123456789"), vfile.MinPartSize/10*2+1)
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:])
+
+		upload, err := InitMultipart(ctx, "video.mp4", "video/mp4", int64(len(content)), hash)
+		t.AssertNil(err)
+		t.Assert(upload.PartCount, 3)
+		t.Assert(upload.PartSize, vfile.MinPartSize)
+		part := func(number int) []byte {
+			start := int64(number-1) * upload.PartSize
+			end := start + upload.PartSize
+			if end > int64(len(content)) {
+				end = int64(len(content))
+			}
+			return content[start:end]
+		}
+
+		// 分片大小、序号与校验值错误
+		_, err = UploadMultipartPart(ctx, upload.UploadId, 1, bytes.NewReader(part(3)), int64(len(part(3))), "")
+		t.AssertNE(err, nil)
+		_, err = UploadMultipartPart(ctx, upload.UploadId, 4, bytes.NewReader(part(3)), int64(len(part(3))), "")
+		t.AssertNE(err, nil)
+		_, err = UploadMultipartPart(ctx, upload.UploadId, 3, bytes.NewReader(part(3)), int64(len(part(3))), "bad")
+		t.AssertNE(err, nil)
+
+		_, err = UploadMultipartPart(ctx, upload.UploadId, 3, bytes.NewReader(part(3)), int64(len(part(3))), "")
+		t.AssertNil(err)
+		_, _, err = CompleteMultipart(ctx, upload.UploadId)
+		t.AssertNE(err, nil)
+
+		// 中断后使用相同的文件重新开始, 返回已上传的分片
+		resumed, err := InitMultipart(ctx, "video.mp4", "video/mp4", int64(len(content)), hash)
+		t.AssertNil(err)
+		t.Assert(resumed.UploadId, upload.UploadId)
+		t.Assert(len(resumed.Parts), 1)
+		t.Assert(resumed.Parts[0].Number, 3)
+
+		for _, number := range []int{2, 1} {
+			partSum := sha256.Sum256(part(number))
+			_, err = UploadMultipartPart(ctx, upload.UploadId, number, bytes.NewReader(part(number)), int64(len(part(number))), hex.EncodeToString(partSum[:]))
+			t.AssertNil(err)
+		}
+		info, url, err := CompleteMultipart(ctx, upload.UploadId)
+		t.AssertNil(err)
+		t.Assert(info.Size, len(content))
+		t.Assert(url, "memory://"+upload.Key)
+		t.Assert(bytes.Equal(driver.contents[upload.Key], content), true)
+		_, err = GetMultipart(ctx, upload.UploadId)
+		t.AssertNE(err, nil)
+
+		// 文件校验失败时删除文件
+		upload, err = InitMultipart(ctx, "a.zip", "", 3, hex.EncodeToString(make([]byte, 32)))
+		t.AssertNil(err)
+		_, err = UploadMultipartPart(ctx, upload.UploadId, 1, bytes.NewReader([]byte("abc")), 3, "")
+		t.AssertNil(err)
+		_, _, err = CompleteMultipart(ctx, upload.UploadId)
+		t.AssertNE(err, nil)
+		_, exists := driver.contents[upload.Key]
+		t.Assert(exists, false)
+
+		// 浏览器会执行的类型需要上传场景显式允许
+		_, err = InitMultipart(ctx, "a.txt", "text/html", 3, "")
+		t.AssertNE(err, nil)
+		upload, err = InitMultipart(ctx, "a.txt", "", 3, "")
+		t.AssertNil(err)
+		t.Assert(upload.ContentType, "text/plain; charset=utf-8")
+		t.AssertNil(AbortMultipart(ctx, upload.UploadId))
+
+		// 合并后的文件未通过病毒扫描时删除文件
+		vfile.RegisterScanner(&testScanner{})
+		upload, err = InitMultipart(ctx, "virus.zip", "", 5, "")
+		t.AssertNil(err)
+		_, err = UploadMultipartPart(ctx, upload.UploadId, 1, bytes.NewReader([]byte("EICAR")), 5, "")
+		t.AssertNil(err)
+		_, _, err = CompleteMultipart(ctx, upload.UploadId)
+		vfile.RegisterScanner(nil)
+		t.AssertNE(err, nil)
+		_, exists = driver.contents[upload.Key]
+		t.Assert(exists, false)
+
+		// 取消上传
+		upload, err = InitMultipart(ctx, "b.zip", "", 3, "")
+		t.AssertNil(err)
+		t.AssertNil(AbortMultipart(ctx, upload.UploadId))
+		_, exists = driver.parts[upload.UploadId]
+		t.Assert(exists, false)
+		t.AssertNE(AbortMultipart(ctx, upload.UploadId), nil)
+	})
+}
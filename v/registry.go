@@ -0,0 +1,120 @@
+package v
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/net/gipv4"
+	"github.com/vera-byte/vgo/v/vconfig"
+	"github.com/vera-byte/vgo/v/vregistry"
+)
+
+var registeredService *vregistry.Service // 已注册的服务实例
+
+// initRegistry 初始化服务注册驱动
+// 未配置 v.registry.mode 时不注册服务; 配置了HTTP健康检查时绑定检查路由
+func initRegistry(ctx g.Ctx) {
+	config := vconfig.Config.Registry
+	if config == nil || config.Mode == "" || config.Mode == "none" {
+		return
+	}
+	if config.Mode == "consul" {
+		if _, ok := vregistry.DriverMap["consul"]; !ok {
+			vregistry.Register("consul", vregistry.NewConsulDriver(&vregistry.ConsulConfig{
+				Address: config.Consul.Address,
+				Token:   config.Consul.Token,
+			}))
+		}
+	}
+	driver, err := vregistry.GetDriver(config.Mode)
+	if err != nil {
+		panic(err)
+	}
+	Registry = driver
+	if check := config.Service.Check; check != nil && check.Path != "" {
+		g.Server().BindHandler("GET:"+check.Path, func(r *ghttp.Request) {
+			r.Response.Write("ok")
+		})
+	}
+}
+
+// RegisterService 将HTTP服务注册到注册中心, 需要在服务启动后调用
+// 未配置服务注册时直接返回
+func RegisterService(ctx context.Context, s *ghttp.Server) error {
+	if Registry == nil {
+		return nil
+	}
+	var (
+		config  = vconfig.Config.Registry.Service
+		address = config.Address
+		port    = config.Port
+	)
+	if address == "" {
+		address, _ = gipv4.GetIntranetIp()
+	}
+	if address == "" {
+		address = "127.0.0.1"
+	}
+	if port == 0 {
+		port = s.GetListenedPort()
+	}
+	service := &vregistry.Service{
+		ID:      config.Id,
+		Name:    config.Name,
+		Address: address,
+		Port:    port,
+		Tags:    config.Tags,
+		Meta:    map[string]string{"processFlag": ProcessFlag, "runMode": RunMode},
+	}
+	for key, value := range config.Meta {
+		service.Meta[key] = value
+	}
+	if service.ID == "" {
+		service.ID = fmt.Sprintf("%s-%s-%d", service.Name, address, port)
+	}
+	if check := config.Check; check != nil {
+		service.Check = &vregistry.Check{
+			TCP:             fmt.Sprintf("%s:%d", address, port),
+			Interval:        parseRegistryDuration(check.Interval, 10*time.Second),
+			Timeout:         parseRegistryDuration(check.Timeout, 3*time.Second),
+			DeregisterAfter: parseRegistryDuration(check.DeregisterAfter, 0),
+		}
+		if check.Path != "" {
+			service.Check.HTTP = fmt.Sprintf("http://%s:%d%s", address, port, check.Path)
+		}
+	}
+	if err := Registry.Register(ctx, service); err != nil {
+		return err
+	}
+	registeredService = service
+	g.Log().Infof(ctx, "服务已注册到 %s: %s %s:%d", Registry.Name(), service.ID, address, port)
+	return nil
+}
+
+// DeregisterService 从注册中心注销已注册的服务
+func DeregisterService(ctx context.Context) error {
+	if Registry == nil || registeredService == nil {
+		return nil
+	}
+	if err := Registry.Deregister(ctx, registeredService); err != nil {
+		return err
+	}
+	g.Log().Infof(ctx, "服务已从 %s 注销: %s", Registry.Name(), registeredService.ID)
+	registeredService = nil
+	return nil
+}
+
+// parseRegistryDuration 解析时间间隔, 为空或格式错误时使用默认值
+func parseRegistryDuration(value string, defaultValue time.Duration) time.Duration {
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return duration
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/gogf/gf/v2/os/gcache"
 	"github.com/gogf/gf/v2/os/gctx"
 	"github.com/gogf/gf/v2/util/guid"
+	"github.com/vera-byte/vgo/v/vbus"
+	"github.com/vera-byte/vgo/v/vconfig"
+	"github.com/vera-byte/vgo/v/vregistry"
 	"gorm.io/gorm"
 )
 
@@ -21,6 +24,8 @@ var (
 	RunMode      = "dev"                     // 定义全局运行模式
 	IsRedisMode  = false                     // 定义全局是否为redis模式
 	I18n         = gi18n.New()               // 定义全局国际化对象
+	Bus          *vbus.Bus                   // 定义全局集群消息总线, 单机模式下为nil
+	Registry     vregistry.Driver            // 定义全局服务注册驱动, 未配置时为nil
 )
 
 func NewVgo() {
@@ -36,6 +41,10 @@ func NewVgo() {
 	if RunMode == "vgo-tools" {
 		return
 	}
+	// 校验已注册结构定义的配置节, 未知的配置项仅警告
+	if err := vconfig.ValidateSchemas(ctx); err != nil {
+		panic(err)
+	}
 	redisVar, err := g.Cfg().Get(ctx, "redis.v")
 	if err != nil {
 		g.Log().Error(ctx, "初始化缓存失败,请检查配置文件")
@@ -49,14 +58,40 @@ func NewVgo() {
 		}
 		CacheManager.SetAdapter(gcache.NewAdapterRedis(redis))
 		IsRedisMode = true
+		vbus.Register("redis", vbus.NewRedisDriver(redis))
 	}
+	initBus(ctx)
+	initRegistry(ctx)
 	g.Log().Debug(ctx, "当前运行模式", RunMode)
 	g.Log().Debug(ctx, "当前实例ID:", ProcessFlag)
 	g.Log().Debug(ctx, "是否缓存模式:", IsRedisMode)
+	if Bus != nil {
+		g.Log().Debug(ctx, "集群消息总线:", Bus.Driver().Name())
+	}
+	if Registry != nil {
+		g.Log().Debug(ctx, "服务注册:", Registry.Name())
+	}
 	g.Log().Debug(ctx, "module v init finished ...")
 
 }
 
+// initBus 初始化集群消息总线
+// 未配置 v.bus.mode 时, 配置了Redis则使用redis驱动, 否则为单机模式, 不创建总线
+func initBus(ctx g.Ctx) {
+	mode := vconfig.Config.Bus.Mode
+	if mode == "" && IsRedisMode {
+		mode = "redis"
+	}
+	if mode == "" || mode == "none" {
+		return
+	}
+	driver, err := vbus.GetDriver(mode)
+	if err != nil {
+		panic(err)
+	}
+	Bus = vbus.New(driver, vconfig.Config.Bus.Channel, ProcessFlag)
+}
+
 // v.OK 正常返回
 type BaseRes struct {
 	Code    int         `json:"code"`
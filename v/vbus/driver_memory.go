@@ -0,0 +1,70 @@
+package vbus
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryDriver 进程内消息总线驱动, 同一个驱动实例上的多个总线可以模拟多个节点, 主要用于测试和单机部署
+type MemoryDriver struct {
+	mu   sync.RWMutex
+	subs map[string]map[chan string]struct{}
+}
+
+// NewMemoryDriver 创建进程内消息总线驱动
+func NewMemoryDriver() *MemoryDriver {
+	return &MemoryDriver{
+		subs: make(map[string]map[chan string]struct{}),
+	}
+}
+
+// Name 驱动名称
+func (d *MemoryDriver) Name() string {
+	return "memory"
+}
+
+// Publish 向频道的所有订阅者投递消息
+func (d *MemoryDriver) Publish(ctx context.Context, channel string, payload string) error {
+	d.mu.RLock()
+	subs := make([]chan string, 0, len(d.subs[channel]))
+	for ch := range d.subs[channel] {
+		subs = append(subs, ch)
+	}
+	d.mu.RUnlock()
+	for _, ch := range subs {
+		select {
+		case ch <- payload:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe 订阅频道, 阻塞直到ctx结束
+func (d *MemoryDriver) Subscribe(ctx context.Context, channel string, handler func(payload string)) error {
+	ch := make(chan string, 1024)
+	d.mu.Lock()
+	if d.subs[channel] == nil {
+		d.subs[channel] = make(map[chan string]struct{})
+	}
+	d.subs[channel][ch] = struct{}{}
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.subs[channel], ch)
+		d.mu.Unlock()
+	}()
+	for {
+		select {
+		case payload := <-ch:
+			handler(payload)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func init() {
+	Register("memory", NewMemoryDriver())
+}
@@ -0,0 +1,81 @@
+package vbus
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// RedisDriver 基于Redis发布/订阅的消息总线驱动
+type RedisDriver struct {
+	redis *gredis.Redis
+}
+
+// NewRedisDriver 创建Redis消息总线驱动
+func NewRedisDriver(redis *gredis.Redis) *RedisDriver {
+	return &RedisDriver{redis: redis}
+}
+
+// Name 驱动名称
+func (d *RedisDriver) Name() string {
+	return "redis"
+}
+
+// Publish 向频道发布消息
+func (d *RedisDriver) Publish(ctx context.Context, channel string, payload string) error {
+	if d.redis == nil {
+		return gerror.New("Redis未配置")
+	}
+	_, err := d.redis.Publish(ctx, channel, payload)
+	return err
+}
+
+// Subscribe 订阅频道, 连接断开后自动重新订阅, 阻塞直到ctx结束
+func (d *RedisDriver) Subscribe(ctx context.Context, channel string, handler func(payload string)) error {
+	if d.redis == nil {
+		return gerror.New("Redis未配置")
+	}
+	for ctx.Err() == nil {
+		err := d.receive(ctx, channel, handler)
+		if ctx.Err() != nil {
+			break
+		}
+		g.Log().Error(ctx, "Redis订阅中断, 10秒后重新订阅", err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(10 * time.Second):
+		}
+	}
+	return nil
+}
+
+// receive 订阅频道并接收消息, 直到连接出错或ctx结束
+func (d *RedisDriver) receive(ctx context.Context, channel string, handler func(payload string)) error {
+	conn, _, err := d.redis.Subscribe(ctx, channel)
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// ctx结束时关闭连接, 使阻塞中的ReceiveMessage返回
+		select {
+		case <-ctx.Done():
+			conn.Close(context.Background())
+		case <-done:
+			conn.Close(context.Background())
+		}
+	}()
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return err
+		}
+		if msg != nil && msg.Channel == channel {
+			handler(msg.Payload)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+// Package vbus 集群消息总线
+// 功能: 在集群节点之间传递消息, 支持广播、发送到单个节点以及请求/应答
+// 消息的传输由可插拔的驱动完成, 内置 memory 与 redis 驱动, pgsql 驱动由 contrib/drivers/pgsql 提供
+package vbus
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/guid"
+)
+
+// Driver 消息总线驱动, 只需要提供基础的发布/订阅能力
+type Driver interface {
+	// Name 驱动名称
+	Name() string
+	// Publish 向频道发布消息
+	Publish(ctx context.Context, channel string, payload string) error
+	// Subscribe 订阅频道, 阻塞直到ctx结束
+	Subscribe(ctx context.Context, channel string, handler func(payload string)) error
+}
+
+// 消息类型
+const (
+	KindEvent   = "event"   // 事件, 不需要应答
+	KindRequest = "request" // 请求, 每个接收节点都需要应答
+	KindReply   = "reply"   // 应答
+	KindPing    = "ping"    // 节点心跳
+	KindLeave   = "leave"   // 节点下线
+)
+
+var (
+	// HeartbeatInterval 节点心跳间隔
+	HeartbeatInterval = 10 * time.Second
+	// NodeTTL 超过该时间未收到心跳的节点视为下线
+	NodeTTL = 30 * time.Second
+)
+
+var (
+	// DriverMap 已注册的消息总线驱动
+	DriverMap = map[string]Driver{}
+)
+
+// Register 注册消息总线驱动
+func Register(name string, driver Driver) error {
+	DriverMap[name] = driver
+	return nil
+}
+
+// GetDriver 获取已注册的消息总线驱动
+func GetDriver(name string) (Driver, error) {
+	if driver, ok := DriverMap[name]; ok {
+		return driver, nil
+	}
+	errorMsg := "\n"
+	errorMsg += `无法找到指定的消息总线驱动 "%s"`
+	errorMsg += `，您是否拼写错误了驱动名称 "%s" 或者忘记导入驱动支持包？`
+	return nil, gerror.Newf(errorMsg, name, name)
+}
+
+// Message 总线消息
+type Message struct {
+	Id      string `json:"id"`                // 消息ID, 应答消息与请求消息的ID相同
+	Kind    string `json:"kind"`              // 消息类型
+	Topic   string `json:"topic,omitempty"`   // 主题
+	From    string `json:"from"`              // 发送节点
+	To      string `json:"to,omitempty"`      // 目标节点, 为空时表示所有节点
+	Payload string `json:"payload,omitempty"` // 消息内容
+	Error   string `json:"error,omitempty"`   // 应答的错误信息
+}
+
+// Reply 节点应答
+type Reply struct {
+	Node   string `json:"node"`            // 应答节点
+	Result string `json:"result"`          // 处理结果
+	Error  string `json:"error,omitempty"` // 错误信息
+}
+
+// Err 返回应答中的错误
+func (r *Reply) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return gerror.New(r.Error)
+}
+
+// Handler 主题处理函数, 返回值仅在请求消息中作为应答返回
+type Handler func(ctx context.Context, msg *Message) (result string, err error)
+
+// Bus 集群消息总线
+type Bus struct {
+	driver   Driver
+	channel  string
+	nodeId   string
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	nodes    map[string]time.Time
+	pending  map[string]chan *Reply
+}
+
+// New 创建消息总线, 所有节点需要使用相同的驱动和频道
+func New(driver Driver, channel string, nodeId string) *Bus {
+	return &Bus{
+		driver:   driver,
+		channel:  channel,
+		nodeId:   nodeId,
+		handlers: make(map[string]Handler),
+		nodes:    map[string]time.Time{nodeId: time.Now()},
+		pending:  make(map[string]chan *Reply),
+	}
+}
+
+// NodeId 当前节点ID
+func (b *Bus) NodeId() string {
+	return b.nodeId
+}
+
+// Driver 当前使用的驱动
+func (b *Bus) Driver() Driver {
+	return b.driver
+}
+
+// Handle 注册主题处理函数
+func (b *Bus) Handle(topic string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[topic] = handler
+}
+
+// Listen 监听总线消息并定时发送心跳, 阻塞直到ctx结束
+func (b *Bus) Listen(ctx context.Context) error {
+	go b.heartbeat(ctx)
+	err := b.driver.Subscribe(ctx, b.channel, func(payload string) {
+		b.dispatch(ctx, payload)
+	})
+	// ctx已结束, 使用新的上下文通知其他节点
+	_ = b.publish(context.Background(), &Message{Kind: KindLeave})
+	return err
+}
+
+// Nodes 当前存活的节点列表, 包含当前节点
+func (b *Bus) Nodes() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	nodes := make([]string, 0, len(b.nodes))
+	for node, seen := range b.nodes {
+		if node == b.nodeId || time.Since(seen) < NodeTTL {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Broadcast 向所有节点(包含当前节点)广播消息
+func (b *Bus) Broadcast(ctx context.Context, topic string, payload string) error {
+	return b.publish(ctx, &Message{Kind: KindEvent, Topic: topic, Payload: payload})
+}
+
+// Send 向指定节点发送消息, node 为空时随机选择一个存活节点
+func (b *Bus) Send(ctx context.Context, node string, topic string, payload string) error {
+	if node == "" {
+		nodes := b.Nodes()
+		node = nodes[rand.Intn(len(nodes))]
+	}
+	return b.publish(ctx, &Message{Kind: KindEvent, Topic: topic, To: node, Payload: payload})
+}
+
+// Request 发送请求并收集应答, node 为空时请求所有存活节点
+// 在收到所有节点的应答或超时后返回, 超时时返回已收到的应答和错误
+func (b *Bus) Request(ctx context.Context, node string, topic string, payload string, timeout time.Duration) (replies []*Reply, err error) {
+	expected := 1
+	if node == "" {
+		expected = len(b.Nodes())
+	}
+	msg := &Message{Id: guid.S(), Kind: KindRequest, Topic: topic, To: node, Payload: payload}
+	ch := make(chan *Reply, expected)
+	b.mu.Lock()
+	b.pending[msg.Id] = ch
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, msg.Id)
+		b.mu.Unlock()
+	}()
+	if err = b.publish(ctx, msg); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(replies) < expected {
+		select {
+		case reply := <-ch:
+			replies = append(replies, reply)
+		case <-timer.C:
+			return replies, gerror.Newf("等待应答超时, 已收到 %d/%d 个节点的应答", len(replies), expected)
+		case <-ctx.Done():
+			return replies, ctx.Err()
+		}
+	}
+	return replies, nil
+}
+
+// publish 发布消息
+func (b *Bus) publish(ctx context.Context, msg *Message) error {
+	if msg.Id == "" {
+		msg.Id = guid.S()
+	}
+	msg.From = b.nodeId
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return b.driver.Publish(ctx, b.channel, string(payload))
+}
+
+// heartbeat 定时发送心跳并移除下线的节点
+func (b *Bus) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		b.prune()
+		if err := b.publish(ctx, &Message{Kind: KindPing}); err != nil && ctx.Err() == nil {
+			g.Log().Warning(ctx, "发送集群心跳失败", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// prune 移除超过 NodeTTL 未收到心跳的节点, 节点重新上线时会被当作新节点立即回复心跳
+func (b *Bus) prune() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for node, seen := range b.nodes {
+		if node != b.nodeId && time.Since(seen) >= NodeTTL {
+			delete(b.nodes, node)
+		}
+	}
+}
+
+// dispatch 处理收到的消息
+func (b *Bus) dispatch(ctx context.Context, payload string) {
+	msg := &Message{}
+	if err := json.Unmarshal([]byte(payload), msg); err != nil {
+		g.Log().Warning(ctx, "无法解析集群消息", payload, err)
+		return
+	}
+	if msg.To != "" && msg.To != b.nodeId {
+		return
+	}
+	switch msg.Kind {
+	case KindLeave:
+		b.mu.Lock()
+		delete(b.nodes, msg.From)
+		b.mu.Unlock()
+		return
+	case KindReply:
+		b.mu.RLock()
+		ch, ok := b.pending[msg.Id]
+		b.mu.RUnlock()
+		if ok {
+			select {
+			case ch <- &Reply{Node: msg.From, Result: msg.Payload, Error: msg.Error}:
+			default:
+			}
+		}
+		return
+	}
+	b.mu.Lock()
+	_, known := b.nodes[msg.From]
+	b.nodes[msg.From] = time.Now()
+	handler := b.handlers[msg.Topic]
+	b.mu.Unlock()
+	switch msg.Kind {
+	case KindPing:
+		// 发现新节点时立即回复心跳, 让新节点尽快获得完整的节点列表
+		// 在订阅回调中同步发布可能与驱动的投递互相等待, 因此异步回复
+		if !known && msg.From != b.nodeId {
+			go func() {
+				_ = b.publish(ctx, &Message{Kind: KindPing, To: msg.From})
+			}()
+		}
+	case KindEvent, KindRequest:
+		go b.handle(ctx, handler, msg)
+	}
+}
+
+// handle 调用主题处理函数, 请求消息需要回复应答
+func (b *Bus) handle(ctx context.Context, handler Handler, msg *Message) {
+	var (
+		result string
+		err    error
+	)
+	if handler == nil {
+		err = gerror.New("未注册的消息主题:" + msg.Topic)
+	} else {
+		result, err = handler(ctx, msg)
+	}
+	if msg.Kind != KindRequest {
+		if err != nil {
+			g.Log().Error(ctx, "处理集群消息失败", msg.Topic, err)
+		}
+		return
+	}
+	reply := &Message{Id: msg.Id, Kind: KindReply, Topic: msg.Topic, To: msg.From, Payload: result}
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	if err = b.publish(ctx, reply); err != nil {
+		g.Log().Error(ctx, "发送集群应答失败", msg.Topic, err)
+	}
+}
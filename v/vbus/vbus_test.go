@@ -0,0 +1,149 @@
+package vbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// newTestNodes 在同一个进程内驱动上创建多个节点并开始监听
+func newTestNodes(ctx context.Context, names ...string) []*Bus {
+	driver := NewMemoryDriver()
+	nodes := make([]*Bus, 0, len(names))
+	for _, name := range names {
+		b := New(driver, "v:bus:test", name)
+		b.Handle("echo", func(ctx context.Context, msg *Message) (string, error) {
+			if msg.Payload == "fail" {
+				return "", gerror.New("fail on " + b.NodeId())
+			}
+			return b.NodeId() + ":" + msg.Payload, nil
+		})
+		go b.Listen(ctx)
+		nodes = append(nodes, b)
+	}
+	return nodes
+}
+
+// waitNodes 等待所有节点互相发现
+func waitNodes(nodes []*Bus) {
+	for i := 0; i < 100; i++ {
+		ready := true
+		for _, b := range nodes {
+			if len(b.Nodes()) != len(nodes) {
+				ready = false
+			}
+		}
+		if ready {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestBusRequest 测试请求/应答
+func TestBusRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nodes := newTestNodes(ctx, "a", "b", "c")
+	waitNodes(nodes)
+
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(nodes[0].Nodes(), []string{"a", "b", "c"})
+
+		replies, err := nodes[0].Request(ctx, "", "echo", "hi", time.Second)
+		t.AssertNil(err)
+		t.Assert(len(replies), 3)
+		results := map[string]string{}
+		for _, reply := range replies {
+			t.AssertNil(reply.Err())
+			results[reply.Node] = reply.Result
+		}
+		t.Assert(results["b"], "b:hi")
+
+		replies, err = nodes[0].Request(ctx, "c", "echo", "fail", time.Second)
+		t.AssertNil(err)
+		t.Assert(len(replies), 1)
+		t.Assert(replies[0].Node, "c")
+		t.Assert(replies[0].Err().Error(), "fail on c")
+
+		replies, err = nodes[0].Request(ctx, "", "unknown", "", time.Second)
+		t.AssertNil(err)
+		t.Assert(len(replies), 3)
+		t.AssertNE(replies[0].Err(), nil)
+	})
+}
+
+// TestBusBroadcastAndSend 测试广播与发送到单个节点
+func TestBusBroadcastAndSend(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nodes := newTestNodes(ctx, "a", "b")
+	received := make(chan string, 10)
+	for _, b := range nodes {
+		b := b
+		b.Handle("event", func(ctx context.Context, msg *Message) (string, error) {
+			received <- b.NodeId() + ":" + msg.Payload
+			return "", nil
+		})
+	}
+	waitNodes(nodes)
+
+	gtest.C(t, func(t *gtest.T) {
+		t.AssertNil(nodes[0].Broadcast(ctx, "event", "all"))
+		got := map[string]bool{}
+		for i := 0; i < 2; i++ {
+			got[<-received] = true
+		}
+		t.Assert(got["a:all"], true)
+		t.Assert(got["b:all"], true)
+
+		t.AssertNil(nodes[0].Send(ctx, "b", "event", "one"))
+		t.Assert(<-received, "b:one")
+		select {
+		case msg := <-received:
+			t.Error("unexpected message " + msg)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+// TestBusLeave 测试节点下线
+func TestBusLeave(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nodeCtx, nodeCancel := context.WithCancel(ctx)
+	driver := NewMemoryDriver()
+	a := New(driver, "v:bus:test", "a")
+	b := New(driver, "v:bus:test", "b")
+	go a.Listen(ctx)
+	go b.Listen(nodeCtx)
+	waitNodes([]*Bus{a, b})
+
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(len(a.Nodes()), 2)
+		nodeCancel()
+		for i := 0; i < 100 && len(a.Nodes()) != 1; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Assert(a.Nodes(), []string{"a"})
+	})
+}
+
+// TestBusPrune 测试移除超时未收到心跳的节点
+func TestBusPrune(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		b := New(NewMemoryDriver(), "v:bus:test", "a")
+		b.nodes["b"] = time.Now()
+		b.nodes["c"] = time.Now().Add(-NodeTTL)
+		b.prune()
+		t.Assert(len(b.nodes), 2)
+		t.Assert(b.Nodes(), []string{"a", "b"})
+
+		// 节点重新上线时作为新节点处理
+		b.dispatch(context.Background(), `{"kind":"ping","from":"c"}`)
+		t.Assert(b.Nodes(), []string{"a", "b", "c"})
+	})
+}
@@ -2,6 +2,9 @@ package vconfig
 
 import (
 	"context"
+	"sort"
+	"strings"
+
 	"github.com/gogf/gf/v2/os/gcfg"
 )
 
@@ -83,4 +86,50 @@ func (w *AdapterWrapper) Watch(ctx context.Context, pattern string, callback fun
 func (w *AdapterWrapper) Close(ctx context.Context) error {
 	// 默认不需要关闭操作，子类可以重写
 	return nil
-}
\ No newline at end of file
+}
+
+// nestFlatMap 将点分格式的键值转换为嵌套map
+func nestFlatMap(flat map[string]interface{}) map[string]interface{} {
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	// 先处理短的键，保证子键可以覆盖叶子值
+	sort.Strings(keys)
+	result := make(map[string]interface{})
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		node := result
+		for _, part := range parts[:len(parts)-1] {
+			// 复制已有的map，避免修改调用方保存的值
+			child := make(map[string]interface{})
+			if m, ok := node[part].(map[string]interface{}); ok {
+				for k, v := range m {
+					child[k] = v
+				}
+			}
+			node[part] = child
+			node = child
+		}
+		node[parts[len(parts)-1]] = flat[key]
+	}
+	return result
+}
+
+// mergeMap 深度合并两个map，high中的值优先，返回新的map
+func mergeMap(high, low map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(high)+len(low))
+	for k, v := range low {
+		result[k] = v
+	}
+	for k, v := range high {
+		highMap, highOk := v.(map[string]interface{})
+		lowMap, lowOk := result[k].(map[string]interface{})
+		if highOk && lowOk {
+			result[k] = mergeMap(highMap, lowMap)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
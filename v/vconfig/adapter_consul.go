@@ -1,6 +1,7 @@
 package vconfig
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -8,11 +9,11 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
-	
-	"github.com/gogf/gf/v2/container/gvar"
+
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/util/gconv"
@@ -20,14 +21,16 @@ import (
 
 // ConsulAdapterConfig Consul适配器配置
 type ConsulAdapterConfig struct {
-	Address    string        // Consul地址，默认 127.0.0.1:8500
-	Scheme     string        // 协议，默认 http
-	Datacenter string        // 数据中心
-	Token      string        // 访问令牌
-	Prefix     string        // 键前缀
-	Watch      bool          // 是否监听配置变化
-	Interval   time.Duration // 监听间隔，默认 30s
-	Timeout    time.Duration // 请求超时，默认 10s
+	Address       string        // Consul地址，默认 127.0.0.1:8500，可包含协议如 http://127.0.0.1:8500
+	Scheme        string        // 协议，默认 http
+	Datacenter    string        // 数据中心
+	Token         string        // 访问令牌
+	Prefix        string        // 键前缀
+	Watch         bool          // 是否监听配置变化
+	WaitTime      time.Duration // 阻塞查询的最长等待时间，默认 5m
+	Interval      time.Duration // 服务端未返回 X-Consul-Index 时的轮询间隔，默认 30s
+	RetryInterval time.Duration // 监听请求失败后的重试间隔，默认 3s
+	Timeout       time.Duration // 请求超时，默认 10s
 }
 
 // ConsulKVPair Consul KV键值对
@@ -38,23 +41,30 @@ type ConsulKVPair struct {
 
 // ConsulAdapter Consul配置适配器
 // 基于HTTP API实现配置管理，不依赖consul/api包
+// 键按前缀映射为嵌套配置，如 config/modules/base/jwt/secret 对应 modules.base.jwt.secret
+// 开启监听时使用带 X-Consul-Index 的阻塞查询，配置变化后立即返回
 type ConsulAdapter struct {
 	config   *ConsulAdapterConfig
 	baseURL  string
-	client   *http.Client
+	client   *http.Client // 普通请求
+	stream   *http.Client // 阻塞查询请求，超时由上下文控制
 	watchers map[string][]func(*ConfigEvent)
 	mutex    sync.RWMutex
 	lastData map[string]interface{}
-	stopCh   chan struct{}
+	index    uint64 // 上次阻塞查询返回的 X-Consul-Index
+	ctx      context.Context
+	cancel   context.CancelFunc
 }
 
+var _ VConfigAdapter = (*ConsulAdapter)(nil)
+
 // NewConsulAdapter 创建Consul配置适配器
 // config: 适配器配置
 func NewConsulAdapter(config *ConsulAdapterConfig) (*ConsulAdapter, error) {
 	if config == nil {
 		return nil, gerror.New("config cannot be nil")
 	}
-	
+
 	// 设置默认值
 	if config.Address == "" {
 		config.Address = "127.0.0.1:8500"
@@ -65,31 +75,43 @@ func NewConsulAdapter(config *ConsulAdapterConfig) (*ConsulAdapter, error) {
 	if config.Prefix == "" {
 		config.Prefix = "config/"
 	}
+	if config.WaitTime == 0 {
+		config.WaitTime = time.Minute * 5
+	}
 	if config.Interval == 0 {
 		config.Interval = time.Second * 30
 	}
+	if config.RetryInterval == 0 {
+		config.RetryInterval = time.Second * 3
+	}
 	if config.Timeout == 0 {
 		config.Timeout = time.Second * 10
 	}
-	
-	baseURL := fmt.Sprintf("%s://%s", config.Scheme, config.Address)
-	
+
+	baseURL := config.Address
+	if !strings.Contains(baseURL, "://") {
+		baseURL = fmt.Sprintf("%s://%s", config.Scheme, config.Address)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	adapter := &ConsulAdapter{
 		config:  config,
-		baseURL: baseURL,
+		baseURL: strings.TrimRight(baseURL, "/"),
 		client: &http.Client{
 			Timeout: config.Timeout,
 		},
+		stream:   &http.Client{},
 		watchers: make(map[string][]func(*ConfigEvent)),
 		lastData: make(map[string]interface{}),
-		stopCh:   make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
 	}
-	
+
 	// 启动配置监听
 	if config.Watch {
 		go adapter.startWatch()
 	}
-	
+
 	return adapter, nil
 }
 
@@ -103,148 +125,73 @@ func (c *ConsulAdapter) Name() string {
 // files: 可选的文件名参数（兼容GoFrame接口）
 func (c *ConsulAdapter) Available(ctx context.Context, files ...string) bool {
 	// 尝试连接Consul
-	url := fmt.Sprintf("%s/v1/kv/health-check", c.baseURL)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := c.newRequest(ctx, http.MethodGet, "health-check", nil, nil)
 	if err != nil {
 		return false
 	}
-	
-	if c.config.Token != "" {
-		req.Header.Set("X-Consul-Token", c.config.Token)
-	}
-	
+
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return false
 	}
 	defer resp.Body.Close()
-	
+
 	return resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound
 }
 
 // Get 获取指定键的配置值
 // ctx: 上下文
 // pattern: 配置键模式
-func (c *ConsulAdapter) Get(ctx context.Context, pattern string) (*gvar.Var, error) {
+func (c *ConsulAdapter) Get(ctx context.Context, pattern string) (any, error) {
 	key := c.buildKey(pattern)
-	
-	url := fmt.Sprintf("%s/v1/kv/%s", c.baseURL, url.QueryEscape(key))
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+
+	req, err := c.newRequest(ctx, http.MethodGet, key, nil, nil)
 	if err != nil {
 		return nil, gerror.Wrapf(err, "failed to create request for key: %s", key)
 	}
-	
-	if c.config.Token != "" {
-		req.Header.Set("X-Consul-Token", c.config.Token)
-	}
-	
+
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, gerror.Wrapf(err, "failed to get config from consul: %s", key)
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode == http.StatusNotFound {
-		return g.NewVar(nil), nil
+		return nil, nil
 	}
-	
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, gerror.Newf("consul returned status %d for key: %s", resp.StatusCode, key)
 	}
-	
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, gerror.Wrapf(err, "failed to read response body for key: %s", key)
 	}
-	
+
 	var pairs []ConsulKVPair
 	if err := json.Unmarshal(body, &pairs); err != nil {
 		return nil, gerror.Wrapf(err, "failed to unmarshal response for key: %s", key)
 	}
-	
+
 	if len(pairs) == 0 {
-		return g.NewVar(nil), nil
+		return nil, nil
 	}
-	
+
 	// Base64解码值
 	valueBytes, err := c.decodeBase64(pairs[0].Value)
 	if err != nil {
 		return nil, gerror.Wrapf(err, "failed to decode value for key: %s", key)
 	}
-	
-	// 尝试解析JSON
-	var value interface{}
-	if err := json.Unmarshal(valueBytes, &value); err != nil {
-		// 如果不是JSON，直接返回字符串
-		value = string(valueBytes)
-	}
-	
-	return g.NewVar(value), nil
+
+	return c.parseValue(valueBytes), nil
 }
 
 // Data 获取所有配置数据
 // ctx: 上下文
 func (c *ConsulAdapter) Data(ctx context.Context) (map[string]interface{}, error) {
-	url := fmt.Sprintf("%s/v1/kv/%s?recurse=true", c.baseURL, url.QueryEscape(c.config.Prefix))
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, gerror.Wrapf(err, "failed to create request for prefix: %s", c.config.Prefix)
-	}
-	
-	if c.config.Token != "" {
-		req.Header.Set("X-Consul-Token", c.config.Token)
-	}
-	
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, gerror.Wrapf(err, "failed to list configs from consul")
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode == http.StatusNotFound {
-		return make(map[string]interface{}), nil
-	}
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, gerror.Newf("consul returned status %d for prefix: %s", resp.StatusCode, c.config.Prefix)
-	}
-	
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, gerror.Wrapf(err, "failed to read response body")
-	}
-	
-	var pairs []ConsulKVPair
-	if err := json.Unmarshal(body, &pairs); err != nil {
-		return nil, gerror.Wrapf(err, "failed to unmarshal response")
-	}
-	
-	result := make(map[string]interface{})
-	for _, pair := range pairs {
-		key := strings.TrimPrefix(pair.Key, c.config.Prefix)
-		if key == "" {
-			continue
-		}
-		
-		// Base64解码值
-		valueBytes, err := c.decodeBase64(pair.Value)
-		if err != nil {
-			g.Log().Warningf(ctx, "failed to decode value for key %s: %v", key, err)
-			continue
-		}
-		
-		// 尝试解析JSON
-		var value interface{}
-		if err := json.Unmarshal(valueBytes, &value); err != nil {
-			// 如果不是JSON，直接使用字符串
-			value = string(valueBytes)
-		}
-		
-		// 构建嵌套结构
-		c.setNestedValue(result, key, value)
-	}
-	
-	return result, nil
+	data, _, err := c.list(ctx, c.client, 0)
+	return data, err
 }
 
 // Set 设置配置值
@@ -253,11 +200,11 @@ func (c *ConsulAdapter) Data(ctx context.Context) (map[string]interface{}, error
 // value: 配置值
 func (c *ConsulAdapter) Set(ctx context.Context, pattern string, value interface{}) error {
 	key := c.buildKey(pattern)
-	
+
 	// 序列化值
 	var data []byte
 	var err error
-	
+
 	switch v := value.(type) {
 	case string:
 		data = []byte(v)
@@ -269,27 +216,49 @@ func (c *ConsulAdapter) Set(ctx context.Context, pattern string, value interface
 			return gerror.Wrapf(err, "failed to marshal value for key: %s", key)
 		}
 	}
-	
-	url := fmt.Sprintf("%s/v1/kv/%s", c.baseURL, url.QueryEscape(key))
-	req, err := http.NewRequestWithContext(ctx, "PUT", url, strings.NewReader(string(data)))
+
+	req, err := c.newRequest(ctx, http.MethodPut, key, nil, bytes.NewReader(data))
 	if err != nil {
 		return gerror.Wrapf(err, "failed to create request for key: %s", key)
 	}
-	
-	if c.config.Token != "" {
-		req.Header.Set("X-Consul-Token", c.config.Token)
-	}
-	
+
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return gerror.Wrapf(err, "failed to set config in consul: %s", key)
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode != http.StatusOK {
 		return gerror.Newf("consul returned status %d for key: %s", resp.StatusCode, key)
 	}
-	
+
+	return nil
+}
+
+// Delete 删除配置键及其子级配置
+// ctx: 上下文
+// pattern: 配置键模式
+func (c *ConsulAdapter) Delete(ctx context.Context, pattern string) error {
+	key := c.buildKey(pattern)
+
+	for _, target := range []string{key, key + "/"} {
+		query := url.Values{}
+		if strings.HasSuffix(target, "/") {
+			query.Set("recurse", "true")
+		}
+		req, err := c.newRequest(ctx, http.MethodDelete, target, query, nil)
+		if err != nil {
+			return gerror.Wrapf(err, "failed to create request for key: %s", target)
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return gerror.Wrapf(err, "failed to delete config in consul: %s", target)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return gerror.Newf("consul returned status %d for key: %s", resp.StatusCode, target)
+		}
+	}
 	return nil
 }
 
@@ -301,10 +270,10 @@ func (c *ConsulAdapter) Watch(ctx context.Context, pattern string, callback func
 	if !c.config.Watch {
 		return gerror.New("consul watching is disabled")
 	}
-	
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	
+
 	c.watchers[pattern] = append(c.watchers[pattern], callback)
 	return nil
 }
@@ -312,25 +281,113 @@ func (c *ConsulAdapter) Watch(ctx context.Context, pattern string, callback func
 // Close 关闭适配器
 // ctx: 上下文
 func (c *ConsulAdapter) Close(ctx context.Context) error {
+	// 停止监听
+	c.cancel()
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	
-	// 停止监听
-	close(c.stopCh)
-	
+
 	// 清理资源
 	c.watchers = make(map[string][]func(*ConfigEvent))
 	c.lastData = make(map[string]interface{})
-	
+
 	return nil
 }
 
-// buildKey 构建完整的Consul键
+// newRequest 创建KV接口请求，附加访问令牌与数据中心参数
+func (c *ConsulAdapter) newRequest(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	if c.config.Datacenter != "" {
+		query.Set("dc", c.config.Datacenter)
+	}
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	reqURL := fmt.Sprintf("%s/v1/kv/%s", c.baseURL, strings.Join(segments, "/"))
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.config.Token != "" {
+		req.Header.Set("X-Consul-Token", c.config.Token)
+	}
+	return req, nil
+}
+
+// list 读取前缀下的全部配置
+// index 大于0时发起阻塞查询，直到配置变化或等待超时才返回
+// 返回: 嵌套配置、响应中的 X-Consul-Index（服务端未返回时为0）
+func (c *ConsulAdapter) list(ctx context.Context, client *http.Client, index uint64) (map[string]interface{}, uint64, error) {
+	query := url.Values{}
+	query.Set("recurse", "true")
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", c.config.WaitTime.String())
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, c.config.Prefix, query, nil)
+	if err != nil {
+		return nil, 0, gerror.Wrapf(err, "failed to create request for prefix: %s", c.config.Prefix)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, gerror.Wrapf(err, "failed to list configs from consul")
+	}
+	defer resp.Body.Close()
+
+	newIndex, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if resp.StatusCode == http.StatusNotFound {
+		return make(map[string]interface{}), newIndex, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, gerror.Newf("consul returned status %d for prefix: %s", resp.StatusCode, c.config.Prefix)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, gerror.Wrapf(err, "failed to read response body")
+	}
+
+	var pairs []ConsulKVPair
+	if err := json.Unmarshal(body, &pairs); err != nil {
+		return nil, 0, gerror.Wrapf(err, "failed to unmarshal response")
+	}
+
+	result := make(map[string]interface{})
+	for _, pair := range pairs {
+		key := strings.Trim(strings.TrimPrefix(pair.Key, c.config.Prefix), "/")
+		if key == "" {
+			continue
+		}
+
+		// Base64解码值
+		valueBytes, err := c.decodeBase64(pair.Value)
+		if err != nil {
+			g.Log().Warningf(ctx, "failed to decode value for key %s: %v", key, err)
+			continue
+		}
+
+		// 构建嵌套结构
+		c.setNestedValue(result, key, c.parseValue(valueBytes))
+	}
+
+	return result, newIndex, nil
+}
+
+// buildKey 构建完整的Consul键，点分格式的配置键转换为路径
 func (c *ConsulAdapter) buildKey(pattern string) string {
 	if strings.HasPrefix(pattern, c.config.Prefix) {
 		return pattern
 	}
-	return c.config.Prefix + pattern
+	return c.config.Prefix + strings.ReplaceAll(pattern, ".", "/")
 }
 
 // decodeBase64 解码Base64字符串
@@ -339,11 +396,20 @@ func (c *ConsulAdapter) decodeBase64(encoded string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(encoded)
 }
 
+// parseValue 解析配置值，不是JSON时作为字符串
+func (c *ConsulAdapter) parseValue(data []byte) interface{} {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return string(data)
+	}
+	return value
+}
+
 // setNestedValue 设置嵌套值
 func (c *ConsulAdapter) setNestedValue(data map[string]interface{}, key string, value interface{}) {
 	parts := strings.Split(key, "/")
 	current := data
-	
+
 	for i, part := range parts {
 		if i == len(parts)-1 {
 			// 最后一个部分，设置值
@@ -361,41 +427,60 @@ func (c *ConsulAdapter) setNestedValue(data map[string]interface{}, key string,
 }
 
 // startWatch 启动配置监听
+// 首次读取建立快照，之后以上次的 X-Consul-Index 发起阻塞查询，配置变化时立即返回
 func (c *ConsulAdapter) startWatch() {
-	ticker := time.NewTicker(c.config.Interval)
-	defer ticker.Stop()
-	
-	ctx := context.Background()
-	
-	for {
-		select {
-		case <-c.stopCh:
+	synced := false
+	for c.ctx.Err() == nil {
+		c.mutex.RLock()
+		index := c.index
+		c.mutex.RUnlock()
+
+		// 阻塞查询由服务端在 wait 后返回，额外预留 wait/16 的抖动时间与请求超时
+		ctx, cancel := context.WithTimeout(c.ctx, c.config.WaitTime+c.config.WaitTime/16+c.config.Timeout)
+		data, newIndex, err := c.list(ctx, c.stream, index)
+		cancel()
+		if c.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			g.Log().Warningf(c.ctx, "consul watch interrupted, retry in %s: %v", c.config.RetryInterval, err)
+			if !c.sleep(c.config.RetryInterval) {
+				return
+			}
+			continue
+		}
+
+		// 索引回退时(如Consul重建)从头开始
+		if newIndex < index {
+			newIndex = 0
+		}
+		c.mutex.Lock()
+		c.index = newIndex
+		oldData := c.lastData
+		c.lastData = data
+		c.mutex.Unlock()
+
+		// 首次同步不通知
+		if synced && newIndex != index {
+			c.compareAndNotify(oldData, data)
+		}
+		synced = true
+
+		// 服务端未返回索引时不支持阻塞查询，退化为轮询
+		if newIndex == 0 && !c.sleep(c.config.Interval) {
 			return
-		case <-ticker.C:
-			c.checkConfigChanges(ctx)
 		}
 	}
 }
 
-// checkConfigChanges 检查配置变化
-func (c *ConsulAdapter) checkConfigChanges(ctx context.Context) {
-	// 获取新的配置数据
-	newData, err := c.Data(ctx)
-	if err != nil {
-		g.Log().Errorf(ctx, "failed to load config data from consul: %v", err)
-		return
-	}
-	
-	c.mutex.Lock()
-	oldData := c.lastData
-	c.lastData = make(map[string]interface{})
-	for k, v := range newData {
-		c.lastData[k] = v
+// sleep 等待指定时间，适配器关闭时返回false
+func (c *ConsulAdapter) sleep(d time.Duration) bool {
+	select {
+	case <-c.ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
 	}
-	c.mutex.Unlock()
-	
-	// 比较配置变化并通知监听器
-	c.compareAndNotify(oldData, newData)
 }
 
 // compareAndNotify 比较配置变化并通知监听器
@@ -407,11 +492,11 @@ func (c *ConsulAdapter) compareAndNotify(oldData, newData map[string]interface{}
 		copy(watchers[k], v)
 	}
 	c.mutex.RUnlock()
-	
+
 	// 扁平化数据进行比较
 	oldFlat := c.flattenData(oldData, "")
 	newFlat := c.flattenData(newData, "")
-	
+
 	// 检查所有配置键的变化
 	allKeys := make(map[string]bool)
 	for k := range oldFlat {
@@ -420,11 +505,11 @@ func (c *ConsulAdapter) compareAndNotify(oldData, newData map[string]interface{}
 	for k := range newFlat {
 		allKeys[k] = true
 	}
-	
+
 	for key := range allKeys {
 		oldValue, oldExists := oldFlat[key]
 		newValue, newExists := newFlat[key]
-		
+
 		var eventType EventType
 		if !oldExists && newExists {
 			eventType = EventTypeAdd
@@ -435,14 +520,14 @@ func (c *ConsulAdapter) compareAndNotify(oldData, newData map[string]interface{}
 		} else {
 			continue // 没有变化
 		}
-		
+
 		event := &ConfigEvent{
 			Key:      key,
 			Value:    newValue,
 			OldValue: oldValue,
 			Type:     eventType,
 		}
-		
+
 		// 通知匹配的监听器
 		for pattern, callbacks := range watchers {
 			if c.matchPattern(key, pattern) {
@@ -464,13 +549,13 @@ func (c *ConsulAdapter) compareAndNotify(oldData, newData map[string]interface{}
 // flattenData 扁平化数据
 func (c *ConsulAdapter) flattenData(data map[string]interface{}, prefix string) map[string]interface{} {
 	result := make(map[string]interface{})
-	
+
 	for key, value := range data {
 		fullKey := key
 		if prefix != "" {
 			fullKey = prefix + "." + key
 		}
-		
+
 		if nested, ok := value.(map[string]interface{}); ok {
 			// 递归处理嵌套数据
 			for k, v := range c.flattenData(nested, fullKey) {
@@ -480,7 +565,7 @@ func (c *ConsulAdapter) flattenData(data map[string]interface{}, prefix string)
 			result[fullKey] = value
 		}
 	}
-	
+
 	return result
 }
 
@@ -490,7 +575,7 @@ func (c *ConsulAdapter) matchPattern(key, pattern string) bool {
 	if pattern == "*" {
 		return true
 	}
-	
+
 	if strings.Contains(pattern, "*") {
 		// 简单的通配符匹配
 		parts := strings.Split(pattern, "*")
@@ -499,11 +584,12 @@ func (c *ConsulAdapter) matchPattern(key, pattern string) bool {
 			return strings.HasPrefix(key, prefix) && strings.HasSuffix(key, suffix)
 		}
 	}
-	
-	return key == pattern || strings.HasPrefix(key, pattern+".")
+
+	// 父级键变化时也需要通知子级键的监听器
+	return isRelatedKey(key, pattern)
 }
 
 // isEqual 比较两个值是否相等
 func (c *ConsulAdapter) isEqual(a, b interface{}) bool {
 	return gconv.String(a) == gconv.String(b)
-}
\ No newline at end of file
+}
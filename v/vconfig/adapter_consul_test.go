@@ -2,13 +2,17 @@ package vconfig
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -16,56 +20,75 @@ import (
 )
 
 // MockConsulServer 模拟Consul服务器
+// 支持带 index 与 wait 参数的阻塞查询
 type MockConsulServer struct {
 	server *httptest.Server
+	mutex  sync.Mutex
+	cond   *sync.Cond
 	kvData map[string]string
+	index  uint64
 }
 
 // NewMockConsulServer 创建模拟Consul服务器
 func NewMockConsulServer() *MockConsulServer {
 	mock := &MockConsulServer{
 		kvData: make(map[string]string),
+		index:  1,
 	}
+	mock.cond = sync.NewCond(&mock.mutex)
 
 	mux := http.NewServeMux()
-	
-	// 处理KV GET请求
+
+	// 处理KV请求
 	mux.HandleFunc("/v1/kv/", func(w http.ResponseWriter, r *http.Request) {
 		key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
-		
+		query := r.URL.Query()
+
 		switch r.Method {
 		case "GET":
-			if r.URL.Query().Get("recurse") == "true" {
-				// 递归获取所有键值
-				var results []ConsulKVPair
-				for k, v := range mock.kvData {
-					if strings.HasPrefix(k, key) {
-						results = append(results, ConsulKVPair{
-							Key:   k,
-							Value: v,
-						})
-					}
+			mock.mutex.Lock()
+			if index, _ := strconv.ParseUint(query.Get("index"), 10, 64); index > 0 {
+				// 阻塞查询, 直到索引变化或等待超时
+				wait, _ := time.ParseDuration(query.Get("wait"))
+				timer := time.AfterFunc(wait, mock.cond.Broadcast)
+				deadline := time.Now().Add(wait)
+				for mock.index == index && time.Now().Before(deadline) {
+					mock.cond.Wait()
 				}
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(results)
-			} else {
-				// 获取单个键值
-				if value, exists := mock.kvData[key]; exists {
-					result := []ConsulKVPair{{
-						Key:   key,
-						Value: value,
-					}}
-					w.Header().Set("Content-Type", "application/json")
-					json.NewEncoder(w).Encode(result)
-				} else {
-					w.WriteHeader(http.StatusNotFound)
+				timer.Stop()
+			}
+			var results []ConsulKVPair
+			for k, v := range mock.kvData {
+				if k == key || (query.Get("recurse") == "true" && strings.HasPrefix(k, key)) {
+					results = append(results, ConsulKVPair{
+						Key:   k,
+						Value: base64.StdEncoding.EncodeToString([]byte(v)),
+					})
 				}
 			}
+			w.Header().Set("X-Consul-Index", strconv.FormatUint(mock.index, 10))
+			mock.mutex.Unlock()
+			if len(results) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(results)
 		case "PUT":
 			// 设置键值
-			body := make([]byte, r.ContentLength)
-			r.Body.Read(body)
-			mock.kvData[key] = string(body)
+			body, _ := io.ReadAll(r.Body)
+			mock.SetKV(key, string(body))
+			w.WriteHeader(http.StatusOK)
+		case "DELETE":
+			mock.mutex.Lock()
+			for k := range mock.kvData {
+				if k == key || (query.Get("recurse") == "true" && strings.HasPrefix(k, key)) {
+					delete(mock.kvData, k)
+				}
+			}
+			mock.index++
+			mock.cond.Broadcast()
+			mock.mutex.Unlock()
 			w.WriteHeader(http.StatusOK)
 		}
 	})
@@ -76,12 +99,20 @@ func NewMockConsulServer() *MockConsulServer {
 
 // Close 关闭模拟服务器
 func (m *MockConsulServer) Close() {
+	m.mutex.Lock()
+	m.index++
+	m.cond.Broadcast()
+	m.mutex.Unlock()
 	m.server.Close()
 }
 
 // SetKV 设置键值对
 func (m *MockConsulServer) SetKV(key, value string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	m.kvData[key] = value
+	m.index++
+	m.cond.Broadcast()
 }
 
 // TestConsulAdapter_Basic 测试Consul适配器基本功能
@@ -264,6 +295,63 @@ func TestConsulAdapter_Watch(t *testing.T) {
 	})
 }
 
+// TestConsulAdapter_BlockingQuery 测试阻塞查询立即返回配置变化
+func TestConsulAdapter_BlockingQuery(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		mockServer := NewMockConsulServer()
+		defer mockServer.Close()
+		mockServer.SetKV("config/app/name", "a")
+
+		// 轮询间隔足够长, 变化只能通过阻塞查询送达
+		adapter, err := NewConsulAdapter(&ConsulAdapterConfig{
+			Address:  mockServer.server.URL,
+			Watch:    true,
+			WaitTime: time.Second * 5,
+			Interval: time.Hour,
+		})
+		t.AssertNil(err)
+		defer adapter.Close(context.Background())
+
+		ctx := context.Background()
+		events := make(chan *ConfigEvent, 10)
+		t.AssertNil(adapter.Watch(ctx, "app", func(event *ConfigEvent) {
+			events <- event
+		}))
+		time.Sleep(time.Millisecond * 100)
+
+		receive := func() *ConfigEvent {
+			select {
+			case event := <-events:
+				return event
+			case <-time.After(time.Second):
+				return nil
+			}
+		}
+
+		mockServer.SetKV("config/app/name", "b")
+		event := receive()
+		t.AssertNE(event, nil)
+		t.Assert(event.Key, "app.name")
+		t.Assert(event.Type, EventTypeUpdate)
+		t.Assert(event.OldValue, "a")
+		t.Assert(event.Value, "b")
+
+		t.AssertNil(adapter.Set(ctx, "app.port", 8001))
+		event = receive()
+		t.AssertNE(event, nil)
+		t.Assert(event.Key, "app.port")
+		t.Assert(event.Type, EventTypeAdd)
+
+		t.AssertNil(adapter.Delete(ctx, "app.port"))
+		event = receive()
+		t.AssertNE(event, nil)
+		t.Assert(event.Type, EventTypeDelete)
+		value, err := adapter.Get(ctx, "app.port")
+		t.AssertNil(err)
+		t.AssertNil(value)
+	})
+}
+
 // TestConsulAdapter_InvalidConfig 测试无效配置处理
 func TestConsulAdapter_InvalidConfig(t *testing.T) {
 	gtest.C(t, func(t *gtest.T) {
@@ -0,0 +1,221 @@
+package vconfig
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EnvAdapterConfig 环境变量与命令行参数适配器配置
+type EnvAdapterConfig struct {
+	Prefix     string   // 环境变量前缀，默认 VGO_
+	Separator  string   // 环境变量层级分隔符，默认 __
+	FlagPrefix string   // 命令行参数前缀，默认 config.，即 --config.key=value
+	Environ    []string // 环境变量列表，默认 os.Environ()
+	Args       []string // 命令行参数列表，默认 os.Args[1:]
+}
+
+// EnvAdapter 环境变量与命令行参数配置适配器
+// 环境变量 VGO_MODULES__BASE__JWT__SECRET 映射为 modules.base.jwt.secret,
+// 层级内的下划线转换为驼峰, 如 VGO_MODULES__BASE__JWT__TOKEN__REFRESH_EXPIRE 映射为 modules.base.jwt.token.refreshExpire
+// 命令行参数 --config.modules.base.jwt.secret=value 映射为 modules.base.jwt.secret, 优先级高于环境变量
+// 值会按 bool、整数、浮点数、JSON数组/对象 的顺序尝试转换, 否则作为字符串
+type EnvAdapter struct {
+	*AdapterWrapper
+	config *EnvAdapterConfig
+	data   map[string]interface{} // 点分格式的配置
+	mutex  sync.RWMutex
+}
+
+// NewEnvAdapter 创建环境变量与命令行参数配置适配器
+// config: 适配器配置，为nil时使用默认配置
+func NewEnvAdapter(config *EnvAdapterConfig) *EnvAdapter {
+	if config == nil {
+		config = &EnvAdapterConfig{}
+	}
+
+	// 设置默认值
+	if config.Prefix == "" {
+		config.Prefix = "VGO_"
+	}
+	if config.Separator == "" {
+		config.Separator = "__"
+	}
+	if config.FlagPrefix == "" {
+		config.FlagPrefix = "config."
+	}
+	if config.Environ == nil {
+		config.Environ = os.Environ()
+	}
+	if config.Args == nil && len(os.Args) > 1 {
+		config.Args = os.Args[1:]
+	}
+
+	adapter := &EnvAdapter{
+		AdapterWrapper: &AdapterWrapper{name: "env"},
+		config:         config,
+		data:           make(map[string]interface{}),
+	}
+	adapter.parseEnviron()
+	adapter.parseArgs()
+	return adapter
+}
+
+// Name 返回适配器名称
+func (e *EnvAdapter) Name() string {
+	return "env"
+}
+
+// Available 检查配置源是否可用，没有任何配置时不可用
+// ctx: 上下文
+// files: 可选的文件名参数（兼容GoFrame接口）
+func (e *EnvAdapter) Available(ctx context.Context, files ...string) bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return len(e.data) > 0
+}
+
+// Get 获取指定键的配置值
+// 键的匹配忽略大小写和下划线，对应叶子节点时返回值，对应父级时返回嵌套的map
+// ctx: 上下文
+// pattern: 配置键模式
+func (e *EnvAdapter) Get(ctx context.Context, pattern string) (any, error) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	normalized := normalizeEnvKey(pattern)
+	depth := len(strings.Split(pattern, "."))
+	children := make(map[string]interface{})
+	for key, value := range e.data {
+		current := normalizeEnvKey(key)
+		if current == normalized {
+			return value, nil
+		}
+		if strings.HasPrefix(current, normalized+".") {
+			children[strings.Join(strings.Split(key, ".")[depth:], ".")] = value
+		}
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+	return nestFlatMap(children), nil
+}
+
+// Data 获取所有配置数据
+// ctx: 上下文
+func (e *EnvAdapter) Data(ctx context.Context) (map[string]interface{}, error) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return nestFlatMap(e.data), nil
+}
+
+// Set 设置配置值，仅在当前进程内生效
+// ctx: 上下文
+// pattern: 配置键模式
+// value: 配置值
+func (e *EnvAdapter) Set(ctx context.Context, pattern string, value interface{}) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.set(pattern, value)
+	return nil
+}
+
+// parseEnviron 解析带前缀的环境变量
+func (e *EnvAdapter) parseEnviron() {
+	for _, item := range e.config.Environ {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok || len(name) <= len(e.config.Prefix) || !strings.EqualFold(name[:len(e.config.Prefix)], e.config.Prefix) {
+			continue
+		}
+		parts := strings.Split(name[len(e.config.Prefix):], e.config.Separator)
+		for i, part := range parts {
+			parts[i] = envSegmentToKey(part)
+		}
+		e.set(strings.Join(parts, "."), coerceValue(value))
+	}
+}
+
+// parseArgs 解析命令行参数，支持 --config.key=value、--config.key value 和 --config.key
+func (e *EnvAdapter) parseArgs() {
+	args := e.config.Args
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimLeft(args[i], "-")
+		if arg == args[i] || !strings.HasPrefix(arg, e.config.FlagPrefix) {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(arg, e.config.FlagPrefix), "=")
+		if key == "" {
+			continue
+		}
+		if !ok {
+			// 下一个参数不是选项时作为值，否则视为布尔开关
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				value = args[i+1]
+				i++
+			} else {
+				value = "true"
+			}
+		}
+		e.set(key, coerceValue(value))
+	}
+}
+
+// set 设置配置值，忽略大小写和下划线相同的键会被覆盖
+func (e *EnvAdapter) set(key string, value interface{}) {
+	normalized := normalizeEnvKey(key)
+	for existing := range e.data {
+		if normalizeEnvKey(existing) == normalized {
+			delete(e.data, existing)
+		}
+	}
+	e.data[key] = value
+}
+
+// envSegmentToKey 将环境变量的一段转换为驼峰格式的配置键，如 REFRESH_EXPIRE 转换为 refreshExpire
+func envSegmentToKey(segment string) string {
+	words := strings.Split(strings.ToLower(segment), "_")
+	for i := 1; i < len(words); i++ {
+		if words[i] != "" {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// normalizeEnvKey 标准化配置键，用于忽略大小写和下划线的比较
+func normalizeEnvKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "")
+}
+
+// coerceValue 将字符串转换为对应类型的值
+func coerceValue(value string) interface{} {
+	trimmed := strings.TrimSpace(value)
+	switch strings.ToLower(trimmed) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	// 以0开头的数字保留为字符串，如编号、手机号
+	if trimmed != "" && (trimmed[0] != '0' || len(trimmed) == 1 || strings.HasPrefix(trimmed, "0.")) {
+		if i, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return i
+		}
+		// 排除 Inf、NaN 等特殊写法
+		if strings.Trim(trimmed, "0123456789+-.eE") == "" {
+			if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+				return f
+			}
+		}
+	}
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		var result interface{}
+		if err := json.Unmarshal([]byte(trimmed), &result); err == nil {
+			return result
+		}
+	}
+	return value
+}
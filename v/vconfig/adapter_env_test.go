@@ -0,0 +1,117 @@
+package vconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// TestEnvAdapter_Basic 测试环境变量与命令行参数的映射和类型转换
+func TestEnvAdapter_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		adapter := NewEnvAdapter(&EnvAdapterConfig{
+			Environ: []string{
+				"VGO_MODULES__BASE__JWT__SECRET=env-secret",
+				"VGO_MODULES__BASE__JWT__SSO=true",
+				"VGO_MODULES__BASE__JWT__TOKEN__REFRESH_EXPIRE=3600",
+				"VGO_V__FILE__DOMAIN=http://cdn",
+				"VGO_APP__RATIO=0.5",
+				"VGO_APP__CODE=0086",
+				"VGO_APP__TAGS=[\"a\",\"b\"]",
+				"PATH=/usr/bin",
+			},
+			Args: []string{
+				"server",
+				"--config.modules.base.jwt.secret=flag-secret",
+				"--config.app.name", "vgo",
+				"--config.app.debug",
+				"--gf.gcfg.file=config.yaml",
+			},
+		})
+		ctx := context.Background()
+		t.Assert(adapter.Available(ctx), true)
+
+		// 命令行参数优先于环境变量
+		value, err := adapter.Get(ctx, "modules.base.jwt.secret")
+		t.AssertNil(err)
+		t.Assert(value, "flag-secret")
+
+		value, _ = adapter.Get(ctx, "modules.base.jwt.sso")
+		t.Assert(value, true)
+		value, _ = adapter.Get(ctx, "modules.base.jwt.token.refreshExpire")
+		t.Assert(value, int64(3600))
+		value, _ = adapter.Get(ctx, "v.file.domain")
+		t.Assert(value, "http://cdn")
+		value, _ = adapter.Get(ctx, "app.ratio")
+		t.Assert(value, 0.5)
+		value, _ = adapter.Get(ctx, "app.code")
+		t.Assert(value, "0086")
+		value, _ = adapter.Get(ctx, "app.tags")
+		t.Assert(value, []interface{}{"a", "b"})
+		value, _ = adapter.Get(ctx, "app.name")
+		t.Assert(value, "vgo")
+		value, _ = adapter.Get(ctx, "app.debug")
+		t.Assert(value, true)
+
+		// 父级键返回嵌套map
+		value, _ = adapter.Get(ctx, "modules.base.jwt")
+		jwt := value.(map[string]interface{})
+		t.Assert(jwt["secret"], "flag-secret")
+		t.Assert(jwt["token"].(map[string]interface{})["refreshExpire"], 3600)
+
+		value, _ = adapter.Get(ctx, "path")
+		t.AssertNil(value)
+		value, _ = adapter.Get(ctx, "gf.gcfg.file")
+		t.AssertNil(value)
+
+		data, err := adapter.Data(ctx)
+		t.AssertNil(err)
+		t.Assert(len(data), 3)
+
+		t.AssertNil(adapter.Set(ctx, "app.name", "updated"))
+		value, _ = adapter.Get(ctx, "app.name")
+		t.Assert(value, "updated")
+	})
+}
+
+// TestEnvAdapter_Precedence 测试环境变量适配器与其他配置源的优先级和合并
+func TestEnvAdapter_Precedence(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		file := newMemoryAdapter(`{"modules":{"base":{"jwt":{"secret":"file-secret","token":{"expire":7200}}}}}`)
+		env := NewEnvAdapter(&EnvAdapterConfig{
+			Environ: []string{"VGO_MODULES__BASE__JWT__SECRET=env-secret"},
+			Args:    []string{},
+		})
+
+		// 环境变量优先
+		manager := NewConfigManager()
+		t.AssertNil(manager.RegisterAdapter("file", file))
+		t.AssertNil(manager.RegisterAdapter("env", env))
+		t.AssertNil(manager.SetPrimary("env"))
+		t.AssertNil(manager.SetFallback("file"))
+
+		value, err := manager.Get(ctx, "modules.base.jwt.secret")
+		t.AssertNil(err)
+		t.Assert(value, "env-secret")
+		// 部分覆盖时保留配置文件中的其余配置
+		value, err = manager.Get(ctx, "modules.base.jwt")
+		t.AssertNil(err)
+		t.Assert(value.MapStrVar()["secret"], "env-secret")
+		t.Assert(value.MapStrVar()["token"].MapStrVar()["expire"], 7200)
+		data, err := manager.Data(ctx)
+		t.AssertNil(err)
+		t.Assert(data["modules"].(map[string]interface{})["base"].(map[string]interface{})["jwt"].(map[string]interface{})["token"], `{"expire":7200}`)
+
+		// 配置文件优先
+		manager = NewConfigManager()
+		t.AssertNil(manager.RegisterAdapter("file", file))
+		t.AssertNil(manager.RegisterAdapter("env", env))
+		t.AssertNil(manager.SetPrimary("file"))
+		t.AssertNil(manager.SetFallback("env"))
+		value, err = manager.Get(ctx, "modules.base.jwt.secret")
+		t.AssertNil(err)
+		t.Assert(value, "file-secret")
+	})
+}
@@ -0,0 +1,668 @@
+package vconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// EtcdAdapterConfig Etcd适配器配置
+type EtcdAdapterConfig struct {
+	Endpoints          []string      // etcd地址列表，默认 http://127.0.0.1:2379
+	Prefix             string        // 键前缀，默认 config/
+	Username           string        // 用户名，开启认证时使用
+	Password           string        // 密码
+	CAFile             string        // CA证书文件
+	CertFile           string        // 客户端证书文件
+	KeyFile            string        // 客户端私钥文件
+	InsecureSkipVerify bool          // 是否跳过服务端证书校验
+	Watch              bool          // 是否监听配置变化
+	Timeout            time.Duration // 请求超时，默认 10s
+	RetryInterval      time.Duration // 监听断开后的重试间隔，默认 3s
+}
+
+// EtcdAdapter Etcd配置适配器
+// 基于etcd v3 HTTP网关实现配置管理，不依赖etcd客户端包
+// 键按前缀映射为嵌套配置，如 config/modules/base/jwt/secret 对应 modules.base.jwt.secret
+// 值为JSON时按JSON解析，否则作为字符串
+// 开启监听时使用watch流维护本地快照，Get与Data直接读取快照
+type EtcdAdapter struct {
+	config   *EtcdAdapterConfig
+	client   *http.Client // 普通请求
+	stream   *http.Client // watch流请求，不设置超时
+	mutex    sync.RWMutex
+	token    string
+	current  int                    // 当前使用的地址下标
+	data     map[string]interface{} // 本地快照，键为点分格式
+	revision int64                  // 快照对应的版本
+	synced   bool                   // 快照是否可用
+	watchers map[string][]func(*ConfigEvent)
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+var _ VConfigAdapter = (*EtcdAdapter)(nil)
+
+// etcdInt64 etcd网关以字符串返回int64，兼容数字与字符串两种格式
+type etcdInt64 int64
+
+// UnmarshalJSON 解析int64
+func (i *etcdInt64) UnmarshalJSON(b []byte) error {
+	*i = etcdInt64(gconv.Int64(strings.Trim(string(b), `"`)))
+	return nil
+}
+
+// etcdKV etcd键值对
+type etcdKV struct {
+	Key         string    `json:"key"`
+	Value       string    `json:"value"`
+	ModRevision etcdInt64 `json:"mod_revision"`
+}
+
+// etcdHeader etcd响应头
+type etcdHeader struct {
+	Revision etcdInt64 `json:"revision"`
+}
+
+// etcdRangeResponse range响应
+type etcdRangeResponse struct {
+	Header etcdHeader `json:"header"`
+	Kvs    []*etcdKV  `json:"kvs"`
+}
+
+// etcdWatchResponse watch流中的单条响应
+type etcdWatchResponse struct {
+	Result *struct {
+		Header          etcdHeader `json:"header"`
+		Created         bool       `json:"created"`
+		Canceled        bool       `json:"canceled"`
+		CompactRevision etcdInt64  `json:"compact_revision"`
+		Events          []struct {
+			Type string  `json:"type"` // PUT为默认值时网关不返回该字段
+			Kv   *etcdKV `json:"kv"`
+		} `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewEtcdAdapter 创建Etcd配置适配器
+// config: 适配器配置
+func NewEtcdAdapter(config *EtcdAdapterConfig) (*EtcdAdapter, error) {
+	if config == nil {
+		return nil, gerror.New("config cannot be nil")
+	}
+
+	// 设置默认值
+	if len(config.Endpoints) == 0 {
+		config.Endpoints = []string{"http://127.0.0.1:2379"}
+	}
+	for i, endpoint := range config.Endpoints {
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+		config.Endpoints[i] = strings.TrimRight(endpoint, "/")
+	}
+	if config.Prefix == "" {
+		config.Prefix = "config/"
+	}
+	if !strings.HasSuffix(config.Prefix, "/") {
+		config.Prefix += "/"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = time.Second * 10
+	}
+	if config.RetryInterval == 0 {
+		config.RetryInterval = time.Second * 3
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig, err := newEtcdTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	ctx, cancel := context.WithCancel(context.Background())
+	adapter := &EtcdAdapter{
+		config:   config,
+		client:   &http.Client{Transport: transport, Timeout: config.Timeout},
+		stream:   &http.Client{Transport: transport},
+		data:     make(map[string]interface{}),
+		watchers: make(map[string][]func(*ConfigEvent)),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	// 启动配置监听
+	if config.Watch {
+		go adapter.startWatch()
+	}
+
+	return adapter, nil
+}
+
+// newEtcdTLSConfig 根据配置创建TLS配置，未配置证书时返回nil
+func newEtcdTLSConfig(config *EtcdAdapterConfig) (*tls.Config, error) {
+	if config.CAFile == "" && config.CertFile == "" && !config.InsecureSkipVerify {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	if config.CAFile != "" {
+		ca, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "failed to read etcd ca file: %s", config.CAFile)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, gerror.Newf("invalid etcd ca file: %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, gerror.Wrap(err, "failed to load etcd client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// Name 返回适配器名称
+func (e *EtcdAdapter) Name() string {
+	return "etcd"
+}
+
+// Available 检查配置源是否可用
+// ctx: 上下文
+// files: 可选的文件名参数（兼容GoFrame接口）
+func (e *EtcdAdapter) Available(ctx context.Context, files ...string) bool {
+	return e.call(ctx, "/v3/maintenance/status", g.Map{}, nil) == nil
+}
+
+// Get 获取指定键的配置值
+// 键对应叶子节点时返回值，对应目录时返回嵌套的map
+// ctx: 上下文
+// pattern: 配置键模式
+func (e *EtcdAdapter) Get(ctx context.Context, pattern string) (any, error) {
+	data, err := e.flatData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if value, ok := data[pattern]; ok {
+		return value, nil
+	}
+	children := make(map[string]interface{})
+	for key, value := range data {
+		if strings.HasPrefix(key, pattern+".") {
+			children[strings.TrimPrefix(key, pattern+".")] = value
+		}
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+	return nestFlatMap(children), nil
+}
+
+// Data 获取所有配置数据
+// ctx: 上下文
+func (e *EtcdAdapter) Data(ctx context.Context) (map[string]interface{}, error) {
+	data, err := e.flatData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return nestFlatMap(data), nil
+}
+
+// Set 设置配置值
+// 字符串按原样写入，其他类型以JSON写入
+// ctx: 上下文
+// pattern: 配置键模式
+// value: 配置值
+func (e *EtcdAdapter) Set(ctx context.Context, pattern string, value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return gerror.Wrapf(err, "failed to marshal value for key: %s", pattern)
+		}
+		raw = string(b)
+	}
+	return e.call(ctx, "/v3/kv/put", g.Map{
+		"key":   etcdEncode(e.patternToKey(pattern)),
+		"value": etcdEncode(raw),
+	}, nil)
+}
+
+// Watch 监听配置变化
+// ctx: 上下文
+// pattern: 配置键模式
+// callback: 回调函数
+func (e *EtcdAdapter) Watch(ctx context.Context, pattern string, callback func(*ConfigEvent)) error {
+	if !e.config.Watch {
+		return gerror.New("etcd watching is disabled")
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.watchers[pattern] = append(e.watchers[pattern], callback)
+	return nil
+}
+
+// Close 关闭适配器
+// ctx: 上下文
+func (e *EtcdAdapter) Close(ctx context.Context) error {
+	e.cancel()
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.watchers = make(map[string][]func(*ConfigEvent))
+	return nil
+}
+
+// flatData 获取点分格式的全部配置，监听中读取本地快照
+func (e *EtcdAdapter) flatData(ctx context.Context) (map[string]interface{}, error) {
+	e.mutex.RLock()
+	if e.synced {
+		data := make(map[string]interface{}, len(e.data))
+		for k, v := range e.data {
+			data[k] = v
+		}
+		e.mutex.RUnlock()
+		return data, nil
+	}
+	e.mutex.RUnlock()
+
+	data, _, err := e.rangeAll(ctx)
+	return data, err
+}
+
+// rangeAll 读取前缀下的所有键值
+func (e *EtcdAdapter) rangeAll(ctx context.Context) (map[string]interface{}, int64, error) {
+	resp := &etcdRangeResponse{}
+	err := e.call(ctx, "/v3/kv/range", g.Map{
+		"key":       etcdEncode(e.config.Prefix),
+		"range_end": etcdEncode(etcdPrefixEnd(e.config.Prefix)),
+	}, resp)
+	if err != nil {
+		return nil, 0, err
+	}
+	data := make(map[string]interface{}, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if key, value, ok := e.decodeKV(kv); ok {
+			data[key] = value
+		}
+	}
+	return data, int64(resp.Header.Revision), nil
+}
+
+// startWatch 启动配置监听，断开后重新同步并继续监听
+func (e *EtcdAdapter) startWatch() {
+	for e.ctx.Err() == nil {
+		err := e.resync(e.ctx)
+		if err == nil {
+			err = e.watchStream(e.ctx)
+		}
+		if e.ctx.Err() != nil {
+			return
+		}
+		g.Log().Warningf(e.ctx, "etcd watch interrupted, retry in %s: %v", e.config.RetryInterval, err)
+		e.mutex.Lock()
+		e.current = (e.current + 1) % len(e.config.Endpoints)
+		e.mutex.Unlock()
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-time.After(e.config.RetryInterval):
+		}
+	}
+}
+
+// resync 重新读取全部配置，与快照比较后通知变化
+func (e *EtcdAdapter) resync(ctx context.Context) error {
+	data, revision, err := e.rangeAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	old, synced := e.data, e.synced
+	e.data = data
+	e.revision = revision
+	e.synced = true
+	e.mutex.Unlock()
+
+	// 首次同步不通知
+	if !synced {
+		return nil
+	}
+	for key, value := range data {
+		if oldValue, ok := old[key]; !ok {
+			e.notify(&ConfigEvent{Key: key, Value: value, Type: EventTypeAdd})
+		} else if !e.isEqual(oldValue, value) {
+			e.notify(&ConfigEvent{Key: key, Value: value, OldValue: oldValue, Type: EventTypeUpdate})
+		}
+	}
+	for key, oldValue := range old {
+		if _, ok := data[key]; !ok {
+			e.notify(&ConfigEvent{Key: key, OldValue: oldValue, Type: EventTypeDelete})
+		}
+	}
+	return nil
+}
+
+// watchStream 建立watch流并处理事件，直到流断开
+func (e *EtcdAdapter) watchStream(ctx context.Context) error {
+	e.mutex.RLock()
+	revision := e.revision
+	e.mutex.RUnlock()
+
+	body, err := json.Marshal(g.Map{
+		"create_request": g.Map{
+			"key":            etcdEncode(e.config.Prefix),
+			"range_end":      etcdEncode(etcdPrefixEnd(e.config.Prefix)),
+			"start_revision": revision + 1,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := e.request(ctx, e.stream, "/v3/watch", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		msg := &etcdWatchResponse{}
+		if err := decoder.Decode(msg); err != nil {
+			return err
+		}
+		if msg.Error != nil {
+			return gerror.New(msg.Error.Message)
+		}
+		if msg.Result == nil {
+			continue
+		}
+		if msg.Result.Canceled || msg.Result.CompactRevision > 0 {
+			// 版本已被压缩，需要重新同步
+			return gerror.Newf("etcd watch canceled, compact revision %d", msg.Result.CompactRevision)
+		}
+		for _, event := range msg.Result.Events {
+			e.applyEvent(event.Type, event.Kv)
+		}
+		if msg.Result.Header.Revision > 0 {
+			e.mutex.Lock()
+			e.revision = int64(msg.Result.Header.Revision)
+			e.mutex.Unlock()
+		}
+	}
+}
+
+// applyEvent 将watch事件应用到快照并通知监听器
+func (e *EtcdAdapter) applyEvent(eventType string, kv *etcdKV) {
+	if kv == nil {
+		return
+	}
+	key, value, ok := e.decodeKV(kv)
+	if !ok {
+		return
+	}
+
+	e.mutex.Lock()
+	oldValue, exists := e.data[key]
+	if eventType == "DELETE" {
+		delete(e.data, key)
+	} else {
+		e.data[key] = value
+	}
+	e.mutex.Unlock()
+
+	switch {
+	case eventType == "DELETE":
+		if exists {
+			e.notify(&ConfigEvent{Key: key, OldValue: oldValue, Type: EventTypeDelete})
+		}
+	case !exists:
+		e.notify(&ConfigEvent{Key: key, Value: value, Type: EventTypeAdd})
+	case !e.isEqual(oldValue, value):
+		e.notify(&ConfigEvent{Key: key, Value: value, OldValue: oldValue, Type: EventTypeUpdate})
+	}
+}
+
+// notify 通知匹配的监听器
+func (e *EtcdAdapter) notify(event *ConfigEvent) {
+	e.mutex.RLock()
+	var callbacks []func(*ConfigEvent)
+	for pattern, list := range e.watchers {
+		if e.matchPattern(event.Key, pattern) {
+			callbacks = append(callbacks, list...)
+		}
+	}
+	e.mutex.RUnlock()
+
+	for _, callback := range callbacks {
+		go func(cb func(*ConfigEvent)) {
+			defer func() {
+				if r := recover(); r != nil {
+					g.Log().Errorf(context.Background(), "config watcher panic: %v", r)
+				}
+			}()
+			cb(event)
+		}(callback)
+	}
+}
+
+// call 发送JSON请求，out不为nil时解析响应
+func (e *EtcdAdapter) call(ctx context.Context, path string, in interface{}, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	resp, err := e.request(ctx, e.client, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// request 发送请求，依次尝试所有地址，认证失效时重新认证
+func (e *EtcdAdapter) request(ctx context.Context, client *http.Client, path string, body []byte) (*http.Response, error) {
+	e.mutex.RLock()
+	start := e.current
+	e.mutex.RUnlock()
+
+	var lastErr error
+	for i := 0; i < len(e.config.Endpoints); i++ {
+		index := (start + i) % len(e.config.Endpoints)
+		resp, err := e.send(ctx, client, e.config.Endpoints[index], path, body, true)
+		if err == nil {
+			e.mutex.Lock()
+			e.current = index
+			e.mutex.Unlock()
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// send 向指定地址发送请求
+func (e *EtcdAdapter) send(ctx context.Context, client *http.Client, endpoint, path string, body []byte, retryAuth bool) (*http.Response, error) {
+	if e.config.Username != "" {
+		e.mutex.RLock()
+		token := e.token
+		e.mutex.RUnlock()
+		if token == "" {
+			if err := e.authenticate(ctx, endpoint); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	e.mutex.RLock()
+	if e.token != "" {
+		req.Header.Set("Authorization", e.token)
+	}
+	e.mutex.RUnlock()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// 令牌过期时重新认证一次
+	if retryAuth && e.config.Username != "" && resp.StatusCode == http.StatusUnauthorized {
+		e.mutex.Lock()
+		e.token = ""
+		e.mutex.Unlock()
+		return e.send(ctx, client, endpoint, path, body, false)
+	}
+	return nil, gerror.Newf("etcd request %s failed, status: %d, body: %s", path, resp.StatusCode, string(data))
+}
+
+// authenticate 使用用户名密码获取令牌
+func (e *EtcdAdapter) authenticate(ctx context.Context, endpoint string) error {
+	body, _ := json.Marshal(g.Map{"name": e.config.Username, "password": e.config.Password})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/v3/auth/authenticate", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return gerror.Newf("etcd authenticate failed, status: %d, body: %s", resp.StatusCode, string(data))
+	}
+	result := &struct {
+		Token string `json:"token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	e.token = result.Token
+	e.mutex.Unlock()
+	return nil
+}
+
+// decodeKV 解码键值对，返回点分格式的键和解析后的值
+func (e *EtcdAdapter) decodeKV(kv *etcdKV) (string, interface{}, bool) {
+	key, err := base64.StdEncoding.DecodeString(kv.Key)
+	if err != nil {
+		return "", nil, false
+	}
+	value, err := base64.StdEncoding.DecodeString(kv.Value)
+	if err != nil {
+		return "", nil, false
+	}
+	pattern := e.keyToPattern(string(key))
+	if pattern == "" {
+		return "", nil, false
+	}
+	return pattern, etcdParseValue(value), true
+}
+
+// patternToKey 将点分格式的配置键转换为etcd键
+func (e *EtcdAdapter) patternToKey(pattern string) string {
+	return e.config.Prefix + strings.ReplaceAll(pattern, ".", "/")
+}
+
+// keyToPattern 将etcd键转换为点分格式的配置键
+func (e *EtcdAdapter) keyToPattern(key string) string {
+	key = strings.Trim(strings.TrimPrefix(key, e.config.Prefix), "/")
+	return strings.ReplaceAll(key, "/", ".")
+}
+
+// matchPattern 检查键是否匹配模式
+func (e *EtcdAdapter) matchPattern(key, pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if strings.Contains(pattern, "*") {
+		parts := strings.Split(pattern, "*")
+		if len(parts) == 2 {
+			prefix, suffix := parts[0], parts[1]
+			return (strings.HasPrefix(key, prefix) && strings.HasSuffix(key, suffix)) || strings.HasPrefix(prefix, key+".")
+		}
+	}
+	return isRelatedKey(key, pattern)
+}
+
+// isEqual 比较两个值是否相等
+func (e *EtcdAdapter) isEqual(a, b interface{}) bool {
+	return g.NewVar(a).String() == g.NewVar(b).String()
+}
+
+// etcdEncode base64编码
+func etcdEncode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// etcdPrefixEnd 计算前缀查询的range_end
+func etcdPrefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	// 前缀全部为0xff时查询到末尾
+	return "\x00"
+}
+
+// etcdParseValue 解析值，JSON格式按JSON解析，否则作为字符串
+func etcdParseValue(value []byte) interface{} {
+	var result interface{}
+	if err := json.Unmarshal(value, &result); err == nil {
+		return result
+	}
+	return string(value)
+}
@@ -0,0 +1,312 @@
+package vconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// MockEtcdServer 模拟etcd v3 HTTP网关
+type MockEtcdServer struct {
+	server   *httptest.Server
+	mutex    sync.Mutex
+	kvData   map[string]string
+	revision int64
+	watchers []chan map[string]interface{}
+	username string
+	password string
+	token    string
+}
+
+// NewMockEtcdServer 创建模拟etcd服务器
+func NewMockEtcdServer() *MockEtcdServer {
+	mock := &MockEtcdServer{kvData: make(map[string]string), revision: 1}
+
+	decode := func(s interface{}) string {
+		b, _ := base64.StdEncoding.DecodeString(s.(string))
+		return string(b)
+	}
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	header := func() map[string]interface{} {
+		mock.mutex.Lock()
+		defer mock.mutex.Unlock()
+		return map[string]interface{}{"revision": itoa(mock.revision)}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/auth/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["name"] != mock.username || req["password"] != mock.password {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mock.mutex.Lock()
+		mock.token = "token-" + itoa(mock.revision)
+		token := mock.token
+		mock.mutex.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{"token": token})
+	})
+	auth := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mock.mutex.Lock()
+			token := mock.token
+			mock.mutex.Unlock()
+			if mock.username != "" && (token == "" || r.Header.Get("Authorization") != token) {
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(map[string]interface{}{"error": "etcdserver: invalid auth token", "code": 16})
+				return
+			}
+			next(w, r)
+		}
+	}
+	mux.HandleFunc("/v3/maintenance/status", auth(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"header": header()})
+	}))
+	mux.HandleFunc("/v3/kv/range", auth(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		start, end := decode(req["key"]), decode(req["range_end"])
+		h := header()
+		mock.mutex.Lock()
+		defer mock.mutex.Unlock()
+		var keys []string
+		for k := range mock.kvData {
+			if k >= start && k < end {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		var kvs []map[string]interface{}
+		for _, k := range keys {
+			kvs = append(kvs, map[string]interface{}{"key": encode(k), "value": encode(mock.kvData[k])})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"header": h, "kvs": kvs})
+	}))
+	mux.HandleFunc("/v3/kv/put", auth(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		mock.Put(decode(req["key"]), decode(req["value"]))
+		json.NewEncoder(w).Encode(map[string]interface{}{"header": header()})
+	}))
+	mux.HandleFunc("/v3/watch", auth(func(w http.ResponseWriter, r *http.Request) {
+		ch := make(chan map[string]interface{}, 16)
+		mock.mutex.Lock()
+		mock.watchers = append(mock.watchers, ch)
+		mock.mutex.Unlock()
+		flusher := w.(http.Flusher)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"header": header(), "created": true}})
+		flusher.Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-ch:
+				json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"header": header(), "events": []interface{}{event}}})
+				flusher.Flush()
+			}
+		}
+	}))
+	mock.server = httptest.NewServer(mux)
+	return mock
+}
+
+// itoa 整数转字符串
+func itoa(i int64) string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}
+
+// Put 写入键值并通知watch流
+func (m *MockEtcdServer) Put(key, value string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.revision++
+	m.kvData[key] = value
+	// 与etcd网关一致，PUT事件不返回type字段
+	event := map[string]interface{}{"kv": map[string]interface{}{
+		"key":   base64.StdEncoding.EncodeToString([]byte(key)),
+		"value": base64.StdEncoding.EncodeToString([]byte(value)),
+	}}
+	for _, ch := range m.watchers {
+		ch <- event
+	}
+}
+
+// Delete 删除键并通知watch流
+func (m *MockEtcdServer) Delete(key string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.revision++
+	delete(m.kvData, key)
+	event := map[string]interface{}{"type": "DELETE", "kv": map[string]interface{}{
+		"key": base64.StdEncoding.EncodeToString([]byte(key)),
+	}}
+	for _, ch := range m.watchers {
+		ch <- event
+	}
+}
+
+// Close 关闭模拟服务器
+func (m *MockEtcdServer) Close() {
+	m.server.CloseClientConnections()
+	m.server.Close()
+}
+
+// TestEtcdAdapter_Basic 测试Etcd适配器基本功能
+func TestEtcdAdapter_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		mock := NewMockEtcdServer()
+		defer mock.Close()
+		mock.Put("config/app/name", "vgo")
+		mock.Put("config/app/port", "8080")
+		mock.Put("config/modules/base/jwt/secret", "s1")
+		mock.Put("config/modules/base/jwt/token", `{"expire":7200}`)
+		mock.Put("other/app/name", "other")
+
+		adapter, err := NewEtcdAdapter(&EtcdAdapterConfig{
+			Endpoints: []string{mock.server.URL},
+			Prefix:    "config",
+		})
+		t.AssertNil(err)
+		defer adapter.Close(context.Background())
+
+		ctx := context.Background()
+		t.Assert(adapter.Available(ctx), true)
+
+		value, err := adapter.Get(ctx, "app.name")
+		t.AssertNil(err)
+		t.Assert(value, "vgo")
+
+		value, err = adapter.Get(ctx, "app.port")
+		t.AssertNil(err)
+		t.Assert(value, 8080)
+
+		value, err = adapter.Get(ctx, "modules.base.jwt")
+		t.AssertNil(err)
+		t.Assert(value.(map[string]interface{})["secret"], "s1")
+		t.Assert(value.(map[string]interface{})["token"].(map[string]interface{})["expire"], 7200)
+
+		value, err = adapter.Get(ctx, "not.exist")
+		t.AssertNil(err)
+		t.AssertNil(value)
+
+		data, err := adapter.Data(ctx)
+		t.AssertNil(err)
+		t.Assert(len(data), 2)
+		t.Assert(data["app"].(map[string]interface{})["name"], "vgo")
+
+		t.AssertNil(adapter.Set(ctx, "app.debug", true))
+		t.Assert(mock.kvData["config/app/debug"], "true")
+		t.AssertNil(adapter.Set(ctx, "app.name", "updated"))
+		t.Assert(mock.kvData["config/app/name"], "updated")
+	})
+}
+
+// TestEtcdAdapter_Auth 测试Etcd认证
+func TestEtcdAdapter_Auth(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		mock := NewMockEtcdServer()
+		defer mock.Close()
+		mock.username, mock.password = "root", "pass"
+		mock.Put("config/app/name", "vgo")
+
+		adapter, err := NewEtcdAdapter(&EtcdAdapterConfig{
+			Endpoints: []string{mock.server.URL},
+			Username:  "root",
+			Password:  "pass",
+		})
+		t.AssertNil(err)
+		ctx := context.Background()
+		value, err := adapter.Get(ctx, "app.name")
+		t.AssertNil(err)
+		t.Assert(value, "vgo")
+
+		// 令牌失效后自动重新认证
+		mock.mutex.Lock()
+		mock.token = "expired"
+		mock.mutex.Unlock()
+		value, err = adapter.Get(ctx, "app.name")
+		t.AssertNil(err)
+		t.Assert(value, "vgo")
+
+		bad, err := NewEtcdAdapter(&EtcdAdapterConfig{
+			Endpoints: []string{mock.server.URL},
+			Username:  "root",
+			Password:  "wrong",
+		})
+		t.AssertNil(err)
+		t.Assert(bad.Available(ctx), false)
+	})
+}
+
+// TestEtcdAdapter_Watch 测试Etcd监听
+func TestEtcdAdapter_Watch(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		mock := NewMockEtcdServer()
+		defer mock.Close()
+		mock.Put("config/app/name", "vgo")
+
+		adapter, err := NewEtcdAdapter(&EtcdAdapterConfig{
+			Endpoints: []string{"127.0.0.1:1", mock.server.URL},
+			Watch:     true,
+		})
+		t.AssertNil(err)
+		defer adapter.Close(context.Background())
+
+		events := make(chan *ConfigEvent, 10)
+		t.AssertNil(adapter.Watch(context.Background(), "app", func(event *ConfigEvent) {
+			events <- event
+		}))
+
+		// 等待watch流建立
+		for i := 0; i < 100; i++ {
+			mock.mutex.Lock()
+			n := len(mock.watchers)
+			mock.mutex.Unlock()
+			if n > 0 {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		mock.Put("config/app/name", "updated")
+		select {
+		case event := <-events:
+			t.Assert(event.Key, "app.name")
+			t.Assert(event.Type, EventTypeUpdate)
+			t.Assert(event.OldValue, "vgo")
+			t.Assert(event.Value, "updated")
+		case <-time.After(2 * time.Second):
+			t.Fatal("配置变更事件超时")
+		}
+
+		value, err := adapter.Get(context.Background(), "app.name")
+		t.AssertNil(err)
+		t.Assert(value, "updated")
+
+		// 不匹配的键不通知
+		mock.Put("config/db/host", "localhost")
+		mock.Delete("config/app/name")
+		select {
+		case event := <-events:
+			t.Assert(event.Key, "app.name")
+			t.Assert(event.Type, EventTypeDelete)
+		case <-time.After(2 * time.Second):
+			t.Fatal("配置删除事件超时")
+		}
+		value, err = adapter.Get(context.Background(), "db.host")
+		t.AssertNil(err)
+		t.Assert(value, "localhost")
+	})
+}
@@ -40,10 +40,11 @@ func NewFileAdapter(config *FileAdapterConfig) (*FileAdapter, error) {
 		return nil, gerror.New("config cannot be nil")
 	}
 	
-	// 设置默认值
 	if config.FileName == "" {
-		config.FileName = "config.yaml"
+		return nil, gerror.New("config file name cannot be empty")
 	}
+
+	// 设置默认值
 	if config.Interval == 0 {
 		config.Interval = time.Second * 3
 	}
@@ -63,10 +64,21 @@ func NewFileAdapter(config *FileAdapterConfig) (*FileAdapter, error) {
 	}
 	
 	// 设置文件路径和名称
+	fileAdapter := cfg.GetAdapter().(*gcfg.AdapterFile)
+	var dir string
 	if config.Path != "" {
-		cfg.GetAdapter().(*gcfg.AdapterFile).SetPath(config.Path)
+		if err := fileAdapter.SetPath(config.Path); err != nil {
+			return nil, err
+		}
+		dir = fileAdapter.GetPaths()[0]
+	}
+	fileAdapter.SetFileName(config.FileName)
+
+	// 开发环境下gcfg还会搜索main包目录, 指定了目录时不能读取其他目录中的同名文件
+	// 文件不存在时适配器不可用, 文件创建后可以读取
+	if filePath, _ := fileAdapter.GetFilePath(config.FileName); filePath != "" && dir != "" && filepath.Dir(filePath) != filepath.Clean(dir) {
+		return nil, gerror.Newf("config file %s not found in %s, found %s", config.FileName, dir, filePath)
 	}
-	cfg.GetAdapter().(*gcfg.AdapterFile).SetFileName(config.FileName)
 	
 	// 启动文件监听
 	if config.Watch {
@@ -171,6 +183,13 @@ func (f *FileAdapter) startWatch() error {
 		return gerror.Newf("config file not found: %s", f.config.FileName)
 	}
 	
+	// 记录当前配置, 作为第一次变化时的比较基准
+	if data, err := f.gcfg.Data(context.Background()); err == nil {
+		for k, v := range data {
+			f.lastData[k] = v
+		}
+	}
+
 	// 监听文件变化
 	_, err := gfsnotify.Add(filePath, func(event *gfsnotify.Event) {
 		if event.IsWrite() || event.IsRename() {
@@ -217,6 +236,10 @@ func (f *FileAdapter) compareAndNotify(oldData, newData map[string]interface{})
 	}
 	f.mutex.RUnlock()
 	
+	// 扁平化数据进行比较
+	oldData = f.flattenData(oldData)
+	newData = f.flattenData(newData)
+
 	// 检查所有配置键的变化
 	allKeys := make(map[string]bool)
 	for k := range oldData {
@@ -278,14 +301,25 @@ func (f *FileAdapter) matchPattern(key, pattern string) bool {
 		parts := strings.Split(pattern, "*")
 		if len(parts) == 2 {
 			prefix, suffix := parts[0], parts[1]
-			return strings.HasPrefix(key, prefix) && strings.HasSuffix(key, suffix)
+			return (strings.HasPrefix(key, prefix) && strings.HasSuffix(key, suffix)) || strings.HasPrefix(prefix, key+".")
 		}
 	}
 	
-	return key == pattern || strings.HasPrefix(key, pattern+".")
+	// 文件变化按扁平化后的叶子键通知, 子级键变化时通知父级键的监听器
+	// 叶子键替换了整个子级结构时也需要通知子级键的监听器
+	return isRelatedKey(key, pattern)
 }
 
 // isEqual 比较两个值是否相等
 func (f *FileAdapter) isEqual(a, b interface{}) bool {
 	return g.NewVar(a).String() == g.NewVar(b).String()
-}
\ No newline at end of file
+}
+
+// flattenData 扁平化数据, 键为点分格式
+func (f *FileAdapter) flattenData(data map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key, value := range data {
+		flattenValue(key, value, result)
+	}
+	return result
+}
@@ -1,17 +1,26 @@
 package vconfig
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
-	
-	"github.com/gogf/gf/v2/container/gvar"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
 	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gfile"
@@ -21,24 +30,30 @@ import (
 // KubecmAdapterConfig Kubecm适配器配置
 type KubecmAdapterConfig struct {
 	// Kubernetes API配置
-	APIServer    string        // API服务器地址
-	Token        string        // 访问令牌
-	TokenFile    string        // 令牌文件路径，默认 /var/run/secrets/kubernetes.io/serviceaccount/token
-	CertFile     string        // 证书文件路径
-	Namespace    string        // 命名空间，默认 default
-	ConfigMap    string        // ConfigMap名称
-	
+	APIServer string // API服务器地址
+	Token     string // 访问令牌
+	TokenFile string // 令牌文件路径，默认 /var/run/secrets/kubernetes.io/serviceaccount/token
+	CertFile  string // CA证书文件路径，集群内默认 /var/run/secrets/kubernetes.io/serviceaccount/ca.crt
+	Namespace string // 命名空间，默认 default
+	ConfigMap string // ConfigMap名称，Set 写入该ConfigMap
+
+	// 合并的配置资源，按顺序合并，后面的覆盖前面的，Secrets 覆盖 ConfigMaps
+	ConfigMaps []string // 额外合并的ConfigMap名称，覆盖 ConfigMap
+	Secrets    []string // 合并的Secret名称，值按base64解码
+
 	// 监听配置
-	Watch        bool          // 是否监听配置变化
-	Interval     time.Duration // 监听间隔，默认 30s
-	Timeout      time.Duration // 请求超时，默认 10s
-	
+	Watch         bool          // 是否监听配置变化，使用watch接口按resourceVersion推送
+	Interval      time.Duration // API服务器不支持watch时的轮询间隔，默认 30s
+	RetryInterval time.Duration // watch断开后的重试间隔，默认 3s
+	WatchTimeout  time.Duration // 单次watch请求的时长，到期后重新发起，默认 5m
+	Timeout       time.Duration // 请求超时，默认 10s
+
 	// 数据格式
-	DataKey      string        // ConfigMap中的数据键，默认 config.yaml
-	Format       string        // 数据格式，支持 yaml, json, properties
+	DataKey string // 资源中的配置文档键，默认 config.yaml
+	Format  string // 配置文档格式，支持 yaml, json, properties, toml
 }
 
-// KubecmConfigMap Kubernetes ConfigMap结构
+// KubecmConfigMap Kubernetes ConfigMap结构, Secret的结构相同, Data中的值为base64编码
 type KubecmConfigMap struct {
 	APIVersion string            `json:"apiVersion"`
 	Kind       string            `json:"kind"`
@@ -55,25 +70,66 @@ type KubecmMetadata struct {
 	Annotations     map[string]string `json:"annotations,omitempty"`
 }
 
+// kubecmWatchEvent watch流中的单条事件
+type kubecmWatchEvent struct {
+	Type   string          `json:"type"` // ADDED MODIFIED DELETED BOOKMARK ERROR
+	Object json.RawMessage `json:"object"`
+}
+
+// kubecmList 资源列表
+type kubecmList struct {
+	Metadata KubecmMetadata     `json:"metadata"`
+	Items    []*KubecmConfigMap `json:"items"`
+}
+
+// kubecmStatus watch流中ERROR事件的对象
+type kubecmStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// kubecmResource 合并的配置资源
+type kubecmResource struct {
+	kind    string                 // configmaps 或 secrets
+	name    string                 // 资源名称
+	version string                 // 资源版本，watch从该版本开始
+	data    map[string]interface{} // 解析后的配置
+}
+
+var (
+	// errKubecmGone 资源版本已过期，需要重新同步
+	errKubecmGone = gerror.New("kubernetes watch resource version expired")
+	// errKubecmWatchUnsupported API服务器不支持watch
+	errKubecmWatchUnsupported = gerror.New("kubernetes api does not support watch")
+)
+
 // KubecmAdapter Kubecm配置适配器
-// 基于Kubernetes API实现ConfigMap配置管理
+// 基于Kubernetes API实现ConfigMap与Secret配置管理
+// 资源中的 DataKey 按 Format 解析为配置文档，其他以 .yaml .json 等扩展名结尾的键按扩展名解析，
+// 其余的键作为点分格式的配置键，如 Secret 中的 database.default.pass
+// 开启监听时每个资源使用一个watch流维护本地快照，Get与Data直接读取快照
 type KubecmAdapter struct {
-	config         *KubecmAdapterConfig
-	client         *http.Client
-	watchers       map[string][]func(*ConfigEvent)
-	mutex          sync.RWMutex
-	lastData       map[string]interface{}
-	lastVersion    string
-	stopCh         chan struct{}
+	config    *KubecmAdapterConfig
+	client    *http.Client // 普通请求
+	stream    *http.Client // watch流请求，超时由上下文控制
+	watchers  map[string][]func(*ConfigEvent)
+	mutex     sync.RWMutex
+	resources []*kubecmResource
+	lastData  map[string]interface{} // 合并后的快照
+	synced    bool                   // 快照是否可用
+	ctx       context.Context
+	cancel    context.CancelFunc
 }
 
+var _ VConfigAdapter = (*KubecmAdapter)(nil)
+
 // NewKubecmAdapter 创建Kubecm配置适配器
 // config: 适配器配置
 func NewKubecmAdapter(config *KubecmAdapterConfig) (*KubecmAdapter, error) {
 	if config == nil {
 		return nil, gerror.New("config cannot be nil")
 	}
-	
+
 	// 设置默认值
 	if config.Namespace == "" {
 		config.Namespace = "default"
@@ -90,33 +146,66 @@ func NewKubecmAdapter(config *KubecmAdapterConfig) (*KubecmAdapter, error) {
 	if config.Interval == 0 {
 		config.Interval = time.Second * 30
 	}
+	if config.RetryInterval == 0 {
+		config.RetryInterval = time.Second * 3
+	}
+	if config.WatchTimeout == 0 {
+		config.WatchTimeout = time.Minute * 5
+	}
 	if config.Timeout == 0 {
 		config.Timeout = time.Second * 10
 	}
-	
+
 	// 自动检测集群内配置
 	if config.APIServer == "" {
 		config.APIServer = "https://kubernetes.default.svc"
 	}
+	config.APIServer = strings.TrimRight(config.APIServer, "/")
 	if config.TokenFile == "" {
 		config.TokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
 	}
-	
+	if config.CertFile == "" && gfile.Exists("/var/run/secrets/kubernetes.io/serviceaccount/ca.crt") {
+		config.CertFile = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.CertFile != "" {
+		pem, err := os.ReadFile(config.CertFile)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "failed to read ca file: %s", config.CertFile)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, gerror.Newf("invalid ca file: %s", config.CertFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	var resources []*kubecmResource
+	for _, name := range append([]string{config.ConfigMap}, config.ConfigMaps...) {
+		resources = append(resources, &kubecmResource{kind: "configmaps", name: name})
+	}
+	for _, name := range config.Secrets {
+		resources = append(resources, &kubecmResource{kind: "secrets", name: name})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	adapter := &KubecmAdapter{
-		config: config,
-		client: &http.Client{
-			Timeout: config.Timeout,
-		},
-		watchers:    make(map[string][]func(*ConfigEvent)),
-		lastData:    make(map[string]interface{}),
-		stopCh:      make(chan struct{}),
-	}
-	
+		config:    config,
+		client:    &http.Client{Transport: transport, Timeout: config.Timeout},
+		stream:    &http.Client{Transport: transport},
+		watchers:  make(map[string][]func(*ConfigEvent)),
+		resources: resources,
+		lastData:  make(map[string]interface{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
 	// 启动配置监听
 	if config.Watch {
 		go adapter.startWatch()
 	}
-	
+
 	return adapter, nil
 }
 
@@ -130,220 +219,274 @@ func (k *KubecmAdapter) Name() string {
 // files: 可选的文件名参数（兼容GoFrame接口）
 func (k *KubecmAdapter) Available(ctx context.Context, files ...string) bool {
 	// 检查是否能访问Kubernetes API
-	url := fmt.Sprintf("%s/api/v1/namespaces/%s/configmaps/%s", 
-		k.config.APIServer, k.config.Namespace, k.config.ConfigMap)
-	
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := k.newRequest(ctx, http.MethodGet, k.resourcePath("configmaps", k.config.ConfigMap), nil, nil)
 	if err != nil {
 		return false
 	}
-	
-	// 设置认证头
-	if err := k.setAuthHeader(req); err != nil {
-		return false
-	}
-	
+
 	resp, err := k.client.Do(req)
 	if err != nil {
 		return false
 	}
 	defer resp.Body.Close()
-	
+
 	return resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound
 }
 
 // Get 获取指定键的配置值
 // ctx: 上下文
 // pattern: 配置键模式
-func (k *KubecmAdapter) Get(ctx context.Context, pattern string) (*gvar.Var, error) {
+func (k *KubecmAdapter) Get(ctx context.Context, pattern string) (any, error) {
 	data, err := k.Data(ctx)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	// 从数据中查找匹配的键
-	value := k.getValueByPattern(data, pattern)
-	return g.NewVar(value), nil
+	return k.getValueByPattern(data, pattern), nil
 }
 
 // Data 获取所有配置数据
+// 开启监听且快照可用时读取快照，否则读取全部资源并合并
 // ctx: 上下文
 func (k *KubecmAdapter) Data(ctx context.Context) (map[string]interface{}, error) {
-	// 获取ConfigMap
-	configMap, err := k.getConfigMap(ctx)
-	if err != nil {
-		return nil, err
-	}
-	
-	if configMap == nil || configMap.Data == nil {
-		return make(map[string]interface{}), nil
-	}
-	
-	// 获取配置数据
-	configData, exists := configMap.Data[k.config.DataKey]
-	if !exists {
-		return make(map[string]interface{}), nil
+	k.mutex.RLock()
+	if k.synced {
+		data := k.lastData
+		k.mutex.RUnlock()
+		return data, nil
 	}
-	
-	// 解析配置数据
-	data, err := k.parseConfigData(configData)
-	if err != nil {
-		return nil, gerror.Wrapf(err, "failed to parse config data")
+	k.mutex.RUnlock()
+
+	var layers []map[string]interface{}
+	for _, resource := range k.resources {
+		object, err := k.getResource(ctx, resource.kind, resource.name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := k.parseResource(resource.kind, object)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "failed to parse %s %s", resource.kind, resource.name)
+		}
+		layers = append(layers, data)
 	}
-	
-	return data, nil
+	return k.mergeLayers(layers), nil
 }
 
-// Set 设置配置值
+// Set 设置配置值，写入 ConfigMap 中的配置文档
 // ctx: 上下文
 // pattern: 配置键模式
 // value: 配置值
 func (k *KubecmAdapter) Set(ctx context.Context, pattern string, value interface{}) error {
+	return k.updateDocument(ctx, func(data map[string]interface{}) {
+		k.setValueByPattern(data, pattern, value)
+	})
+}
+
+// Delete 删除配置值，从 ConfigMap 中的配置文档删除
+// ctx: 上下文
+// pattern: 配置键模式
+func (k *KubecmAdapter) Delete(ctx context.Context, pattern string) error {
+	return k.updateDocument(ctx, func(data map[string]interface{}) {
+		k.deleteValueByPattern(data, pattern)
+	})
+}
+
+// Watch 监听配置变化
+// ctx: 上下文
+// pattern: 配置键模式
+// callback: 回调函数
+func (k *KubecmAdapter) Watch(ctx context.Context, pattern string, callback func(*ConfigEvent)) error {
+	if !k.config.Watch {
+		return gerror.New("kubecm watching is disabled")
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.watchers[pattern] = append(k.watchers[pattern], callback)
+	return nil
+}
+
+// Close 关闭适配器
+// ctx: 上下文
+func (k *KubecmAdapter) Close(ctx context.Context) error {
+	// 停止监听
+	k.cancel()
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	// 清理资源
+	k.watchers = make(map[string][]func(*ConfigEvent))
+	k.lastData = make(map[string]interface{})
+	k.synced = false
+
+	return nil
+}
+
+// updateDocument 读取 ConfigMap 中的配置文档，修改后写回
+// 写回时携带resourceVersion，资源已被修改时返回冲突错误
+func (k *KubecmAdapter) updateDocument(ctx context.Context, modify func(data map[string]interface{})) error {
 	// 获取当前ConfigMap
-	configMap, err := k.getConfigMap(ctx)
+	configMap, err := k.getResource(ctx, "configmaps", k.config.ConfigMap)
 	if err != nil {
 		return err
 	}
-	
+
 	if configMap == nil {
 		return gerror.New("configmap not found")
 	}
-	
+
 	// 获取当前配置数据
 	currentData := make(map[string]interface{})
 	if configData, exists := configMap.Data[k.config.DataKey]; exists {
-		currentData, err = k.parseConfigData(configData)
+		currentData, err = k.parseDocument(k.config.Format, configData)
 		if err != nil {
 			return gerror.Wrapf(err, "failed to parse current config data")
 		}
 	}
-	
-	// 设置新值
-	k.setValueByPattern(currentData, pattern, value)
-	
+
+	modify(currentData)
+
 	// 序列化配置数据
 	newConfigData, err := k.serializeConfigData(currentData)
 	if err != nil {
 		return gerror.Wrapf(err, "failed to serialize config data")
 	}
-	
+
 	// 更新ConfigMap
 	if configMap.Data == nil {
 		configMap.Data = make(map[string]string)
 	}
 	configMap.Data[k.config.DataKey] = newConfigData
-	
+
 	// 提交更新
 	return k.updateConfigMap(ctx, configMap)
 }
 
-// Watch 监听配置变化
-// ctx: 上下文
-// pattern: 配置键模式
-// callback: 回调函数
-func (k *KubecmAdapter) Watch(ctx context.Context, pattern string, callback func(*ConfigEvent)) error {
-	if !k.config.Watch {
-		return gerror.New("kubecm watching is disabled")
+// resourcePath 资源的API路径，name为空时为列表路径
+func (k *KubecmAdapter) resourcePath(kind, name string) string {
+	path := fmt.Sprintf("/api/v1/namespaces/%s/%s", url.PathEscape(k.config.Namespace), kind)
+	if name != "" {
+		path += "/" + url.PathEscape(name)
 	}
-	
-	k.mutex.Lock()
-	defer k.mutex.Unlock()
-	
-	k.watchers[pattern] = append(k.watchers[pattern], callback)
-	return nil
-}
-
-// Close 关闭适配器
-// ctx: 上下文
-func (k *KubecmAdapter) Close(ctx context.Context) error {
-	k.mutex.Lock()
-	defer k.mutex.Unlock()
-	
-	// 停止监听
-	close(k.stopCh)
-	
-	// 清理资源
-	k.watchers = make(map[string][]func(*ConfigEvent))
-	k.lastData = make(map[string]interface{})
-	k.lastVersion = ""
-	
-	return nil
+	return path
 }
 
-// getConfigMap 获取ConfigMap
-func (k *KubecmAdapter) getConfigMap(ctx context.Context) (*KubecmConfigMap, error) {
-	url := fmt.Sprintf("%s/api/v1/namespaces/%s/configmaps/%s", 
-		k.config.APIServer, k.config.Namespace, k.config.ConfigMap)
-	
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// newRequest 创建API请求并设置认证头
+func (k *KubecmAdapter) newRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	reqURL := k.config.APIServer + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
 	if err != nil {
 		return nil, gerror.Wrapf(err, "failed to create request")
 	}
-	
+
 	// 设置认证头
 	if err := k.setAuthHeader(req); err != nil {
 		return nil, err
 	}
-	
+	return req, nil
+}
+
+// getResource 获取ConfigMap或Secret，不存在时返回nil
+func (k *KubecmAdapter) getResource(ctx context.Context, kind, name string) (*KubecmConfigMap, error) {
+	req, err := k.newRequest(ctx, http.MethodGet, k.resourcePath(kind, name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := k.client.Do(req)
 	if err != nil {
-		return nil, gerror.Wrapf(err, "failed to get configmap")
+		return nil, gerror.Wrapf(err, "failed to get %s %s", kind, name)
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode == http.StatusNotFound {
 		return nil, nil
 	}
-	
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, gerror.Newf("kubernetes api returned status %d", resp.StatusCode)
+		return nil, gerror.Newf("kubernetes api returned status %d for %s %s", resp.StatusCode, kind, name)
 	}
-	
-	body, err := io.ReadAll(resp.Body)
+
+	var object KubecmConfigMap
+	if err := json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		return nil, gerror.Wrapf(err, "failed to unmarshal %s %s", kind, name)
+	}
+
+	return &object, nil
+}
+
+// listResource 按名称列出ConfigMap或Secret
+// 返回: 资源(不存在时为nil)、列表的资源版本，watch从该版本开始不会因单个资源的版本过旧而过期
+func (k *KubecmAdapter) listResource(ctx context.Context, kind, name string) (*KubecmConfigMap, string, error) {
+	query := url.Values{}
+	query.Set("fieldSelector", "metadata.name="+name)
+	req, err := k.newRequest(ctx, http.MethodGet, k.resourcePath(kind, ""), query, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := k.client.Do(req)
 	if err != nil {
-		return nil, gerror.Wrapf(err, "failed to read response body")
+		return nil, "", gerror.Wrapf(err, "failed to list %s %s", kind, name)
 	}
-	
-	var configMap KubecmConfigMap
-	if err := json.Unmarshal(body, &configMap); err != nil {
-		return nil, gerror.Wrapf(err, "failed to unmarshal configmap")
+	defer resp.Body.Close()
+
+	// 不支持列出资源时读取单个资源
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		object, err := k.getResource(ctx, kind, name)
+		if err != nil || object == nil {
+			return nil, "", err
+		}
+		return object, object.Metadata.ResourceVersion, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", gerror.Newf("kubernetes api returned status %d for %s %s", resp.StatusCode, kind, name)
+	}
+
+	var list kubecmList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, "", gerror.Wrapf(err, "failed to unmarshal %s list", kind)
+	}
+	for _, item := range list.Items {
+		if item.Metadata.Name == name {
+			return item, list.Metadata.ResourceVersion, nil
+		}
 	}
-	
-	return &configMap, nil
+	return nil, list.Metadata.ResourceVersion, nil
 }
 
 // updateConfigMap 更新ConfigMap
 func (k *KubecmAdapter) updateConfigMap(ctx context.Context, configMap *KubecmConfigMap) error {
-	url := fmt.Sprintf("%s/api/v1/namespaces/%s/configmaps/%s", 
-		k.config.APIServer, k.config.Namespace, k.config.ConfigMap)
-	
 	data, err := json.Marshal(configMap)
 	if err != nil {
 		return gerror.Wrapf(err, "failed to marshal configmap")
 	}
-	
-	req, err := http.NewRequestWithContext(ctx, "PUT", url, strings.NewReader(string(data)))
+
+	req, err := k.newRequest(ctx, http.MethodPut, k.resourcePath("configmaps", k.config.ConfigMap), nil, bytes.NewReader(data))
 	if err != nil {
-		return gerror.Wrapf(err, "failed to create request")
-	}
-	
-	req.Header.Set("Content-Type", "application/json")
-	
-	// 设置认证头
-	if err := k.setAuthHeader(req); err != nil {
 		return err
 	}
-	
+	req.Header.Set("Content-Type", "application/json")
+
 	resp, err := k.client.Do(req)
 	if err != nil {
 		return gerror.Wrapf(err, "failed to update configmap")
 	}
 	defer resp.Body.Close()
-	
+
+	if resp.StatusCode == http.StatusConflict {
+		return gerror.Newf("configmap %s has been modified, please retry", k.config.ConfigMap)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return gerror.Newf("kubernetes api returned status %d", resp.StatusCode)
 	}
-	
+
 	return nil
 }
 
@@ -353,7 +496,7 @@ func (k *KubecmAdapter) setAuthHeader(req *http.Request) error {
 		req.Header.Set("Authorization", "Bearer "+k.config.Token)
 		return nil
 	}
-	
+
 	if k.config.TokenFile != "" && gfile.Exists(k.config.TokenFile) {
 		token := gfile.GetContents(k.config.TokenFile)
 		if token != "" {
@@ -361,117 +504,110 @@ func (k *KubecmAdapter) setAuthHeader(req *http.Request) error {
 			return nil
 		}
 	}
-	
+
 	return gerror.New("no valid authentication token found")
 }
 
-// parseValue 解析字符串值为合适的类型
-func (k *KubecmAdapter) parseValue(value string) interface{} {
-	// 去除引号
-	value = strings.Trim(value, `"'`)
-	
-	// 尝试解析为布尔值
-	if value == "true" {
-		return true
-	}
-	if value == "false" {
-		return false
-	}
-	
-	// 尝试解析为整数
-	if intVal, err := strconv.Atoi(value); err == nil {
-		return intVal
+// parseResource 解析ConfigMap或Secret中的配置
+// DataKey 与带格式扩展名的键按文档解析，其余的键作为点分格式的配置键
+func (k *KubecmAdapter) parseResource(kind string, object *KubecmConfigMap) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if object == nil {
+		return result, nil
 	}
-	
-	// 尝试解析为浮点数
-	if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
-		return floatVal
+
+	// 配置文档按键名排序后合并，点分格式的配置键最后合并
+	keys := make([]string, 0, len(object.Data))
+	for key := range object.Data {
+		keys = append(keys, key)
 	}
-	
-	// 返回原始字符串
-	return value
-}
+	sort.Strings(keys)
 
-// parseConfigData 解析配置数据
-func (k *KubecmAdapter) parseConfigData(data string) (map[string]interface{}, error) {
-	result := make(map[string]interface{})
-	
-	switch strings.ToLower(k.config.Format) {
-	case "json":
-		if err := json.Unmarshal([]byte(data), &result); err != nil {
-			return nil, gerror.Wrapf(err, "failed to parse json data")
-		}
-	case "yaml", "yml":
-		// 简单的YAML解析，支持基本的键值对
-		lines := strings.Split(data, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				value := strings.TrimSpace(parts[1])
-				result[key] = k.parseValue(value)
+	flat := make(map[string]interface{})
+	for _, key := range keys {
+		content := object.Data[key]
+		if kind == "secrets" {
+			decoded, err := base64.StdEncoding.DecodeString(content)
+			if err != nil {
+				return nil, gerror.Wrapf(err, "failed to decode secret key %s", key)
 			}
+			content = string(decoded)
 		}
-	case "properties":
-		// 解析properties格式
-		lines := strings.Split(data, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				value := strings.TrimSpace(parts[1])
-				result[key] = k.parseValue(value)
-			}
+
+		format := ""
+		if key == k.config.DataKey {
+			format = k.config.Format
+		} else if ext := strings.TrimPrefix(filepath.Ext(key), "."); gjson.IsValidDataType(gjson.ContentType(ext)) {
+			format = ext
 		}
-	default:
-		return nil, gerror.Newf("unsupported format: %s", k.config.Format)
+		if format == "" {
+			flat[key] = content
+			continue
+		}
+		data, err := k.parseDocument(format, content)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "failed to parse key %s", key)
+		}
+		result = mergeMap(data, result)
+	}
+	return mergeMap(nestFlatMap(flat), result), nil
+}
+
+// parseDocument 按格式解析配置文档
+func (k *KubecmAdapter) parseDocument(format, content string) (map[string]interface{}, error) {
+	format = strings.ToLower(format)
+	if format == "yml" {
+		format = "yaml"
+	}
+	if !gjson.IsValidDataType(gjson.ContentType(format)) {
+		return nil, gerror.Newf("unsupported format: %s", format)
 	}
-	
-	return result, nil
+	if strings.TrimSpace(content) == "" {
+		return make(map[string]interface{}), nil
+	}
+	j, err := gjson.LoadContentType(gjson.ContentType(format), []byte(content))
+	if err != nil {
+		return nil, gerror.Wrapf(err, "failed to parse %s data", format)
+	}
+	data := j.Map()
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	return data, nil
 }
 
 // serializeConfigData 序列化配置数据
 func (k *KubecmAdapter) serializeConfigData(data map[string]interface{}) (string, error) {
+	j := gjson.New(data)
 	switch strings.ToLower(k.config.Format) {
 	case "json":
-		bytes, err := json.MarshalIndent(data, "", "  ")
-		if err != nil {
-			return "", gerror.Wrapf(err, "failed to marshal json data")
-		}
-		return string(bytes), nil
+		return j.ToJsonIndentString()
 	case "yaml", "yml":
-		// 简单的YAML序列化
-		var lines []string
-		for key, value := range data {
-			lines = append(lines, fmt.Sprintf("%s: %v", key, value))
-		}
-		return strings.Join(lines, "\n"), nil
+		return j.ToYamlString()
 	case "properties":
-		// 序列化为properties格式
-		var lines []string
-		for key, value := range data {
-			lines = append(lines, fmt.Sprintf("%s=%v", key, value))
-		}
-		return strings.Join(lines, "\n"), nil
+		return j.ToPropertiesString()
+	case "toml":
+		return j.ToTomlString()
 	default:
 		return "", gerror.Newf("unsupported format: %s", k.config.Format)
 	}
 }
 
+// mergeLayers 按顺序合并各资源的配置，后面的覆盖前面的
+func (k *KubecmAdapter) mergeLayers(layers []map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, layer := range layers {
+		result = mergeMap(layer, result)
+	}
+	return result
+}
+
 // getValueByPattern 根据模式获取值
 func (k *KubecmAdapter) getValueByPattern(data map[string]interface{}, pattern string) interface{} {
 	// 支持点号分隔的嵌套键
 	parts := strings.Split(pattern, ".")
 	current := data
-	
+
 	for i, part := range parts {
 		if i == len(parts)-1 {
 			// 最后一个部分，返回值
@@ -485,7 +621,7 @@ func (k *KubecmAdapter) getValueByPattern(data map[string]interface{}, pattern s
 			}
 		}
 	}
-	
+
 	return nil
 }
 
@@ -493,76 +629,246 @@ func (k *KubecmAdapter) getValueByPattern(data map[string]interface{}, pattern s
 func (k *KubecmAdapter) setValueByPattern(data map[string]interface{}, pattern string, value interface{}) {
 	parts := strings.Split(pattern, ".")
 	current := data
-	
+
 	for i, part := range parts {
 		if i == len(parts)-1 {
 			// 最后一个部分，设置值
 			current[part] = value
 		} else {
 			// 中间部分，创建嵌套map
-			if _, exists := current[part]; !exists {
-				current[part] = make(map[string]interface{})
-			}
-			if nested, ok := current[part].(map[string]interface{}); ok {
-				current = nested
+			nested, ok := current[part].(map[string]interface{})
+			if !ok {
+				nested = make(map[string]interface{})
+				current[part] = nested
 			}
+			current = nested
+		}
+	}
+}
+
+// deleteValueByPattern 根据模式删除值
+func (k *KubecmAdapter) deleteValueByPattern(data map[string]interface{}, pattern string) {
+	parts := strings.Split(pattern, ".")
+	current := data
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := current[part].(map[string]interface{})
+		if !ok {
+			return
 		}
+		current = nested
 	}
+	delete(current, parts[len(parts)-1])
 }
 
 // startWatch 启动配置监听
+// 首次读取全部资源建立快照，之后每个资源使用一个watch流
 func (k *KubecmAdapter) startWatch() {
-	ticker := time.NewTicker(k.config.Interval)
-	defer ticker.Stop()
-	
-	ctx := context.Background()
-	
-	for {
-		select {
-		case <-k.stopCh:
+	for k.ctx.Err() == nil {
+		err := k.resync(k.ctx, k.resources...)
+		if err == nil {
+			break
+		}
+		g.Log().Warningf(k.ctx, "kubecm sync failed, retry in %s: %v", k.config.RetryInterval, err)
+		if !k.sleep(k.config.RetryInterval) {
 			return
-		case <-ticker.C:
-			k.checkConfigChanges(ctx)
 		}
 	}
+	for _, resource := range k.resources {
+		go k.watchResource(resource)
+	}
 }
 
-// checkConfigChanges 检查配置变化
-func (k *KubecmAdapter) checkConfigChanges(ctx context.Context) {
-	// 获取ConfigMap
-	configMap, err := k.getConfigMap(ctx)
-	if err != nil {
-		g.Log().Errorf(ctx, "failed to get configmap: %v", err)
-		return
+// watchResource 监听单个资源，断开后继续监听，版本过期时重新同步
+func (k *KubecmAdapter) watchResource(resource *kubecmResource) {
+	for k.ctx.Err() == nil {
+		err := k.watchStream(k.ctx, resource)
+		if k.ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err == nil:
+			// watch请求到期，从当前版本继续
+			continue
+		case err == errKubecmGone:
+			g.Log().Debugf(k.ctx, "kubecm %s %s resource version expired, resync", resource.kind, resource.name)
+		case err == errKubecmWatchUnsupported:
+			// 不支持watch时退化为轮询
+			if !k.sleep(k.config.Interval) {
+				return
+			}
+		default:
+			g.Log().Warningf(k.ctx, "kubecm watch %s %s interrupted, retry in %s: %v", resource.kind, resource.name, k.config.RetryInterval, err)
+			if !k.sleep(k.config.RetryInterval) {
+				return
+			}
+		}
+		if err := k.resync(k.ctx, resource); err != nil {
+			g.Log().Warningf(k.ctx, "kubecm resync %s %s failed: %v", resource.kind, resource.name, err)
+		}
 	}
-	
-	if configMap == nil {
-		return
+}
+
+// resync 重新读取资源，与快照比较后通知变化
+func (k *KubecmAdapter) resync(ctx context.Context, resources ...*kubecmResource) error {
+	type result struct {
+		version string
+		data    map[string]interface{}
+	}
+	results := make([]result, len(resources))
+	for i, resource := range resources {
+		object, version, err := k.listResource(ctx, resource.kind, resource.name)
+		if err != nil {
+			return err
+		}
+		data, err := k.parseResource(resource.kind, object)
+		if err != nil {
+			return gerror.Wrapf(err, "failed to parse %s %s", resource.kind, resource.name)
+		}
+		results[i].version = version
+		results[i].data = data
 	}
-	
-	// 检查资源版本是否变化
-	if k.lastVersion == configMap.Metadata.ResourceVersion {
-		return
+
+	k.mutex.Lock()
+	for i, resource := range resources {
+		resource.version = results[i].version
+		resource.data = results[i].data
 	}
-	
-	// 获取新的配置数据
-	newData, err := k.Data(ctx)
+	k.mutex.Unlock()
+	k.rebuild()
+	return nil
+}
+
+// watchStream 建立资源的watch流并处理事件，直到流断开
+func (k *KubecmAdapter) watchStream(ctx context.Context, resource *kubecmResource) error {
+	k.mutex.RLock()
+	version := resource.version
+	k.mutex.RUnlock()
+
+	query := url.Values{}
+	query.Set("watch", "1")
+	query.Set("fieldSelector", "metadata.name="+resource.name)
+	query.Set("allowWatchBookmarks", "true")
+	query.Set("timeoutSeconds", strconv.Itoa(int(k.config.WatchTimeout.Seconds())))
+	if version != "" {
+		query.Set("resourceVersion", version)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, k.config.WatchTimeout+k.config.Timeout)
+	defer cancel()
+	req, err := k.newRequest(ctx, http.MethodGet, k.resourcePath(resource.kind, ""), query, nil)
 	if err != nil {
-		g.Log().Errorf(ctx, "failed to load config data: %v", err)
-		return
+		return err
 	}
-	
+	resp, err := k.stream.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusGone:
+		return errKubecmGone
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return errKubecmWatchUnsupported
+	default:
+		return gerror.Newf("kubernetes api returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		event := &kubecmWatchEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), event); err != nil {
+			return gerror.Wrapf(err, "failed to unmarshal watch event")
+		}
+		if err := k.applyEvent(resource, event); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// applyEvent 将watch事件应用到快照并通知变化
+func (k *KubecmAdapter) applyEvent(resource *kubecmResource, event *kubecmWatchEvent) error {
+	if event.Type == "ERROR" {
+		status := &kubecmStatus{}
+		json.Unmarshal(event.Object, status)
+		if status.Code == http.StatusGone {
+			return errKubecmGone
+		}
+		return gerror.Newf("kubernetes watch error %d: %s", status.Code, status.Message)
+	}
+
+	object := &KubecmConfigMap{}
+	if err := json.Unmarshal(event.Object, object); err != nil {
+		return gerror.Wrapf(err, "failed to unmarshal watch object")
+	}
+	if event.Type == "BOOKMARK" {
+		k.mutex.Lock()
+		resource.version = object.Metadata.ResourceVersion
+		k.mutex.Unlock()
+		return nil
+	}
+
+	var data map[string]interface{}
+	if event.Type == "DELETED" {
+		data = make(map[string]interface{})
+	} else {
+		var err error
+		data, err = k.parseResource(resource.kind, object)
+		if err != nil {
+			// 内容无法解析时保留旧配置，继续监听
+			g.Log().Warningf(k.ctx, "kubecm %s %s: %v", resource.kind, resource.name, err)
+			data = nil
+		}
+	}
+
 	k.mutex.Lock()
-	oldData := k.lastData
-	k.lastData = make(map[string]interface{})
-	for key, value := range newData {
-		k.lastData[key] = value
+	resource.version = object.Metadata.ResourceVersion
+	if data != nil {
+		resource.data = data
 	}
-	k.lastVersion = configMap.Metadata.ResourceVersion
 	k.mutex.Unlock()
-	
-	// 比较配置变化并通知监听器
-	k.compareAndNotify(oldData, newData)
+	if data != nil {
+		k.rebuild()
+	}
+	return nil
+}
+
+// rebuild 重新合并全部资源的快照，首次同步后通知变化
+func (k *KubecmAdapter) rebuild() {
+	k.mutex.Lock()
+	layers := make([]map[string]interface{}, 0, len(k.resources))
+	for _, resource := range k.resources {
+		layers = append(layers, resource.data)
+	}
+	newData := k.mergeLayers(layers)
+	oldData, synced := k.lastData, k.synced
+	k.lastData = newData
+	k.synced = true
+	k.mutex.Unlock()
+
+	// 首次同步不通知
+	if synced {
+		k.compareAndNotify(oldData, newData)
+	}
+}
+
+// sleep 等待指定时间，适配器关闭时返回false
+func (k *KubecmAdapter) sleep(d time.Duration) bool {
+	select {
+	case <-k.ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
 }
 
 // compareAndNotify 比较配置变化并通知监听器
@@ -574,11 +880,11 @@ func (k *KubecmAdapter) compareAndNotify(oldData, newData map[string]interface{}
 		copy(watchers[key], callbacks)
 	}
 	k.mutex.RUnlock()
-	
+
 	// 扁平化数据进行比较
 	oldFlat := k.flattenData(oldData, "")
 	newFlat := k.flattenData(newData, "")
-	
+
 	// 检查所有配置键的变化
 	allKeys := make(map[string]bool)
 	for key := range oldFlat {
@@ -587,11 +893,11 @@ func (k *KubecmAdapter) compareAndNotify(oldData, newData map[string]interface{}
 	for key := range newFlat {
 		allKeys[key] = true
 	}
-	
+
 	for key := range allKeys {
 		oldValue, oldExists := oldFlat[key]
 		newValue, newExists := newFlat[key]
-		
+
 		var eventType EventType
 		if !oldExists && newExists {
 			eventType = EventTypeAdd
@@ -602,14 +908,14 @@ func (k *KubecmAdapter) compareAndNotify(oldData, newData map[string]interface{}
 		} else {
 			continue // 没有变化
 		}
-		
+
 		event := &ConfigEvent{
 			Key:      key,
 			Value:    newValue,
 			OldValue: oldValue,
 			Type:     eventType,
 		}
-		
+
 		// 通知匹配的监听器
 		for pattern, callbacks := range watchers {
 			if k.matchPattern(key, pattern) {
@@ -631,13 +937,13 @@ func (k *KubecmAdapter) compareAndNotify(oldData, newData map[string]interface{}
 // flattenData 扁平化数据
 func (k *KubecmAdapter) flattenData(data map[string]interface{}, prefix string) map[string]interface{} {
 	result := make(map[string]interface{})
-	
+
 	for key, value := range data {
 		fullKey := key
 		if prefix != "" {
 			fullKey = prefix + "." + key
 		}
-		
+
 		if nested, ok := value.(map[string]interface{}); ok {
 			// 递归处理嵌套数据
 			for nestedKey, nestedValue := range k.flattenData(nested, fullKey) {
@@ -647,7 +953,7 @@ func (k *KubecmAdapter) flattenData(data map[string]interface{}, prefix string)
 			result[fullKey] = value
 		}
 	}
-	
+
 	return result
 }
 
@@ -657,7 +963,7 @@ func (k *KubecmAdapter) matchPattern(key, pattern string) bool {
 	if pattern == "*" {
 		return true
 	}
-	
+
 	if strings.Contains(pattern, "*") {
 		// 简单的通配符匹配
 		parts := strings.Split(pattern, "*")
@@ -666,11 +972,12 @@ func (k *KubecmAdapter) matchPattern(key, pattern string) bool {
 			return strings.HasPrefix(key, prefix) && strings.HasSuffix(key, suffix)
 		}
 	}
-	
-	return key == pattern || strings.HasPrefix(key, pattern+".")
+
+	// 父级键变化时也需要通知子级键的监听器
+	return isRelatedKey(key, pattern)
 }
 
 // isEqual 比较两个值是否相等
 func (k *KubecmAdapter) isEqual(a, b interface{}) bool {
 	return gconv.String(a) == gconv.String(b)
-}
\ No newline at end of file
+}
@@ -2,16 +2,20 @@ package vconfig
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/test/gtest"
 )
 
@@ -55,7 +59,7 @@ func TestKubecmAdapter_Basic(t *testing.T) {
 		// 测试获取特定配置
 		value, err := adapter.Get(ctx, "app.name")
 		t.AssertNil(err)
-		t.Assert(value.String(), "test-app")
+		t.Assert(value, "test-app")
 
 		// 关闭适配器
 		err = adapter.Close(ctx)
@@ -132,49 +136,151 @@ database:
 // TestKubecmAdapter_Watch 测试Kubecm适配器监听功能
 func TestKubecmAdapter_Watch(t *testing.T) {
 	gtest.C(t, func(t *gtest.T) {
-		// 创建模拟的Kubernetes API服务器
-		server := createMockKubernetesServerWithWatch(t)
-		defer server.Close()
+		// 创建支持watch的模拟Kubernetes API服务器
+		api := newFakeKubeAPI()
+		defer api.Close()
+		api.Put("configmaps", "test-config", map[string]string{
+			"config.yaml": "app:\n  name: test-app\n",
+		})
 
-		// 创建适配器配置
-		config := &KubecmAdapterConfig{
-			APIServer: server.URL,
+		// 轮询间隔足够长, 变化只能通过watch送达
+		adapter, err := NewKubecmAdapter(&KubecmAdapterConfig{
+			APIServer: api.server.URL,
 			Token:     "test-token",
-			Namespace: "default",
 			ConfigMap: "test-config",
-			DataKey:   "config.yaml",
-			Format:    "yaml",
 			Watch:     true,
-			Interval:  time.Millisecond * 100,
-			Timeout:   time.Second * 5,
-		}
-
-		// 创建适配器
-		adapter, err := NewKubecmAdapter(config)
+			Interval:  time.Hour,
+		})
 		t.AssertNil(err)
+		defer adapter.Close(context.Background())
 
 		ctx := context.Background()
-		eventReceived := make(chan *ConfigEvent, 1)
+		eventReceived := make(chan *ConfigEvent, 10)
 
 		// 设置监听回调
 		err = adapter.Watch(ctx, "app.name", func(event *ConfigEvent) {
 			eventReceived <- event
 		})
 		t.AssertNil(err)
+		api.WaitWatches(1)
+
+		api.Put("configmaps", "test-config", map[string]string{
+			"config.yaml": "app:\n  name: test-app-updated\n",
+		})
 
 		// 等待配置变化事件
 		select {
 		case event := <-eventReceived:
-			t.AssertNE(event, nil)
 			t.Assert(event.Key, "app.name")
-			t.AssertNE(event.Value, nil)
+			t.Assert(event.Type, EventTypeUpdate)
+			t.Assert(event.OldValue, "test-app")
+			t.Assert(event.Value, "test-app-updated")
 		case <-time.After(time.Second * 2):
 			t.Error("未收到配置变化事件")
 		}
+		value, err := adapter.Get(ctx, "app.name")
+		t.AssertNil(err)
+		t.Assert(value, "test-app-updated")
+	})
+}
 
-		// 关闭适配器
-		err = adapter.Close(ctx)
+// TestKubecmAdapter_ResyncOnGone 测试资源版本过期(410)后重新同步
+func TestKubecmAdapter_ResyncOnGone(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		api := newFakeKubeAPI()
+		defer api.Close()
+		api.Put("configmaps", "test-config", map[string]string{"config.yaml": "app:\n  name: a\n"})
+
+		adapter, err := NewKubecmAdapter(&KubecmAdapterConfig{
+			APIServer:     api.server.URL,
+			Token:         "test-token",
+			ConfigMap:     "test-config",
+			Watch:         true,
+			Interval:      time.Hour,
+			RetryInterval: time.Hour,
+		})
 		t.AssertNil(err)
+		defer adapter.Close(context.Background())
+
+		ctx := context.Background()
+		events := make(chan *ConfigEvent, 10)
+		t.AssertNil(adapter.Watch(ctx, "app", func(event *ConfigEvent) {
+			events <- event
+		}))
+		api.WaitWatches(1)
+
+		// 变更在版本过期前发生, watch流只收到410, 需要重新同步才能看到变更
+		api.PutAndExpire("configmaps", "test-config", map[string]string{"config.yaml": "app:\n  name: b\n"})
+		select {
+		case event := <-events:
+			t.Assert(event.Key, "app.name")
+			t.Assert(event.Value, "b")
+		case <-time.After(time.Second * 2):
+			t.Error("未收到重新同步后的配置变化事件")
+		}
+
+		// 重新同步后继续监听
+		api.WaitWatches(2)
+		api.Put("configmaps", "test-config", map[string]string{"config.yaml": "app:\n  name: c\n"})
+		select {
+		case event := <-events:
+			t.Assert(event.Value, "c")
+		case <-time.After(time.Second * 2):
+			t.Error("重新同步后未继续监听")
+		}
+	})
+}
+
+// TestKubecmAdapter_MergeResources 测试合并多个ConfigMap与Secret
+func TestKubecmAdapter_MergeResources(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		api := newFakeKubeAPI()
+		defer api.Close()
+		api.Put("configmaps", "base", map[string]string{
+			"config.yaml":   "app:\n  name: base\n  port: 8001\ndatabase:\n  host: db\n  pass: ''\n",
+			"redis.json":    `{"redis":{"address":"127.0.0.1:6379"}}`,
+			"app.mode":      "dev",
+			"notes.txt.bak": "ignored.format",
+		})
+		api.Put("configmaps", "override", map[string]string{
+			"config.yaml": "app:\n  name: override\n",
+		})
+		api.Put("secrets", "credentials", map[string]string{
+			"database.pass": base64.StdEncoding.EncodeToString([]byte("s3cret")),
+			"jwt.yaml":      base64.StdEncoding.EncodeToString([]byte("jwt:\n  secret: key\n")),
+		})
+
+		adapter, err := NewKubecmAdapter(&KubecmAdapterConfig{
+			APIServer:  api.server.URL,
+			Token:      "test-token",
+			ConfigMap:  "base",
+			ConfigMaps: []string{"override", "missing"},
+			Secrets:    []string{"credentials"},
+		})
+		t.AssertNil(err)
+		defer adapter.Close(context.Background())
+
+		ctx := context.Background()
+		data, err := adapter.Data(ctx)
+		t.AssertNil(err)
+		t.Assert(data["app"], g.Map{"name": "override", "port": 8001, "mode": "dev"})
+		t.Assert(data["database"], g.Map{"host": "db", "pass": "s3cret"})
+		t.Assert(data["redis"], g.Map{"address": "127.0.0.1:6379"})
+		t.Assert(data["jwt"], g.Map{"secret": "key"})
+		t.Assert(data["notes"], g.Map{"txt": g.Map{"bak": "ignored.format"}})
+
+		// Set 写入主ConfigMap的配置文档, 保留其他配置
+		t.AssertNil(adapter.Set(ctx, "app.port", 9000))
+		value, err := adapter.Get(ctx, "app.port")
+		t.AssertNil(err)
+		t.Assert(value, 9000)
+		value, err = adapter.Get(ctx, "database.host")
+		t.AssertNil(err)
+		t.Assert(value, "db")
+		t.AssertNil(adapter.Delete(ctx, "database.host"))
+		value, err = adapter.Get(ctx, "database.host")
+		t.AssertNil(err)
+		t.AssertNil(value)
 	})
 }
 
@@ -247,7 +353,7 @@ func TestKubecmAdapter_Set(t *testing.T) {
 		// 验证设置的值
 		value, err := adapter.Get(ctx, "app.version")
 		t.AssertNil(err)
-		t.Assert(value.String(), "2.0.0")
+		t.Assert(value, "2.0.0")
 
 		// 关闭适配器
 		err = adapter.Close(ctx)
@@ -287,7 +393,7 @@ func TestKubecmAdapter_ConcurrentAccess(t *testing.T) {
 				
 				value, err := adapter.Get(ctx, "app.name")
 				t.AssertNil(err)
-				t.Assert(value.String(), "test-app")
+				t.Assert(value, "test-app")
 			}(i)
 		}
 
@@ -392,53 +498,6 @@ database:
 	}))
 }
 
-// createMockKubernetesServerWithWatch 创建支持监听的模拟Kubernetes API服务器
-func createMockKubernetesServerWithWatch(t *gtest.T) *httptest.Server {
-	version := "12345"
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 验证认证头
-		auth := r.Header.Get("Authorization")
-		if !strings.HasPrefix(auth, "Bearer ") {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-
-		// 模拟ConfigMap响应
-		if r.Method == "GET" && strings.Contains(r.URL.Path, "/api/v1/namespaces/default/configmaps/test-config") {
-			// 模拟配置变化
-			if version == "12345" {
-				version = "12346"
-			}
-
-			configMap := KubecmConfigMap{
-				APIVersion: "v1",
-				Kind:       "ConfigMap",
-				Metadata: KubecmMetadata{
-					Name:            "test-config",
-					Namespace:       "default",
-					ResourceVersion: version,
-				},
-				Data: map[string]string{
-					"config.yaml": fmt.Sprintf(`
-app:
-  name: test-app-updated-%s
-  version: 1.0.1
-database:
-  host: localhost
-  port: 3306
-`, version),
-				},
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(configMap)
-			return
-		}
-
-		w.WriteHeader(http.StatusNotFound)
-	}))
-}
-
 // createMockKubernetesServerWithUpdate 创建支持更新的模拟Kubernetes API服务器
 func createMockKubernetesServerWithUpdate(t *gtest.T) *httptest.Server {
 	configData := map[string]string{
@@ -499,4 +558,182 @@ database:
 
 		w.WriteHeader(http.StatusNotFound)
 	}))
-}
\ No newline at end of file
+}
+// fakeKubeAPI 模拟Kubernetes API服务器
+// 支持ConfigMap与Secret的读取、列表、更新以及watch, 可以模拟资源版本过期
+type fakeKubeAPI struct {
+	server     *httptest.Server
+	mutex      sync.Mutex
+	cond       *sync.Cond
+	version    int
+	goneBefore int // watch请求的版本小于该版本时返回410
+	objects    map[string]*KubecmConfigMap
+	events     []*fakeKubeEvent
+	watches    int // 已建立的watch流数量
+	closed     bool
+}
+
+// fakeKubeEvent 模拟的资源变化
+type fakeKubeEvent struct {
+	path    string // kind/name
+	version int
+	object  *KubecmConfigMap
+}
+
+func newFakeKubeAPI() *fakeKubeAPI {
+	api := &fakeKubeAPI{objects: make(map[string]*KubecmConfigMap), version: 1}
+	api.cond = sync.NewCond(&api.mutex)
+	api.server = httptest.NewServer(http.HandlerFunc(api.handle))
+	return api
+}
+
+// Close 关闭模拟服务器
+func (api *fakeKubeAPI) Close() {
+	api.mutex.Lock()
+	api.closed = true
+	api.cond.Broadcast()
+	api.mutex.Unlock()
+	api.server.Close()
+}
+
+// Put 创建或更新资源, Secret的值需要为base64编码
+func (api *fakeKubeAPI) Put(kind, name string, data map[string]string) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+	api.put(kind, name, data)
+}
+
+// PutAndExpire 更新资源并使之前的资源版本过期
+func (api *fakeKubeAPI) PutAndExpire(kind, name string, data map[string]string) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+	api.put(kind, name, data)
+	api.version++
+	api.goneBefore = api.version
+	api.events = nil
+	api.cond.Broadcast()
+}
+
+// WaitWatches 等待建立指定数量的watch流
+func (api *fakeKubeAPI) WaitWatches(count int) {
+	for i := 0; i < 200; i++ {
+		api.mutex.Lock()
+		watches := api.watches
+		api.mutex.Unlock()
+		if watches >= count {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func (api *fakeKubeAPI) put(kind, name string, data map[string]string) {
+	api.version++
+	object := &KubecmConfigMap{
+		APIVersion: "v1",
+		Kind:       map[string]string{"configmaps": "ConfigMap", "secrets": "Secret"}[kind],
+		Metadata: KubecmMetadata{
+			Name:            name,
+			Namespace:       "default",
+			ResourceVersion: strconv.Itoa(api.version),
+		},
+		Data: data,
+	}
+	api.objects[kind+"/"+name] = object
+	api.events = append(api.events, &fakeKubeEvent{path: kind + "/" + name, version: api.version, object: object})
+	api.cond.Broadcast()
+}
+
+func (api *fakeKubeAPI) handle(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/default/"), "/")
+	kind := parts[0]
+	query := r.URL.Query()
+	name := strings.TrimPrefix(query.Get("fieldSelector"), "metadata.name=")
+	if len(parts) == 2 {
+		name = parts[1]
+	}
+	path := kind + "/" + name
+
+	switch {
+	case r.Method == http.MethodGet && query.Get("watch") == "1":
+		api.watch(w, r, path)
+	case r.Method == http.MethodGet && len(parts) == 1:
+		api.mutex.Lock()
+		list := &kubecmList{Metadata: KubecmMetadata{ResourceVersion: strconv.Itoa(api.version)}}
+		if object, ok := api.objects[path]; ok {
+			list.Items = append(list.Items, object)
+		}
+		api.mutex.Unlock()
+		json.NewEncoder(w).Encode(list)
+	case r.Method == http.MethodGet:
+		api.mutex.Lock()
+		object, ok := api.objects[path]
+		api.mutex.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(object)
+	case r.Method == http.MethodPut:
+		var object KubecmConfigMap
+		if err := json.NewDecoder(r.Body).Decode(&object); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		api.mutex.Lock()
+		defer api.mutex.Unlock()
+		if current, ok := api.objects[path]; !ok || current.Metadata.ResourceVersion != object.Metadata.ResourceVersion {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		api.put(kind, name, object.Data)
+		json.NewEncoder(w).Encode(api.objects[path])
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (api *fakeKubeAPI) watch(w http.ResponseWriter, r *http.Request, path string) {
+	version, _ := strconv.Atoi(r.URL.Query().Get("resourceVersion"))
+	flusher := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-r.Context().Done():
+			api.mutex.Lock()
+			api.cond.Broadcast()
+			api.mutex.Unlock()
+		case <-done:
+		}
+	}()
+
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+	api.watches++
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for !api.closed && r.Context().Err() == nil {
+		if version < api.goneBefore {
+			encoder.Encode(g.Map{
+				"type":   "ERROR",
+				"object": g.Map{"kind": "Status", "code": http.StatusGone, "message": "too old resource version"},
+			})
+			flusher.Flush()
+			return
+		}
+		for _, event := range api.events {
+			if event.path == path && event.version > version {
+				encoder.Encode(g.Map{"type": "MODIFIED", "object": event.object})
+				version = event.version
+			}
+		}
+		flusher.Flush()
+		api.cond.Wait()
+	}
+}
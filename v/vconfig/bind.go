@@ -0,0 +1,147 @@
+package vconfig
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// Defaulter 可选接口, 绑定的配置结构体实现后, 每次加载配置前先设置默认值
+type Defaulter interface {
+	SetDefaults()
+}
+
+// Validator 可选接口, 绑定的配置结构体实现后, 每次加载配置后进行校验, 校验失败的配置不会生效
+type Validator interface {
+	Validate() error
+}
+
+// Binding 类型化的配置绑定
+// 将指定前缀下的配置解析为结构体快照, 配置变化时重新加载并原子替换快照
+type Binding[T any] struct {
+	prefix    string
+	value     atomic.Pointer[T]
+	mutex     sync.Mutex
+	callbacks []func(old, new *T)
+}
+
+// Bind 绑定指定前缀下的配置到结构体 T, 并监听配置变化
+// 通过 RegisterSchema 注册了结构定义的配置节, 每次加载时都会校验
+// prefix: 配置键前缀, 如 "modules.base"
+func Bind[T any](prefix string) (*Binding[T], error) {
+	ctx := context.Background()
+	b := &Binding[T]{prefix: prefix}
+	value, err := b.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.value.Store(value)
+
+	initConfigManager()
+	if configManager != nil {
+		if err := configManager.Watch(ctx, prefix, func(event *ConfigEvent) {
+			if err := b.Reload(ctx); err != nil {
+				g.Log().Errorf(ctx, "配置 %s 重新加载失败, 继续使用旧配置: %v", prefix, err)
+			}
+		}); err != nil {
+			g.Log().Warningf(ctx, "监听配置 %s 失败: %v", prefix, err)
+		}
+	}
+	return b, nil
+}
+
+// MustBind 绑定配置, 失败时panic
+func MustBind[T any](prefix string) *Binding[T] {
+	b, err := Bind[T](prefix)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Prefix 配置键前缀
+func (b *Binding[T]) Prefix() string {
+	return b.prefix
+}
+
+// Get 获取当前的配置快照, 快照为只读, 不要修改
+func (b *Binding[T]) Get() *T {
+	return b.value.Load()
+}
+
+// OnChange 注册配置变化回调, 仅在配置内容发生变化且校验通过后调用
+func (b *Binding[T]) OnChange(callback func(old, new *T)) *Binding[T] {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.callbacks = append(b.callbacks, callback)
+	return b
+}
+
+// Reload 重新加载配置, 校验失败时保留旧配置并返回错误
+func (b *Binding[T]) Reload(ctx context.Context) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	value, err := b.load(ctx)
+	if err != nil {
+		return err
+	}
+	old := b.value.Load()
+	if reflect.DeepEqual(old, value) {
+		return nil
+	}
+	b.value.Store(value)
+	for _, callback := range b.callbacks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					g.Log().Errorf(ctx, "配置 %s 变化回调panic: %v", b.prefix, r)
+				}
+			}()
+			callback(old, value)
+		}()
+	}
+	return nil
+}
+
+// load 加载配置并生成新的快照
+func (b *Binding[T]) load(ctx context.Context) (*T, error) {
+	// 清除配置管理器中的缓存, 确保读取到最新的配置
+	initConfigManager()
+	if configManager != nil {
+		configManager.clearCache(b.prefix)
+	}
+	value := new(T)
+	if d, ok := any(value).(Defaulter); ok {
+		d.SetDefaults()
+	}
+	var data map[string]interface{}
+	if v := GetCfgWithDefault(ctx, b.prefix, nil); v != nil {
+		data = v.Map()
+	}
+	// 按注册的结构定义校验, 未知的配置项仅警告
+	if schema := GetSchema(b.prefix); schema != nil {
+		report := schema.Check(b.prefix, data)
+		for _, warning := range report.Warnings {
+			g.Log().Warning(ctx, warning)
+		}
+		if err := report.Err(); err != nil {
+			return nil, gerror.Wrapf(err, "配置 %s 校验失败", b.prefix)
+		}
+	}
+	if data != nil {
+		if err := gconv.Struct(data, value); err != nil {
+			return nil, gerror.Wrapf(err, "解析配置 %s 失败", b.prefix)
+		}
+	}
+	if v, ok := any(value).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, gerror.Wrapf(err, "配置 %s 校验失败", b.prefix)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,110 @@
+package vconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// memoryAdapter 测试用的内存配置适配器
+type memoryAdapter struct {
+	*AdapterWrapper
+	data *gjson.Json
+}
+
+func newMemoryAdapter(content string) *memoryAdapter {
+	return &memoryAdapter{AdapterWrapper: &AdapterWrapper{name: "memory"}, data: gjson.New(content)}
+}
+
+func (m *memoryAdapter) SetContent(content string) {
+	m.data = gjson.New(content)
+}
+
+func (m *memoryAdapter) Available(ctx context.Context, resource ...string) bool {
+	return true
+}
+
+func (m *memoryAdapter) Get(ctx context.Context, pattern string) (any, error) {
+	return m.data.Get(pattern).Val(), nil
+}
+
+func (m *memoryAdapter) Data(ctx context.Context) (map[string]any, error) {
+	return m.data.Map(), nil
+}
+
+type testBindConfig struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Sub   *struct {
+		Enable bool `json:"enable"`
+		Limit  int  `json:"limit"`
+	} `json:"sub"`
+}
+
+func (c *testBindConfig) SetDefaults() {
+	c.Count = 10
+	c.Sub = &struct {
+		Enable bool `json:"enable"`
+		Limit  int  `json:"limit"`
+	}{Limit: 100}
+}
+
+func (c *testBindConfig) Validate() error {
+	if c.Count <= 0 {
+		return gerror.New("count必须大于0")
+	}
+	return nil
+}
+
+// TestBind 测试类型化配置绑定与重新加载
+func TestBind(t *testing.T) {
+	adapter := newMemoryAdapter(`{"test":{"bind":{"name":"a","sub":{"enable":true}}}}`)
+	initConfigManager()
+	primary := configManager.primary
+	configManager.RegisterAdapter("memory", adapter)
+	configManager.SetPrimary("memory")
+	defer func() {
+		configManager.primary = primary
+	}()
+
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+
+		b, err := Bind[testBindConfig]("test.bind")
+		t.AssertNil(err)
+		t.Assert(b.Get().Name, "a")
+		t.Assert(b.Get().Count, 10)
+		t.Assert(b.Get().Sub.Enable, true)
+		t.Assert(b.Get().Sub.Limit, 100)
+
+		var changes int
+		b.OnChange(func(old, new *testBindConfig) {
+			changes++
+			t.Assert(old.Name, "a")
+			t.Assert(new.Name, "b")
+		})
+
+		// 配置未变化时不触发回调
+		t.AssertNil(b.Reload(ctx))
+		t.Assert(changes, 0)
+
+		first := b.Get()
+		adapter.SetContent(`{"test":{"bind":{"name":"b","count":5}}}`)
+		t.AssertNil(b.Reload(ctx))
+		t.Assert(changes, 1)
+		t.Assert(b.Get().Name, "b")
+		t.Assert(b.Get().Count, 5)
+		t.Assert(b.Get().Sub.Enable, false)
+		// 旧快照不受影响
+		t.Assert(first.Name, "a")
+
+		// 校验失败时保留旧配置
+		adapter.SetContent(`{"test":{"bind":{"name":"c","count":-1}}}`)
+		t.AssertNE(b.Reload(ctx), nil)
+		t.Assert(changes, 1)
+		t.Assert(b.Get().Name, "b")
+	})
+}
@@ -21,6 +21,10 @@ func initConfigManager() {
 	initOnce.Do(func() {
 		configManager = NewConfigManager()
 
+		// 使 g.Cfg() 读取的配置同样支持 ENC(...) 加密值
+		decryptDefaultConfig(context.Background())
+		watchDefaultConfig(context.Background())
+
 		// 注册文件配置适配器作为主配置源
 		fileAdapter, err := NewFileAdapter(&FileAdapterConfig{
 			Path:     "manifest/config",
@@ -32,18 +36,107 @@ func initConfigManager() {
 			configManager.SetPrimary("file")
 		}
 
+		// 注册环境变量与命令行参数配置适配器
+		registerEnvAdapter(fileAdapter)
+
 		// 可以在这里动态注册其他配置源
-		// 例如：consul、kubecm等
+		// 例如：consul、kubecm、etcd等
 		// 这些配置源可以通过环境变量或配置文件进行配置
 	})
 }
 
+// GetManager 获取全局配置管理器
+func GetManager() *ConfigManager {
+	initConfigManager()
+	return configManager
+}
+
+// Explain 说明配置键下每个配置项的生效值、来源和被覆盖的值
+// ctx: 上下文
+// key: 配置键, 为空时说明全部配置
+func Explain(ctx context.Context, key string) ([]*ExplainEntry, error) {
+	return GetManager().Explain(ctx, key)
+}
+
+// registerEnvAdapter 注册环境变量与命令行参数配置适配器
+// 通过配置文件中的 v.config.env 控制是否启用、环境变量前缀以及优先级
+// precedence 为 high(默认) 时环境变量覆盖配置文件, 为 low 时仅在配置文件中不存在时生效
+func registerEnvAdapter(fileAdapter *FileAdapter) {
+	ctx := context.Background()
+	settings := g.NewVar(nil)
+	if fileAdapter != nil {
+		if value, err := fileAdapter.Get(ctx, "v.config.env"); err == nil {
+			settings = g.NewVar(value)
+		}
+	}
+	options := settings.MapStrVar()
+	if enable, ok := options["enable"]; ok && !enable.Bool() {
+		return
+	}
+	envAdapter := NewEnvAdapter(&EnvAdapterConfig{
+		Prefix:     options["prefix"].String(),
+		FlagPrefix: options["flagPrefix"].String(),
+	})
+	configManager.RegisterAdapter("env", envAdapter)
+	if fileAdapter == nil {
+		configManager.SetPrimary("env")
+		return
+	}
+	if options["precedence"].String() == "low" {
+		configManager.SetFallback("env")
+		return
+	}
+	configManager.SetPrimary("env")
+	configManager.SetFallback("file")
+}
+
 // sConfig v框架配置结构体
-// 支持从多种配置源获取配置：file、consul、kubecm等
+// 支持从多种配置源获取配置：file、consul、kubecm、etcd等
 type sConfig struct {
-	AutoMigrate bool  `json:"auto_migrate,omitempty"` // 是否自动创建表
-	Eps         bool  `json:"eps,omitempty"`          // 是否开启eps
-	File        *file `json:"file,omitempty"`         // 文件上传配置
+	AutoMigrate bool      `json:"autoMigrate,omitempty"` // 是否自动创建表
+	Eps         bool      `json:"eps,omitempty"`         // 是否开启eps
+	File        *file     `json:"file,omitempty"`        // 文件上传配置
+	Bus         *bus      `json:"bus,omitempty"`         // 集群消息总线配置
+	Registry    *registry `json:"registry,omitempty"`    // 服务注册配置
+}
+
+// bus 集群消息总线配置结构体
+type bus struct {
+	Mode    string `json:"mode"`    // 驱动 redis pgsql memory none, 为空时配置了Redis则使用redis
+	Channel string `json:"channel"` // 频道名称
+	Group   string `json:"group"`   // pgsql驱动使用的数据库分组
+}
+
+// registry 服务注册配置结构体
+type registry struct {
+	Mode    string           `json:"mode"`    // 驱动 consul none, 为空时不注册
+	Consul  *registryConsul  `json:"consul"`  // consul驱动配置
+	Service *registryService `json:"service"` // 注册的服务实例
+}
+
+// registryConsul consul服务注册配置结构体
+type registryConsul struct {
+	Address string `json:"address"` // Consul地址
+	Token   string `json:"token"`   // 访问令牌
+}
+
+// registryService 注册的服务实例配置结构体
+type registryService struct {
+	Name    string            `json:"name"`    // 服务名称
+	Id      string            `json:"id"`      // 实例ID, 为空时使用 名称-地址-端口
+	Address string            `json:"address"` // 服务地址, 为空时使用内网IP
+	Port    int               `json:"port"`    // 服务端口, 为0时使用HTTP服务监听的端口
+	Tags    []string          `json:"tags"`    // 标签
+	Meta    map[string]string `json:"meta"`    // 元数据
+	Check   *registryCheck    `json:"check"`   // 健康检查
+}
+
+// registryCheck 健康检查配置结构体
+type registryCheck struct {
+	Path            string `json:"path"`            // HTTP检查路径, 为空时检查TCP端口
+	Interval        string `json:"interval"`        // 检查间隔
+	Timeout         string `json:"timeout"`         // 检查超时
+	DeregisterAfter string `json:"deregisterAfter"` // 持续不健康超过该时间后自动注销
 }
 
 // oss OSS相关配置结构体
@@ -58,38 +151,191 @@ type oss struct {
 
 // file 文件上传配置结构体
 type file struct {
-	Mode   string `json:"mode"`          // 模式 local oss
-	Domain string `json:"domain"`        // 域名 http://
-	Oss    *oss   `json:"oss,omitempty"` // OSS配置
-}
-
-// newConfig 创建新的配置实例
-// 支持从多种配置源获取配置：file、consul、kubecm等
-// 返回: 配置实例指针
-func newConfig() *sConfig {
-	var ctx = context.Background()
-	config := &sConfig{
-		AutoMigrate: GetCfgWithDefault(ctx, "v.autoMigrate", gvar.New(false)).Bool(),
-		Eps:         GetCfgWithDefault(ctx, "v.eps", gvar.New(false)).Bool(),
-		File: &file{
-			Mode:   GetCfgWithDefault(ctx, "v.file.mode", gvar.New("none")).String(),
-			Domain: GetCfgWithDefault(ctx, "v.file.domain", gvar.New("http://127.0.0.1:8300")).String(),
-			Oss: &oss{
-				Endpoint:        GetCfgWithDefault(ctx, "v.file.oss.endpoint", gvar.New("127.0.0.1:9000")).String(),
-				AccessKeyID:     GetCfgWithDefault(ctx, "v.file.oss.accessKeyID", gvar.New("")).String(),
-				SecretAccessKey: GetCfgWithDefault(ctx, "v.file.oss.secretAccessKey", gvar.New("")).String(),
-				UseSSL:          GetCfgWithDefault(ctx, "v.file.oss.useSSL", gvar.New(false)).Bool(),
-				BucketName:      GetCfgWithDefault(ctx, "v.file.oss.bucketName", gvar.New("vgo")).String(),
-				Location:        GetCfgWithDefault(ctx, "v.file.oss.location", gvar.New("us-east-1")).String(),
+	Mode      string                  `json:"mode"`                // 模式 local minio oss s3, 也可以是 profiles 中的名称
+	Domain    string                  `json:"domain"`              // 域名 http://
+	Fallback  string                  `json:"fallback"`            // 驱动不可用时使用的驱动, 为空时不回退
+	Oss       *oss                    `json:"oss,omitempty"`       // OSS配置
+	Profiles  map[string]*fileProfile `json:"profiles,omitempty"`  // 命名存储配置, 不同模块或上传场景可以使用不同的存储桶或驱动
+	Presign   *filePresign            `json:"presign,omitempty"`   // 浏览器直传配置
+	Multipart *fileMultipart          `json:"multipart,omitempty"` // 分片上传配置
+	Upload    *fileUpload             `json:"upload,omitempty"`    // 上传校验配置
+	Image     *fileImage              `json:"image,omitempty"`     // 图片处理配置
+	Temporary *fileTemporary          `json:"temporary,omitempty"` // 临时文件配置
+}
+
+// fileProfile 命名存储配置结构体
+type fileProfile struct {
+	Driver          string `json:"driver"`          // 驱动 local minio oss s3
+	Endpoint        string `json:"endpoint"`        // 服务端点, 如 s3.amazonaws.com
+	Region          string `json:"region"`          // 区域
+	BucketName      string `json:"bucketName"`      // 存储桶名称
+	AccessKeyID     string `json:"accessKeyID"`     // 访问密钥ID
+	SecretAccessKey string `json:"secretAccessKey"` // 访问密钥
+	UseSSL          bool   `json:"useSSL"`          // 是否使用SSL
+	PathStyle       bool   `json:"pathStyle"`       // 是否以路径方式访问存储桶, MinIO需要开启
+	Domain          string `json:"domain"`          // 访问地址前缀, 如CDN域名, 为空时使用服务端点
+}
+
+// filePresign 浏览器直传配置结构体, 仅对支持直传的驱动生效
+type filePresign struct {
+	Enable       bool     `json:"enable"`       // 是否开启直传
+	Expire       string   `json:"expire"`       // 凭证有效期
+	MaxSize      int64    `json:"maxSize"`      // 最大文件大小(字节), 为0时不限制
+	ContentTypes []string `json:"contentTypes"` // 允许的文件类型, 以/结尾时按前缀匹配, 为空时不限制
+}
+
+// fileMultipart 分片上传配置结构体
+type fileMultipart struct {
+	PartSize int64  `json:"partSize"` // 分片大小(字节), 对象存储要求不小于5MB
+	MaxSize  int64  `json:"maxSize"`  // 最大文件大小(字节), 为0时不限制
+	Expire   string `json:"expire"`   // 未完成的上传保留时间, 超过后需要重新上传
+}
+
+// fileUpload 上传校验配置结构体, 对所有驱动生效
+type fileUpload struct {
+	Scenes map[string]*fileRule `json:"scenes"` // 按上传场景配置的校验规则, 未指定场景时使用default
+}
+
+// fileRule 上传场景的校验规则
+type fileRule struct {
+	MaxSize      int64    `json:"maxSize"`      // 最大文件大小(字节), 为0时不限制
+	ContentTypes []string `json:"contentTypes"` // 允许的文件类型, 按文件内容识别, 以/结尾时按前缀匹配, 为空时不限制
+	Extensions   []string `json:"extensions"`   // 允许的扩展名, 如 .png, 为空时不限制
+	Profile      string   `json:"profile"`      // 保存使用的命名存储配置, 为空时使用 mode
+}
+
+// fileImage 图片处理配置结构体, 处理通过上传接口上传的jpeg png gif webp图片
+type fileImage struct {
+	Enable     bool             `json:"enable"`           // 是否处理上传的图片
+	StripExif  bool             `json:"stripExif"`        // 是否去除EXIF等元数据, 包含拍摄位置等隐私信息
+	MaxWidth   int              `json:"maxWidth"`         // 原图最大宽度, 超过时等比缩小, 为0时不限制
+	MaxHeight  int              `json:"maxHeight"`        // 原图最大高度, 超过时等比缩小, 为0时不限制
+	Format     string           `json:"format"`           // 原图转换的格式 jpeg | png | webp, 为空时保持原格式
+	Quality    int              `json:"quality"`          // jpeg压缩质量 1-100
+	Thumbnails []string         `json:"thumbnails"`       // 缩略图尺寸, 如 200x200, 文件键为原文件键加 _200x200 后缀
+	Resize     *fileImageResize `json:"resize,omitempty"` // 访问时缩放配置
+}
+
+// fileImageResize 访问时缩放配置结构体, 仅本地驱动支持, 通过参数 w h fit format 缩放图片
+// 访问地址不需要登录, 只允许 sizes 和 qualities 中的参数, sizes 为空时不缩放
+type fileImageResize struct {
+	Enable       bool     `json:"enable"`       // 是否开启
+	CacheDir     string   `json:"cacheDir"`     // 缩放结果的缓存目录
+	CacheTTL     string   `json:"cacheTTL"`     // 缓存文件未被访问超过该时间后删除
+	CacheMaxSize int64    `json:"cacheMaxSize"` // 缓存目录最大字节数, 超过时从最久未访问的文件开始删除, 为0时不限制
+	MaxWidth     int      `json:"maxWidth"`     // 最大宽度
+	MaxHeight    int      `json:"maxHeight"`    // 最大高度
+	Sizes        []string `json:"sizes"`        // 允许的尺寸, 如 200x200, 为空时不缩放
+	Qualities    []int    `json:"qualities"`    // 允许的参数 q, 为空时不能指定压缩质量
+}
+
+// fileTemporary 临时文件配置结构体
+type fileTemporary struct {
+	Enable bool   `json:"enable"` // 是否开启, 开启后上传的文件为临时文件, 被业务数据引用后转为永久文件
+	TTL    string `json:"ttl"`    // 临时文件和存储中孤立文件的保留时间, 超过后由清理任务删除
+	Orphan bool   `json:"orphan"` // 清理任务是否扫描存储中没有元数据记录的孤立文件
+	Prefix string `json:"prefix"` // 扫描存储时的文件键前缀, 开启 orphan 时不能为空
+}
+
+// SetDefaults 设置默认值, 配置源中存在的配置会覆盖默认值
+func (c *sConfig) SetDefaults() {
+	c.AutoMigrate = false
+	c.Eps = false
+	c.File = &file{
+		Mode:     "none",
+		Domain:   "http://127.0.0.1:8300",
+		Fallback: "local",
+		Oss: &oss{
+			Endpoint:   "127.0.0.1:9000",
+			UseSSL:     false,
+			BucketName: "vgo",
+			Location:   "us-east-1",
+		},
+		Presign: &filePresign{
+			Enable:  true,
+			Expire:  "10m",
+			MaxSize: 100 << 20,
+		},
+		Multipart: &fileMultipart{
+			PartSize: 5 << 20,
+			MaxSize:  10 << 30,
+			Expire:   "24h",
+		},
+		Upload: &fileUpload{
+			Scenes: map[string]*fileRule{
+				"default": {
+					MaxSize: 100 << 20,
+					Extensions: []string{
+						".jpg", ".jpeg", ".png", ".gif", ".webp", ".bmp", ".ico",
+						".mp3", ".wav", ".mp4", ".mov", ".webm",
+						".pdf", ".txt", ".csv", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx",
+						".zip", ".rar", ".7z",
+					},
+				},
+				"image": {
+					MaxSize:      10 << 20,
+					ContentTypes: []string{"image/"},
+					Extensions:   []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".bmp", ".ico"},
+				},
+			},
+		},
+		Image: &fileImage{
+			Enable:    true,
+			StripExif: true,
+			Quality:   85,
+			Resize: &fileImageResize{
+				CacheDir:     "./temp/image",
+				CacheTTL:     "168h",
+				CacheMaxSize: 1 << 30,
+				MaxWidth:     2048,
+				MaxHeight:    2048,
+				Qualities:    []int{60, 75, 85},
+			},
+		},
+		Temporary: &fileTemporary{
+			TTL:    "24h",
+			Prefix: "uploads/",
+		},
+	}
+	c.Bus = &bus{
+		Channel: "v:bus",
+		Group:   "default",
+	}
+	c.Registry = &registry{
+		Consul: &registryConsul{
+			Address: "127.0.0.1:8500",
+		},
+		Service: &registryService{
+			Name: "vgo",
+			Check: &registryCheck{
+				Path:            "/health",
+				Interval:        "10s",
+				Timeout:         "3s",
+				DeregisterAfter: "1m",
 			},
 		},
 	}
-	return config
 }
 
-// Config 全局配置实例
-// 支持从多种配置源获取配置：file、consul、kubecm等
-var Config = newConfig()
+// configSchema v节配置的结构定义, 加载时校验
+var configSchema = RegisterSchema("v", newConfigSchema())
+
+// newConfigSchema 创建v节配置的结构定义
+func newConfigSchema() *Schema {
+	// file.mode 和 bus.mode 可由驱动扩展, 不限制可选值
+	schema := SchemaOf[sConfig]()
+	// 配置源相关配置, 在初始化配置管理器时读取
+	schema.Define("config", TypeMap)
+	return schema
+}
+
+// ConfigBinding v节配置的热更新绑定
+// 配置源变化时自动重新加载, 需要读取最新配置时使用 ConfigBinding.Get()
+var ConfigBinding = MustBind[sConfig]("v")
+
+// Config 全局配置实例, 为启动时的配置快照
+// 支持从多种配置源获取配置：file、consul、kubecm、etcd等
+var Config = ConfigBinding.Get()
 
 // GetCfgWithDefault 获取配置值，支持多配置源和默认值
 // ctx: 上下文
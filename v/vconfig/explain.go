@@ -0,0 +1,112 @@
+package vconfig
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// ExplainEntry 配置项来源说明
+type ExplainEntry struct {
+	Key       string             `json:"key"`                 // 配置键
+	Value     interface{}        `json:"value"`               // 生效的值
+	Source    string             `json:"source"`              // 提供生效值的配置源
+	Overrides []*ExplainOverride `json:"overrides,omitempty"` // 被覆盖的低优先级配置源的值, 按优先级从高到低
+}
+
+// ExplainOverride 被覆盖的配置值
+type ExplainOverride struct {
+	Source string      `json:"source"` // 配置源
+	Key    string      `json:"key"`    // 配置键, 被整个替换的子级配置与生效的键不同
+	Value  interface{} `json:"value"`  // 被覆盖的值
+}
+
+// Explain 说明配置键下每个叶子配置项的生效值、来源和被覆盖的值
+// 加密的配置值会脱敏为 ******
+// ctx: 上下文
+// pattern: 配置键模式, 为空时说明全部配置
+// 返回: 按配置键排序的说明列表
+func (m *ConfigManager) Explain(ctx context.Context, pattern string) ([]*ExplainEntry, error) {
+	entries := make(map[string]*ExplainEntry)
+	for _, layer := range m.layers() {
+		if !layer.adapter.Available(ctx) {
+			continue
+		}
+		var (
+			value interface{}
+			err   error
+		)
+		if pattern == "" {
+			value, err = layer.adapter.Data(ctx)
+		} else {
+			value, err = layer.adapter.Get(ctx, pattern)
+		}
+		if err != nil || value == nil {
+			continue
+		}
+		leaves := make(map[string]interface{})
+		flattenValue(pattern, value, leaves)
+		for key, leaf := range leaves {
+			leaf = Redact(leaf)
+			if entry, exists := entries[key]; exists {
+				entry.Overrides = append(entry.Overrides, &ExplainOverride{Source: layer.name, Key: key, Value: leaf})
+				continue
+			}
+			// 高优先级配置源中的父级是叶子值时, 整个子级配置被替换
+			if entry := findParentEntry(entries, key); entry != nil {
+				entry.Overrides = append(entry.Overrides, &ExplainOverride{Source: layer.name, Key: key, Value: leaf})
+				continue
+			}
+			// 高优先级配置源中存在子级配置时, 低优先级的叶子值被替换
+			if hasChildEntry(entries, key) {
+				continue
+			}
+			entries[key] = &ExplainEntry{Key: key, Value: leaf, Source: layer.name}
+		}
+	}
+
+	result := make([]*ExplainEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result, nil
+}
+
+// flattenValue 将嵌套的配置值展开为点分格式的叶子配置项
+func flattenValue(prefix string, value interface{}, leaves map[string]interface{}) {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		leaves[prefix] = value
+		return
+	}
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		flattenValue(key, v, leaves)
+	}
+}
+
+// findParentEntry 查找键的父级说明
+func findParentEntry(entries map[string]*ExplainEntry, key string) *ExplainEntry {
+	for i := strings.LastIndex(key, "."); i > 0; i = strings.LastIndex(key[:i], ".") {
+		if entry, exists := entries[key[:i]]; exists {
+			return entry
+		}
+	}
+	return nil
+}
+
+// hasChildEntry 检查是否存在键的子级说明
+func hasChildEntry(entries map[string]*ExplainEntry, key string) bool {
+	for existing := range entries {
+		if strings.HasPrefix(existing, key+".") {
+			return true
+		}
+	}
+	return false
+}
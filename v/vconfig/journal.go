@@ -0,0 +1,280 @@
+package vconfig
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// DefaultJournalSize 默认保留的配置变更记录数量
+const DefaultJournalSize = 500
+
+// Setter 支持写入配置的适配器
+type Setter interface {
+	Set(ctx context.Context, pattern string, value interface{}) error
+}
+
+// Deleter 支持删除配置的适配器, 未实现时回滚新增的配置会写入nil
+type Deleter interface {
+	Delete(ctx context.Context, pattern string) error
+}
+
+// JournalEntry 配置变更记录
+type JournalEntry struct {
+	Version  int64         `json:"version"`            // 版本号, 递增
+	Time     time.Time     `json:"time"`               // 变更时间
+	Source   string        `json:"source"`             // 配置源
+	Key      string        `json:"key"`                // 配置键
+	Type     string        `json:"type"`               // 变更类型 ADD UPDATE DELETE
+	OldValue interface{}   `json:"oldValue"`           // 变更前的值
+	Value    interface{}   `json:"value"`              // 变更后的值
+	Diff     []*ConfigDiff `json:"diff"`               // 叶子配置项的变化
+	Operator string        `json:"operator"`           // 操作人, 配置源中观察到的外部变化为空
+	External bool          `json:"external"`           // 是否为配置源中观察到的外部变化
+	Rollback int64         `json:"rollback,omitempty"` // 回滚操作回滚到的版本
+}
+
+// ConfigDiff 叶子配置项的变化
+type ConfigDiff struct {
+	Key      string      `json:"key"`
+	OldValue interface{} `json:"oldValue"`
+	Value    interface{} `json:"value"`
+}
+
+// SetJournalSize 设置保留的配置变更记录数量
+func (m *ConfigManager) SetJournalSize(size int) {
+	m.journalMutex.Lock()
+	defer m.journalMutex.Unlock()
+	m.journalSize = size
+	m.trimJournal()
+}
+
+// Set 通过配置源写入配置并记录变更
+// ctx: 上下文
+// source: 配置源名称, 为空时使用主配置源
+// key: 配置键
+// value: 配置值
+// operator: 操作人
+func (m *ConfigManager) Set(ctx context.Context, source, key string, value interface{}, operator string) (*JournalEntry, error) {
+	return m.set(ctx, source, key, value, operator, 0)
+}
+
+// History 获取配置变更记录, 按版本从新到旧
+// key: 配置键, 不为空时只返回该键及其父级、子级的变更
+// limit: 返回数量, 小于等于0时返回全部
+func (m *ConfigManager) History(key string, limit int) []*JournalEntry {
+	m.journalMutex.RLock()
+	defer m.journalMutex.RUnlock()
+
+	var result []*JournalEntry
+	for i := len(m.journal) - 1; i >= 0; i-- {
+		entry := m.journal[i]
+		if key != "" && !isRelatedKey(entry.Key, key) {
+			continue
+		}
+		result = append(result, entry)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+// Rollback 回滚到指定版本
+// 撤销该版本之后的所有变更, 每个配置键写回该版本时的值, 通过产生变更的配置源写入
+// ctx: 上下文
+// version: 回滚到的版本
+// operator: 操作人
+// 返回: 回滚产生的变更记录
+func (m *ConfigManager) Rollback(ctx context.Context, version int64, operator string) ([]*JournalEntry, error) {
+	type target struct {
+		source string
+		key    string
+	}
+	m.journalMutex.RLock()
+	if len(m.journal) > 0 && version < m.journal[0].Version-1 {
+		m.journalMutex.RUnlock()
+		return nil, gerror.Newf("版本 %d 的变更记录已过期, 无法回滚", version)
+	}
+	var (
+		targets []target
+		values  = make(map[target]interface{})
+	)
+	// 每个配置键在该版本时的值为之后第一次变更前的值
+	for _, entry := range m.journal {
+		if entry.Version <= version {
+			continue
+		}
+		t := target{source: entry.Source, key: entry.Key}
+		if _, exists := values[t]; exists {
+			continue
+		}
+		targets = append(targets, t)
+		values[t] = entry.OldValue
+	}
+	m.journalMutex.RUnlock()
+	if len(targets) == 0 {
+		return nil, gerror.Newf("版本 %d 之后没有配置变更", version)
+	}
+
+	var (
+		entries []*JournalEntry
+		errs    []error
+	)
+	for _, t := range targets {
+		entry, err := m.set(ctx, t.source, t.key, values[t], operator, version)
+		if err != nil {
+			errs = append(errs, gerror.Wrapf(err, "回滚 %s 的配置 %s 失败", t.source, t.key))
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(errs) > 0 {
+		return entries, gerror.Newf("%v", errs)
+	}
+	return entries, nil
+}
+
+// set 写入配置并记录变更
+func (m *ConfigManager) set(ctx context.Context, source, key string, value interface{}, operator string, rollback int64) (*JournalEntry, error) {
+	if source == "" {
+		m.mutex.RLock()
+		source = m.primary
+		m.mutex.RUnlock()
+	}
+	adapter, exists := m.Adapter(source)
+	if !exists {
+		return nil, gerror.Newf("adapter '%s' not found", source)
+	}
+	setter, ok := adapter.(Setter)
+	if !ok {
+		return nil, gerror.Newf("adapter '%s' does not support setting", source)
+	}
+
+	oldValue, _ := adapter.Get(ctx, key)
+	var err error
+	if deleter, ok := adapter.(Deleter); ok && value == nil {
+		err = deleter.Delete(ctx, key)
+	} else {
+		err = setter.Set(ctx, key, value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.clearCache(key)
+
+	entry := newJournalEntry(source, key, oldValue, value)
+	entry.Operator = operator
+	entry.Rollback = rollback
+	m.appendJournal(entry)
+	return entry, nil
+}
+
+// watchJournal 监听配置源的外部变化并记录
+func (m *ConfigManager) watchJournal(name string, adapter VConfigAdapter) {
+	ctx := context.Background()
+	err := adapter.Watch(ctx, "*", func(event *ConfigEvent) {
+		m.clearCache(event.Key)
+		m.recordEvent(name, event)
+	})
+	if err != nil {
+		g.Log().Debugf(ctx, "config journal does not watch adapter %s: %v", name, err)
+	}
+}
+
+// recordEvent 记录配置源中观察到的变化, 已通过 Set 记录的变化不重复记录
+func (m *ConfigManager) recordEvent(source string, event *ConfigEvent) {
+	var value interface{}
+	if event.Type != EventTypeDelete {
+		value = event.Value
+	}
+	m.journalMutex.RLock()
+	for i := len(m.journal) - 1; i >= 0; i-- {
+		entry := m.journal[i]
+		if entry.Source == source && entry.Key == event.Key {
+			if !entry.External && isEqualValue(entry.Value, value) {
+				m.journalMutex.RUnlock()
+				return
+			}
+			break
+		}
+	}
+	m.journalMutex.RUnlock()
+
+	entry := newJournalEntry(source, event.Key, event.OldValue, value)
+	entry.External = true
+	m.appendJournal(entry)
+}
+
+// appendJournal 添加变更记录并分配版本号
+func (m *ConfigManager) appendJournal(entry *JournalEntry) {
+	m.journalMutex.Lock()
+	defer m.journalMutex.Unlock()
+	m.version++
+	entry.Version = m.version
+	m.journal = append(m.journal, entry)
+	m.trimJournal()
+}
+
+// trimJournal 删除超出数量的旧记录, 调用方需持有锁
+func (m *ConfigManager) trimJournal() {
+	size := m.journalSize
+	if size <= 0 {
+		size = DefaultJournalSize
+	}
+	if len(m.journal) > size {
+		m.journal = append([]*JournalEntry(nil), m.journal[len(m.journal)-size:]...)
+	}
+}
+
+// newJournalEntry 创建变更记录并计算叶子配置项的变化
+func newJournalEntry(source, key string, oldValue, value interface{}) *JournalEntry {
+	entry := &JournalEntry{
+		Time:     time.Now(),
+		Source:   source,
+		Key:      key,
+		Type:     EventTypeUpdate.String(),
+		OldValue: oldValue,
+		Value:    value,
+	}
+	switch {
+	case oldValue == nil && value != nil:
+		entry.Type = EventTypeAdd.String()
+	case oldValue != nil && value == nil:
+		entry.Type = EventTypeDelete.String()
+	}
+
+	oldLeaves := make(map[string]interface{})
+	newLeaves := make(map[string]interface{})
+	if oldValue != nil {
+		flattenValue(key, oldValue, oldLeaves)
+	}
+	if value != nil {
+		flattenValue(key, value, newLeaves)
+	}
+	for k, v := range newLeaves {
+		if old, ok := oldLeaves[k]; !ok || !isEqualValue(old, v) {
+			entry.Diff = append(entry.Diff, &ConfigDiff{Key: k, OldValue: oldLeaves[k], Value: v})
+		}
+	}
+	for k, v := range oldLeaves {
+		if _, ok := newLeaves[k]; !ok {
+			entry.Diff = append(entry.Diff, &ConfigDiff{Key: k, OldValue: v})
+		}
+	}
+	sort.Slice(entry.Diff, func(i, j int) bool {
+		return entry.Diff[i].Key < entry.Diff[j].Key
+	})
+	return entry
+}
+
+// isEqualValue 比较两个配置值是否相等
+func isEqualValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return g.NewVar(a).String() == g.NewVar(b).String()
+}
@@ -0,0 +1,73 @@
+package vconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// TestConfigManager_Journal 测试配置变更记录与回滚
+func TestConfigManager_Journal(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		env := NewEnvAdapter(&EnvAdapterConfig{
+			Environ: []string{"VGO_APP__NAME=a"},
+			Args:    []string{},
+		})
+		manager := NewConfigManager()
+		t.AssertNil(manager.RegisterAdapter("env", env))
+		t.AssertNil(manager.RegisterAdapter("readonly", newMemoryAdapter(`{}`)))
+		t.AssertNil(manager.SetPrimary("env"))
+
+		_, err := manager.Set(ctx, "readonly", "app.name", "x", "admin")
+		t.AssertNE(err, nil)
+
+		entry, err := manager.Set(ctx, "", "app.name", "b", "admin")
+		t.AssertNil(err)
+		t.Assert(entry.Version, 1)
+		t.Assert(entry.Type, "UPDATE")
+		t.Assert(entry.OldValue, "a")
+		t.Assert(entry.Operator, "admin")
+		value, _ := manager.Get(ctx, "app.name")
+		t.Assert(value, "b")
+
+		_, err = manager.Set(ctx, "env", "app.db", map[string]interface{}{"host": "h", "port": 1}, "admin")
+		t.AssertNil(err)
+		_, err = manager.Set(ctx, "env", "app.name", "c", "admin")
+		t.AssertNil(err)
+
+		// 已记录的写入不重复记录, 外部变化单独记录
+		manager.recordEvent("env", &ConfigEvent{Key: "app.name", Value: "c", OldValue: "b", Type: EventTypeUpdate})
+		manager.recordEvent("env", &ConfigEvent{Key: "app.db", Value: map[string]interface{}{"host": "h2", "port": 1}, OldValue: map[string]interface{}{"host": "h", "port": 1}, Type: EventTypeUpdate})
+
+		history := manager.History("", 0)
+		t.Assert(len(history), 4)
+		t.Assert(history[0].Version, 4)
+		t.Assert(history[0].External, true)
+		t.Assert(len(history[0].Diff), 1)
+		t.Assert(history[0].Diff[0].Key, "app.db.host")
+		t.Assert(history[0].Diff[0].OldValue, "h")
+		t.Assert(history[0].Diff[0].Value, "h2")
+		t.Assert(len(manager.History("app.name", 1)), 1)
+		t.Assert(len(manager.History("app.name", 0)), 2)
+
+		// 回滚到版本1, app.name恢复为b, app.db被删除
+		entries, err := manager.Rollback(ctx, 1, "admin")
+		t.AssertNil(err)
+		t.Assert(len(entries), 2)
+		t.Assert(entries[0].Rollback, 1)
+		value, _ = manager.Get(ctx, "app.name")
+		t.Assert(value, "b")
+		value, _ = manager.Get(ctx, "app.db.host")
+		t.AssertNil(value.Val())
+
+		_, err = manager.Rollback(ctx, 100, "admin")
+		t.AssertNE(err, nil)
+
+		manager.SetJournalSize(2)
+		t.Assert(len(manager.History("", 0)), 2)
+		_, err = manager.Rollback(ctx, 1, "admin")
+		t.AssertNE(err, nil)
+	})
+}
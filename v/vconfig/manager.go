@@ -3,6 +3,8 @@ package vconfig
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"github.com/gogf/gf/v2/container/gvar"
 	"github.com/gogf/gf/v2/errors/gerror"
@@ -11,21 +13,29 @@ import (
 
 // ConfigManager 配置管理器
 // 支持多种配置源的统一管理和配置热更新
+// 多个配置源按优先级从高到低深度合并, 优先级相同时按主配置源、备用配置源的顺序
 type ConfigManager struct {
-	adapters map[string]VConfigAdapter // 配置适配器映射
-	primary  string                    // 主配置源名称
-	fallback []string                  // 备用配置源列表
+	adapters   map[string]VConfigAdapter // 配置适配器映射
+	primary    string                    // 主配置源名称
+	fallback   []string                  // 备用配置源列表
+	priorities map[string]int            // 配置源优先级, 未设置时为0
 	cache    map[string]*gvar.Var      // 配置缓存
 	mutex    sync.RWMutex              // 读写锁
 	watchers map[string][]func(*ConfigEvent) // 配置监听器
+
+	journal      []*JournalEntry // 配置变更记录
+	journalSize  int             // 保留的配置变更记录数量
+	version      int64           // 最新的配置版本
+	journalMutex sync.RWMutex    // 配置变更记录读写锁
 }
 
 // NewConfigManager 创建新的配置管理器
 func NewConfigManager() *ConfigManager {
 	return &ConfigManager{
-		adapters: make(map[string]VConfigAdapter),
-		cache:    make(map[string]*gvar.Var),
-		watchers: make(map[string][]func(*ConfigEvent)),
+		adapters:   make(map[string]VConfigAdapter),
+		priorities: make(map[string]int),
+		cache:      make(map[string]*gvar.Var),
+		watchers:   make(map[string][]func(*ConfigEvent)),
 	}
 }
 
@@ -41,9 +51,11 @@ func (m *ConfigManager) RegisterAdapter(name string, adapter VConfigAdapter) err
 	}
 	
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	
 	m.adapters[name] = adapter
+	m.mutex.Unlock()
+	
+	// 记录配置源中的外部变化
+	m.watchJournal(name, adapter)
 	return nil
 }
 
@@ -77,6 +89,114 @@ func (m *ConfigManager) SetFallback(names ...string) error {
 	return nil
 }
 
+// SetPriority 设置配置源优先级
+// 设置了优先级的配置源即使不是主配置源或备用配置源也会参与合并, 数值越大优先级越高
+// name: 配置源名称
+// priority: 优先级
+func (m *ConfigManager) SetPriority(name string, priority int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	
+	if _, exists := m.adapters[name]; !exists {
+		return gerror.Newf("adapter '%s' not found", name)
+	}
+	
+	m.priorities[name] = priority
+	m.cache = make(map[string]*gvar.Var)
+	return nil
+}
+
+// Sources 按优先级从高到低返回参与合并的配置源名称
+func (m *ConfigManager) Sources() []string {
+	layers := m.layers()
+	names := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		names = append(names, layer.name)
+	}
+	return names
+}
+
+// Adapter 获取指定名称的配置适配器
+// name: 配置源名称
+func (m *ConfigManager) Adapter(name string) (VConfigAdapter, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	adapter, exists := m.adapters[name]
+	return adapter, exists
+}
+
+// AdapterInfo 配置源状态
+type AdapterInfo struct {
+	Name      string `json:"name"`      // 注册名称
+	Type      string `json:"type"`      // 适配器名称
+	Available bool   `json:"available"` // 是否可用
+	Role      string `json:"role"`      // 角色 primary fallback priority, 不参与合并时为空
+	Priority  int    `json:"priority"`  // 优先级
+	Order     int    `json:"order"`     // 合并顺序, 0为最高, 不参与合并时为-1
+	Writable  bool   `json:"writable"`  // 是否支持写入配置
+}
+
+// AdapterInfos 获取所有已注册配置源的状态, 按合并顺序排列, 不参与合并的配置源按名称排在最后
+// ctx: 上下文
+func (m *ConfigManager) AdapterInfos(ctx context.Context) []*AdapterInfo {
+	order := make(map[string]int)
+	for i, layer := range m.layers() {
+		order[layer.name] = i
+	}
+
+	m.mutex.RLock()
+	infos := make([]*AdapterInfo, 0, len(m.adapters))
+	for name, adapter := range m.adapters {
+		info := &AdapterInfo{
+			Name:     name,
+			Type:     adapter.Name(),
+			Priority: m.priorities[name],
+			Order:    -1,
+		}
+		if i, exists := order[name]; exists {
+			info.Order = i
+			switch {
+			case name == m.primary:
+				info.Role = "primary"
+			case containsString(m.fallback, name):
+				info.Role = "fallback"
+			default:
+				info.Role = "priority"
+			}
+		}
+		_, info.Writable = adapter.(Setter)
+		infos = append(infos, info)
+	}
+	m.mutex.RUnlock()
+
+	// 检查可用性可能访问远程配置源, 不持有锁
+	for _, info := range infos {
+		if adapter, exists := m.Adapter(info.Name); exists {
+			info.Available = adapter.Available(ctx)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Order != infos[j].Order {
+			if infos[i].Order < 0 || infos[j].Order < 0 {
+				return infos[j].Order < 0
+			}
+			return infos[i].Order < infos[j].Order
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// containsString 判断字符串切片中是否包含指定字符串
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
 // Get 获取配置值
 // ctx: 上下文
 // pattern: 配置键模式
@@ -89,35 +209,83 @@ func (m *ConfigManager) Get(ctx context.Context, pattern string) (*gvar.Var, err
 	}
 	m.mutex.RUnlock()
 	
-	// 从主配置源获取
-	if m.primary != "" {
-		if adapter, exists := m.adapters[m.primary]; exists && adapter.Available(ctx) {
-			if value, err := adapter.Get(ctx, pattern); err == nil && value != nil {
-				gvarValue := gvar.New(value)
-				if !gvarValue.IsEmpty() {
-					m.setCache(pattern, gvarValue)
-					return gvarValue, nil
-				}
-			}
+	// 按优先级从主配置源和备用配置源获取
+	// 高优先级配置源返回map时, 与低优先级配置源的map深度合并, 避免部分覆盖时丢失其余配置
+	var result interface{}
+	for _, layer := range m.layers() {
+		if !layer.adapter.Available(ctx) {
+			continue
 		}
-	}
-	
-	// 从备用配置源获取
-	for _, name := range m.fallback {
-		if adapter, exists := m.adapters[name]; exists && adapter.Available(ctx) {
-			if value, err := adapter.Get(ctx, pattern); err == nil && value != nil {
-				gvarValue := gvar.New(value)
-				if !gvarValue.IsEmpty() {
-					m.setCache(pattern, gvarValue)
-					return gvarValue, nil
-				}
+		value, err := layer.adapter.Get(ctx, pattern)
+		// 配置源中存在的值即使为false、0或空字符串也会生效, 仅跳过不存在的键和空的map
+		if err != nil || value == nil {
+			continue
+		}
+		if data, ok := value.(map[string]interface{}); ok && len(data) == 0 {
+			continue
+		}
+		if result == nil {
+			result = value
+			if _, ok := value.(map[string]interface{}); !ok {
+				break
 			}
+			continue
+		}
+		if lower, ok := value.(map[string]interface{}); ok {
+			result = mergeMap(result.(map[string]interface{}), lower)
 		}
 	}
+	if result != nil {
+		// 解密 ENC(...) 格式的加密配置
+		gvarValue := gvar.New(decryptValue(ctx, result))
+		m.setCache(pattern, gvarValue)
+		return gvarValue, nil
+	}
 	
 	return g.NewVar(nil), gerror.Newf("config key '%s' not found", pattern)
 }
 
+// configLayer 参与合并的配置源
+type configLayer struct {
+	name     string
+	adapter  VConfigAdapter
+	priority int
+}
+
+// layers 按优先级从高到低返回参与合并的配置源
+func (m *ConfigManager) layers() []configLayer {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	
+	names := make([]string, 0, len(m.adapters))
+	if m.primary != "" {
+		names = append(names, m.primary)
+	}
+	names = append(names, m.fallback...)
+	// 设置了优先级的其他配置源按名称排序后追加, 保证顺序稳定
+	var others []string
+	for name := range m.priorities {
+		others = append(others, name)
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+	
+	layers := make([]configLayer, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		adapter, exists := m.adapters[name]
+		if !exists || seen[name] {
+			continue
+		}
+		seen[name] = true
+		layers = append(layers, configLayer{name: name, adapter: adapter, priority: m.priorities[name]})
+	}
+	sort.SliceStable(layers, func(i, j int) bool {
+		return layers[i].priority > layers[j].priority
+	})
+	return layers
+}
+
 // GetWithDefault 获取配置值，如果不存在则返回默认值
 // ctx: 上下文
 // pattern: 配置键模式
@@ -135,31 +303,16 @@ func (m *ConfigManager) GetWithDefault(ctx context.Context, pattern string, defa
 func (m *ConfigManager) Data(ctx context.Context) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
 	
-	// 从主配置源获取
-	if m.primary != "" {
-		if adapter, exists := m.adapters[m.primary]; exists && adapter.Available(ctx) {
-			if data, err := adapter.Data(ctx); err == nil {
-				for k, v := range data {
-					result[k] = v
-				}
-			}
-		}
-	}
-	
-	// 从备用配置源获取（不覆盖已存在的键）
-	for _, name := range m.fallback {
-		if adapter, exists := m.adapters[name]; exists && adapter.Available(ctx) {
-			if data, err := adapter.Data(ctx); err == nil {
-				for k, v := range data {
-					if _, exists := result[k]; !exists {
-						result[k] = v
-					}
-				}
+	// 按优先级深度合并所有配置源, 高优先级的值覆盖低优先级的值
+	for _, layer := range m.layers() {
+		if layer.adapter.Available(ctx) {
+			if data, err := layer.adapter.Data(ctx); err == nil {
+				result = mergeMap(result, data)
 			}
 		}
 	}
 	
-	return result, nil
+	return decryptValue(ctx, result).(map[string]interface{}), nil
 }
 
 // Watch 监听配置变化
@@ -196,10 +349,20 @@ func (m *ConfigManager) setCache(key string, value *gvar.Var) {
 }
 
 // clearCache 清除缓存
+// 同时清除该键的父级和子级缓存, 如 modules 变化时清除 modules.base.jwt 的缓存
 func (m *ConfigManager) clearCache(key string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	delete(m.cache, key)
+	for cached := range m.cache {
+		if isRelatedKey(cached, key) {
+			delete(m.cache, cached)
+		}
+	}
+}
+
+// isRelatedKey 判断两个配置键是否相同或存在父子关系
+func isRelatedKey(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
 }
 
 // notifyWatchers 通知监听器
@@ -235,6 +398,7 @@ func (m *ConfigManager) Close(ctx context.Context) error {
 	
 	// 清理资源
 	m.adapters = make(map[string]VConfigAdapter)
+	m.priorities = make(map[string]int)
 	m.cache = make(map[string]*gvar.Var)
 	m.watchers = make(map[string][]func(*ConfigEvent))
 	
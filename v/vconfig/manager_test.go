@@ -402,4 +402,68 @@ app:
 		err = manager.Close(ctx)
 		t.AssertNil(err)
 	})
-}
\ No newline at end of file
+}
+// TestConfigManager_Explain 测试多配置源按优先级深度合并及来源说明
+func TestConfigManager_Explain(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		file := newMemoryAdapter(`{"app":{"name":"file","debug":true,"db":{"host":"localhost","port":5432}}}`)
+		remote := newMemoryAdapter(`{"app":{"db":{"host":"remote"},"debug":false}}`)
+		env := newMemoryAdapter(`{"app":{"db":"sqlite"}}`)
+
+		manager := NewConfigManager()
+		t.AssertNil(manager.RegisterAdapter("file", file))
+		t.AssertNil(manager.RegisterAdapter("remote", remote))
+		t.AssertNil(manager.RegisterAdapter("env", env))
+		t.AssertNil(manager.SetPrimary("file"))
+		t.AssertNil(manager.SetPriority("remote", 10))
+		t.AssertNE(manager.SetPriority("missing", 1), nil)
+		t.Assert(manager.Sources(), []string{"remote", "file"})
+
+		// 深度合并, 高优先级的false也会生效
+		value, err := manager.Get(ctx, "app")
+		t.AssertNil(err)
+		t.Assert(value.MapStrVar()["name"], "file")
+		t.Assert(value.MapStrVar()["debug"], false)
+		t.Assert(value.MapStrVar()["db"].MapStrVar()["host"], "remote")
+		t.Assert(value.MapStrVar()["db"].MapStrVar()["port"], 5432)
+
+		entries, err := manager.Explain(ctx, "app")
+		t.AssertNil(err)
+		t.Assert(len(entries), 4)
+		t.Assert(entries[0].Key, "app.db.host")
+		t.Assert(entries[0].Source, "remote")
+		t.Assert(len(entries[0].Overrides), 1)
+		t.Assert(entries[0].Overrides[0].Source, "file")
+		t.Assert(entries[0].Overrides[0].Value, "localhost")
+		t.Assert(entries[1].Key, "app.db.port")
+		t.Assert(entries[1].Source, "file")
+		t.Assert(len(entries[1].Overrides), 0)
+
+		// 最高优先级的叶子值替换整个子级配置
+		t.AssertNil(manager.SetPriority("env", 20))
+		value, err = manager.Get(ctx, "app.db")
+		t.AssertNil(err)
+		t.Assert(value, "sqlite")
+		entries, err = manager.Explain(ctx, "")
+		t.AssertNil(err)
+		t.Assert(entries[0].Key, "app.db")
+		t.Assert(entries[0].Source, "env")
+		t.Assert(len(entries[0].Overrides), 3)
+
+		// 配置源状态按合并顺序排列, 未参与合并的配置源排在最后
+		t.AssertNil(manager.RegisterAdapter("extra", NewEnvAdapter(&EnvAdapterConfig{Environ: []string{}, Args: []string{}})))
+		infos := manager.AdapterInfos(ctx)
+		t.Assert(len(infos), 4)
+		t.Assert(infos[0].Name, "env")
+		t.Assert(infos[0].Role, "priority")
+		t.Assert(infos[0].Priority, 20)
+		t.Assert(infos[2].Name, "file")
+		t.Assert(infos[2].Role, "primary")
+		t.Assert(infos[2].Available, true)
+		t.Assert(infos[2].Writable, false)
+		t.Assert(infos[3].Name, "extra")
+		t.Assert(infos[3].Order, -1)
+		t.Assert(infos[3].Writable, true)
+	})
+}
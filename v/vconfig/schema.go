@@ -0,0 +1,456 @@
+package vconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// FieldType 配置项类型
+type FieldType string
+
+const (
+	TypeAny    FieldType = "any"    // 任意类型, 不校验子级配置
+	TypeString FieldType = "string" // 字符串, 数字也可以作为字符串
+	TypeInt    FieldType = "int"    // 整数
+	TypeFloat  FieldType = "float"  // 数字
+	TypeBool   FieldType = "bool"   // 布尔值
+	TypeMap    FieldType = "map"    // 对象, 未声明的子级配置不会产生警告
+	TypeArray  FieldType = "array"  // 数组
+)
+
+// SchemaField 配置项定义
+type SchemaField struct {
+	Type        FieldType     `json:"type"`                  // 类型
+	Required    bool          `json:"required"`              // 是否必填
+	Enum        []interface{} `json:"enum,omitempty"`        // 可选值
+	Min         *float64      `json:"min,omitempty"`         // 最小值
+	Max         *float64      `json:"max,omitempty"`         // 最大值
+	Description string        `json:"description,omitempty"` // 说明
+}
+
+// Require 设置为必填
+func (f *SchemaField) Require() *SchemaField {
+	f.Required = true
+	return f
+}
+
+// OneOf 设置可选值
+func (f *SchemaField) OneOf(values ...interface{}) *SchemaField {
+	f.Enum = values
+	return f
+}
+
+// Range 设置数值范围
+func (f *SchemaField) Range(min, max float64) *SchemaField {
+	f.Min, f.Max = &min, &max
+	return f
+}
+
+// AtLeast 设置最小值
+func (f *SchemaField) AtLeast(min float64) *SchemaField {
+	f.Min = &min
+	return f
+}
+
+// Describe 设置说明
+func (f *SchemaField) Describe(description string) *SchemaField {
+	f.Description = description
+	return f
+}
+
+// Schema 配置节的结构定义
+// 键为相对于配置节前缀的点分格式配置键
+type Schema struct {
+	Fields map[string]*SchemaField `json:"fields"`
+}
+
+// SchemaReport 配置校验结果
+type SchemaReport struct {
+	Errors   []string `json:"errors"`   // 校验失败的配置项, 存在时配置不会生效
+	Warnings []string `json:"warnings"` // 未知的配置项等警告
+}
+
+// Err 将校验失败的配置项合并为错误, 校验通过时返回nil
+func (r *SchemaReport) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return gerror.New(strings.Join(r.Errors, "; "))
+}
+
+var (
+	schemas      = make(map[string]*Schema)
+	schemasMutex sync.RWMutex
+)
+
+// NewSchema 创建空的配置结构定义
+func NewSchema() *Schema {
+	return &Schema{Fields: make(map[string]*SchemaField)}
+}
+
+// SchemaOf 根据配置结构体的json标签生成配置结构定义, 所有配置项均为可选
+func SchemaOf[T any]() *Schema {
+	s := NewSchema()
+	s.addStruct("", reflect.TypeOf((*T)(nil)).Elem())
+	return s
+}
+
+// RegisterSchema 注册配置节的结构定义
+// 绑定该配置节时每次加载都会校验, 校验失败的配置不会生效
+// prefix: 配置节前缀, 如 "modules.base"
+func RegisterSchema(prefix string, schema *Schema) *Schema {
+	schemasMutex.Lock()
+	defer schemasMutex.Unlock()
+	schemas[prefix] = schema
+	return schema
+}
+
+// GetSchema 获取配置节的结构定义, 未注册时返回nil
+func GetSchema(prefix string) *Schema {
+	schemasMutex.RLock()
+	defer schemasMutex.RUnlock()
+	return schemas[prefix]
+}
+
+// ValidateSchemas 使用当前配置校验所有已注册的配置节, 用于启动时诊断
+// 警告会记录到日志, 返回所有校验失败的配置项
+func ValidateSchemas(ctx context.Context) error {
+	schemasMutex.RLock()
+	prefixes := make([]string, 0, len(schemas))
+	for prefix := range schemas {
+		prefixes = append(prefixes, prefix)
+	}
+	schemasMutex.RUnlock()
+	sort.Strings(prefixes)
+
+	var errs []string
+	for _, prefix := range prefixes {
+		report := checkPrefix(ctx, prefix)
+		if report == nil {
+			continue
+		}
+		errs = append(errs, report.Errors...)
+	}
+	if len(errs) > 0 {
+		return gerror.Newf("配置校验失败: %s", strings.Join(errs, "; "))
+	}
+	g.Log().Debugf(ctx, "配置校验通过, 共 %d 个配置节", len(prefixes))
+	return nil
+}
+
+// checkPrefix 校验配置节并记录警告, 未注册结构定义时返回nil
+func checkPrefix(ctx context.Context, prefix string) *SchemaReport {
+	schema := GetSchema(prefix)
+	if schema == nil {
+		return nil
+	}
+	var data map[string]interface{}
+	if value := GetCfgWithDefault(ctx, prefix, nil); value != nil {
+		data = value.Map()
+	}
+	report := schema.Check(prefix, data)
+	for _, warning := range report.Warnings {
+		g.Log().Warning(ctx, warning)
+	}
+	return report
+}
+
+// Field 获取配置项定义, 不存在时创建类型为 any 的配置项
+// key: 相对于配置节前缀的配置键
+func (s *Schema) Field(key string) *SchemaField {
+	if field, ok := s.Fields[key]; ok {
+		return field
+	}
+	field := &SchemaField{Type: TypeAny}
+	s.Fields[key] = field
+	return field
+}
+
+// Define 定义配置项的类型
+func (s *Schema) Define(key string, fieldType FieldType) *SchemaField {
+	field := s.Field(key)
+	field.Type = fieldType
+	return field
+}
+
+// Check 校验配置数据
+// prefix: 配置节前缀, 用于生成提示信息
+// data: 配置节的数据
+func (s *Schema) Check(prefix string, data map[string]interface{}) *SchemaReport {
+	report := &SchemaReport{}
+	fullKey := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	// 按键排序, 保证提示顺序稳定
+	keys := make([]string, 0, len(s.Fields))
+	for key := range s.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field := s.Fields[key]
+		value, ok := lookupValue(data, key)
+		if !ok || value == nil {
+			if field.Required {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s 不能为空", fullKey(key)))
+			}
+			continue
+		}
+		if message := field.check(value); message != "" {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s %s", fullKey(key), message))
+		}
+	}
+
+	// 检查未知的配置项
+	s.checkUnknown("", data, func(key string) {
+		message := fmt.Sprintf("未知的配置项 %s", fullKey(key))
+		if suggestion := s.suggest(key); suggestion != "" {
+			message += fmt.Sprintf(", 是否为 %s", fullKey(suggestion))
+		}
+		report.Warnings = append(report.Warnings, message)
+	})
+	sort.Strings(report.Warnings)
+	return report
+}
+
+// check 校验配置项的值, 校验通过时返回空字符串
+func (f *SchemaField) check(value interface{}) string {
+	var number float64
+	switch f.Type {
+	case TypeString:
+		switch value.(type) {
+		case map[string]interface{}, []interface{}, bool:
+			return "必须为字符串"
+		}
+	case TypeInt, TypeFloat:
+		switch v := value.(type) {
+		case string, json.Number:
+			parsed, err := strconv.ParseFloat(gconv.String(v), 64)
+			if err != nil {
+				return "必须为数字"
+			}
+			number = parsed
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			number = gconv.Float64(v)
+		default:
+			return "必须为数字"
+		}
+		if f.Type == TypeInt && number != float64(int64(number)) {
+			return "必须为整数"
+		}
+	case TypeBool:
+		switch v := value.(type) {
+		case bool:
+		case string:
+			if v != "true" && v != "false" {
+				return "必须为布尔值"
+			}
+		default:
+			return "必须为布尔值"
+		}
+	case TypeMap:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return "必须为对象"
+		}
+	case TypeArray:
+		if _, ok := value.([]interface{}); !ok {
+			return "必须为数组"
+		}
+	}
+	if len(f.Enum) > 0 {
+		matched := false
+		for _, item := range f.Enum {
+			if gconv.String(item) == gconv.String(value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("必须为 %v 之一, 当前为 %v", f.Enum, value)
+		}
+	}
+	if f.Type == TypeInt || f.Type == TypeFloat {
+		if f.Min != nil && number < *f.Min {
+			return fmt.Sprintf("不能小于 %v, 当前为 %v", *f.Min, value)
+		}
+		if f.Max != nil && number > *f.Max {
+			return fmt.Sprintf("不能大于 %v, 当前为 %v", *f.Max, value)
+		}
+	}
+	return ""
+}
+
+// checkUnknown 递归检查未在结构定义中声明的配置项
+// 配置键的比较忽略大小写和下划线, 与配置解析到结构体时的规则一致
+func (s *Schema) checkUnknown(parent string, data map[string]interface{}, report func(key string)) {
+	for name, value := range data {
+		key := name
+		if parent != "" {
+			key = parent + "." + name
+		}
+		field, known := s.lookupField(key)
+		if !known {
+			if !s.hasChildField(key) {
+				report(key)
+				continue
+			}
+		} else if field.Type == TypeAny || field.Type == TypeMap {
+			continue
+		}
+		if child, ok := value.(map[string]interface{}); ok {
+			s.checkUnknown(key, child, report)
+		}
+	}
+}
+
+// lookupField 忽略大小写和下划线查找配置项定义
+func (s *Schema) lookupField(key string) (*SchemaField, bool) {
+	if field, ok := s.Fields[key]; ok {
+		return field, true
+	}
+	normalized := normalizeEnvKey(key)
+	for name, field := range s.Fields {
+		if normalizeEnvKey(name) == normalized {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+// hasChildField 检查是否声明了子级配置项
+func (s *Schema) hasChildField(key string) bool {
+	normalized := normalizeEnvKey(key) + "."
+	for name := range s.Fields {
+		if strings.HasPrefix(normalizeEnvKey(name), normalized) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest 查找与未知配置键最相近的已声明配置键
+func (s *Schema) suggest(key string) string {
+	var (
+		best     string
+		bestDist = 3 // 编辑距离超过2时不提示
+	)
+	for name := range s.Fields {
+		if strings.Count(name, ".") != strings.Count(key, ".") {
+			continue
+		}
+		if dist := editDistance(strings.ToLower(name), strings.ToLower(key)); dist < bestDist || (dist == bestDist && name < best) {
+			best, bestDist = name, dist
+		}
+	}
+	return best
+}
+
+// addStruct 根据结构体字段生成配置项定义
+func (s *Schema) addStruct(prefix string, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name[:1]) + field.Name[1:]
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			s.addStruct(key, fieldType)
+			continue
+		case reflect.String:
+			s.Define(key, TypeString)
+		case reflect.Bool:
+			s.Define(key, TypeBool)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s.Define(key, TypeInt)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			s.Define(key, TypeInt).AtLeast(0)
+		case reflect.Float32, reflect.Float64:
+			s.Define(key, TypeFloat)
+		case reflect.Slice, reflect.Array:
+			s.Define(key, TypeArray)
+		case reflect.Map:
+			s.Define(key, TypeMap)
+		default:
+			s.Define(key, TypeAny)
+		}
+	}
+}
+
+// lookupValue 忽略大小写和下划线查找点分格式配置键对应的值
+func lookupValue(data map[string]interface{}, key string) (interface{}, bool) {
+	var current interface{} = data
+	for _, part := range strings.Split(key, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, found := m[part]
+		if !found {
+			normalized := normalizeEnvKey(part)
+			for name, item := range m {
+				if normalizeEnvKey(name) == normalized {
+					value, found = item, true
+					break
+				}
+			}
+		}
+		if !found {
+			return nil, false
+		}
+		current = value
+	}
+	return current, true
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,102 @@
+package vconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+type testSchemaConfig struct {
+	Jwt *struct {
+		Secret string `json:"secret"`
+		Token  *struct {
+			Expire        uint `json:"expire"`
+			RefreshExpire uint `json:"refreshExpire"`
+		} `json:"token"`
+	} `json:"jwt"`
+	Mode  string            `json:"mode"`
+	Debug bool              `json:"debug"`
+	Ratio float64           `json:"ratio"`
+	Tags  []string          `json:"tags"`
+	Extra map[string]string `json:"extra"`
+}
+
+// TestSchema_Check 测试配置结构定义校验
+func TestSchema_Check(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		schema := SchemaOf[testSchemaConfig]()
+		schema.Field("jwt.secret").Require()
+		schema.Field("jwt.token.expire").Range(1, 86400)
+		schema.Field("mode").OneOf("local", "oss")
+		t.Assert(schema.Fields["jwt.token.refreshExpire"].Type, TypeInt)
+		t.Assert(schema.Fields["tags"].Type, TypeArray)
+		t.Assert(schema.Fields["extra"].Type, TypeMap)
+
+		report := schema.Check("app", gjson.New(`{
+			"jwt":{"secret":"s","token":{"expire":7200,"refreshExprire":100}},
+			"mode":"local","debug":"true","ratio":"0.5","tags":["a"],
+			"extra":{"any":"value"},"unknown":{"a":1}
+		}`).Map())
+		t.AssertNil(report.Err())
+		t.Assert(report.Warnings, []string{
+			"未知的配置项 app.jwt.token.refreshExprire, 是否为 app.jwt.token.refreshExpire",
+			"未知的配置项 app.unknown",
+		})
+
+		report = schema.Check("app", gjson.New(`{
+			"jwt":{"token":{"expire":0,"refreshExpire":-1}},
+			"mode":"s3","debug":1,"ratio":"x","tags":"a"
+		}`).Map())
+		t.Assert(report.Errors, []string{
+			"app.debug 必须为布尔值",
+			"app.jwt.secret 不能为空",
+			"app.jwt.token.expire 不能小于 1, 当前为 0",
+			"app.jwt.token.refreshExpire 不能小于 0, 当前为 -1",
+			"app.mode 必须为 [local oss] 之一, 当前为 s3",
+			"app.ratio 必须为数字",
+			"app.tags 必须为数组",
+		})
+		t.AssertNE(report.Err(), nil)
+	})
+}
+
+// TestSchema_Bind 测试绑定配置时拒绝校验失败的配置
+func TestSchema_Bind(t *testing.T) {
+	adapter := newMemoryAdapter(`{"test":{"schema":{"name":"a","count":1}}}`)
+	// 使用独立的配置管理器, 避免影响其他测试
+	initConfigManager()
+	global := configManager
+	configManager = NewConfigManager()
+	configManager.RegisterAdapter("memory", adapter)
+	configManager.SetPrimary("memory")
+	defer func() {
+		configManager = global
+		schemasMutex.Lock()
+		delete(schemas, "test.schema")
+		schemasMutex.Unlock()
+	}()
+
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		schema := NewSchema()
+		schema.Define("name", TypeString).Require()
+		schema.Define("count", TypeInt).Range(1, 10)
+		RegisterSchema("test.schema", schema)
+		t.AssertNil(ValidateSchemas(ctx))
+
+		b, err := Bind[testBindConfig]("test.schema")
+		t.AssertNil(err)
+		t.Assert(b.Get().Count, 1)
+
+		adapter.SetContent(`{"test":{"schema":{"name":"b","count":100}}}`)
+		t.AssertNE(b.Reload(ctx), nil)
+		t.Assert(b.Get().Name, "a")
+		t.AssertNE(ValidateSchemas(ctx), nil)
+
+		adapter.SetContent(`{"test":{"schema":{"count":2}}}`)
+		_, err = Bind[testBindConfig]("test.schema")
+		t.AssertNE(err, nil)
+	})
+}
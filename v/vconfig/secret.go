@@ -0,0 +1,453 @@
+package vconfig
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gfsnotify"
+	"github.com/gogf/gf/v2/os/gres"
+)
+
+const (
+	// SecretKeyEnv 本地密钥环境变量, 多个密钥使用逗号分隔, 第一个用于加密
+	SecretKeyEnv = "VCONFIG_SECRET_KEY"
+	// SecretKeyFileEnv 本地密钥文件路径环境变量
+	SecretKeyFileEnv = "VCONFIG_SECRET_KEY_FILE"
+	// DefaultSecretKeyFile 默认的本地密钥文件, 每行一个密钥, 第一行用于加密
+	DefaultSecretKeyFile = "manifest/config/secret.key"
+	// RedactedValue 脱敏后的值
+	RedactedValue = "******"
+)
+
+// encryptedPattern 匹配 ENC(...) 格式的加密值
+var encryptedPattern = regexp.MustCompile(`ENC\(([^()\s]+)\)`)
+
+// KeyProvider 配置加密密钥提供者
+// 本地密钥由 LocalKeyProvider 实现, 接入KMS时实现该接口并通过 SetKeyProvider 设置
+type KeyProvider interface {
+	// Name 返回提供者名称
+	Name() string
+	// Encrypt 加密明文, 返回 ENC(...) 括号内的密文
+	Encrypt(ctx context.Context, plaintext []byte) (string, error)
+	// Decrypt 解密 ENC(...) 括号内的密文
+	Decrypt(ctx context.Context, ciphertext string) ([]byte, error)
+}
+
+var (
+	keyProvider     KeyProvider
+	keyProviderOnce sync.Once
+	keyProviderMu   sync.RWMutex
+	// secretValues 已解密的明文, 用于日志和输出时脱敏
+	secretValues sync.Map
+)
+
+// SetKeyProvider 设置全局密钥提供者
+// provider: 密钥提供者, 为nil时不再解密配置
+func SetKeyProvider(provider KeyProvider) {
+	keyProviderOnce.Do(func() {})
+	keyProviderMu.Lock()
+	defer keyProviderMu.Unlock()
+	keyProvider = provider
+}
+
+// GetKeyProvider 获取全局密钥提供者
+// 未设置时从 VCONFIG_SECRET_KEY、VCONFIG_SECRET_KEY_FILE 或默认密钥文件加载本地密钥, 均不存在时返回nil
+func GetKeyProvider() KeyProvider {
+	keyProviderOnce.Do(func() {
+		provider, err := LoadLocalKeyProvider()
+		if err != nil {
+			g.Log().Warningf(context.Background(), "加载配置密钥失败: %v", err)
+			return
+		}
+		if provider != nil {
+			keyProviderMu.Lock()
+			keyProvider = provider
+			keyProviderMu.Unlock()
+		}
+	})
+	keyProviderMu.RLock()
+	defer keyProviderMu.RUnlock()
+	return keyProvider
+}
+
+// IsEncrypted 检查值是否为 ENC(...) 格式的加密值
+func IsEncrypted(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, "ENC(") && strings.HasSuffix(value, ")")
+}
+
+// Encrypt 使用全局密钥提供者加密明文
+// 返回: ENC(...) 格式的加密值
+func Encrypt(ctx context.Context, plaintext string) (string, error) {
+	provider := GetKeyProvider()
+	if provider == nil {
+		return "", gerror.Newf("未配置加密密钥, 请设置 %s 或 %s", SecretKeyEnv, SecretKeyFileEnv)
+	}
+	ciphertext, err := provider.Encrypt(ctx, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return "ENC(" + ciphertext + ")", nil
+}
+
+// Decrypt 使用全局密钥提供者解密 ENC(...) 格式的加密值, 非加密值原样返回
+func Decrypt(ctx context.Context, value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	if !IsEncrypted(trimmed) {
+		return value, nil
+	}
+	provider := GetKeyProvider()
+	if provider == nil {
+		return "", gerror.Newf("配置中存在加密值, 但未配置解密密钥, 请设置 %s 或 %s", SecretKeyEnv, SecretKeyFileEnv)
+	}
+	plaintext, err := provider.Decrypt(ctx, trimmed[4:len(trimmed)-1])
+	if err != nil {
+		return "", err
+	}
+	if len(plaintext) > 0 {
+		secretValues.Store(string(plaintext), struct{}{})
+	}
+	return string(plaintext), nil
+}
+
+// Reencrypt 使用当前的加密密钥重新加密内容中的所有 ENC(...) 值, 用于密钥轮换
+// content: 配置文件内容
+// 返回: 重新加密后的内容和重新加密的数量
+func Reencrypt(ctx context.Context, content []byte) ([]byte, int, error) {
+	var (
+		count    int
+		firstErr error
+	)
+	result := encryptedPattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		if firstErr != nil {
+			return match
+		}
+		plaintext, err := Decrypt(ctx, string(match))
+		if err != nil {
+			firstErr = err
+			return match
+		}
+		encrypted, err := Encrypt(ctx, plaintext)
+		if err != nil {
+			firstErr = err
+			return match
+		}
+		count++
+		return []byte(encrypted)
+	})
+	if firstErr != nil {
+		return nil, 0, firstErr
+	}
+	return result, count, nil
+}
+
+// Redact 将值中已解密的敏感配置替换为 ******, 用于日志和配置输出
+// 返回新的值, 不修改传入的值
+func Redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if IsEncrypted(v) {
+			return RedactedValue
+		}
+		if _, ok := secretValues.Load(v); ok {
+			return RedactedValue
+		}
+		return v
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = Redact(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = Redact(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// sensitiveKeys 配置键名包含这些内容时视为敏感配置, 比较时忽略大小写、下划线和中划线
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "privatekey", "credential"}
+
+// RedactKey 按配置键脱敏, 在 Redact 的基础上将键名为 password、secret、token 等的配置项替换为 ******
+// 用于输出未加密保存的敏感配置, 返回新的值, 不修改传入的值
+// key: 值对应的配置键, 为空时表示全部配置
+func RedactKey(key string, value interface{}) interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		result := make(map[string]interface{}, len(m))
+		for k, item := range m {
+			childKey := k
+			if key != "" {
+				childKey = key + "." + k
+			}
+			result[k] = RedactKey(childKey, item)
+		}
+		return result
+	}
+	if value != nil && isSensitiveKey(key) {
+		return RedactedValue
+	}
+	return Redact(value)
+}
+
+// isSensitiveKey 判断配置键的最后一段是否为敏感配置
+func isSensitiveKey(key string) bool {
+	name := key[strings.LastIndex(key, ".")+1:]
+	name = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// decryptValue 解密值中所有 ENC(...) 格式的字符串, 解密失败时记录错误并保留原值
+// 返回新的值, 不修改配置源中的数据
+func decryptValue(ctx context.Context, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if !IsEncrypted(v) {
+			return v
+		}
+		plaintext, err := Decrypt(ctx, v)
+		if err != nil {
+			g.Log().Errorf(ctx, "配置解密失败: %v", err)
+			return v
+		}
+		return plaintext
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = decryptValue(ctx, item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = decryptValue(ctx, item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// LocalKeyProvider 本地密钥提供者, 使用AES-256-GCM加密
+// 密文格式为 密钥ID:base64(nonce+密文), 支持同时持有多个密钥以便轮换
+type LocalKeyProvider struct {
+	keys []localKey // 第一个密钥用于加密
+}
+
+// localKey 本地密钥
+type localKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewLocalKeyProvider 创建本地密钥提供者
+// keys: 32字节的密钥, 第一个用于加密, 其余仅用于解密
+func NewLocalKeyProvider(keys ...[]byte) (*LocalKeyProvider, error) {
+	if len(keys) == 0 {
+		return nil, gerror.New("至少需要一个密钥")
+	}
+	provider := &LocalKeyProvider{}
+	for _, key := range keys {
+		if len(key) != 32 {
+			return nil, gerror.Newf("密钥长度必须为32字节, 当前为%d字节", len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(key)
+		provider.keys = append(provider.keys, localKey{id: hex.EncodeToString(sum[:4]), aead: aead})
+	}
+	return provider, nil
+}
+
+// LoadLocalKeyProvider 加载本地密钥提供者
+// 依次读取 VCONFIG_SECRET_KEY、VCONFIG_SECRET_KEY_FILE 指定的文件和默认密钥文件, 均不存在时返回nil
+func LoadLocalKeyProvider() (*LocalKeyProvider, error) {
+	var encoded []string
+	if value := os.Getenv(SecretKeyEnv); value != "" {
+		encoded = strings.Split(value, ",")
+	} else {
+		path := os.Getenv(SecretKeyFileEnv)
+		if path == "" {
+			path = DefaultSecretKeyFile
+			if _, err := os.Stat(path); err != nil {
+				return nil, nil
+			}
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "读取密钥文件 %s 失败", path)
+		}
+		encoded = strings.Split(string(content), "\n")
+	}
+	var keys [][]byte
+	for _, item := range encoded {
+		item = strings.TrimSpace(item)
+		if item == "" || strings.HasPrefix(item, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(item)
+		if err != nil {
+			return nil, gerror.Wrap(err, "密钥必须为base64编码")
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return NewLocalKeyProvider(keys...)
+}
+
+// GenerateKey 生成base64编码的随机密钥
+func GenerateKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Name 返回提供者名称
+func (p *LocalKeyProvider) Name() string {
+	return "local"
+}
+
+// Encrypt 使用第一个密钥加密明文
+func (p *LocalKeyProvider) Encrypt(ctx context.Context, plaintext []byte) (string, error) {
+	key := p.keys[0]
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := key.aead.Seal(nonce, nonce, plaintext, nil)
+	return key.id + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 根据密钥ID选择密钥解密
+func (p *LocalKeyProvider) Decrypt(ctx context.Context, ciphertext string) ([]byte, error) {
+	id, data, ok := strings.Cut(ciphertext, ":")
+	if !ok {
+		return nil, gerror.New("密文格式错误")
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return nil, gerror.Wrap(err, "密文格式错误")
+	}
+	for _, key := range p.keys {
+		if key.id != id {
+			continue
+		}
+		if len(sealed) < key.aead.NonceSize() {
+			return nil, gerror.New("密文格式错误")
+		}
+		nonce := sealed[:key.aead.NonceSize()]
+		plaintext, err := key.aead.Open(nil, nonce, sealed[key.aead.NonceSize():], nil)
+		if err != nil {
+			return nil, gerror.Wrap(err, "解密失败")
+		}
+		return plaintext, nil
+	}
+	return nil, gerror.Newf("未找到ID为 %s 的密钥", id)
+}
+
+// decryptDefaultConfig 将 g.Cfg() 中 ENC(...) 格式的加密值替换为明文
+// 使数据库、Redis等直接读取 g.Cfg() 的组件也能获取到解密后的配置
+// 解密后的内容作为 gcfg 的自定义配置内容, gcfg 清除缓存后重新加载的也是明文
+// g.Cfg() 的适配器保持为 *gcfg.AdapterFile, gins 读取数据库配置时依赖该类型
+func decryptDefaultConfig(ctx context.Context) {
+	adapter, ok := g.Cfg().GetAdapter().(*gcfg.AdapterFile)
+	if !ok {
+		return
+	}
+	content := adapter.GetContent(adapter.GetFileName())
+	if content == "" {
+		filePath, err := adapter.GetFilePath()
+		if err != nil || filePath == "" {
+			return
+		}
+		content = readConfigFile(filePath)
+	}
+	setDecryptedContent(ctx, adapter, content)
+}
+
+// watchDefaultConfig 配置文件变化后重新读取文件并解密
+// gcfg 自身的回调只清除缓存, 重新加载时读取的是此处更新后的明文内容, 无需等待其完成
+func watchDefaultConfig(ctx context.Context) {
+	adapter, ok := g.Cfg().GetAdapter().(*gcfg.AdapterFile)
+	if !ok {
+		return
+	}
+	filePath, err := adapter.GetFilePath()
+	if err != nil || filePath == "" || gres.Contains(filePath) {
+		return
+	}
+	_, err = gfsnotify.Add(filePath, func(event *gfsnotify.Event) {
+		setDecryptedContent(ctx, adapter, readConfigFile(filePath))
+	})
+	if err != nil {
+		g.Log().Warningf(ctx, "监听配置文件 %s 失败: %v", filePath, err)
+	}
+}
+
+// readConfigFile 读取配置文件内容, 支持资源管理器中打包的文件
+func readConfigFile(filePath string) string {
+	if gres.Contains(filePath) {
+		return string(gres.GetContent(filePath))
+	}
+	return gfile.GetContents(filePath)
+}
+
+// setDecryptedContent 解密配置内容并设置为适配器的自定义配置内容
+// 内容中不含加密值且此前未设置过自定义内容时保持读取原文件
+func setDecryptedContent(ctx context.Context, adapter *gcfg.AdapterFile, content string) {
+	if content == "" {
+		return
+	}
+	j, err := gjson.LoadContent([]byte(content), true)
+	if err != nil {
+		g.Log().Errorf(ctx, "解析配置内容失败: %v", err)
+		return
+	}
+	data := j.Map()
+	plaintext := decryptValue(ctx, data)
+	name := adapter.GetFileName()
+	if reflect.DeepEqual(plaintext, data) && adapter.GetContent(name) == "" {
+		return
+	}
+	decrypted, err := gjson.New(plaintext, true).ToJsonString()
+	if err != nil {
+		g.Log().Errorf(ctx, "编码解密后的配置失败: %v", err)
+		return
+	}
+	adapter.SetContent(decrypted, name)
+	adapter.Clear()
+}
@@ -0,0 +1,149 @@
+package vconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// TestSecret 测试加密配置的解密、密钥轮换和脱敏
+func TestSecret(t *testing.T) {
+	oldKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(SecretKeyEnv, oldKey)
+	provider, err := LoadLocalKeyProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetKeyProvider(provider)
+	defer SetKeyProvider(nil)
+
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		encrypted, err := Encrypt(ctx, "db-password")
+		t.AssertNil(err)
+		t.Assert(IsEncrypted(encrypted), true)
+
+		plaintext, err := Decrypt(ctx, encrypted)
+		t.AssertNil(err)
+		t.Assert(plaintext, "db-password")
+		plaintext, err = Decrypt(ctx, "plain")
+		t.AssertNil(err)
+		t.Assert(plaintext, "plain")
+		_, err = Decrypt(ctx, "ENC(unknown:abc)")
+		t.AssertNE(err, nil)
+
+		// 配置管理器透明解密
+		adapter := newMemoryAdapter(`{"database":{"default":{"host":"localhost","pass":"` + encrypted + `"}}}`)
+		manager := NewConfigManager()
+		t.AssertNil(manager.RegisterAdapter("memory", adapter))
+		t.AssertNil(manager.SetPrimary("memory"))
+		value, err := manager.Get(ctx, "database.default.pass")
+		t.AssertNil(err)
+		t.Assert(value, "db-password")
+		value, err = manager.Get(ctx, "database")
+		t.AssertNil(err)
+		t.Assert(value.MapStrVar()["default"].MapStrVar()["pass"], "db-password")
+		data, err := manager.Data(ctx)
+		t.AssertNil(err)
+		t.Assert(data["database"].(map[string]interface{})["default"].(map[string]interface{})["pass"], "db-password")
+		// 配置源中的数据保持加密
+		raw, _ := adapter.Get(ctx, "database.default.pass")
+		t.Assert(raw, encrypted)
+
+		// 脱敏
+		redacted := Redact(data).(map[string]interface{})["database"].(map[string]interface{})["default"].(map[string]interface{})
+		t.Assert(redacted["pass"], RedactedValue)
+		t.Assert(redacted["host"], "localhost")
+		keyRedacted := RedactKey("", map[string]interface{}{
+			"jwt":   map[string]interface{}{"secret": "plain", "expire": 7200},
+			"redis": map[string]interface{}{"db_password": "plain", "address": "127.0.0.1"},
+			"pass":  "db-password",
+		}).(map[string]interface{})
+		t.Assert(keyRedacted["jwt"].(map[string]interface{})["secret"], RedactedValue)
+		t.Assert(keyRedacted["jwt"].(map[string]interface{})["expire"], 7200)
+		t.Assert(keyRedacted["redis"].(map[string]interface{})["db_password"], RedactedValue)
+		t.Assert(keyRedacted["redis"].(map[string]interface{})["address"], "127.0.0.1")
+		t.Assert(keyRedacted["pass"], RedactedValue)
+		t.Assert(RedactKey("modules.base.jwt.token.expire", 7200), 7200)
+		entries, err := manager.Explain(ctx, "database")
+		t.AssertNil(err)
+		t.Assert(entries[1].Key, "database.default.pass")
+		t.Assert(entries[1].Value, RedactedValue)
+
+		// 密钥轮换: 新密钥在前, 旧密钥仅用于解密
+		t.Setenv(SecretKeyEnv, newKey+","+oldKey)
+		rotated, err := LoadLocalKeyProvider()
+		t.AssertNil(err)
+		SetKeyProvider(rotated)
+		content, count, err := Reencrypt(ctx, []byte("pass: \""+encrypted+"\"\nuser: root\n"))
+		t.AssertNil(err)
+		t.Assert(count, 1)
+		t.AssertNE(string(content), "pass: \""+encrypted+"\"\nuser: root\n")
+
+		t.Setenv(SecretKeyEnv, newKey)
+		newOnly, err := LoadLocalKeyProvider()
+		t.AssertNil(err)
+		SetKeyProvider(newOnly)
+		_, err = Decrypt(ctx, encrypted)
+		t.AssertNE(err, nil)
+		match := encryptedPattern.Find(content)
+		plaintext, err = Decrypt(ctx, string(match))
+		t.AssertNil(err)
+		t.Assert(plaintext, "db-password")
+	})
+}
+
+// TestDecryptDefaultConfig 测试 g.Cfg() 中加密值的原地解密
+func TestDecryptDefaultConfig(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(SecretKeyEnv, key)
+	provider, err := LoadLocalKeyProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetKeyProvider(provider)
+	defer SetKeyProvider(nil)
+
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		encrypted, err := Encrypt(ctx, "db-password")
+		t.AssertNil(err)
+		adapter, ok := g.Cfg().GetAdapter().(*gcfg.AdapterFile)
+		t.Assert(ok, true)
+		adapter.SetContent(`{"database":{"default":{"host":"localhost","pass":"`+encrypted+`"}}}`, adapter.GetFileName())
+		adapter.Clear()
+		defer func() {
+			adapter.RemoveContent(adapter.GetFileName())
+			adapter.Clear()
+		}()
+
+		decryptDefaultConfig(ctx)
+		_, ok = g.Cfg().GetAdapter().(*gcfg.AdapterFile)
+		t.Assert(ok, true)
+		t.Assert(g.Cfg().MustGet(ctx, "database.default.pass"), "db-password")
+		t.Assert(g.Cfg().MustGet(ctx, "database.default.host"), "localhost")
+
+		// gcfg 监听到文件变化时只清除缓存, 重新加载后仍为明文
+		adapter.Clear()
+		t.Assert(g.Cfg().MustGet(ctx, "database.default.pass"), "db-password")
+
+		// 文件变化后重新解密新的内容
+		rotated, err := Encrypt(ctx, "new-password")
+		t.AssertNil(err)
+		setDecryptedContent(ctx, adapter, `{"database":{"default":{"host":"localhost","pass":"`+rotated+`"}}}`)
+		t.Assert(g.Cfg().MustGet(ctx, "database.default.pass"), "new-password")
+	})
+}
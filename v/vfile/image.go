@@ -0,0 +1,267 @@
+package vfile
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// maxImagePixels 处理图片的像素上限, 防止解码超大图片耗尽内存
+const maxImagePixels = 50_000_000
+
+// 图片缩放方式
+const (
+	FitContain = "contain" // 等比缩放到尺寸范围内
+	FitCover   = "cover"   // 等比缩放后居中裁剪, 填满尺寸
+	FitFill    = "fill"    // 拉伸到指定尺寸
+)
+
+// ImageOptions 图片处理参数
+type ImageOptions struct {
+	Width   int    // 宽度, 为0时按高度等比缩放
+	Height  int    // 高度, 为0时按宽度等比缩放
+	Fit     string // 缩放方式, 默认 FitContain
+	Format  string // 输出格式 jpeg | png | webp | gif, 为空时保持原格式
+	Quality int    // 压缩质量 1-100, 默认85, 仅对jpeg生效
+}
+
+// ImageEncoder 图片编码器
+type ImageEncoder struct {
+	ContentType string                                                // 文件类型
+	Ext         string                                                // 扩展名
+	Encode      func(w io.Writer, img image.Image, quality int) error // 编码函数
+}
+
+var (
+	imageEncoders = map[string]*ImageEncoder{
+		"jpeg": {ContentType: "image/jpeg", Ext: ".jpg", Encode: encodeJPEG},
+		"png": {ContentType: "image/png", Ext: ".png", Encode: func(w io.Writer, img image.Image, quality int) error {
+			return png.Encode(w, img)
+		}},
+		"gif": {ContentType: "image/gif", Ext: ".gif", Encode: func(w io.Writer, img image.Image, quality int) error {
+			return gif.Encode(w, img, nil)
+		}},
+		"webp": {ContentType: "image/webp", Ext: ".webp", Encode: func(w io.Writer, img image.Image, quality int) error {
+			return EncodeWebP(w, img)
+		}},
+	}
+	imageEncodersMu sync.RWMutex
+)
+
+// RegisterImageEncoder 注册图片编码器, 可替换内置的编码器, 如使用有损WebP编码
+func RegisterImageEncoder(format string, encoder *ImageEncoder) {
+	imageEncodersMu.Lock()
+	defer imageEncodersMu.Unlock()
+	imageEncoders[format] = encoder
+}
+
+// imageEncoder 获取图片编码器
+func imageEncoder(format string) (*ImageEncoder, error) {
+	imageEncodersMu.RLock()
+	defer imageEncodersMu.RUnlock()
+	if encoder, ok := imageEncoders[format]; ok {
+		return encoder, nil
+	}
+	return nil, gerror.Newf("不支持的图片格式: %s", format)
+}
+
+// encodeJPEG 编码JPEG, JPEG不支持透明, 透明部分填充白色
+func encodeJPEG(w io.Writer, img image.Image, quality int) error {
+	if opaque, ok := img.(interface{ Opaque() bool }); !ok || !opaque.Opaque() {
+		background := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+		draw.Draw(background, background.Bounds(), image.White, image.Point{}, draw.Src)
+		draw.Draw(background, background.Bounds(), img, img.Bounds().Min, draw.Over)
+		img = background
+	}
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+// ImageFormat 文件类型对应的图片格式, 不支持处理的类型返回空
+func ImageFormat(contentType string) string {
+	switch baseContentType(contentType) {
+	case "image/jpeg":
+		return "jpeg"
+	case "image/png":
+		return "png"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	}
+	return ""
+}
+
+// ParseImageSize 解析图片尺寸, 如 200x100, 宽或高为0时等比缩放
+func ParseImageSize(size string) (width, height int, err error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(size)), "x")
+	if len(parts) != 2 {
+		return 0, 0, gerror.Newf("图片尺寸格式错误: %s", size)
+	}
+	width, err1 := strconv.Atoi(parts[0])
+	height, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || width < 0 || height < 0 || width+height == 0 {
+		return 0, 0, gerror.Newf("图片尺寸格式错误: %s", size)
+	}
+	return width, height, nil
+}
+
+// ThumbnailKey 缩略图的文件键, 如 uploads/20060102/name_200x200.jpg
+func ThumbnailKey(key string, width, height int, ext string) string {
+	return replaceExt(key, "_"+strconv.Itoa(width)+"x"+strconv.Itoa(height)+ext)
+}
+
+// DecodeImage 解码图片并按EXIF方向旋转, 超过像素上限时返回错误
+func DecodeImage(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", gerror.Wrap(err, "图片解码失败")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, "", gerror.Newf("图片尺寸过大: %dx%d", config.Width, config.Height)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", gerror.Wrap(err, "图片解码失败")
+	}
+	if format == "jpeg" {
+		img = orientImage(img, jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+// ProcessImage 缩放图片并转换格式, 返回输出的文件类型
+// 重新编码的图片不包含EXIF等元数据
+func ProcessImage(w io.Writer, r io.Reader, options *ImageOptions) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	img, format, err := DecodeImage(data)
+	if err != nil {
+		return "", err
+	}
+	return EncodeImage(w, ResizeImage(img, options), format, options)
+}
+
+// EncodeImage 按参数编码图片, 格式为空时使用 format
+func EncodeImage(w io.Writer, img image.Image, format string, options *ImageOptions) (string, error) {
+	quality := 85
+	if options != nil {
+		if options.Format != "" {
+			format = options.Format
+		}
+		if options.Quality > 0 && options.Quality <= 100 {
+			quality = options.Quality
+		}
+	}
+	encoder, err := imageEncoder(format)
+	if err != nil {
+		return "", err
+	}
+	if err = encoder.Encode(w, img, quality); err != nil {
+		return "", err
+	}
+	return encoder.ContentType, nil
+}
+
+// ResizeImage 按参数缩放图片, 不放大小于目标尺寸的图片
+func ResizeImage(img image.Image, options *ImageOptions) image.Image {
+	if options == nil || (options.Width <= 0 && options.Height <= 0) {
+		return img
+	}
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	width, height := options.Width, options.Height
+	if width <= 0 {
+		width = srcW * height / srcH
+	}
+	if height <= 0 {
+		height = srcH * width / srcW
+	}
+	src := bounds
+	switch options.Fit {
+	case FitFill:
+	case FitCover:
+		// 按目标比例居中裁剪原图
+		if srcW*height > srcH*width {
+			cropW := srcH * width / height
+			src = image.Rect(bounds.Min.X+(srcW-cropW)/2, bounds.Min.Y, bounds.Min.X+(srcW-cropW)/2+cropW, bounds.Max.Y)
+		} else {
+			cropH := srcW * height / width
+			src = image.Rect(bounds.Min.X, bounds.Min.Y+(srcH-cropH)/2, bounds.Max.X, bounds.Min.Y+(srcH-cropH)/2+cropH)
+		}
+		if width > src.Dx() || height > src.Dy() {
+			width, height = src.Dx(), src.Dy()
+		}
+	default:
+		if srcW*height > srcH*width {
+			height = srcH * width / srcW
+		} else {
+			width = srcW * height / srcH
+		}
+		if width >= srcW || height >= srcH {
+			return img
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	if src == bounds && width == srcW && height == srcH {
+		return img
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+	return dst
+}
+
+// orientImage 按EXIF方向旋转或翻转图片
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	src := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 水平翻转
+				dx, dy = w-1-x, y
+			case 3: // 旋转180度
+				dx, dy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				dx, dy = x, h-1-y
+			case 5: // 沿左上-右下对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针旋转90度
+				dx, dy = h-1-y, x
+			case 7: // 沿右上-左下对角线翻转
+				dx, dy = h-1-y, w-1-x
+			case 8: // 逆时针旋转90度
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
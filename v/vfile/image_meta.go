@@ -0,0 +1,143 @@
+package vfile
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// pngMetaChunks PNG中保存元数据的数据块
+var pngMetaChunks = []string{"eXIf", "tEXt", "zTXt", "iTXt", "tIME"}
+
+// StripMetadata 去除图片中的EXIF、XMP、IPTC和文本元数据, 不重新编码
+// 支持jpeg和png, 其他格式或解析失败时返回原数据
+func StripMetadata(data []byte, format string) []byte {
+	switch format {
+	case "jpeg":
+		return stripJPEG(data)
+	case "png":
+		return stripPNG(data)
+	}
+	return data
+}
+
+// stripJPEG 去除JPEG的APP1(EXIF/XMP)、APP13(IPTC)和注释段, 保留ICC颜色配置
+func stripJPEG(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return data
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+	for p := 2; p+4 <= len(data); {
+		if data[p] != 0xff {
+			return data
+		}
+		marker := data[p+1]
+		if marker == 0xff {
+			p++
+			continue
+		}
+		// 图像数据开始, 之后的内容原样保留
+		if marker == 0xda {
+			out.Write(data[p:])
+			return out.Bytes()
+		}
+		length := int(binary.BigEndian.Uint16(data[p+2 : p+4]))
+		if length < 2 || p+2+length > len(data) {
+			return data
+		}
+		if marker != 0xe1 && marker != 0xed && marker != 0xfe {
+			out.Write(data[p : p+2+length])
+		}
+		p += 2 + length
+	}
+	return data
+}
+
+// stripPNG 去除PNG的EXIF和文本数据块
+func stripPNG(data []byte) []byte {
+	if len(data) < 8 || !bytes.Equal(data[:8], []byte("\x89PNG\r\n\x1a\n")) {
+		return data
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:8])
+	for p := 8; p < len(data); {
+		if p+12 > len(data) {
+			return data
+		}
+		length := int(binary.BigEndian.Uint32(data[p : p+4]))
+		end := p + 12 + length
+		if length < 0 || end > len(data) {
+			return data
+		}
+		if !containsFold(pngMetaChunks, string(data[p+4:p+8])) {
+			out.Write(data[p:end])
+		}
+		p = end
+	}
+	return out.Bytes()
+}
+
+// jpegOrientation 读取JPEG的EXIF方向, 未设置时返回1
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return 1
+	}
+	for p := 2; p+4 <= len(data); {
+		if data[p] != 0xff {
+			return 1
+		}
+		marker := data[p+1]
+		if marker == 0xff {
+			p++
+			continue
+		}
+		if marker == 0xda {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[p+2 : p+4]))
+		if length < 2 || p+2+length > len(data) {
+			return 1
+		}
+		segment := data[p+4 : p+2+length]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		p += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation 从TIFF格式的EXIF数据中读取方向标签(0x0112)
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + 12*i
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
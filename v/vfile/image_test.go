@@ -0,0 +1,129 @@
+package vfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/v/vconfig"
+	"golang.org/x/image/webp"
+)
+
+// testImage 生成左半红色右半蓝色的图片
+func testImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.NRGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// withExif 在JPEG中插入带有方向标签的EXIF
+func withExif(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	out := append([]byte{0xff, 0xd8, 0xff, 0xe1}, byte((len(segment)+2)>>8), byte(len(segment)+2))
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+// TestEncodeWebP 测试无损WebP编码可以被解码还原
+func TestEncodeWebP(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		random := rand.New(rand.NewSource(1))
+		for _, size := range [][2]int{{1, 1}, {17, 33}, {300, 200}} {
+			noise := image.NewNRGBA(image.Rect(0, 0, size[0], size[1]))
+			random.Read(noise.Pix)
+			for _, img := range []*image.NRGBA{noise, testImage(size[0], size[1])} {
+				var buf bytes.Buffer
+				t.AssertNil(EncodeWebP(&buf, img))
+				decoded, err := webp.Decode(&buf)
+				t.AssertNil(err)
+				t.Assert(bytes.Equal(decoded.(*image.NRGBA).Pix, img.Pix), true)
+			}
+		}
+	})
+}
+
+// TestResizeImage 测试缩放方式
+func TestResizeImage(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		img := testImage(400, 200)
+		t.Assert(ResizeImage(img, &ImageOptions{Width: 100}).Bounds().Size(), image.Pt(100, 50))
+		t.Assert(ResizeImage(img, &ImageOptions{Width: 100, Height: 100}).Bounds().Size(), image.Pt(100, 50))
+		t.Assert(ResizeImage(img, &ImageOptions{Width: 100, Height: 100, Fit: FitCover}).Bounds().Size(), image.Pt(100, 100))
+		t.Assert(ResizeImage(img, &ImageOptions{Width: 100, Height: 100, Fit: FitFill}).Bounds().Size(), image.Pt(100, 100))
+		t.Assert(ResizeImage(img, &ImageOptions{Width: 800}).Bounds().Size(), image.Pt(400, 200))
+
+		w, h, err := ParseImageSize("200x0")
+		t.AssertNil(err)
+		t.Assert([]int{w, h}, []int{200, 0})
+		_, _, err = ParseImageSize("0x0")
+		t.AssertNE(err, nil)
+		t.Assert(ThumbnailKey("uploads/20060102/a.png", 200, 100, ".webp"), "uploads/20060102/a_200x100.webp")
+	})
+}
+
+// TestProcessUploadImage 测试上传图片的元数据去除、方向校正、格式转换与缩略图
+func TestProcessUploadImage(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		config := vconfig.ConfigBinding.Get().File.Image
+		original := *config
+		defer func() { *config = original }()
+
+		var buf bytes.Buffer
+		t.AssertNil(jpeg.Encode(&buf, testImage(400, 300), nil))
+
+		// 方向正常时只去除EXIF, 不重新编码
+		data := withExif(buf.Bytes(), 1)
+		upload := &Upload{Key: "uploads/20060102/a.jpg", ContentType: "image/jpeg", Size: int64(len(data))}
+		files, err := ProcessUploadImage(upload, bytes.NewReader(data))
+		t.AssertNil(err)
+		t.Assert(len(files), 1)
+		t.Assert(bytes.Contains(files[0].Data, []byte("Exif")), false)
+		t.Assert(len(files[0].Data), buf.Len())
+		t.Assert(upload.Size, buf.Len())
+
+		// 旋转后限制宽度, 转换为webp并生成缩略图
+		config.Format = "webp"
+		config.MaxWidth = 150
+		config.Thumbnails = []string{"50x50"}
+		data = withExif(buf.Bytes(), 6)
+		upload = &Upload{Key: "uploads/20060102/b.jpg", ContentType: "image/jpeg", Size: int64(len(data))}
+		files, err = ProcessUploadImage(upload, bytes.NewReader(data))
+		t.AssertNil(err)
+		t.Assert(len(files), 2)
+		t.Assert(upload.Key, "uploads/20060102/b.webp")
+		t.Assert(upload.ContentType, "image/webp")
+		main, err := webp.Decode(bytes.NewReader(files[0].Data))
+		t.AssertNil(err)
+		t.Assert(main.Bounds().Size(), image.Pt(150, 200))
+		// 顺时针旋转90度后原图左侧的红色在上方
+		top, _, _, _ := main.At(75, 10).RGBA()
+		bottom, _, _, _ := main.At(75, 190).RGBA()
+		t.Assert(top > 0xc000 && bottom < 0x4000, true)
+		t.Assert(files[1].Key, "uploads/20060102/b_50x50.webp")
+		thumbnail, err := webp.Decode(bytes.NewReader(files[1].Data))
+		t.AssertNil(err)
+		t.Assert(thumbnail.Bounds().Size(), image.Pt(50, 50))
+
+		// 非图片不处理
+		files, err = ProcessUploadImage(&Upload{Key: "a.pdf", ContentType: "application/pdf"}, strings.NewReader("%PDF"))
+		t.AssertNil(err)
+		t.Assert(files == nil, true)
+	})
+}
@@ -0,0 +1,39 @@
+package vfile
+
+import (
+	"io"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// MinPartSize 对象存储分片上传的最小分片大小, 最后一个分片除外
+const MinPartSize = 5 << 20
+
+// Part 已上传的分片
+type Part struct {
+	Number int    `json:"number"` // 分片序号, 从1开始
+	ETag   string `json:"etag"`   // 驱动返回的分片标识
+	Size   int64  `json:"size"`   // 分片大小
+	Hash   string `json:"hash"`   // 分片内容的SHA-256
+}
+
+// Multipart 支持分片上传的驱动
+// 分片可以乱序、重复上传, 完成时按序号合并
+type Multipart interface {
+	// InitMultipart 开始分片上传, 返回上传ID
+	InitMultipart(ctx g.Ctx, key, contentType string) (uploadId string, err error)
+	// UploadPart 上传分片, 返回分片标识
+	UploadPart(ctx g.Ctx, key, uploadId string, number int, reader io.Reader, size int64) (etag string, err error)
+	// CompleteMultipart 按序号合并分片
+	CompleteMultipart(ctx g.Ctx, key, uploadId string, parts []*Part) error
+	// AbortMultipart 取消分片上传并删除已上传的分片
+	AbortMultipart(ctx g.Ctx, key, uploadId string) error
+}
+
+// AsMultipart 返回驱动的分片上传接口, 驱动不支持时返回 ErrUnsupported
+func AsMultipart(d Driver) (Multipart, error) {
+	if multipart, ok := d.(Multipart); ok {
+		return multipart, nil
+	}
+	return nil, unsupported(d, "Multipart")
+}
@@ -0,0 +1,139 @@
+package vfile
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/v/vconfig"
+)
+
+// Profile 命名存储配置, 对应 v.file.profiles 中的一项
+type Profile struct {
+	Name            string // 配置名称
+	Driver          string // 驱动 local minio oss s3
+	Endpoint        string // 服务端点
+	Region          string // 区域
+	BucketName      string // 存储桶名称
+	AccessKeyID     string // 访问密钥ID
+	SecretAccessKey string // 访问密钥
+	UseSSL          bool   // 是否使用SSL
+	PathStyle       bool   // 是否以路径方式访问存储桶
+	Domain          string // 访问地址前缀, 为空时使用服务端点
+}
+
+// ProfileDriver 支持按命名存储配置创建实例的驱动
+// 未实现的驱动使用 New 创建实例, 忽略配置中的连接参数
+type ProfileDriver interface {
+	NewProfile(profile *Profile) (Driver, error)
+}
+
+// profileDriver 按命名存储配置创建的驱动实例
+type profileDriver struct {
+	profile Profile
+	driver  Driver
+}
+
+var (
+	profileDrivers   = map[string]*profileDriver{}
+	profileDriversMu sync.Mutex
+	// driverNames 驱动实例对应的命名存储配置, 用于区分同一驱动的不同存储桶
+	driverNames sync.Map
+)
+
+// ProfileOf 获取命名存储配置, 未配置时返回nil
+func ProfileOf(name string) *Profile {
+	config, ok := vconfig.ConfigBinding.Get().File.Profiles[name]
+	if !ok || config == nil {
+		return nil
+	}
+	return &Profile{
+		Name:            name,
+		Driver:          config.Driver,
+		Endpoint:        config.Endpoint,
+		Region:          config.Region,
+		BucketName:      config.BucketName,
+		AccessKeyID:     config.AccessKeyID,
+		SecretAccessKey: config.SecretAccessKey,
+		UseSSL:          config.UseSSL,
+		PathStyle:       config.PathStyle,
+		Domain:          config.Domain,
+	}
+}
+
+// Use 按名称获取文件驱动, 名称可以是命名存储配置或驱动名称, 为空时使用 v.file.mode
+// 命名存储配置的驱动实例会被复用, 配置变化后重新创建
+func Use(name string) (Driver, error) {
+	if name == "" {
+		name = vconfig.ConfigBinding.Get().File.Mode
+	}
+	profile := ProfileOf(name)
+	if profile == nil {
+		return newDriver(name)
+	}
+
+	profileDriversMu.Lock()
+	defer profileDriversMu.Unlock()
+	if cached, ok := profileDrivers[name]; ok && cached.profile == *profile {
+		return cached.driver, nil
+	}
+	var (
+		d   Driver
+		err error
+	)
+	if driver, ok := FileMap[profile.Driver].(ProfileDriver); ok {
+		d, err = driver.NewProfile(profile)
+	} else {
+		d, err = newDriver(profile.Driver)
+	}
+	if err != nil {
+		return nil, gerror.Wrapf(err, "存储配置 %s 初始化失败", name)
+	}
+	profileDrivers[name] = &profileDriver{profile: *profile, driver: d}
+	driverNames.Store(d, name)
+	return d, nil
+}
+
+// UseOrFallback 按名称获取文件驱动, 驱动不可用时使用 v.file.fallback
+func UseOrFallback(name string) (Driver, error) {
+	if name == "" {
+		name = vconfig.ConfigBinding.Get().File.Mode
+	}
+	d, err := Use(name)
+	if err == nil {
+		return d, nil
+	}
+	fallback := vconfig.ConfigBinding.Get().File.Fallback
+	if fallback == "" || fallback == name {
+		return nil, err
+	}
+	d, fallbackErr := Use(fallback)
+	if fallbackErr != nil {
+		return nil, err
+	}
+	g.Log().Warningf(context.Background(), "文件驱动 %s 不可用, 使用 %s: %v", name, fallback, err)
+	return d, nil
+}
+
+// ForScene 上传场景使用的文件驱动, 场景未配置存储时使用 v.file.mode
+func ForScene(scene string) (Driver, error) {
+	rule, err := RuleOf(scene)
+	if err != nil {
+		return nil, err
+	}
+	return UseOrFallback(rule.Profile)
+}
+
+// newDriver 创建已注册的驱动实例
+func newDriver(name string) (Driver, error) {
+	driver, ok := FileMap[name]
+	if !ok || driver == nil {
+		return nil, gerror.Newf("文件驱动未注册: %s", name)
+	}
+	d := driver.New()
+	if d == nil {
+		return nil, gerror.Newf("文件驱动不可用: %s", name)
+	}
+	return d, nil
+}
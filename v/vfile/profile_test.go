@@ -0,0 +1,46 @@
+package vfile
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/vera-byte/vgo/v/vconfig"
+)
+
+// TestUseOrFallback 测试命名存储配置和驱动回退
+func TestUseOrFallback(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		file := vconfig.ConfigBinding.Get().File
+		mode, fallback, profiles, scenes := file.Mode, file.Fallback, file.Profiles, file.Upload.Scenes
+		defer func() {
+			file.Mode, file.Fallback, file.Profiles, file.Upload.Scenes = mode, fallback, profiles, scenes
+		}()
+		driver := &memoryStorage{files: map[string]string{}}
+		FileMap["memory"] = driver
+		defer delete(FileMap, "memory")
+
+		file.Mode = "missing"
+		file.Fallback = ""
+		_, err := UseOrFallback("")
+		t.AssertNE(err, nil)
+		file.Fallback = "memory"
+		d, err := UseOrFallback("")
+		t.AssertNil(err)
+		t.Assert(d == driver, true)
+		t.Assert(NewFile() == driver, true)
+
+		// 上传场景使用命名存储配置, 未实现 ProfileDriver 的驱动使用 New 创建
+		t.AssertNil(json.Unmarshal([]byte(`{"private": {"driver": "memory"}}`), &file.Profiles))
+		t.AssertNil(json.Unmarshal([]byte(`{"export": {"profile": "private"}, "broken": {"profile": "missing"}}`), &file.Upload.Scenes))
+		d, err = ForScene("export")
+		t.AssertNil(err)
+		t.Assert(d == driver, true)
+		t.Assert(DriverName(d), "private")
+		d, err = ForScene("broken")
+		t.AssertNil(err)
+		t.Assert(d == driver, true)
+		_, err = ForScene("unknown")
+		t.AssertNE(err, nil)
+	})
+}
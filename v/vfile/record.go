@@ -0,0 +1,201 @@
+package vfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/v/vconfig"
+)
+
+// ErrRecordExists 存储驱动中已有相同哈希的文件记录
+var ErrRecordExists = gerror.New("文件记录已存在")
+
+// Record 文件元数据记录, 同一存储驱动中内容相同的文件只保存一份, 按引用计数删除
+type Record struct {
+	Driver      string `json:"driver"`      // 存储驱动
+	Key         string `json:"key"`         // 文件键
+	URL         string `json:"url"`         // 访问地址
+	Hash        string `json:"hash"`        // 上传内容的SHA-256
+	Size        int64  `json:"size"`        // 保存的文件大小
+	ContentType string `json:"contentType"` // 保存的文件类型
+	FileName    string `json:"fileName"`    // 原文件名
+	UserId      uint   `json:"userId"`      // 上传者ID
+	RefCount    int    `json:"refCount"`    // 引用计数
+	Temporary   bool   `json:"temporary"`   // 是否为临时文件, 被业务数据引用前为临时文件
+}
+
+// RecordStore 文件元数据存储, 由业务模块实现并注册, 未注册时上传的文件不去重
+type RecordStore interface {
+	// Acquire 查找存储驱动中哈希相同的记录并增加一次引用, 不存在时返回nil
+	Acquire(ctx g.Ctx, driver, hash string) (*Record, error)
+	// Create 保存引用计数为1的新记录, 哈希已存在时返回 ErrRecordExists
+	Create(ctx g.Ctx, record *Record) error
+	// Release 按访问地址减少一次引用, 返回减少后的记录, 没有记录时返回nil
+	Release(ctx g.Ctx, url string) (*Record, error)
+}
+
+// RecordMover 支持在存储之间转移记录的元数据存储, 用于存储迁移
+type RecordMover interface {
+	// MoveRecords 将 from 中文件键以 prefix 开头的记录转移到 to 并改写访问地址
+	// 跳过 skip 中的文件键, to 中已有相同哈希的记录保持不变
+	MoveRecords(ctx g.Ctx, from, to, prefix string, skip []string) error
+}
+
+var (
+	recordStore   RecordStore
+	recordStoreMu sync.RWMutex
+)
+
+// RegisterRecordStore 注册文件元数据存储, 传入nil时取消注册
+func RegisterRecordStore(store RecordStore) {
+	recordStoreMu.Lock()
+	defer recordStoreMu.Unlock()
+	recordStore = store
+}
+
+// getRecordStore 获取已注册的文件元数据存储
+func getRecordStore() RecordStore {
+	recordStoreMu.RLock()
+	defer recordStoreMu.RUnlock()
+	return recordStore
+}
+
+// DriverName 文件驱动的名称, 按命名存储配置创建的实例返回配置名称, 未注册的驱动返回空
+func DriverName(d Driver) string {
+	if name, ok := driverNames.Load(d); ok {
+		return name.(string)
+	}
+	t := reflect.TypeOf(d)
+	if driver, ok := FileMap[vconfig.ConfigBinding.Get().File.Mode]; ok && reflect.TypeOf(driver) == t {
+		return vconfig.ConfigBinding.Get().File.Mode
+	}
+	for name, driver := range FileMap {
+		if reflect.TypeOf(driver) == t {
+			return name
+		}
+	}
+	return ""
+}
+
+// Hash 计算内容的SHA-256
+func Hash(reader io.Reader) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// AcquireRecord 查找驱动中内容相同的文件并增加一次引用, 未注册元数据存储或不存在时返回nil
+func AcquireRecord(ctx g.Ctx, d Driver, hash string) (*Record, error) {
+	store := getRecordStore()
+	if store == nil || hash == "" {
+		return nil, nil
+	}
+	return store.Acquire(ctx, DriverName(d), hash)
+}
+
+// TrackRecord 登记已保存的文件, 返回实际使用的记录
+// 开启临时文件时新记录为临时文件, 需要通过 Keep 转为永久文件
+// 驱动中已有内容相同的文件时删除刚保存的 keys 并返回已有记录, 未注册元数据存储时原样返回
+// 记录没有哈希时读取文件计算, 驱动不支持读取时不登记
+func TrackRecord(ctx g.Ctx, d Driver, record *Record, keys ...string) (*Record, error) {
+	store := getRecordStore()
+	if store == nil {
+		return record, nil
+	}
+	if record.Hash == "" {
+		hasher := sha256.New()
+		_, err := Download(ctx, d, record.Key, hasher)
+		if gerror.Is(err, ErrUnsupported) {
+			return record, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		record.Hash = hex.EncodeToString(hasher.Sum(nil))
+	}
+	record.Driver = DriverName(d)
+	record.RefCount = 1
+	record.Temporary = TemporaryEnabled()
+	// 并发上传相同内容时创建失败, 重新查找已有记录
+	for i := 0; i < 2; i++ {
+		existing, err := store.Acquire(ctx, record.Driver, record.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			for _, key := range keys {
+				if err = Delete(ctx, d, key); err != nil && !gerror.Is(err, ErrNotFound) {
+					g.Log().Warningf(ctx, "删除重复的文件 %s 失败: %v", key, err)
+				}
+			}
+			return existing, nil
+		}
+		if err = store.Create(ctx, record); !gerror.Is(err, ErrRecordExists) {
+			return record, err
+		}
+	}
+	return nil, ErrRecordExists
+}
+
+// MoveRecords 存储迁移完成后转移文件元数据记录, 元数据存储不支持时不处理
+// 元数据存储负责改写记录中的访问地址
+func MoveRecords(ctx g.Ctx, from, to, prefix string, skip []string) error {
+	mover, ok := getRecordStore().(RecordMover)
+	if !ok {
+		return nil
+	}
+	return mover.MoveRecords(ctx, from, to, prefix, skip)
+}
+
+// ReleaseRecord 按访问地址减少一次文件引用, 没有引用时从存储驱动删除文件及其缩略图
+// 没有元数据记录的文件无法确定引用, 不会删除
+func ReleaseRecord(ctx g.Ctx, url string) error {
+	store := getRecordStore()
+	if store == nil || url == "" {
+		return nil
+	}
+	record, err := store.Release(ctx, url)
+	if err != nil || record == nil || record.RefCount > 0 {
+		return err
+	}
+	d, err := Use(record.Driver)
+	if err != nil {
+		return err
+	}
+	for _, key := range recordKeys(record) {
+		if err = Delete(ctx, d, key); err != nil && !gerror.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordKeys 记录对应的全部文件键, 包括按当前配置生成的缩略图
+func recordKeys(record *Record) []string {
+	keys := []string{record.Key}
+	config := vconfig.ConfigBinding.Get().File.Image
+	format := ImageFormat(record.ContentType)
+	if config == nil || !config.Enable || format == "" {
+		return keys
+	}
+	if format == "gif" {
+		format = "png"
+	}
+	encoder, err := imageEncoder(format)
+	if err != nil {
+		return keys
+	}
+	for _, size := range config.Thumbnails {
+		if width, height, err := ParseImageSize(size); err == nil {
+			keys = append(keys, ThumbnailKey(record.Key, width, height, encoder.Ext))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,93 @@
+package vfile
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// memoryRecordStore 内存文件元数据存储
+type memoryRecordStore struct {
+	records map[string]*Record
+}
+
+func (s *memoryRecordStore) Acquire(ctx g.Ctx, driver, hash string) (*Record, error) {
+	record, ok := s.records[driver+":"+hash]
+	if !ok {
+		return nil, nil
+	}
+	record.RefCount++
+	return record, nil
+}
+
+func (s *memoryRecordStore) Create(ctx g.Ctx, record *Record) error {
+	if _, ok := s.records[record.Driver+":"+record.Hash]; ok {
+		return ErrRecordExists
+	}
+	s.records[record.Driver+":"+record.Hash] = record
+	return nil
+}
+
+func (s *memoryRecordStore) Release(ctx g.Ctx, url string) (*Record, error) {
+	for id, record := range s.records {
+		if record.URL == url {
+			record.RefCount--
+			if record.RefCount <= 0 {
+				delete(s.records, id)
+			}
+			return record, nil
+		}
+	}
+	return nil, nil
+}
+
+// TestRecord 测试按内容去重和引用计数
+func TestRecord(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		driver := &memoryStorage{files: map[string]string{}}
+		FileMap["memory"] = driver
+		defer delete(FileMap, "memory")
+		store := &memoryRecordStore{records: map[string]*Record{}}
+		RegisterRecordStore(store)
+		defer RegisterRecordStore(nil)
+
+		t.Assert(DriverName(driver), "memory")
+		hash, err := Hash(strings.NewReader("hello"))
+		t.AssertNil(err)
+		t.Assert(hash, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+
+		// 首次保存登记记录
+		driver.files["a.txt"] = "hello"
+		record, err := TrackRecord(ctx, driver, &Record{Key: "a.txt", URL: driver.URL("a.txt"), Size: 5}, "a.txt")
+		t.AssertNil(err)
+		t.Assert(record.Hash, hash)
+		t.Assert(record.Driver, "memory")
+		t.Assert(record.RefCount, 1)
+
+		// 相同内容删除新文件, 返回已有记录
+		driver.files["b.txt"] = "hello"
+		record, err = TrackRecord(ctx, driver, &Record{Key: "b.txt", URL: driver.URL("b.txt")}, "b.txt")
+		t.AssertNil(err)
+		t.Assert(record.Key, "a.txt")
+		t.Assert(record.RefCount, 2)
+		_, ok := driver.files["b.txt"]
+		t.Assert(ok, false)
+		record, err = AcquireRecord(ctx, driver, hash)
+		t.AssertNil(err)
+		t.Assert(record.RefCount, 3)
+
+		// 引用全部释放后删除文件
+		for i := 0; i < 2; i++ {
+			t.AssertNil(ReleaseRecord(ctx, driver.URL("a.txt")))
+			t.Assert(driver.files["a.txt"], "hello")
+		}
+		t.AssertNil(ReleaseRecord(ctx, driver.URL("a.txt")))
+		_, ok = driver.files["a.txt"]
+		t.Assert(ok, false)
+		t.AssertNil(ReleaseRecord(ctx, driver.URL("a.txt")))
+	})
+}
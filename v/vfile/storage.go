@@ -0,0 +1,224 @@
+package vfile
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/grand"
+)
+
+var (
+	// ErrNotFound 文件不存在
+	ErrNotFound = gerror.New("文件不存在")
+	// ErrUnsupported 文件驱动不支持该操作
+	ErrUnsupported = gerror.New("文件驱动不支持该操作")
+)
+
+// ObjectInfo 存储中的文件信息
+type ObjectInfo struct {
+	Key          string    `json:"key"`          // 文件键, 如 uploads/20060102/name.png
+	Size         int64     `json:"size"`         // 文件大小
+	ContentType  string    `json:"contentType"`  // 文件类型
+	ETag         string    `json:"etag"`         // 文件标识, 本地文件为空
+	LastModified time.Time `json:"lastModified"` // 最后修改时间
+}
+
+// PresignOptions 直传凭证的限制
+type PresignOptions struct {
+	Expire      time.Duration // 有效期
+	MaxSize     int64         // 最大文件大小, 为0时不限制
+	ContentType string        // 文件类型, 以/结尾时限制类型前缀, 如 image/, 为空时不限制
+}
+
+// PresignedUpload 浏览器直传凭证
+type PresignedUpload struct {
+	Method      string            `json:"method"`      // 上传方式 POST为表单上传 PUT为直接上传文件内容
+	URL         string            `json:"url"`         // 上传地址
+	Key         string            `json:"key"`         // 文件键
+	Fields      map[string]string `json:"fields"`      // POST表单字段, 文件字段名为file, 需放在表单最后
+	Headers     map[string]string `json:"headers"`     // PUT请求需要携带的请求头
+	ExpireTime  time.Time         `json:"expireTime"`  // 过期时间
+	MaxSize     int64             `json:"maxSize"`     // 最大文件大小
+	ContentType string            `json:"contentType"` // 文件类型限制
+	FileURL     string            `json:"fileUrl"`     // 上传完成后的访问地址
+}
+
+// 以下为文件驱动的可选接口, 驱动按需实现, 调用方通过同名函数调用
+// 驱动未实现时同名函数返回 ErrUnsupported, 文件不存在时返回 ErrNotFound
+
+// Putter 支持按文件键保存文件的驱动
+type Putter interface {
+	Put(ctx g.Ctx, key string, reader io.Reader, size int64, contentType string) error
+}
+
+// Deleter 支持删除文件的驱动
+type Deleter interface {
+	Delete(ctx g.Ctx, key string) error
+}
+
+// Stater 支持获取文件信息的驱动
+type Stater interface {
+	Stat(ctx g.Ctx, key string) (*ObjectInfo, error)
+}
+
+// Opener 支持读取文件内容的驱动, 调用方负责关闭返回的 io.ReadCloser
+type Opener interface {
+	Open(ctx g.Ctx, key string) (io.ReadCloser, *ObjectInfo, error)
+}
+
+// Lister 支持按前缀列出文件的驱动
+type Lister interface {
+	List(ctx g.Ctx, prefix string) ([]*ObjectInfo, error)
+}
+
+// Signer 支持生成限时访问地址的驱动, 用于私有存储桶
+type Signer interface {
+	SignedURL(ctx g.Ctx, key string, expire time.Duration) (string, error)
+}
+
+// Presigner 支持浏览器直传的驱动
+type Presigner interface {
+	PresignUpload(ctx g.Ctx, key string, options *PresignOptions) (*PresignedUpload, error)
+}
+
+// Resolver 支持文件键与访问地址相互转换的驱动
+type Resolver interface {
+	// URL 返回文件键对应的访问地址
+	URL(key string) string
+	// Key 返回访问地址对应的文件键, 不是该驱动的地址时返回false
+	Key(url string) (string, bool)
+}
+
+// Storage 实现了文件操作可选接口的文件驱动, 直传需要另外实现 Presigner
+type Storage interface {
+	Driver
+	Putter
+	Deleter
+	Stater
+	Opener
+	Lister
+	Signer
+	Resolver
+}
+
+// NewKey 生成上传文件的文件键, 以当前日期为目录, 保留原文件的扩展名
+// 文件名由随机字符生成, 扩展名只保留字母和数字, 不使用客户端传入的路径
+func NewKey(fileName string) string {
+	return "uploads/" + gtime.Now().Format("Ymd") + "/" + grand.S(16, false) + fileExt(fileName)
+}
+
+// Put 按文件键保存文件, size 为-1时表示大小未知
+func Put(ctx g.Ctx, d Driver, key string, reader io.Reader, size int64, contentType string) error {
+	if putter, ok := d.(Putter); ok {
+		return putter.Put(ctx, key, reader, size, contentType)
+	}
+	return unsupported(d, "Put")
+}
+
+// Delete 删除文件
+func Delete(ctx g.Ctx, d Driver, key string) error {
+	if deleter, ok := d.(Deleter); ok {
+		return deleter.Delete(ctx, key)
+	}
+	return unsupported(d, "Delete")
+}
+
+// Stat 获取文件信息
+func Stat(ctx g.Ctx, d Driver, key string) (*ObjectInfo, error) {
+	if stater, ok := d.(Stater); ok {
+		return stater.Stat(ctx, key)
+	}
+	return nil, unsupported(d, "Stat")
+}
+
+// Exists 判断文件是否存在
+func Exists(ctx g.Ctx, d Driver, key string) (bool, error) {
+	_, err := Stat(ctx, d, key)
+	if gerror.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Open 读取文件内容, 调用方负责关闭返回的 io.ReadCloser
+func Open(ctx g.Ctx, d Driver, key string) (io.ReadCloser, *ObjectInfo, error) {
+	if opener, ok := d.(Opener); ok {
+		return opener.Open(ctx, key)
+	}
+	return nil, nil, unsupported(d, "Open")
+}
+
+// Download 将文件内容写入w, 返回文件信息
+func Download(ctx g.Ctx, d Driver, key string, w io.Writer) (*ObjectInfo, error) {
+	reader, info, err := Open(ctx, d, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	if _, err = io.Copy(w, reader); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// List 按前缀列出文件
+func List(ctx g.Ctx, d Driver, prefix string) ([]*ObjectInfo, error) {
+	if lister, ok := d.(Lister); ok {
+		return lister.List(ctx, prefix)
+	}
+	return nil, unsupported(d, "List")
+}
+
+// SignedURL 生成限时访问地址
+func SignedURL(ctx g.Ctx, d Driver, key string, expire time.Duration) (string, error) {
+	if signer, ok := d.(Signer); ok {
+		return signer.SignedURL(ctx, key, expire)
+	}
+	return "", unsupported(d, "SignedURL")
+}
+
+// PresignUpload 生成浏览器直传凭证
+func PresignUpload(ctx g.Ctx, d Driver, key string, options *PresignOptions) (*PresignedUpload, error) {
+	if presigner, ok := d.(Presigner); ok {
+		return presigner.PresignUpload(ctx, key, options)
+	}
+	return nil, unsupported(d, "PresignUpload")
+}
+
+// URL 返回文件键对应的访问地址
+func URL(d Driver, key string) (string, error) {
+	if resolver, ok := d.(Resolver); ok {
+		return resolver.URL(key), nil
+	}
+	return "", unsupported(d, "URL")
+}
+
+// Key 返回访问地址对应的文件键, 驱动不支持或不是该驱动的地址时返回false
+func Key(d Driver, url string) (string, bool) {
+	if resolver, ok := d.(Resolver); ok {
+		return resolver.Key(url)
+	}
+	return "", false
+}
+
+// MatchContentType 判断文件类型是否符合限制, 限制以/结尾时按前缀匹配, 为空时不限制
+func MatchContentType(contentType, limit string) bool {
+	if limit == "" {
+		return true
+	}
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	limit = strings.ToLower(limit)
+	if strings.HasSuffix(limit, "/") {
+		return strings.HasPrefix(contentType, limit)
+	}
+	return contentType == limit
+}
+
+// unsupported 返回驱动不支持操作的错误
+func unsupported(d Driver, operation string) error {
+	return gerror.Wrapf(ErrUnsupported, "%T: %s", d, operation)
+}
@@ -0,0 +1,160 @@
+package vfile
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// uploadOnly 仅实现上传的文件驱动
+type uploadOnly struct{}
+
+func (d *uploadOnly) New() Driver                            { return d }
+func (d *uploadOnly) GetMode() (data interface{}, err error) { return nil, nil }
+func (d *uploadOnly) Upload(ctx g.Ctx) (string, error)       { return "", nil }
+
+// memoryStorage 实现全部可选接口的内存文件驱动
+type memoryStorage struct {
+	uploadOnly
+	files map[string]string
+}
+
+func (d *memoryStorage) New() Driver { return d }
+
+func (d *memoryStorage) Put(ctx g.Ctx, key string, reader io.Reader, size int64, contentType string) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	d.files[key] = string(content)
+	return nil
+}
+
+func (d *memoryStorage) Delete(ctx g.Ctx, key string) error {
+	delete(d.files, key)
+	return nil
+}
+
+func (d *memoryStorage) Stat(ctx g.Ctx, key string) (*ObjectInfo, error) {
+	content, ok := d.files[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &ObjectInfo{Key: key, Size: int64(len(content))}, nil
+}
+
+func (d *memoryStorage) Open(ctx g.Ctx, key string) (io.ReadCloser, *ObjectInfo, error) {
+	info, err := d.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return io.NopCloser(strings.NewReader(d.files[key])), info, nil
+}
+
+func (d *memoryStorage) List(ctx g.Ctx, prefix string) ([]*ObjectInfo, error) {
+	var infos []*ObjectInfo
+	for key := range d.files {
+		if strings.HasPrefix(key, prefix) {
+			info, _ := d.Stat(ctx, key)
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+func (d *memoryStorage) SignedURL(ctx g.Ctx, key string, expire time.Duration) (string, error) {
+	return "memory://" + key + "?expire=" + expire.String(), nil
+}
+
+func (d *memoryStorage) PresignUpload(ctx g.Ctx, key string, options *PresignOptions) (*PresignedUpload, error) {
+	return &PresignedUpload{Method: "PUT", URL: "memory://upload/" + key, Key: key, FileURL: d.URL(key)}, nil
+}
+
+func (d *memoryStorage) URL(key string) string {
+	return "memory://" + key
+}
+
+func (d *memoryStorage) Key(url string) (string, bool) {
+	if !strings.HasPrefix(url, "memory://") {
+		return "", false
+	}
+	return strings.TrimPrefix(url, "memory://"), true
+}
+
+var (
+	_ Storage   = (*memoryStorage)(nil)
+	_ Presigner = (*memoryStorage)(nil)
+)
+
+func TestStorage(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		storage := &memoryStorage{files: map[string]string{
+			"uploads/a/1.txt": "one",
+			"uploads/b/2.txt": "two",
+		}}
+
+		exists, err := Exists(ctx, storage, "uploads/a/1.txt")
+		t.AssertNil(err)
+		t.Assert(exists, true)
+		exists, err = Exists(ctx, storage, "uploads/a/none.txt")
+		t.AssertNil(err)
+		t.Assert(exists, false)
+
+		var buf bytes.Buffer
+		info, err := Download(ctx, storage, "uploads/b/2.txt", &buf)
+		t.AssertNil(err)
+		t.Assert(info.Size, 3)
+		t.Assert(buf.String(), "two")
+
+		infos, err := List(ctx, storage, "uploads/a/")
+		t.AssertNil(err)
+		t.Assert(len(infos), 1)
+		url, err := SignedURL(ctx, storage, "uploads/a/1.txt", time.Minute)
+		t.AssertNil(err)
+		t.Assert(url, "memory://uploads/a/1.txt?expire=1m0s")
+
+		key, ok := Key(storage, "memory://uploads/a/1.txt")
+		t.Assert(ok, true)
+		t.Assert(key, "uploads/a/1.txt")
+		_, ok = Key(storage, "http://other/uploads/a/1.txt")
+		t.Assert(ok, false)
+
+		t.AssertNil(Delete(ctx, storage, "uploads/a/1.txt"))
+		_, err = Stat(ctx, storage, "uploads/a/1.txt")
+		t.Assert(err, ErrNotFound)
+
+		// 未实现可选接口的驱动返回 ErrUnsupported
+		driver := &uploadOnly{}
+		t.Assert(gerror.Is(Delete(ctx, driver, "key"), ErrUnsupported), true)
+		_, err = Exists(ctx, driver, "key")
+		t.AssertNE(err, nil)
+		_, err = List(ctx, driver, "")
+		t.Assert(gerror.Is(err, ErrUnsupported), true)
+		_, _, err = Open(ctx, driver, "key")
+		t.Assert(gerror.Is(err, ErrUnsupported), true)
+		_, err = PresignUpload(ctx, driver, "key", &PresignOptions{})
+		t.Assert(gerror.Is(err, ErrUnsupported), true)
+	})
+}
+
+func TestNewKey(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		key := NewKey("Photo.PNG")
+		t.Assert(strings.HasPrefix(key, "uploads/"), true)
+		t.Assert(strings.HasSuffix(key, ".png"), true)
+		t.AssertNE(NewKey("a.png"), NewKey("a.png"))
+
+		t.Assert(MatchContentType("image/png", ""), true)
+		t.Assert(MatchContentType("image/png", "image/"), true)
+		t.Assert(MatchContentType("Image/PNG; charset=binary", "image/png"), true)
+		t.Assert(MatchContentType("video/mp4", "image/"), false)
+	})
+}
@@ -0,0 +1,125 @@
+package vfile
+
+import (
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/v/vconfig"
+)
+
+// TemporaryStore 支持临时文件的元数据存储, 用于清理未被引用的临时文件和存储中的孤立文件
+type TemporaryStore interface {
+	// Keep 将访问地址对应的临时文件转为永久文件
+	Keep(ctx g.Ctx, urls []string) error
+	// Expired 返回创建时间早于 before 的临时文件记录
+	Expired(ctx g.Ctx, before time.Time) ([]*Record, error)
+	// Purge 引用计数不大于1时删除访问地址对应的记录, 返回是否已删除
+	Purge(ctx g.Ctx, url string) (bool, error)
+	// Records 返回驱动中文件键以 prefix 开头的全部记录
+	Records(ctx g.Ctx, driver, prefix string) ([]*Record, error)
+	// FirstCreated 返回驱动中最早的记录的创建时间, 没有记录时返回零值
+	FirstCreated(ctx g.Ctx, driver string) (time.Time, error)
+}
+
+// TemporaryEnabled 是否开启临时文件, 需要注册支持临时文件的元数据存储
+func TemporaryEnabled() bool {
+	temporary := vconfig.ConfigBinding.Get().File.Temporary
+	if temporary == nil || !temporary.Enable {
+		return false
+	}
+	_, ok := getRecordStore().(TemporaryStore)
+	return ok
+}
+
+// TemporaryTTL 临时文件和孤立文件的保留时间, 默认24小时
+func TemporaryTTL() time.Duration {
+	temporary := vconfig.ConfigBinding.Get().File.Temporary
+	if temporary != nil {
+		if ttl, err := time.ParseDuration(temporary.TTL); err == nil && ttl > 0 {
+			return ttl
+		}
+	}
+	return 24 * time.Hour
+}
+
+// getTemporaryStore 获取支持临时文件的元数据存储
+func getTemporaryStore() (TemporaryStore, error) {
+	store, ok := getRecordStore().(TemporaryStore)
+	if !ok {
+		return nil, gerror.Wrap(ErrUnsupported, "文件元数据存储不支持临时文件")
+	}
+	return store, nil
+}
+
+// Keep 业务数据引用文件后调用, 将临时文件转为永久文件, 元数据存储不支持时不处理
+func Keep(ctx g.Ctx, urls ...string) error {
+	store, ok := getRecordStore().(TemporaryStore)
+	if !ok || len(urls) == 0 {
+		return nil
+	}
+	return store.Keep(ctx, urls)
+}
+
+// ExpiredRecords 返回创建时间早于 before 的临时文件记录
+func ExpiredRecords(ctx g.Ctx, before time.Time) ([]*Record, error) {
+	store, err := getTemporaryStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.Expired(ctx, before)
+}
+
+// PurgeRecord 删除引用计数不大于1的文件记录以及存储驱动中的文件和缩略图, 返回是否已删除
+// 引用计数大于1时文件被多次上传, 只减少一次引用
+func PurgeRecord(ctx g.Ctx, record *Record) (bool, error) {
+	store, err := getTemporaryStore()
+	if err != nil {
+		return false, err
+	}
+	d, err := Use(record.Driver)
+	if err != nil {
+		return false, err
+	}
+	purged, err := store.Purge(ctx, record.URL)
+	if err != nil {
+		return false, err
+	}
+	if !purged {
+		return false, ReleaseRecord(ctx, record.URL)
+	}
+	for _, key := range recordKeys(record) {
+		if err = Delete(ctx, d, key); err != nil && !gerror.Is(err, ErrNotFound) {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// RecordKeys 返回驱动中文件键以 prefix 开头的记录对应的全部文件键, 包括缩略图
+func RecordKeys(ctx g.Ctx, d Driver, prefix string) (map[string]bool, error) {
+	store, err := getTemporaryStore()
+	if err != nil {
+		return nil, err
+	}
+	records, err := store.Records(ctx, DriverName(d), prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(records))
+	for _, record := range records {
+		for _, key := range recordKeys(record) {
+			keys[key] = true
+		}
+	}
+	return keys, nil
+}
+
+// FirstRecordTime 返回驱动中最早的记录的创建时间, 没有记录时返回零值
+func FirstRecordTime(ctx g.Ctx, d Driver) (time.Time, error) {
+	store, err := getTemporaryStore()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return store.FirstCreated(ctx, DriverName(d))
+}
@@ -0,0 +1,172 @@
+package vfile
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"strings"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/v/vconfig"
+)
+
+// ImageFile 图片处理生成的文件
+type ImageFile struct {
+	Key         string // 文件键
+	ContentType string // 文件类型
+	Data        []byte // 文件内容
+}
+
+// SaveUpload 保存请求中的上传文件, 返回文件地址
+// 依次执行上传校验和图片处理, 图片的缩略图与原图一起保存
+// 注册了元数据存储时按上传内容的SHA-256去重, 已有相同文件时直接返回已有地址
+func SaveUpload(ctx g.Ctx, d Driver, field string) (string, error) {
+	upload, src, err := ValidateUpload(ctx, field)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	hash, err := Hash(src)
+	if err != nil {
+		return "", err
+	}
+	record, err := AcquireRecord(ctx, d, hash)
+	if err != nil {
+		return "", err
+	}
+	if record != nil {
+		return record.URL, nil
+	}
+	if _, err = src.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	files, err := ProcessUploadImage(upload, src)
+	if err != nil {
+		return "", err
+	}
+	if files == nil {
+		if _, err = src.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		if err = Put(ctx, d, upload.Key, src, upload.Size, upload.ContentType); err != nil {
+			return "", err
+		}
+	}
+	keys := []string{upload.Key}
+	for _, file := range files {
+		if err = Put(ctx, d, file.Key, bytes.NewReader(file.Data), int64(len(file.Data)), file.ContentType); err != nil {
+			return "", err
+		}
+		if file.Key != upload.Key {
+			keys = append(keys, file.Key)
+		}
+	}
+	url, err := URL(d, upload.Key)
+	if err != nil {
+		return "", err
+	}
+	record, err = TrackRecord(ctx, d, &Record{
+		Key:         upload.Key,
+		URL:         url,
+		Hash:        hash,
+		Size:        upload.Size,
+		ContentType: upload.ContentType,
+		FileName:    upload.FileName,
+	}, keys...)
+	if err != nil {
+		return "", err
+	}
+	return record.URL, nil
+}
+
+// ProcessUploadImage 按配置处理上传的图片, 第一个文件为处理后的原图, 之后为缩略图
+// 处理后原图的文件键、类型和大小更新到 upload 中, 不是图片或未开启时返回nil
+// 原图超过尺寸上限、需要转换格式或带有EXIF方向时重新编码, 否则只去除元数据; 动图不重新编码
+func ProcessUploadImage(upload *Upload, reader io.Reader) ([]*ImageFile, error) {
+	config := vconfig.ConfigBinding.Get().File.Image
+	format := ImageFormat(upload.ContentType)
+	if config == nil || !config.Enable || format == "" {
+		return nil, nil
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var img image.Image
+	decode := func() (image.Image, error) {
+		if img == nil {
+			img, _, err = DecodeImage(data)
+		}
+		return img, err
+	}
+	target := config.Format
+	if target == "" || format == "gif" {
+		target = format
+	}
+	encoder, err := imageEncoder(target)
+	if err != nil {
+		return nil, err
+	}
+	options := &ImageOptions{Width: config.MaxWidth, Height: config.MaxHeight, Format: target, Quality: config.Quality}
+	if format != "gif" && (target != format || jpegOrientation(data) != 1 || imageExceeds(data, config.MaxWidth, config.MaxHeight)) {
+		if _, err = decode(); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if _, err = EncodeImage(&buf, ResizeImage(img, options), format, options); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+		upload.Key = replaceExt(upload.Key, encoder.Ext)
+		upload.ContentType = encoder.ContentType
+	} else if config.StripExif {
+		data = StripMetadata(data, format)
+	}
+	upload.Size = int64(len(data))
+	files := []*ImageFile{{Key: upload.Key, ContentType: upload.ContentType, Data: data}}
+
+	// 缩略图, 宽高都指定时居中裁剪, 动图使用第一帧生成png
+	if format == "gif" {
+		target = "png"
+	}
+	for _, size := range config.Thumbnails {
+		width, height, err := ParseImageSize(size)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = decode(); err != nil {
+			return nil, err
+		}
+		thumbnail := &ImageOptions{Width: width, Height: height, Fit: FitCover, Format: target, Quality: config.Quality}
+		var buf bytes.Buffer
+		contentType, err := EncodeImage(&buf, ResizeImage(img, thumbnail), format, thumbnail)
+		if err != nil {
+			return nil, err
+		}
+		thumbEncoder, _ := imageEncoder(target)
+		files = append(files, &ImageFile{
+			Key:         ThumbnailKey(upload.Key, width, height, thumbEncoder.Ext),
+			ContentType: contentType,
+			Data:        buf.Bytes(),
+		})
+	}
+	return files, nil
+}
+
+// imageExceeds 图片尺寸是否超过上限, 为0时不限制
+func imageExceeds(data []byte, maxWidth, maxHeight int) bool {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	return (maxWidth > 0 && config.Width > maxWidth) || (maxHeight > 0 && config.Height > maxHeight)
+}
+
+// replaceExt 替换文件键的扩展名
+func replaceExt(key, ext string) string {
+	if i := strings.LastIndex(key, "."); i > strings.LastIndex(key, "/") {
+		key = key[:i]
+	}
+	return key + ext
+}
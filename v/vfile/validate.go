@@ -0,0 +1,354 @@
+package vfile
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/vera-byte/vgo/v/vconfig"
+)
+
+// DefaultScene 未指定上传场景时使用的场景
+const DefaultScene = "default"
+
+// sniffSize 识别文件类型读取的字节数
+const sniffSize = 512
+
+// activeContentTypes 浏览器会执行的文件类型, 公开访问时可能导致XSS, 需要在规则中显式允许
+var activeContentTypes = []string{
+	"text/html",
+	"text/xml",
+	"text/javascript",
+	"application/javascript",
+	"application/xhtml+xml",
+	"application/xml",
+	"image/svg+xml",
+}
+
+// activeExtensions 浏览器会执行的文件扩展名, 需要在规则中显式允许
+var activeExtensions = []string{".html", ".htm", ".xhtml", ".shtml", ".svg", ".js", ".mjs", ".xml"}
+
+// Rule 上传场景的校验规则
+type Rule struct {
+	Scene        string   // 场景名称
+	MaxSize      int64    // 最大文件大小(字节), 为0时不限制
+	ContentTypes []string // 允许的文件类型, 按文件内容识别, 以/结尾时按前缀匹配, 为空时不限制
+	Extensions   []string // 允许的扩展名, 为空时不限制
+	Profile      string   // 保存使用的命名存储配置, 为空时使用 v.file.mode
+}
+
+// Upload 通过校验的上传文件
+type Upload struct {
+	Key         string `json:"key"`         // 生成的文件键
+	FileName    string `json:"fileName"`    // 清理后的原文件名
+	ContentType string `json:"contentType"` // 按文件内容识别的文件类型
+	Size        int64  `json:"size"`        // 文件大小
+	Scene       string `json:"scene"`       // 上传场景
+}
+
+// Scanner 病毒扫描接口, 返回错误时拒绝上传
+type Scanner interface {
+	Scan(ctx g.Ctx, reader io.Reader, upload *Upload) error
+}
+
+// Quota 存储配额接口, 保存上传文件前检查, 返回错误时拒绝上传
+type Quota interface {
+	Check(ctx g.Ctx, size int64) error
+}
+
+var (
+	scanner   Scanner
+	scannerMu sync.RWMutex
+	quota     Quota
+)
+
+// RegisterScanner 注册病毒扫描器, 所有上传文件在保存前扫描, 传入nil取消扫描
+func RegisterScanner(s Scanner) {
+	scannerMu.Lock()
+	defer scannerMu.Unlock()
+	scanner = s
+}
+
+// RegisterQuota 注册存储配额检查, 普通上传、直传和分片上传开始前检查, 传入nil取消检查
+func RegisterQuota(q Quota) {
+	scannerMu.Lock()
+	defer scannerMu.Unlock()
+	quota = q
+}
+
+// CheckQuota 检查当前用户是否还能上传 size 字节, 未注册配额检查时不限制
+func CheckQuota(ctx g.Ctx, size int64) error {
+	scannerMu.RLock()
+	q := quota
+	scannerMu.RUnlock()
+	if q == nil {
+		return nil
+	}
+	return q.Check(ctx, size)
+}
+
+// RuleOf 获取上传场景的校验规则, 场景为空时使用 DefaultScene
+func RuleOf(scene string) (*Rule, error) {
+	if scene == "" {
+		scene = DefaultScene
+	}
+	rule := &Rule{Scene: scene}
+	upload := vconfig.ConfigBinding.Get().File.Upload
+	if upload != nil {
+		if r, ok := upload.Scenes[scene]; ok && r != nil {
+			rule.MaxSize = r.MaxSize
+			rule.ContentTypes = r.ContentTypes
+			rule.Extensions = r.Extensions
+			rule.Profile = r.Profile
+			return rule, nil
+		}
+	}
+	// 未配置默认场景时只拒绝可执行的文件类型
+	if scene == DefaultScene {
+		return rule, nil
+	}
+	return nil, gerror.Newf("未知的上传场景: %s", scene)
+}
+
+// ValidateUpload 读取请求中的上传文件并校验, 返回校验结果和已打开的文件, 调用方负责关闭
+// 上传场景由请求参数 scene 指定
+func ValidateUpload(ctx g.Ctx, field string) (*Upload, multipart.File, error) {
+	request := g.RequestFromCtx(ctx)
+	if request == nil {
+		return nil, nil, gerror.New("上传文件为空")
+	}
+	file := request.GetUploadFile(field)
+	if file == nil {
+		return nil, nil, gerror.New("上传文件为空")
+	}
+	rule, err := RuleOf(request.Get("scene").String())
+	if err != nil {
+		return nil, nil, err
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	upload, err := Validate(ctx, src, file.Filename, file.Size, rule)
+	if err != nil {
+		src.Close()
+		return nil, nil, err
+	}
+	return upload, src, nil
+}
+
+// Validate 校验文件并生成文件键, 校验完成后 reader 重置到开头
+// 依次检查文件大小、扩展名、存储配额、按文件内容识别的类型, 最后调用病毒扫描器
+func Validate(ctx g.Ctx, reader io.ReadSeeker, fileName string, size int64, rule *Rule) (*Upload, error) {
+	if rule == nil {
+		rule = &Rule{Scene: DefaultScene}
+	}
+	if size <= 0 {
+		return nil, gerror.New("上传文件为空")
+	}
+	if rule.MaxSize > 0 && size > rule.MaxSize {
+		return nil, gerror.Newf("文件大小不能超过%d字节", rule.MaxSize)
+	}
+	fileName = SanitizeFileName(fileName)
+	if err := rule.CheckName(fileName); err != nil {
+		return nil, err
+	}
+	if err := CheckQuota(ctx, size); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	contentType, err := rule.detect(head[:n], fileExt(fileName))
+	if err != nil {
+		return nil, err
+	}
+	upload := &Upload{
+		Key:         NewKey(fileName),
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		Scene:       rule.Scene,
+	}
+
+	scannerMu.RLock()
+	s := scanner
+	scannerMu.RUnlock()
+	if s != nil {
+		if _, err = reader.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err = s.Scan(ctx, reader, upload); err != nil {
+			return nil, gerror.Wrap(err, "文件未通过安全扫描")
+		}
+	}
+	if _, err = reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// CheckName 按文件名校验扩展名, 用于无法读取文件内容的直传和分片上传
+func (r *Rule) CheckName(fileName string) error {
+	ext := fileExt(fileName)
+	if len(r.Extensions) > 0 && !r.allowExtension(ext) {
+		return gerror.Newf("不允许上传该扩展名的文件: %s", ext)
+	}
+	if containsFold(activeExtensions, ext) && !r.allowExtension(ext) {
+		return gerror.Newf("不允许上传该扩展名的文件: %s", ext)
+	}
+	return nil
+}
+
+// CheckContentType 按声明的或存储中的文件类型校验, 用于无法读取文件内容的直传和分片上传
+// 浏览器会执行的类型需要在规则中显式允许
+func (r *Rule) CheckContentType(contentType string) error {
+	base := baseContentType(contentType)
+	if containsFold(activeContentTypes, base) && !r.allowContentType(base) {
+		return gerror.Newf("不允许上传该类型的文件: %s", base)
+	}
+	if len(r.ContentTypes) > 0 && !r.allowContentType(contentType) {
+		return gerror.Newf("不允许上传该类型的文件: %s", base)
+	}
+	return nil
+}
+
+// SafeContentType 未声明文件类型时按扩展名确定的类型
+// 扩展名未知或对应浏览器会执行的类型时使用 application/octet-stream
+func SafeContentType(fileName string) string {
+	contentType := mime.TypeByExtension(fileExt(fileName))
+	if contentType == "" || containsFold(activeContentTypes, baseContentType(contentType)) {
+		return "application/octet-stream"
+	}
+	return contentType
+}
+
+// ScanObject 读取存储中的文件并调用病毒扫描器, 用于直传和分片上传完成后扫描, 未注册扫描器时不扫描
+func ScanObject(ctx g.Ctx, d Driver, upload *Upload) error {
+	scannerMu.RLock()
+	s := scanner
+	scannerMu.RUnlock()
+	if s == nil {
+		return nil
+	}
+	reader, _, err := Open(ctx, d, upload.Key)
+	if err != nil {
+		return gerror.Wrap(err, "读取文件进行安全扫描失败")
+	}
+	defer reader.Close()
+	if err = s.Scan(ctx, reader, upload); err != nil {
+		return gerror.Wrap(err, "文件未通过安全扫描")
+	}
+	return nil
+}
+
+// detect 按文件内容识别文件类型并按规则校验
+// 内容无法识别为具体类型时使用扩展名对应的类型
+func (r *Rule) detect(head []byte, ext string) (string, error) {
+	sniffed := http.DetectContentType(head)
+	base := baseContentType(sniffed)
+	extType := mime.TypeByExtension(ext)
+	if containsFold(activeContentTypes, base) && !r.allowContentType(base) {
+		return "", gerror.Newf("不允许上传该类型的文件: %s", base)
+	}
+	// 图片扩展名的文件内容必须是图片, 避免伪装成图片的文件
+	if strings.HasPrefix(extType, "image/") && !strings.HasPrefix(base, "image/") {
+		return "", gerror.Newf("文件内容与扩展名不符: %s", ext)
+	}
+	contentType := sniffed
+	switch base {
+	case "application/octet-stream", "application/zip", "text/plain":
+		if extType != "" && !containsFold(activeContentTypes, baseContentType(extType)) {
+			contentType = extType
+		}
+	}
+	if len(r.ContentTypes) > 0 && !r.allowContentType(contentType) {
+		return "", gerror.Newf("不允许上传该类型的文件: %s", baseContentType(contentType))
+	}
+	return contentType, nil
+}
+
+// allowExtension 扩展名是否在规则中
+func (r *Rule) allowExtension(ext string) bool {
+	for _, allowed := range r.Extensions {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if !strings.HasPrefix(allowed, ".") {
+			allowed = "." + allowed
+		}
+		if allowed == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// allowContentType 文件类型是否在规则中
+func (r *Rule) allowContentType(contentType string) bool {
+	for _, limit := range r.ContentTypes {
+		if limit = strings.TrimSpace(limit); limit != "" && MatchContentType(contentType, limit) {
+			return true
+		}
+	}
+	return false
+}
+
+// SanitizeFileName 清理客户端传入的文件名
+// 去除路径、控制字符和文件系统保留字符, 长度不超过255字节, 结果为空时返回 file
+func SanitizeFileName(fileName string) string {
+	fileName = path.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	fileName = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`<>:"/\|?*`, r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, fileName)
+	fileName = strings.Trim(fileName, " .")
+	for len(fileName) > 255 {
+		_, size := utf8.DecodeLastRuneInString(fileName)
+		fileName = fileName[:len(fileName)-size]
+	}
+	if fileName == "" {
+		return "file"
+	}
+	return fileName
+}
+
+// fileExt 文件名中的扩展名, 转为小写, 只保留字母和数字, 不合法时为空
+func fileExt(fileName string) string {
+	ext := strings.ToLower(path.Ext(SanitizeFileName(fileName)))
+	if len(ext) < 2 || len(ext) > 16 {
+		return ""
+	}
+	for _, c := range ext[1:] {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return ""
+		}
+	}
+	return ext
+}
+
+// baseContentType 去掉参数的文件类型
+func baseContentType(contentType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+}
+
+// containsFold 忽略大小写判断是否包含
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
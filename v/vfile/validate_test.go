@@ -0,0 +1,125 @@
+package vfile
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// pngHeader PNG文件头
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// fakeScanner 内容包含特征串时报告病毒的扫描器
+type fakeScanner struct {
+	scanned []string
+}
+
+func (s *fakeScanner) Scan(ctx g.Ctx, reader io.Reader, upload *Upload) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	s.scanned = append(s.scanned, upload.FileName)
+	if bytes.Contains(content, []byte("EICAR")) {
+		return gerror.New("发现病毒")
+	}
+	return nil
+}
+
+// fakeQuota 剩余 remain 字节的存储配额
+type fakeQuota struct {
+	remain int64
+}
+
+func (q *fakeQuota) Check(ctx g.Ctx, size int64) error {
+	if size > q.remain {
+		return gerror.New("存储空间不足")
+	}
+	return nil
+}
+
+// TestValidate 测试上传文件校验
+func TestValidate(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		image := &Rule{Scene: "image", MaxSize: 100, ContentTypes: []string{"image/"}, Extensions: []string{".png", "jpg"}}
+		validate := func(content []byte, fileName string, rule *Rule) (*Upload, error) {
+			return Validate(ctx, bytes.NewReader(content), fileName, int64(len(content)), rule)
+		}
+
+		upload, err := validate(pngHeader, "../../etc/Avatar.PNG", image)
+		t.AssertNil(err)
+		t.Assert(upload.FileName, "Avatar.PNG")
+		t.Assert(upload.ContentType, "image/png")
+		t.Assert(upload.Scene, "image")
+		t.Assert(strings.HasPrefix(upload.Key, "uploads/"), true)
+		t.Assert(strings.HasSuffix(upload.Key, ".png"), true)
+		t.Assert(strings.Contains(upload.Key, ".."), false)
+
+		// 大小、扩展名、内容类型
+		_, err = validate(bytes.Repeat(pngHeader, 10), "a.png", image)
+		t.AssertNE(err, nil)
+		_, err = validate(pngHeader, "a.gif", image)
+		t.AssertNE(err, nil)
+		_, err = validate([]byte("%PDF-1.4"), "a.jpg", image)
+		t.AssertNE(err, nil)
+		_, err = validate(nil, "a.png", image)
+		t.AssertNE(err, nil)
+
+		// 可执行内容需要显式允许
+		html := []byte("<html><script>alert(1)</script></html>")
+		_, err = validate(html, "a.txt", nil)
+		t.AssertNE(err, nil)
+		_, err = validate([]byte("alert(1)"), "a.js", nil)
+		t.AssertNE(err, nil)
+		upload, err = validate(html, "a.html", &Rule{Extensions: []string{".html"}, ContentTypes: []string{"text/html"}})
+		t.AssertNil(err)
+		t.Assert(upload.ContentType, "text/html; charset=utf-8")
+
+		// 未识别的内容使用扩展名对应的类型
+		upload, err = validate([]byte("%PDF-1.4"), "a.pdf", nil)
+		t.AssertNil(err)
+		t.Assert(upload.ContentType, "application/pdf")
+
+		// 扫描器
+		scanner := &fakeScanner{}
+		RegisterScanner(scanner)
+		defer RegisterScanner(nil)
+		reader := bytes.NewReader([]byte("hello"))
+		upload, err = Validate(ctx, reader, "a.txt", 5, nil)
+		t.AssertNil(err)
+		content, _ := io.ReadAll(reader)
+		t.Assert(string(content), "hello")
+		_, err = validate([]byte("EICAR test"), "b.txt", nil)
+		t.AssertNE(err, nil)
+		t.Assert(scanner.scanned, []string{"a.txt", "b.txt"})
+
+		// 超出存储配额的文件在扫描前拒绝
+		RegisterQuota(&fakeQuota{remain: 5})
+		defer RegisterQuota(nil)
+		_, err = validate([]byte("hello"), "c.txt", nil)
+		t.AssertNil(err)
+		_, err = validate([]byte("hello!"), "d.txt", nil)
+		t.AssertNE(err, nil)
+		t.Assert(scanner.scanned, []string{"a.txt", "b.txt", "c.txt"})
+	})
+}
+
+// TestSanitizeFileName 测试文件名清理
+func TestSanitizeFileName(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(SanitizeFileName(`C:\Users\a\report.pdf`), "report.pdf")
+		t.Assert(SanitizeFileName("a\x00b<>.txt"), "ab.txt")
+		t.Assert(SanitizeFileName(".."), "file")
+		t.Assert(SanitizeFileName(" name. "), "name")
+		t.Assert(len(SanitizeFileName(strings.Repeat("文", 100))) <= 255, true)
+		t.Assert(fileExt("a.p%hp"), "")
+		t.Assert(fileExt("a.TAR.GZ"), ".gz")
+	})
+}
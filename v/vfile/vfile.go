@@ -17,15 +17,19 @@ var (
 	FileMap = map[string]Driver{}
 )
 
+// NewFile 按 v.file.mode 创建文件驱动, 驱动不可用时使用 v.file.fallback
 func NewFile() (d Driver) {
-	if driver, ok := FileMap[vconfig.Config.File.Mode]; ok {
-		return driver.New()
+	// 每次使用最新的配置, 支持运行时切换上传模式
+	d, err := UseOrFallback("")
+	if err == nil {
+		return d
 	}
+	mode := vconfig.ConfigBinding.Get().File.Mode
 	errorMsg := "\n"
 	errorMsg += `无法找到指定文件上传类型 "%s"`
 	errorMsg += `，您是否拼写错误了类型名称 "%s" 或者忘记导入上传支持包？`
 	errorMsg += `参考:https://github.com/vera-byte/vgo/tree/master/contrib/files`
-	err := gerror.Newf(errorMsg, vconfig.Config.File.Mode, vconfig.Config.File.Mode)
+	err = gerror.Wrapf(err, errorMsg, mode, mode)
 
 	panic(err)
 
@@ -0,0 +1,401 @@
+package vfile
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"io"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// WebP无损格式(VP8L)编码
+// 使用减绿色和预测变换, 像素以哈夫曼编码的字面量保存, 不使用回溯引用和颜色缓存
+
+const (
+	webpMaxSize       = 1 << 14 // 宽高上限
+	webpPredictorBits = 4       // 预测变换的块大小为 1<<4 像素
+	webpPredictorMode = 11      // 预测模式: Select
+	webpMaxCodeLength = 15      // 哈夫曼编码长度上限
+	webpMaxCLCLength  = 7       // 编码长度的编码长度上限
+)
+
+// webpCodeLengthOrder 编码长度的编码写入顺序
+var webpCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP 将图片编码为无损WebP
+func EncodeWebP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 || width > webpMaxSize || height > webpMaxSize {
+		return gerror.Newf("WebP不支持该图片尺寸: %dx%d", width, height)
+	}
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok || nrgba.Rect.Min != (image.Point{}) || nrgba.Stride != 4*width {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+	}
+
+	pix := make([]byte, len(nrgba.Pix))
+	copy(pix, nrgba.Pix)
+	alpha := false
+	for i := 3; i < len(pix); i += 4 {
+		if pix[i] != 0xff {
+			alpha = true
+			break
+		}
+	}
+	// 减绿色变换
+	for i := 0; i < len(pix); i += 4 {
+		pix[i] -= pix[i+1]
+		pix[i+2] -= pix[i+1]
+	}
+	residuals := webpPredict(pix, width, height)
+
+	bw := &webpBitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint64(width-1), 14)
+	bw.write(uint64(height-1), 14)
+	if alpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3)
+	// 变换按写入顺序的逆序还原, 先写减绿色再写预测
+	bw.write(1, 1)
+	bw.write(2, 2)
+	bw.write(1, 1)
+	bw.write(0, 2)
+	bw.write(webpPredictorBits-2, 3)
+	tiles := webpTiles(width) * webpTiles(height)
+	modes := make([]byte, 4*tiles)
+	for i := 0; i < len(modes); i += 4 {
+		modes[i+1] = webpPredictorMode
+		modes[i+3] = 0xff
+	}
+	webpWritePixels(bw, modes, false)
+	bw.write(0, 1)
+	webpWritePixels(bw, residuals, true)
+	data := bw.bytes()
+
+	chunkSize := len(data)
+	padding := chunkSize & 1
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+chunkSize+padding))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(chunkSize))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if padding == 1 {
+		data = append(data, 0)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// webpTiles 预测变换每行的块数
+func webpTiles(size int) int {
+	return (size + 1<<webpPredictorBits - 1) >> webpPredictorBits
+}
+
+// webpPredict 计算预测变换后的残差
+// 第一个像素预测为不透明黑色, 第一行使用左侧像素, 第一列使用上方像素
+func webpPredict(pix []byte, width, height int) []byte {
+	out := make([]byte, len(pix))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := 4 * (y*width + x)
+			var predict [4]byte
+			switch {
+			case x == 0 && y == 0:
+				predict = [4]byte{0, 0, 0, 0xff}
+			case y == 0:
+				copy(predict[:], pix[p-4:p])
+			case x == 0:
+				copy(predict[:], pix[p-4*width:p-4*width+4])
+			default:
+				predict = webpSelect(pix[p-4:p], pix[p-4*width:p-4*width+4], pix[p-4*width-4:p-4*width])
+			}
+			for c := 0; c < 4; c++ {
+				out[p+c] = pix[p+c] - predict[c]
+			}
+		}
+	}
+	return out
+}
+
+// webpSelect 预测模式Select, 选择与左上像素梯度更接近的左侧或上方像素
+func webpSelect(l, t, tl []byte) [4]byte {
+	var predictL, predictT int
+	for c := 0; c < 4; c++ {
+		estimate := int(l[c]) + int(t[c]) - int(tl[c])
+		predictL += webpAbs(estimate - int(l[c]))
+		predictT += webpAbs(estimate - int(t[c]))
+	}
+	var out [4]byte
+	if predictL < predictT {
+		copy(out[:], l)
+	} else {
+		copy(out[:], t)
+	}
+	return out
+}
+
+func webpAbs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// webpWritePixels 写入像素, 每个通道使用一个哈夫曼编码
+// main 为主图像时写入不使用分组哈夫曼编码的标记
+func webpWritePixels(bw *webpBitWriter, pix []byte, main bool) {
+	// 不使用颜色缓存
+	bw.write(0, 1)
+	if main {
+		bw.write(0, 1)
+	}
+	// 通道顺序: 绿 红 蓝 透明
+	channels := [4]int{1, 0, 2, 3}
+	alphabets := [5]int{256 + 24, 256, 256, 256, 40}
+	codes := make([]*webpCode, 5)
+	for i := 0; i < 5; i++ {
+		counts := make([]int, alphabets[i])
+		if i < 4 {
+			for p := channels[i]; p < len(pix); p += 4 {
+				counts[pix[p]]++
+			}
+		}
+		codes[i] = newWebpCode(counts, webpMaxCodeLength)
+		codes[i].writeHeader(bw)
+	}
+	for p := 0; p < len(pix); p += 4 {
+		for i, channel := range channels {
+			codes[i].writeSymbol(bw, int(pix[p+channel]))
+		}
+	}
+}
+
+// webpCode 规范哈夫曼编码
+type webpCode struct {
+	lengths []int
+	codes   []uint64
+	symbols []int // 使用的符号
+}
+
+// newWebpCode 按符号出现次数生成长度不超过 limit 的哈夫曼编码
+func newWebpCode(counts []int, limit int) *webpCode {
+	c := &webpCode{lengths: make([]int, len(counts)), codes: make([]uint64, len(counts))}
+	for symbol, count := range counts {
+		if count > 0 {
+			c.symbols = append(c.symbols, symbol)
+		}
+	}
+	switch len(c.symbols) {
+	case 0:
+		return c
+	case 1:
+		c.lengths[c.symbols[0]] = 1
+		return c
+	}
+	// 超过长度上限时提高最小次数, 使编码树更平衡
+	for minCount := 1; ; minCount *= 2 {
+		depths := webpHuffmanDepths(counts, minCount)
+		max := 0
+		for _, depth := range depths {
+			if depth > max {
+				max = depth
+			}
+		}
+		if max <= limit {
+			copy(c.lengths, depths)
+			break
+		}
+	}
+	// 规范编码, 与解码器的分配方式一致
+	var histogram [webpMaxCodeLength + 2]int
+	for _, length := range c.lengths {
+		histogram[length]++
+	}
+	histogram[0] = 0
+	var next [webpMaxCodeLength + 2]uint64
+	code := uint64(0)
+	for length := 1; length < len(next); length++ {
+		code = (code + uint64(histogram[length-1])) << 1
+		next[length] = code
+	}
+	for symbol, length := range c.lengths {
+		if length > 0 {
+			c.codes[symbol] = webpReverse(next[length], length)
+			next[length]++
+		}
+	}
+	return c
+}
+
+// simple 是否使用简单编码, 最多两个小于256的符号
+func (c *webpCode) simple() bool {
+	if len(c.symbols) > 2 {
+		return false
+	}
+	for _, symbol := range c.symbols {
+		if symbol >= 256 {
+			return false
+		}
+	}
+	return true
+}
+
+// writeHeader 写入编码表
+func (c *webpCode) writeHeader(bw *webpBitWriter) {
+	if c.simple() {
+		symbols := c.symbols
+		if len(symbols) == 0 {
+			symbols = []int{0}
+		}
+		bw.write(1, 1)
+		bw.write(uint64(len(symbols)-1), 1)
+		if symbols[0] < 2 {
+			bw.write(0, 1)
+			bw.write(uint64(symbols[0]), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint64(symbols[0]), 8)
+		}
+		if len(symbols) == 2 {
+			bw.write(uint64(symbols[1]), 8)
+		}
+		return
+	}
+	bw.write(0, 1)
+	counts := make([]int, 19)
+	for _, length := range c.lengths {
+		counts[length]++
+	}
+	lengthCode := newWebpCode(counts, webpMaxCLCLength)
+	count := 4
+	for i := len(webpCodeLengthOrder) - 1; i >= 4; i-- {
+		if lengthCode.lengths[webpCodeLengthOrder[i]] > 0 {
+			count = i + 1
+			break
+		}
+	}
+	bw.write(uint64(count-4), 4)
+	for i := 0; i < count; i++ {
+		bw.write(uint64(lengthCode.lengths[webpCodeLengthOrder[i]]), 3)
+	}
+	// 写入全部符号的编码长度
+	bw.write(0, 1)
+	for _, length := range c.lengths {
+		lengthCode.writeSymbol(bw, length)
+	}
+}
+
+// writeSymbol 写入符号, 只有一个符号时不占用位
+func (c *webpCode) writeSymbol(bw *webpBitWriter, symbol int) {
+	if len(c.symbols) <= 1 {
+		return
+	}
+	if c.simple() {
+		if symbol == c.symbols[1] {
+			bw.write(1, 1)
+		} else {
+			bw.write(0, 1)
+		}
+		return
+	}
+	bw.write(c.codes[symbol], uint(c.lengths[symbol]))
+}
+
+// webpHuffmanDepths 计算哈夫曼树中每个符号的深度
+func webpHuffmanDepths(counts []int, minCount int) []int {
+	nodes := &webpNodeHeap{}
+	for symbol, count := range counts {
+		if count > 0 {
+			if count < minCount {
+				count = minCount
+			}
+			*nodes = append(*nodes, &webpNode{weight: count, symbol: symbol})
+		}
+	}
+	heap.Init(nodes)
+	for nodes.Len() > 1 {
+		a := heap.Pop(nodes).(*webpNode)
+		b := heap.Pop(nodes).(*webpNode)
+		heap.Push(nodes, &webpNode{weight: a.weight + b.weight, symbol: -1, left: a, right: b})
+	}
+	depths := make([]int, len(counts))
+	var walk func(n *webpNode, depth int)
+	walk = func(n *webpNode, depth int) {
+		if n.left == nil {
+			depths[n.symbol] = depth
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	walk(heap.Pop(nodes).(*webpNode), 0)
+	return depths
+}
+
+// webpNode 哈夫曼树节点
+type webpNode struct {
+	weight      int
+	symbol      int
+	left, right *webpNode
+}
+
+// webpNodeHeap 按权重排序的最小堆
+type webpNodeHeap []*webpNode
+
+func (h webpNodeHeap) Len() int           { return len(h) }
+func (h webpNodeHeap) Less(i, j int) bool { return h[i].weight < h[j].weight }
+func (h webpNodeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *webpNodeHeap) Push(x any)        { *h = append(*h, x.(*webpNode)) }
+func (h *webpNodeHeap) Pop() any {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// webpReverse 反转编码的位顺序, 哈夫曼编码从高位开始读取, 位流从低位开始写入
+func webpReverse(code uint64, length int) uint64 {
+	var out uint64
+	for i := 0; i < length; i++ {
+		out = out<<1 | code&1
+		code >>= 1
+	}
+	return out
+}
+
+// webpBitWriter 低位优先的位写入器
+type webpBitWriter struct {
+	buf   []byte
+	acc   uint64
+	count uint
+}
+
+func (bw *webpBitWriter) write(value uint64, bits uint) {
+	bw.acc |= value << bw.count
+	bw.count += bits
+	for bw.count >= 8 {
+		bw.buf = append(bw.buf, byte(bw.acc))
+		bw.acc >>= 8
+		bw.count -= 8
+	}
+}
+
+func (bw *webpBitWriter) bytes() []byte {
+	if bw.count > 0 {
+		bw.buf = append(bw.buf, byte(bw.acc))
+		bw.acc, bw.count = 0, 0
+	}
+	return bw.buf
+}
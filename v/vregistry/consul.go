@@ -0,0 +1,134 @@
+package vregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// ConsulConfig Consul服务注册配置
+type ConsulConfig struct {
+	Address string        `json:"address"` // Consul地址，默认 127.0.0.1:8500，可包含协议如 http://127.0.0.1:8500
+	Scheme  string        `json:"scheme"`  // 协议，默认 http
+	Token   string        `json:"token"`   // 访问令牌
+	Timeout time.Duration `json:"timeout"` // 请求超时，默认 10s
+}
+
+// ConsulDriver Consul服务注册驱动
+// 基于agent HTTP API实现，不依赖consul/api包
+type ConsulDriver struct {
+	config  *ConsulConfig
+	baseURL string
+	client  *http.Client
+}
+
+var _ Driver = (*ConsulDriver)(nil)
+
+// NewConsulDriver 创建Consul服务注册驱动
+// config: 驱动配置
+func NewConsulDriver(config *ConsulConfig) *ConsulDriver {
+	if config == nil {
+		config = &ConsulConfig{}
+	}
+	if config.Address == "" {
+		config.Address = "127.0.0.1:8500"
+	}
+	if config.Scheme == "" {
+		config.Scheme = "http"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = time.Second * 10
+	}
+	baseURL := config.Address
+	if !strings.Contains(baseURL, "://") {
+		baseURL = fmt.Sprintf("%s://%s", config.Scheme, config.Address)
+	}
+	return &ConsulDriver{
+		config:  config,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Name 驱动名称
+func (d *ConsulDriver) Name() string {
+	return "consul"
+}
+
+// Register 注册服务实例
+// ctx: 上下文
+// service: 服务实例
+func (d *ConsulDriver) Register(ctx context.Context, service *Service) error {
+	body := g.Map{
+		"ID":      service.ID,
+		"Name":    service.Name,
+		"Address": service.Address,
+		"Port":    service.Port,
+		"Tags":    service.Tags,
+		"Meta":    service.Meta,
+	}
+	if check := service.Check; check != nil {
+		serviceCheck := g.Map{
+			"Interval": check.Interval.String(),
+			"Timeout":  check.Timeout.String(),
+		}
+		if check.HTTP != "" {
+			serviceCheck["HTTP"] = check.HTTP
+		} else {
+			serviceCheck["TCP"] = check.TCP
+		}
+		if check.DeregisterAfter > 0 {
+			serviceCheck["DeregisterCriticalServiceAfter"] = check.DeregisterAfter.String()
+		}
+		body["Check"] = serviceCheck
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	if err = d.put(ctx, "/v1/agent/service/register", data); err != nil {
+		return gerror.Wrapf(err, "failed to register service %s to consul", service.ID)
+	}
+	return nil
+}
+
+// Deregister 注销服务实例
+// ctx: 上下文
+// service: 服务实例
+func (d *ConsulDriver) Deregister(ctx context.Context, service *Service) error {
+	if err := d.put(ctx, "/v1/agent/service/deregister/"+url.PathEscape(service.ID), nil); err != nil {
+		return gerror.Wrapf(err, "failed to deregister service %s from consul", service.ID)
+	}
+	return nil
+}
+
+// put 发送PUT请求
+func (d *ConsulDriver) put(ctx context.Context, path string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.config.Token != "" {
+		req.Header.Set("X-Consul-Token", d.config.Token)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return gerror.Newf("consul returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package vregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+// mockConsulAgent 模拟Consul agent服务注册接口
+type mockConsulAgent struct {
+	mutex    sync.Mutex
+	services map[string]map[string]interface{}
+	tokens   []string
+}
+
+func newMockConsulAgent() (*mockConsulAgent, *httptest.Server) {
+	agent := &mockConsulAgent{services: make(map[string]map[string]interface{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/agent/service/register", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if r.Method != http.MethodPut || json.NewDecoder(r.Body).Decode(&body) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		agent.mutex.Lock()
+		agent.services[body["ID"].(string)] = body
+		agent.tokens = append(agent.tokens, r.Header.Get("X-Consul-Token"))
+		agent.mutex.Unlock()
+	})
+	mux.HandleFunc("/v1/agent/service/deregister/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/")
+		agent.mutex.Lock()
+		defer agent.mutex.Unlock()
+		if _, ok := agent.services[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("Unknown service ID"))
+			return
+		}
+		delete(agent.services, id)
+	})
+	return agent, httptest.NewServer(mux)
+}
+
+// TestConsulDriver 测试Consul服务注册与注销
+func TestConsulDriver(t *testing.T) {
+	agent, server := newMockConsulAgent()
+	defer server.Close()
+
+	gtest.C(t, func(t *gtest.T) {
+		ctx := context.Background()
+		Register("consul", NewConsulDriver(&ConsulConfig{Address: server.URL, Token: "token"}))
+		driver, err := GetDriver("consul")
+		t.AssertNil(err)
+		_, err = GetDriver("none")
+		t.AssertNE(err, nil)
+
+		service := &Service{
+			ID:      "vgo-10.0.0.1-8001",
+			Name:    "vgo",
+			Address: "10.0.0.1",
+			Port:    8001,
+			Tags:    []string{"api"},
+			Check: &Check{
+				HTTP:            "http://10.0.0.1:8001/health",
+				Interval:        time.Second * 10,
+				Timeout:         time.Second * 3,
+				DeregisterAfter: time.Minute,
+			},
+		}
+		t.AssertNil(driver.Register(ctx, service))
+		registered := agent.services[service.ID]
+		t.Assert(registered["Name"], "vgo")
+		t.Assert(registered["Port"], 8001)
+		t.Assert(registered["Check"], map[string]interface{}{
+			"HTTP":                           "http://10.0.0.1:8001/health",
+			"Interval":                       "10s",
+			"Timeout":                        "3s",
+			"DeregisterCriticalServiceAfter": "1m0s",
+		})
+		t.Assert(agent.tokens, []string{"token"})
+
+		service.Check = &Check{TCP: "10.0.0.1:8001", Interval: time.Second, Timeout: time.Second}
+		t.AssertNil(driver.Register(ctx, service))
+		t.Assert(agent.services[service.ID]["Check"], map[string]interface{}{
+			"TCP":      "10.0.0.1:8001",
+			"Interval": "1s",
+			"Timeout":  "1s",
+		})
+
+		t.AssertNil(driver.Deregister(ctx, service))
+		t.Assert(len(agent.services), 0)
+		t.AssertNE(driver.Deregister(ctx, service), nil)
+	})
+}
@@ -0,0 +1,64 @@
+// Package vregistry 服务注册
+// 功能: 启动时将HTTP服务注册到注册中心并附带健康检查, 停止时注销
+// 注册中心由可插拔的驱动完成, 内置 consul 驱动
+package vregistry
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// Driver 服务注册驱动
+type Driver interface {
+	// Name 驱动名称
+	Name() string
+	// Register 注册服务实例, 已存在相同ID的实例时覆盖
+	Register(ctx context.Context, service *Service) error
+	// Deregister 注销服务实例
+	Deregister(ctx context.Context, service *Service) error
+}
+
+// Service 服务实例
+type Service struct {
+	ID      string            `json:"id"`      // 实例ID, 同一服务内唯一
+	Name    string            `json:"name"`    // 服务名称
+	Address string            `json:"address"` // 服务地址
+	Port    int               `json:"port"`    // 服务端口
+	Tags    []string          `json:"tags"`    // 标签
+	Meta    map[string]string `json:"meta"`    // 元数据
+	Check   *Check            `json:"check"`   // 健康检查, 为nil时不检查
+}
+
+// Check 健康检查
+// HTTP 不为空时使用HTTP检查, 否则检查 TCP 端口
+type Check struct {
+	HTTP            string        `json:"http"`            // HTTP检查地址, 返回2xx为健康
+	TCP             string        `json:"tcp"`             // TCP检查地址 host:port
+	Interval        time.Duration `json:"interval"`        // 检查间隔
+	Timeout         time.Duration `json:"timeout"`         // 检查超时
+	DeregisterAfter time.Duration `json:"deregisterAfter"` // 持续不健康超过该时间后自动注销, 为0时不自动注销
+}
+
+var (
+	// DriverMap 已注册的服务注册驱动
+	DriverMap = map[string]Driver{}
+)
+
+// Register 注册服务注册驱动
+func Register(name string, driver Driver) error {
+	DriverMap[name] = driver
+	return nil
+}
+
+// GetDriver 获取已注册的服务注册驱动
+func GetDriver(name string) (Driver, error) {
+	if driver, ok := DriverMap[name]; ok {
+		return driver, nil
+	}
+	errorMsg := "\n"
+	errorMsg += `无法找到指定的服务注册驱动 "%s"`
+	errorMsg += `，您是否拼写错误了驱动名称 "%s" 或者忘记导入驱动支持包？`
+	return nil, gerror.Newf(errorMsg, name, name)
+}